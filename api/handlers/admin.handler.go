@@ -0,0 +1,645 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"todo-api/internal/dtos"
+	"todo-api/internal/services"
+	"todo-api/internal/utils"
+
+	"go.uber.org/zap"
+)
+
+type AdminHandler struct {
+	BaseHandler
+	service *services.AdminService
+}
+
+func NewAdminHandler(logger *zap.Logger) *AdminHandler {
+	return &AdminHandler{
+		BaseHandler: BaseHandler{
+			Logger: logger,
+		},
+		service: services.NewAdminService(logger),
+	}
+}
+
+// @Summary List roles
+// @Description List every role with the permissions it grants
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dtos.StructuredResponse "Roles retrieved successfully"
+// @Failure 401 {object} dtos.StructuredResponse "Unauthorized"
+// @Failure 403 {object} dtos.StructuredResponse "Forbidden"
+// @Failure 500 {object} dtos.StructuredResponse "Internal server error"
+// @Router /admin/roles [get]
+func (h *AdminHandler) GetRoles(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("GetRoles request received")
+
+	response, err := h.service.GetRoles(r.Context())
+
+	if err != nil {
+		h.Logger.Error("Failed to get roles", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	h.ReturnJSONResponse(w, response)
+}
+
+// @Summary Set the roles of a user
+// @Description Replace the roles of a user. Removing a role logs the user out so the change applies immediately.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param roles body dtos.SetUserRolesDto true "Roles to assign"
+// @Success 200 {object} dtos.StructuredResponse{payload=dtos.UserRolesDto} "Roles updated successfully"
+// @Failure 400 {object} dtos.StructuredResponse "Unknown role"
+// @Failure 401 {object} dtos.StructuredResponse "Unauthorized"
+// @Failure 403 {object} dtos.StructuredResponse "Forbidden"
+// @Failure 404 {object} dtos.StructuredResponse "User not found"
+// @Failure 409 {object} dtos.StructuredResponse "Cannot remove the last administrator"
+// @Failure 500 {object} dtos.StructuredResponse "Internal server error"
+// @Router /admin/users/{id}/roles [put]
+func (h *AdminHandler) SetUserRoles(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("SetUserRoles request received")
+
+	userID, ok := h.ParseIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req dtos.SetUserRolesDto
+
+	if !h.DecodeJSONBody(w, r, &req) {
+		return
+	}
+
+	req.UserID = userID
+
+	response, err := h.service.SetUserRoles(r.Context(), req)
+
+	if err != nil {
+		h.Logger.Error("Failed to set user roles", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	h.ReturnJSONResponse(w, response)
+}
+
+// @Summary List users
+// @Description List users ordered by ID, one page at a time. The search matches email addresses and names, ignoring case.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param search query string false "Text to look for in email and name"
+// @Param page query int false "Page number, starting at 1" default(1)
+// @Param pageSize query int false "Users per page, at most 100" default(20)
+// @Success 200 {object} dtos.StructuredResponse{payload=dtos.UserListDto} "Users retrieved successfully"
+// @Failure 400 {object} dtos.StructuredResponse "Invalid page or pageSize"
+// @Failure 401 {object} dtos.StructuredResponse "Unauthorized"
+// @Failure 403 {object} dtos.StructuredResponse "Forbidden"
+// @Failure 500 {object} dtos.StructuredResponse "Internal server error"
+// @Router /admin/users [get]
+func (h *AdminHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("ListUsers request received")
+
+	page, pageSize, ok := h.ParsePageParams(w, r)
+	if !ok {
+		return
+	}
+
+	req := dtos.ListUsersDto{
+		Search:   r.URL.Query().Get("search"),
+		Page:     page,
+		PageSize: pageSize,
+	}
+
+	response, err := h.service.ListUsers(r.Context(), req)
+
+	if err != nil {
+		h.Logger.Error("Failed to list users", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	h.ReturnJSONResponse(w, response)
+}
+
+// @Summary Create a user
+// @Description Create an account with the user role, whatever REGISTRATION_MODE and REGISTRATION_ALLOWED_DOMAINS allow. With a password the user is sent the usual verification email, without one they are emailed a link to choose a password, which also verifies their address.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param user body dtos.CreateUserDto true "Account to create"
+// @Success 201 {object} dtos.StructuredResponse{payload=dtos.AdminUserDto} "User created successfully"
+// @Failure 400 {object} dtos.StructuredResponse "Invalid request body or password does not meet the policy"
+// @Failure 401 {object} dtos.StructuredResponse "Unauthorized"
+// @Failure 403 {object} dtos.StructuredResponse "Forbidden"
+// @Failure 409 {object} dtos.StructuredResponse "Email already registered"
+// @Failure 500 {object} dtos.StructuredResponse "Internal server error"
+// @Router /admin/users [post]
+func (h *AdminHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("CreateUser request received")
+
+	var req dtos.CreateUserDto
+
+	if !h.DecodeJSONBody(w, r, &req) {
+		return
+	}
+
+	claims, err := utils.GetClaimsFromContext(r.Context())
+	if err != nil {
+		h.Logger.Error("Failed to get claims from context", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	req.ActorID = claims.UserID
+
+	response, err := h.service.CreateUser(r.Context(), req)
+
+	if err != nil {
+		h.Logger.Error("Failed to create user", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	h.ReturnJSONResponse(w, response)
+}
+
+// @Summary Get a user
+// @Description Return a user with their status, roles and todo counts
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} dtos.StructuredResponse{payload=dtos.AdminUserDto} "User retrieved successfully"
+// @Failure 401 {object} dtos.StructuredResponse "Unauthorized"
+// @Failure 403 {object} dtos.StructuredResponse "Forbidden"
+// @Failure 404 {object} dtos.StructuredResponse "User not found"
+// @Failure 500 {object} dtos.StructuredResponse "Internal server error"
+// @Router /admin/users/{id} [get]
+func (h *AdminHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("GetUser request received")
+
+	userID, ok := h.ParseIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	response, err := h.service.GetUser(r.Context(), userID)
+
+	if err != nil {
+		h.Logger.Error("Failed to get user", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	h.ReturnJSONResponse(w, response)
+}
+
+// @Summary List a user's security events
+// @Description Return the logins, password changes, token changes and other security events of a user, newest first. Events older than SECURITY_EVENT_RETENTION are not kept.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Param type query string false "Only return events of this type, e.g. login.failed"
+// @Param page query int false "Page number, starting at 1" default(1)
+// @Param pageSize query int false "Events per page, at most 100" default(20)
+// @Success 200 {object} dtos.StructuredResponse{payload=dtos.SecurityEventListDto} "Security events retrieved successfully"
+// @Failure 400 {object} dtos.StructuredResponse "Invalid page or pageSize"
+// @Failure 401 {object} dtos.StructuredResponse "Unauthorized"
+// @Failure 403 {object} dtos.StructuredResponse "Forbidden"
+// @Failure 500 {object} dtos.StructuredResponse "Internal server error"
+// @Router /admin/users/{id}/security-events [get]
+func (h *AdminHandler) GetUserSecurityEvents(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("GetUserSecurityEvents request received")
+
+	userID, ok := h.ParseIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	page, pageSize, ok := h.ParsePageParams(w, r)
+	if !ok {
+		return
+	}
+
+	response, err := h.service.GetUserSecurityEvents(r.Context(), dtos.ListSecurityEventsDto{
+		UserID:   userID,
+		Type:     r.URL.Query().Get("type"),
+		Page:     page,
+		PageSize: pageSize,
+	})
+
+	if err != nil {
+		h.Logger.Error("Failed to get security events", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	h.ReturnJSONResponse(w, response)
+}
+
+// @Summary Disable a user
+// @Description Block the account: the user is logged out everywhere, cannot log in and their personal access tokens stop working until the account is enabled again
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} dtos.StructuredResponse{payload=dtos.AdminUserDto} "User disabled successfully"
+// @Failure 401 {object} dtos.StructuredResponse "Unauthorized"
+// @Failure 403 {object} dtos.StructuredResponse "Forbidden"
+// @Failure 404 {object} dtos.StructuredResponse "User not found"
+// @Failure 409 {object} dtos.StructuredResponse "Cannot disable your own account"
+// @Failure 500 {object} dtos.StructuredResponse "Internal server error"
+// @Router /admin/users/{id}/disable [post]
+func (h *AdminHandler) DisableUser(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("DisableUser request received")
+	h.handleUserAction(w, r, "disable user", h.service.DisableUser)
+}
+
+// @Summary Enable a user
+// @Description Let a disabled user log in again
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} dtos.StructuredResponse{payload=dtos.AdminUserDto} "User enabled successfully"
+// @Failure 401 {object} dtos.StructuredResponse "Unauthorized"
+// @Failure 403 {object} dtos.StructuredResponse "Forbidden"
+// @Failure 404 {object} dtos.StructuredResponse "User not found"
+// @Failure 500 {object} dtos.StructuredResponse "Internal server error"
+// @Router /admin/users/{id}/enable [post]
+func (h *AdminHandler) EnableUser(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("EnableUser request received")
+	h.handleUserAction(w, r, "enable user", h.service.EnableUser)
+}
+
+// @Summary Reset a user's password
+// @Description Remove the user's password, log them out everywhere and email them a link to choose a new one
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} dtos.StructuredResponse "Password reset successfully"
+// @Failure 401 {object} dtos.StructuredResponse "Unauthorized"
+// @Failure 403 {object} dtos.StructuredResponse "Forbidden"
+// @Failure 404 {object} dtos.StructuredResponse "User not found"
+// @Failure 500 {object} dtos.StructuredResponse "Internal server error"
+// @Router /admin/users/{id}/reset-password [post]
+func (h *AdminHandler) ResetUserPassword(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("ResetUserPassword request received")
+	h.handleUserAction(w, r, "reset password", h.service.ResetUserPassword)
+}
+
+// @Summary Log a user out
+// @Description End every session of the user and invalidate all of their access tokens
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} dtos.StructuredResponse "User logged out from all devices"
+// @Failure 401 {object} dtos.StructuredResponse "Unauthorized"
+// @Failure 403 {object} dtos.StructuredResponse "Forbidden"
+// @Failure 404 {object} dtos.StructuredResponse "User not found"
+// @Failure 500 {object} dtos.StructuredResponse "Internal server error"
+// @Router /admin/users/{id}/logout [post]
+func (h *AdminHandler) ForceLogout(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("ForceLogout request received")
+	h.handleUserAction(w, r, "log out user", h.service.ForceLogout)
+}
+
+// @Summary Impersonate a user
+// @Description Issue a short-lived access token to act as the user. The token records the administrator in its act claim, every request made with it is logged, it cannot be refreshed and cannot be used for account management. Administrators cannot be impersonated.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} dtos.StructuredResponse{payload=dtos.ImpersonationTokenDto} "Impersonation token issued"
+// @Failure 400 {object} dtos.StructuredResponse "Cannot impersonate yourself"
+// @Failure 401 {object} dtos.StructuredResponse "Unauthorized"
+// @Failure 403 {object} dtos.StructuredResponse "Forbidden or the user is an administrator"
+// @Failure 404 {object} dtos.StructuredResponse "User not found"
+// @Failure 409 {object} dtos.StructuredResponse "Account has been disabled"
+// @Failure 500 {object} dtos.StructuredResponse "Internal server error"
+// @Router /admin/users/{id}/impersonate [post]
+func (h *AdminHandler) ImpersonateUser(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("ImpersonateUser request received")
+	h.handleUserAction(w, r, "impersonate user", h.service.ImpersonateUser)
+}
+
+// @Summary Create an invite code
+// @Description Create a code that lets up to maxUses people register while REGISTRATION_MODE is invite. The code is only returned in this response.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param inviteCode body dtos.CreateInviteCodeDto true "Invite code settings"
+// @Success 201 {object} dtos.StructuredResponse{payload=dtos.CreatedInviteCodeDto} "Invite code created successfully"
+// @Failure 400 {object} dtos.StructuredResponse "Invalid maxUses or expiresInDays"
+// @Failure 401 {object} dtos.StructuredResponse "Unauthorized"
+// @Failure 403 {object} dtos.StructuredResponse "Forbidden"
+// @Failure 500 {object} dtos.StructuredResponse "Internal server error"
+// @Router /admin/invite-codes [post]
+func (h *AdminHandler) CreateInviteCode(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("CreateInviteCode request received")
+
+	var req dtos.CreateInviteCodeDto
+
+	if !h.DecodeJSONBody(w, r, &req) {
+		return
+	}
+
+	claims, err := utils.GetClaimsFromContext(r.Context())
+	if err != nil {
+		h.Logger.Error("Failed to get claims from context", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	req.CreatedByID = claims.UserID
+
+	response, err := h.service.CreateInviteCode(r.Context(), req)
+
+	if err != nil {
+		h.Logger.Error("Failed to create invite code", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	h.ReturnJSONResponse(w, response)
+}
+
+// @Summary List invite codes
+// @Description List every invite code, newest first, with how often it was used. The codes themselves are not returned.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dtos.StructuredResponse{payload=[]dtos.InviteCodeDto} "Invite codes retrieved successfully"
+// @Failure 401 {object} dtos.StructuredResponse "Unauthorized"
+// @Failure 403 {object} dtos.StructuredResponse "Forbidden"
+// @Failure 500 {object} dtos.StructuredResponse "Internal server error"
+// @Router /admin/invite-codes [get]
+func (h *AdminHandler) GetInviteCodes(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("GetInviteCodes request received")
+
+	response, err := h.service.GetInviteCodes(r.Context())
+
+	if err != nil {
+		h.Logger.Error("Failed to get invite codes", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	h.ReturnJSONResponse(w, response)
+}
+
+// @Summary Revoke an invite code
+// @Description Stop an invite code from registering further accounts. Accounts already created with it are kept.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Invite code ID"
+// @Success 200 {object} dtos.StructuredResponse "Invite code revoked successfully"
+// @Failure 401 {object} dtos.StructuredResponse "Unauthorized"
+// @Failure 403 {object} dtos.StructuredResponse "Forbidden"
+// @Failure 404 {object} dtos.StructuredResponse "Invite code not found"
+// @Failure 500 {object} dtos.StructuredResponse "Internal server error"
+// @Router /admin/invite-codes/{id} [delete]
+func (h *AdminHandler) RevokeInviteCode(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("RevokeInviteCode request received")
+
+	inviteCodeID, ok := h.ParseIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	response, err := h.service.RevokeInviteCode(r.Context(), inviteCodeID)
+
+	if err != nil {
+		h.Logger.Error("Failed to revoke invite code", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	h.ReturnJSONResponse(w, response)
+}
+
+// @Summary Register an OAuth2 app
+// @Description Register a third-party app with the OAuth2 authorization server. Confidential apps get a client secret, which is only returned in this response. Redirect URIs must use https, or http on localhost.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param client body dtos.CreateOAuthClientDto true "App to register"
+// @Success 201 {object} dtos.StructuredResponse{payload=dtos.CreatedOAuthClientDto} "App registered successfully"
+// @Failure 400 {object} dtos.StructuredResponse "Invalid redirect URI or scope"
+// @Failure 401 {object} dtos.StructuredResponse "Unauthorized"
+// @Failure 403 {object} dtos.StructuredResponse "Forbidden"
+// @Failure 500 {object} dtos.StructuredResponse "Internal server error"
+// @Router /admin/oauth-clients [post]
+func (h *AdminHandler) CreateOAuthClient(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("CreateOAuthClient request received")
+
+	var req dtos.CreateOAuthClientDto
+
+	if !h.DecodeJSONBody(w, r, &req) {
+		return
+	}
+
+	claims, err := utils.GetClaimsFromContext(r.Context())
+	if err != nil {
+		h.Logger.Error("Failed to get claims from context", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	req.CreatedByID = claims.UserID
+
+	response, err := h.service.CreateOAuthClient(r.Context(), req)
+
+	if err != nil {
+		h.Logger.Error("Failed to register OAuth client", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	h.ReturnJSONResponse(w, response)
+}
+
+// @Summary List OAuth2 apps
+// @Description List every registered app, newest first. Client secrets are not returned.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dtos.StructuredResponse{payload=[]dtos.OAuthClientDto} "Apps retrieved successfully"
+// @Failure 401 {object} dtos.StructuredResponse "Unauthorized"
+// @Failure 403 {object} dtos.StructuredResponse "Forbidden"
+// @Failure 500 {object} dtos.StructuredResponse "Internal server error"
+// @Router /admin/oauth-clients [get]
+func (h *AdminHandler) GetOAuthClients(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("GetOAuthClients request received")
+
+	response, err := h.service.GetOAuthClients(r.Context())
+
+	if err != nil {
+		h.Logger.Error("Failed to get OAuth clients", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	h.ReturnJSONResponse(w, response)
+}
+
+// @Summary Delete an OAuth2 app
+// @Description Delete an app together with its pending authorization codes and every token issued to it
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "App ID"
+// @Success 200 {object} dtos.StructuredResponse "App deleted successfully"
+// @Failure 401 {object} dtos.StructuredResponse "Unauthorized"
+// @Failure 403 {object} dtos.StructuredResponse "Forbidden"
+// @Failure 404 {object} dtos.StructuredResponse "App not found"
+// @Failure 500 {object} dtos.StructuredResponse "Internal server error"
+// @Router /admin/oauth-clients/{id} [delete]
+func (h *AdminHandler) DeleteOAuthClient(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("DeleteOAuthClient request received")
+
+	clientID, ok := h.ParseIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	response, err := h.service.DeleteOAuthClient(r.Context(), clientID)
+
+	if err != nil {
+		h.Logger.Error("Failed to delete OAuth client", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	h.ReturnJSONResponse(w, response)
+}
+
+// handleUserAction runs an action on the user named in the path on behalf of the current administrator
+func (h *AdminHandler) handleUserAction(w http.ResponseWriter, r *http.Request, description string, action func(ctx context.Context, actionDto dtos.AdminUserActionDto) (dtos.StructuredResponse, error)) {
+	userID, ok := h.ParseIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	claims, err := utils.GetClaimsFromContext(r.Context())
+	if err != nil {
+		h.Logger.Error("Failed to get claims from context", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	response, err := action(r.Context(), dtos.AdminUserActionDto{
+		UserID:     userID,
+		ActorID:    claims.UserID,
+		ActorEmail: claims.Email,
+	})
+
+	if err != nil {
+		h.Logger.Error("Failed to "+description, zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	h.ReturnJSONResponse(w, response)
+}
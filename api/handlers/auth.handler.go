@@ -2,8 +2,11 @@ package handlers
 
 import (
 	"net/http"
+	"strings"
+	"todo-api/config"
 	"todo-api/internal/dtos"
 	"todo-api/internal/services"
+	"todo-api/internal/utils"
 
 	"go.uber.org/zap"
 )
@@ -23,12 +26,15 @@ func NewAuthHandler(logger *zap.Logger) *AuthHandler {
 }
 
 // @Summary Register a new user
-// @Description Register a new user with the provided details
+// @Description Register a new user with the provided details. The account starts unverified and a verification link is emailed to the user. Depending on REGISTRATION_MODE an invite code is required or registration is closed.
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param user body dtos.RegisterUserDto true "User registration data"
 // @Success 201 {object} dtos.StructuredResponse "User registered successfully"
+// @Failure 400 {object} dtos.StructuredResponse{payload=dtos.ValidationErrorsDto} "Invalid fields or password does not meet the policy"
+// @Failure 403 {object} dtos.StructuredResponse "Registration is closed, needs a valid invite code or the email domain is not allowed"
+// @Failure 409 {object} dtos.StructuredResponse{payload=dtos.ValidationErrorsDto} "Email address is already registered"
 // @Failure 500 {object} dtos.StructuredResponse "Internal server error"
 // @Router /auth/register [post]
 func (h *AuthHandler) RegisterUser(w http.ResponseWriter, r *http.Request) {
@@ -59,13 +65,16 @@ func (h *AuthHandler) RegisterUser(w http.ResponseWriter, r *http.Request) {
 }
 
 // @Summary Login a user
-// @Description Login a user with the provided credentials
+// @Description Login a user with the provided credentials. When two-factor authentication is enabled the payload is a challenge to complete at /auth/login/2fa instead of tokens.
 // @Tags auth
 // @Accept json
 // @Produce json
+// @Param X-Session-Mode header string false "Send cookie to receive the tokens as HttpOnly cookies instead of in the body"
 // @Param user body dtos.LoginUserDto true "User login data"
-// @Success 200 {object} dtos.StructuredResponse "User logged in successfully"
+// @Success 200 {object} dtos.StructuredResponse{payload=dtos.AuthTokensDto} "User logged in successfully, or dtos.TwoFactorChallengeDto when two-factor authentication is enabled"
 // @Failure 401 {object} dtos.StructuredResponse "Invalid credentials"
+// @Failure 403 {object} dtos.StructuredResponse "Email address has not been verified"
+// @Failure 429 {object} dtos.StructuredResponse{payload=dtos.RateLimitedDto} "Too many failed login attempts"
 // @Failure 500 {object} dtos.StructuredResponse "Internal server error"
 // @Router /auth/login [post]
 func (h *AuthHandler) LoginUser(w http.ResponseWriter, r *http.Request) {
@@ -77,6 +86,9 @@ func (h *AuthHandler) LoginUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	req.IPAddress = utils.GetClientIP(r)
+	req.UserAgent = r.UserAgent()
+
 	h.Logger.Debug("Logging in user", zap.String("email", req.Email))
 
 	response, err := h.service.LoginUser(r.Context(), req)
@@ -92,5 +104,1002 @@ func (h *AuthHandler) LoginUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	h.ReturnAuthResponse(w, response, utils.UsesSessionCookies(r))
+}
+
+// @Summary Refresh an access token
+// @Description Exchange a refresh token for a new access token and refresh token. Each refresh token can only be used once; replaying a used token revokes every token issued from the same login.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param X-Session-Mode header string false "Send cookie to receive the tokens as HttpOnly cookies instead of in the body"
+// @Param token body dtos.RefreshTokenDto false "Refresh token, left out in cookie session mode"
+// @Success 200 {object} dtos.StructuredResponse{payload=dtos.AuthTokensDto} "Token refreshed successfully"
+// @Failure 401 {object} dtos.StructuredResponse "Invalid refresh token"
+// @Failure 403 {object} dtos.StructuredResponse "Refresh cookie sent without a valid CSRF token"
+// @Failure 500 {object} dtos.StructuredResponse "Internal server error"
+// @Router /auth/refresh [post]
+func (h *AuthHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Refresh token request received")
+
+	var req dtos.RefreshTokenDto
+
+	useCookies := utils.UsesSessionCookies(r)
+	refreshCookie, cookieErr := r.Cookie(utils.RefreshTokenCookie)
+	fromCookie := useCookies && cookieErr == nil && r.ContentLength == 0
+
+	if fromCookie {
+		// Browsers attach the cookie to any request, so the repository checks the CSRF token
+		// against the session of the refresh token before using it
+		req.RefreshToken = refreshCookie.Value
+		req.FromCookie = true
+		req.CSRFToken = r.Header.Get(utils.CSRFHeader)
+	} else if !h.DecodeJSONBody(w, r, &req) {
+		return
+	}
+
+	req.IPAddress = utils.GetClientIP(r)
+	req.UserAgent = r.UserAgent()
+
+	response, err := h.service.RefreshToken(r.Context(), req)
+
+	if err != nil {
+		h.Logger.Error("Failed to refresh token", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	// A rejected refresh cookie is of no further use, stop the browser from sending it
+	if fromCookie && response.Status == http.StatusUnauthorized {
+		utils.ClearSessionCookies(w)
+	}
+
+	h.ReturnAuthResponse(w, response, useCookies)
+}
+
+// @Summary Logout
+// @Description Revoke the access token used for this request and end its session, which revokes the session's refresh tokens. If a refresh token is sent, its whole token family is revoked as well.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param token body dtos.LogoutDto false "Refresh token to revoke"
+// @Success 200 {object} dtos.StructuredResponse "Logged out successfully"
+// @Failure 401 {object} dtos.StructuredResponse "Unauthorized"
+// @Failure 500 {object} dtos.StructuredResponse "Internal server error"
+// @Router /auth/logout [post]
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Logout request received")
+
+	var req dtos.LogoutDto
+
+	// The body is optional, a bare POST only revokes the access token
+	if r.ContentLength != 0 && !h.DecodeJSONBody(w, r, &req) {
+		return
+	}
+
+	if cookie, err := r.Cookie(utils.RefreshTokenCookie); err == nil && req.RefreshToken == "" {
+		req.RefreshToken = cookie.Value
+	}
+
+	response, err := h.service.Logout(r.Context(), req)
+
+	if err != nil {
+		h.Logger.Error("Failed to logout", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	if response.Success {
+		h.clearSessionCookies(w, r)
+	}
+
+	h.ReturnJSONResponse(w, response)
+}
+
+// @Summary Logout from all devices
+// @Description Invalidate every access token and refresh token issued to the current user before now
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dtos.StructuredResponse "Logged out from all devices successfully"
+// @Failure 401 {object} dtos.StructuredResponse "Unauthorized"
+// @Failure 500 {object} dtos.StructuredResponse "Internal server error"
+// @Router /auth/logout-all [post]
+func (h *AuthHandler) LogoutAll(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Logout all request received")
+
+	response, err := h.service.LogoutAll(r.Context())
+
+	if err != nil {
+		h.Logger.Error("Failed to logout from all devices", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	if response.Success {
+		h.clearSessionCookies(w, r)
+	}
+
+	h.ReturnJSONResponse(w, response)
+}
+
+// clearSessionCookies logs the browser out of the cookie session mode when it was using it
+func (h *AuthHandler) clearSessionCookies(w http.ResponseWriter, r *http.Request) {
+	if _, err := r.Cookie(utils.AccessTokenCookie); err == nil {
+		utils.ClearSessionCookies(w)
+	}
+}
+
+// @Summary Request a password reset
+// @Description Email a single-use password reset link. The response is the same whether or not the email belongs to an account. Requests are limited per email address and per client IP.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param user body dtos.ForgotPasswordDto true "Account email"
+// @Success 200 {object} dtos.StructuredResponse "Password reset link sent if the account exists"
+// @Failure 400 {object} dtos.StructuredResponse "Invalid request body"
+// @Failure 429 {object} dtos.StructuredResponse{payload=dtos.RateLimitedDto} "Too many password reset links requested"
+// @Failure 500 {object} dtos.StructuredResponse "Internal server error"
+// @Router /auth/forgot-password [post]
+func (h *AuthHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Forgot password request received")
+
+	var req dtos.ForgotPasswordDto
+
+	if !h.DecodeJSONBody(w, r, &req) {
+		return
+	}
+
+	req.IPAddress = utils.GetClientIP(r)
+
+	response, err := h.service.ForgotPassword(r.Context(), req)
+
+	if err != nil {
+		h.Logger.Error("Failed to process forgot password request", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	h.ReturnJSONResponse(w, response)
+}
+
+// @Summary Reset a password
+// @Description Set a new password using the token from a password reset email. The token can only be used once and every existing session is logged out.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param user body dtos.ResetPasswordDto true "Reset token and new password"
+// @Success 200 {object} dtos.StructuredResponse "Password has been reset successfully"
+// @Failure 400 {object} dtos.StructuredResponse "Invalid or expired password reset token, or the password does not meet the policy"
+// @Failure 500 {object} dtos.StructuredResponse "Internal server error"
+// @Router /auth/reset-password [post]
+func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Reset password request received")
+
+	var req dtos.ResetPasswordDto
+
+	if !h.DecodeJSONBody(w, r, &req) {
+		return
+	}
+
+	req.IPAddress = utils.GetClientIP(r)
+	req.UserAgent = r.UserAgent()
+
+	response, err := h.service.ResetPassword(r.Context(), req)
+
+	if err != nil {
+		h.Logger.Error("Failed to reset password", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	h.ReturnJSONResponse(w, response)
+}
+
+// @Summary Verify an email address
+// @Description Confirm the user's email address with the signed token from the verification email
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param token body dtos.VerifyEmailDto true "Verification token"
+// @Success 200 {object} dtos.StructuredResponse "Email verified successfully"
+// @Failure 400 {object} dtos.StructuredResponse "Invalid or expired verification link"
+// @Failure 500 {object} dtos.StructuredResponse "Internal server error"
+// @Router /auth/verify-email [post]
+func (h *AuthHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Verify email request received")
+
+	var req dtos.VerifyEmailDto
+
+	if !h.DecodeJSONBody(w, r, &req) {
+		return
+	}
+
+	response, err := h.service.VerifyEmail(r.Context(), req)
+
+	if err != nil {
+		h.Logger.Error("Failed to verify email", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	h.ReturnJSONResponse(w, response)
+}
+
+// @Summary Confirm a new email address
+// @Description Switch the account to the new email address with the token from the link sent by PUT /me/email. The new address counts as verified.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param token body dtos.ConfirmEmailChangeDto true "Confirmation token"
+// @Success 200 {object} dtos.StructuredResponse "Email changed successfully"
+// @Failure 400 {object} dtos.StructuredResponse "Invalid or expired confirmation link"
+// @Failure 409 {object} dtos.StructuredResponse "Email address is already in use"
+// @Failure 500 {object} dtos.StructuredResponse "Internal server error"
+// @Router /auth/confirm-email-change [post]
+func (h *AuthHandler) ConfirmEmailChange(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Confirm email change request received")
+
+	var req dtos.ConfirmEmailChangeDto
+
+	if !h.DecodeJSONBody(w, r, &req) {
+		return
+	}
+
+	req.IPAddress = utils.GetClientIP(r)
+	req.UserAgent = r.UserAgent()
+
+	response, err := h.service.ConfirmEmailChange(r.Context(), req)
+
+	if err != nil {
+		h.Logger.Error("Failed to confirm email change", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	h.ReturnJSONResponse(w, response)
+}
+
+// @Summary Resend the verification email
+// @Description Send a new verification link. The response is the same whether or not the email belongs to an unverified account.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param user body dtos.ResendVerificationDto true "Account email"
+// @Success 200 {object} dtos.StructuredResponse "Verification link sent if the account exists"
+// @Failure 400 {object} dtos.StructuredResponse "Invalid request body"
+// @Router /auth/resend-verification [post]
+func (h *AuthHandler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Resend verification request received")
+
+	var req dtos.ResendVerificationDto
+
+	if !h.DecodeJSONBody(w, r, &req) {
+		return
+	}
+
+	response, err := h.service.ResendVerification(r.Context(), req)
+
+	if err != nil {
+		h.Logger.Error("Failed to resend verification email", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	h.ReturnJSONResponse(w, response)
+}
+
+// @Summary Create a personal access token
+// @Description Create a named API key for scripts and CI. It is sent as "Authorization: Bearer tdp_..." and can be limited to scopes and given an expiry. The token is only returned once.
+// @Tags tokens
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param token body dtos.CreatePersonalAccessTokenDto true "Token settings"
+// @Success 201 {object} dtos.StructuredResponse{payload=dtos.CreatedPersonalAccessTokenDto} "Token created successfully"
+// @Failure 400 {object} dtos.StructuredResponse "Invalid scope"
+// @Failure 401 {object} dtos.StructuredResponse "Unauthorized"
+// @Failure 403 {object} dtos.StructuredResponse "Personal access tokens cannot create tokens"
+// @Failure 500 {object} dtos.StructuredResponse "Internal server error"
+// @Router /auth/tokens [post]
+func (h *AuthHandler) CreatePersonalAccessToken(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("CreatePersonalAccessToken request received")
+
+	var req dtos.CreatePersonalAccessTokenDto
+
+	if !h.DecodeJSONBody(w, r, &req) {
+		return
+	}
+
+	userID, err := utils.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.Logger.Error("Failed to get user ID from context", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	req.UserID = userID
+
+	response, err := h.service.CreatePersonalAccessToken(r.Context(), req)
+
+	if err != nil {
+		h.Logger.Error("Failed to create personal access token", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	h.ReturnJSONResponse(w, response)
+}
+
+// @Summary List personal access tokens
+// @Description List the active personal access tokens of the current user, without their secrets
+// @Tags tokens
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dtos.StructuredResponse{payload=[]dtos.PersonalAccessTokenDto} "Tokens retrieved successfully"
+// @Failure 401 {object} dtos.StructuredResponse "Unauthorized"
+// @Failure 500 {object} dtos.StructuredResponse "Internal server error"
+// @Router /auth/tokens [get]
+func (h *AuthHandler) GetPersonalAccessTokens(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("GetPersonalAccessTokens request received")
+
+	userID, err := utils.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.Logger.Error("Failed to get user ID from context", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	response, err := h.service.GetPersonalAccessTokens(r.Context(), userID)
+
+	if err != nil {
+		h.Logger.Error("Failed to get personal access tokens", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	h.ReturnJSONResponse(w, response)
+}
+
+// @Summary Revoke a personal access token
+// @Description Revoke one of the current user's personal access tokens
+// @Tags tokens
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Token ID"
+// @Success 200 {object} dtos.StructuredResponse "Token revoked successfully"
+// @Failure 401 {object} dtos.StructuredResponse "Unauthorized"
+// @Failure 404 {object} dtos.StructuredResponse "Token not found"
+// @Failure 500 {object} dtos.StructuredResponse "Internal server error"
+// @Router /auth/tokens/{id} [delete]
+func (h *AuthHandler) RevokePersonalAccessToken(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("RevokePersonalAccessToken request received")
+
+	tokenID, ok := h.ParseIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	userID, err := utils.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.Logger.Error("Failed to get user ID from context", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	response, err := h.service.RevokePersonalAccessToken(r.Context(), dtos.RevokePersonalAccessTokenDto{
+		ID:     tokenID,
+		UserID: userID,
+	})
+
+	if err != nil {
+		h.Logger.Error("Failed to revoke personal access token", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	h.ReturnJSONResponse(w, response)
+}
+
+// @Summary Complete a two-factor login
+// @Description Exchange the challenge returned by /auth/login and a TOTP or recovery code for an access token and refresh token. Wrong codes count as failed logins.
+// @Tags 2fa
+// @Accept json
+// @Produce json
+// @Param X-Session-Mode header string false "Send cookie to receive the tokens as HttpOnly cookies instead of in the body"
+// @Param login body dtos.TwoFactorLoginDto true "Challenge token and code"
+// @Success 200 {object} dtos.StructuredResponse{payload=dtos.AuthTokensDto} "User logged in successfully"
+// @Failure 401 {object} dtos.StructuredResponse "Invalid or expired challenge or code"
+// @Failure 429 {object} dtos.StructuredResponse{payload=dtos.RateLimitedDto} "Too many failed login attempts"
+// @Failure 500 {object} dtos.StructuredResponse "Internal server error"
+// @Router /auth/login/2fa [post]
+func (h *AuthHandler) LoginWithTwoFactor(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("LoginWithTwoFactor request received")
+
+	var req dtos.TwoFactorLoginDto
+
+	if !h.DecodeJSONBody(w, r, &req) {
+		return
+	}
+
+	req.IPAddress = utils.GetClientIP(r)
+	req.UserAgent = r.UserAgent()
+
+	response, err := h.service.LoginWithTwoFactor(r.Context(), req)
+
+	if err != nil {
+		h.Logger.Error("Failed to complete two-factor login", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	h.ReturnAuthResponse(w, response, utils.UsesSessionCookies(r))
+}
+
+// @Summary Start two-factor enrollment
+// @Description Generate a TOTP secret for the current user. Two-factor authentication stays off until a code is confirmed with /auth/2fa/confirm.
+// @Tags 2fa
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dtos.StructuredResponse{payload=dtos.TwoFactorEnrollmentDto} "Enrollment started"
+// @Failure 401 {object} dtos.StructuredResponse "Unauthorized"
+// @Failure 409 {object} dtos.StructuredResponse "Two-factor authentication is already enabled"
+// @Failure 500 {object} dtos.StructuredResponse "Internal server error"
+// @Router /auth/2fa/enroll [post]
+func (h *AuthHandler) EnrollTwoFactor(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("EnrollTwoFactor request received")
+
+	userID, err := utils.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.Logger.Error("Failed to get user ID from context", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	response, err := h.service.EnrollTwoFactor(r.Context(), userID)
+
+	if err != nil {
+		h.Logger.Error("Failed to start two-factor enrollment", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	h.ReturnJSONResponse(w, response)
+}
+
+// @Summary Confirm two-factor enrollment
+// @Description Enable two-factor authentication with a code from the authenticator app. Returns ten single-use recovery codes, which are only shown once. Wrong codes count as failed logins.
+// @Tags 2fa
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param code body dtos.TwoFactorCodeDto true "TOTP code"
+// @Success 200 {object} dtos.StructuredResponse{payload=dtos.RecoveryCodesDto} "Two-factor authentication enabled"
+// @Failure 400 {object} dtos.StructuredResponse "Invalid code"
+// @Failure 401 {object} dtos.StructuredResponse "Unauthorized"
+// @Failure 409 {object} dtos.StructuredResponse "Two-factor authentication is already enabled"
+// @Failure 429 {object} dtos.StructuredResponse{payload=dtos.RateLimitedDto} "Too many failed login attempts"
+// @Failure 500 {object} dtos.StructuredResponse "Internal server error"
+// @Router /auth/2fa/confirm [post]
+func (h *AuthHandler) ConfirmTwoFactor(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("ConfirmTwoFactor request received")
+
+	var req dtos.TwoFactorCodeDto
+
+	if !h.DecodeJSONBody(w, r, &req) {
+		return
+	}
+
+	userID, err := utils.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.Logger.Error("Failed to get user ID from context", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	req.UserID = userID
+	req.IPAddress = utils.GetClientIP(r)
+
+	response, err := h.service.ConfirmTwoFactor(r.Context(), req)
+
+	if err != nil {
+		h.Logger.Error("Failed to confirm two-factor enrollment", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	h.ReturnJSONResponse(w, response)
+}
+
+// @Summary Disable two-factor authentication
+// @Description Turn two-factor authentication off and delete the recovery codes. Requires the password and a TOTP or recovery code.
+// @Tags 2fa
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param credentials body dtos.DisableTwoFactorDto true "Password and code"
+// @Success 200 {object} dtos.StructuredResponse "Two-factor authentication disabled"
+// @Failure 400 {object} dtos.StructuredResponse "Two-factor authentication is not enabled"
+// @Failure 401 {object} dtos.StructuredResponse "Invalid password or code"
+// @Failure 500 {object} dtos.StructuredResponse "Internal server error"
+// @Router /auth/2fa/disable [post]
+func (h *AuthHandler) DisableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("DisableTwoFactor request received")
+
+	var req dtos.DisableTwoFactorDto
+
+	if !h.DecodeJSONBody(w, r, &req) {
+		return
+	}
+
+	userID, err := utils.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.Logger.Error("Failed to get user ID from context", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	req.UserID = userID
+
+	response, err := h.service.DisableTwoFactor(r.Context(), req)
+
+	if err != nil {
+		h.Logger.Error("Failed to disable two-factor authentication", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	h.ReturnJSONResponse(w, response)
+}
+
+// oidcStateCookie holds the encrypted state of a single sign-on login between redirect and callback
+const oidcStateCookie = "oidc_state"
+
+// @Summary Start single sign-on
+// @Description Redirect the browser to the OpenID Connect provider. Only available when OIDC_ENABLED is set.
+// @Tags sso
+// @Success 302 "Redirect to the identity provider"
+// @Failure 502 {object} dtos.StructuredResponse "Identity provider is unavailable"
+// @Failure 500 {object} dtos.StructuredResponse "Internal server error"
+// @Router /auth/oidc/login [get]
+func (h *AuthHandler) StartOIDCLogin(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("StartOIDCLogin request received")
+
+	response, err := h.service.StartOIDCLogin(r.Context())
+
+	if err != nil {
+		h.Logger.Error("Failed to start OIDC login", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	redirect, ok := response.Payload.(dtos.OIDCRedirectDto)
+	if !ok {
+		h.ReturnJSONResponse(w, response)
+		return
+	}
+
+	oidcConfig := config.GetConfig().OIDC
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    redirect.StateCookie,
+		Path:     "/api/v1/auth/oidc",
+		MaxAge:   int(oidcConfig.StateTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   strings.HasPrefix(oidcConfig.RedirectURL, "https://"),
+		// Lax so the cookie comes back with the top-level redirect from the provider
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	http.Redirect(w, r, redirect.AuthorizationURL, http.StatusFound)
+}
+
+// @Summary Complete single sign-on
+// @Description Callback for the OpenID Connect provider. Verifies the login and returns an access token and refresh token. Users are matched by provider subject, then by verified email, and created when OIDC_AUTO_PROVISION is set. Users with two-factor authentication get a challenge to finish at /auth/login/2fa.
+// @Tags sso
+// @Produce json
+// @Param code query string false "Authorization code"
+// @Param state query string true "State from the login request"
+// @Success 200 {object} dtos.StructuredResponse{payload=dtos.AuthTokensDto} "User logged in successfully, or dtos.TwoFactorChallengeDto when two-factor authentication is enabled"
+// @Failure 400 {object} dtos.StructuredResponse "Invalid or expired single sign-on attempt"
+// @Failure 401 {object} dtos.StructuredResponse "Login refused by the provider or invalid ID token"
+// @Failure 403 {object} dtos.StructuredResponse "No account for this identity"
+// @Failure 500 {object} dtos.StructuredResponse "Internal server error"
+// @Router /auth/oidc/callback [get]
+func (h *AuthHandler) CompleteOIDCLogin(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("CompleteOIDCLogin request received")
+
+	query := r.URL.Query()
+	req := dtos.OIDCCallbackDto{
+		Code:             query.Get("code"),
+		State:            query.Get("state"),
+		Error:            query.Get("error"),
+		ErrorDescription: query.Get("error_description"),
+		IPAddress:        utils.GetClientIP(r),
+		UserAgent:        r.UserAgent(),
+	}
+
+	if cookie, err := r.Cookie(oidcStateCookie); err == nil {
+		req.StateCookie = cookie.Value
+	}
+
+	// The state is single use, clear it whatever the outcome
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    "",
+		Path:     "/api/v1/auth/oidc",
+		MaxAge:   -1,
+		HttpOnly: true,
+	})
+
+	response, err := h.service.CompleteOIDCLogin(r.Context(), req)
+
+	if err != nil {
+		h.Logger.Error("Failed to complete OIDC login", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	// The callback is a browser navigation that cannot send headers, so it always uses cookies when they are enabled
+	h.ReturnAuthResponse(w, response, config.GetConfig().SessionCookie.Enabled)
+}
+
+// magicLinkCookie binds emailed login links to the browser that requested them
+const magicLinkCookie = "magic_link"
+
+// @Summary Request a login link
+// @Description Email a single-use login link. The link only works in the browser that requested it, which gets a cookie for that. The response is the same whether or not the email belongs to an account. Only available when MAGIC_LINK_ENABLED is set.
+// @Tags magic-link
+// @Accept json
+// @Produce json
+// @Param user body dtos.RequestMagicLinkDto true "Account email"
+// @Success 200 {object} dtos.StructuredResponse "Login link sent if the account exists"
+// @Failure 400 {object} dtos.StructuredResponse{payload=dtos.ValidationErrorsDto} "Invalid email address"
+// @Failure 429 {object} dtos.StructuredResponse{payload=dtos.RateLimitedDto} "Too many login links requested"
+// @Failure 500 {object} dtos.StructuredResponse "Internal server error"
+// @Router /auth/magic-link [post]
+func (h *AuthHandler) RequestMagicLink(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("RequestMagicLink request received")
+
+	var req dtos.RequestMagicLinkDto
+
+	if !h.DecodeJSONBody(w, r, &req) {
+		return
+	}
+
+	req.IPAddress = utils.GetClientIP(r)
+	if cookie, err := r.Cookie(magicLinkCookie); err == nil {
+		req.BrowserCookie = cookie.Value
+	}
+
+	response, err := h.service.RequestMagicLink(r.Context(), req)
+
+	if err != nil {
+		h.Logger.Error("Failed to send magic link", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	if sent, ok := response.Payload.(dtos.MagicLinkSentDto); ok {
+		http.SetCookie(w, &http.Cookie{
+			Name:     magicLinkCookie,
+			Value:    sent.BrowserCookie,
+			Path:     "/api/v1/auth/magic-link",
+			MaxAge:   int(config.GetConfig().MagicLink.TTL.Seconds()),
+			HttpOnly: true,
+			Secure:   strings.HasPrefix(config.GetConfig().AppURL, "https://"),
+			SameSite: http.SameSiteLaxMode,
+		})
+		response.Payload = nil
+	}
+
+	h.ReturnJSONResponse(w, response)
+}
+
+// @Summary Log in with a login link
+// @Description Exchange the token from a login link for an access token and refresh token. The request must come from the browser that asked for the link. When two-factor authentication is enabled the payload is a challenge to complete at /auth/login/2fa instead of tokens.
+// @Tags magic-link
+// @Accept json
+// @Produce json
+// @Param X-Session-Mode header string false "Send cookie to receive the tokens as HttpOnly cookies instead of in the body"
+// @Param token body dtos.MagicLinkLoginDto true "Login link token"
+// @Success 200 {object} dtos.StructuredResponse{payload=dtos.AuthTokensDto} "User logged in successfully, or dtos.TwoFactorChallengeDto when two-factor authentication is enabled"
+// @Failure 400 {object} dtos.StructuredResponse "Invalid or expired login link"
+// @Failure 403 {object} dtos.StructuredResponse "Link opened in another browser"
+// @Failure 500 {object} dtos.StructuredResponse "Internal server error"
+// @Router /auth/magic-link/verify [post]
+func (h *AuthHandler) LoginWithMagicLink(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("LoginWithMagicLink request received")
+
+	var req dtos.MagicLinkLoginDto
+
+	if !h.DecodeJSONBody(w, r, &req) {
+		return
+	}
+
+	req.IPAddress = utils.GetClientIP(r)
+	req.UserAgent = r.UserAgent()
+	if cookie, err := r.Cookie(magicLinkCookie); err == nil {
+		req.BrowserCookie = cookie.Value
+	}
+
+	response, err := h.service.LoginWithMagicLink(r.Context(), req)
+
+	if err != nil {
+		h.Logger.Error("Failed to login with magic link", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	// The binding is only needed until the browser has logged in
+	if response.Success {
+		http.SetCookie(w, &http.Cookie{
+			Name:     magicLinkCookie,
+			Value:    "",
+			Path:     "/api/v1/auth/magic-link",
+			MaxAge:   -1,
+			HttpOnly: true,
+		})
+	}
+
+	h.ReturnAuthResponse(w, response, utils.UsesSessionCookies(r))
+}
+
+// @Summary List sessions
+// @Description List the devices the current user is logged in on. The session making the request is marked as current.
+// @Tags sessions
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dtos.StructuredResponse{payload=[]dtos.SessionDto} "Sessions retrieved successfully"
+// @Failure 401 {object} dtos.StructuredResponse "Unauthorized"
+// @Failure 500 {object} dtos.StructuredResponse "Internal server error"
+// @Router /auth/sessions [get]
+func (h *AuthHandler) GetSessions(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("GetSessions request received")
+
+	claims, err := utils.GetClaimsFromContext(r.Context())
+	if err != nil {
+		h.Logger.Error("Failed to get claims from context", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	response, err := h.service.GetSessions(r.Context(), claims.UserID, claims.SessionID)
+
+	if err != nil {
+		h.Logger.Error("Failed to get sessions", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	h.ReturnJSONResponse(w, response)
+}
+
+// @Summary Revoke a session
+// @Description Log out one of the current user's devices. Its refresh tokens stop working at once and its access tokens are rejected.
+// @Tags sessions
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Session ID"
+// @Success 200 {object} dtos.StructuredResponse "Session revoked successfully"
+// @Failure 401 {object} dtos.StructuredResponse "Unauthorized"
+// @Failure 404 {object} dtos.StructuredResponse "Session not found"
+// @Failure 500 {object} dtos.StructuredResponse "Internal server error"
+// @Router /auth/sessions/{id} [delete]
+func (h *AuthHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("RevokeSession request received")
+
+	sessionID, ok := h.ParseIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	userID, err := utils.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.Logger.Error("Failed to get user ID from context", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	response, err := h.service.RevokeSession(r.Context(), dtos.RevokeSessionDto{
+		ID:     sessionID,
+		UserID: userID,
+	})
+
+	if err != nil {
+		h.Logger.Error("Failed to revoke session", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	h.ReturnJSONResponse(w, response)
+}
+
+// @Summary Revoke all other sessions
+// @Description Log out every device of the current user except the one making the request
+// @Tags sessions
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dtos.StructuredResponse "Other sessions revoked successfully"
+// @Failure 401 {object} dtos.StructuredResponse "Unauthorized"
+// @Failure 500 {object} dtos.StructuredResponse "Internal server error"
+// @Router /auth/sessions [delete]
+func (h *AuthHandler) RevokeOtherSessions(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("RevokeOtherSessions request received")
+
+	claims, err := utils.GetClaimsFromContext(r.Context())
+	if err != nil {
+		h.Logger.Error("Failed to get claims from context", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	response, err := h.service.RevokeOtherSessions(r.Context(), dtos.RevokeOtherSessionsDto{
+		UserID:           claims.UserID,
+		CurrentSessionID: claims.SessionID,
+	})
+
+	if err != nil {
+		h.Logger.Error("Failed to revoke other sessions", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
 	h.ReturnJSONResponse(w, response)
 }
@@ -3,8 +3,12 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
 	"todo-api/internal/dtos"
+	"todo-api/internal/utils"
+	"todo-api/internal/validation"
 
+	"github.com/gorilla/mux"
 	"go.uber.org/zap"
 )
 
@@ -22,11 +26,29 @@ func (h *BaseHandler) ReturnJSONResponse(w http.ResponseWriter, response dtos.St
 		return
 	}
 
+	if rateLimited, ok := response.Payload.(dtos.RateLimitedDto); ok {
+		w.Header().Set("Retry-After", strconv.Itoa(rateLimited.RetryAfter))
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(response.Status)
 	w.Write(responseJSON)
 }
 
+// ReturnAuthResponse returns a response that may carry a new token pair. With useCookies the
+// tokens are moved into HttpOnly cookies and the body only keeps the CSRF token.
+func (h *BaseHandler) ReturnAuthResponse(w http.ResponseWriter, response dtos.StructuredResponse, useCookies bool) {
+	if tokens, ok := response.Payload.(dtos.AuthTokensDto); ok && useCookies {
+		tokens.CSRFToken = utils.CSRFToken(tokens.SessionID)
+		utils.SetSessionCookies(w, tokens.Token, tokens.RefreshToken, tokens.CSRFToken)
+		tokens.Token = ""
+		tokens.RefreshToken = ""
+		response.Payload = tokens
+	}
+
+	h.ReturnJSONResponse(w, response)
+}
+
 func (h *BaseHandler) DecodeJSONBody(w http.ResponseWriter, r *http.Request, dst interface{}) bool {
 	if err := json.NewDecoder(r.Body).Decode(dst); err != nil {
 		h.Logger.Error("Failed to decode request body", zap.Error(err))
@@ -39,5 +61,63 @@ func (h *BaseHandler) DecodeJSONBody(w http.ResponseWriter, r *http.Request, dst
 		return false
 	}
 	defer r.Body.Close()
+
+	// Enforce the binding tags of the DTO, every rejected field is reported at once
+	if fieldErrors := validation.Validate(dst); len(fieldErrors) > 0 {
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusBadRequest,
+			Message: "Validation failed",
+			Payload: dtos.ValidationErrorsDto{Errors: fieldErrors},
+		})
+		return false
+	}
+
 	return true
 }
+
+// ParseIDParam reads a numeric path parameter such as {id}, responding with 400 when it is invalid
+func (h *BaseHandler) ParseIDParam(w http.ResponseWriter, r *http.Request, name string) (uint, bool) {
+	id, err := strconv.ParseUint(mux.Vars(r)[name], 10, 64)
+	if err != nil || id == 0 {
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusBadRequest,
+			Message: "Invalid " + name,
+			Payload: nil,
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// ParsePageParams reads the optional page and pageSize query parameters, responding with 400 when
+// one is not a positive number. Parameters that are left out are returned as zero.
+func (h *BaseHandler) ParsePageParams(w http.ResponseWriter, r *http.Request) (int, int, bool) {
+	query := r.URL.Query()
+	var page, pageSize int
+
+	for _, param := range []struct {
+		name   string
+		target *int
+	}{
+		{"page", &page},
+		{"pageSize", &pageSize},
+	} {
+		if value := query.Get(param.name); value != "" {
+			number, err := strconv.Atoi(value)
+			if err != nil || number < 1 {
+				h.ReturnJSONResponse(w, dtos.StructuredResponse{
+					Success: false,
+					Status:  http.StatusBadRequest,
+					Message: "Invalid " + param.name,
+					Payload: nil,
+				})
+				return 0, 0, false
+			}
+			*param.target = number
+		}
+	}
+
+	return page, pageSize, true
+}
@@ -0,0 +1,316 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"todo-api/internal/dtos"
+	"todo-api/internal/services"
+	"todo-api/internal/utils"
+
+	"go.uber.org/zap"
+)
+
+type OAuthHandler struct {
+	BaseHandler
+	service *services.OAuthService
+}
+
+func NewOAuthHandler(logger *zap.Logger) *OAuthHandler {
+	return &OAuthHandler{
+		BaseHandler: BaseHandler{
+			Logger: logger,
+		},
+		service: services.NewOAuthService(logger),
+	}
+}
+
+// @Summary Check an authorization request
+// @Description Validate the query of an app's authorization request and describe it for the consent screen. The web app page at OAUTH_CONSENT_URL calls this with the query it received. When the app or redirect URI is unknown the user must be told, other problems come with a redirectUri payload that reports the error to the app.
+// @Tags oauth
+// @Produce json
+// @Security BearerAuth
+// @Param response_type query string true "Must be code"
+// @Param client_id query string true "Client identifier of the app"
+// @Param redirect_uri query string false "Registered redirect URI, optional when the app has only one"
+// @Param scope query string false "Space separated scopes, all scopes of the app when empty"
+// @Param state query string false "Opaque value returned to the app"
+// @Param code_challenge query string true "PKCE code challenge"
+// @Param code_challenge_method query string true "Must be S256"
+// @Success 200 {object} dtos.StructuredResponse{payload=dtos.OAuthConsentDto} "Authorization request is valid"
+// @Failure 400 {object} dtos.StructuredResponse{payload=dtos.OAuthRedirectDto} "Invalid authorization request"
+// @Failure 401 {object} dtos.StructuredResponse "Unauthorized"
+// @Failure 403 {object} dtos.StructuredResponse "Not a session token"
+// @Failure 500 {object} dtos.StructuredResponse "Internal server error"
+// @Router /oauth/authorize [get]
+func (h *OAuthHandler) GetAuthorization(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("GetAuthorization request received")
+
+	userID, err := utils.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.Logger.Error("Failed to get user ID from context", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusUnauthorized,
+			Message: "Unauthorized",
+			Payload: nil,
+		})
+		return
+	}
+
+	query := r.URL.Query()
+	req := dtos.OAuthAuthorizationRequestDto{
+		ResponseType:        query.Get("response_type"),
+		ClientID:            query.Get("client_id"),
+		RedirectURI:         query.Get("redirect_uri"),
+		Scope:               query.Get("scope"),
+		State:               query.Get("state"),
+		CodeChallenge:       query.Get("code_challenge"),
+		CodeChallengeMethod: query.Get("code_challenge_method"),
+		UserID:              userID,
+	}
+
+	response, err := h.service.GetAuthorization(r.Context(), req)
+
+	if err != nil {
+		h.Logger.Error("Failed to check authorization request", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	h.ReturnJSONResponse(w, response)
+}
+
+// @Summary Answer an authorization request
+// @Description Record the user's decision on the consent screen. The payload holds the URL to send the browser to, carrying an authorization code when the user approved and an access_denied error otherwise.
+// @Tags oauth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param authorization body dtos.OAuthAuthorizationRequestDto true "Authorization request and the user's decision"
+// @Success 200 {object} dtos.StructuredResponse{payload=dtos.OAuthRedirectDto} "Redirect back to the app"
+// @Failure 400 {object} dtos.StructuredResponse{payload=dtos.OAuthRedirectDto} "Invalid authorization request"
+// @Failure 401 {object} dtos.StructuredResponse "Unauthorized"
+// @Failure 403 {object} dtos.StructuredResponse "Not a session token or email not verified"
+// @Failure 500 {object} dtos.StructuredResponse "Internal server error"
+// @Router /oauth/authorize [post]
+func (h *OAuthHandler) Authorize(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Authorize request received")
+
+	userID, err := utils.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.Logger.Error("Failed to get user ID from context", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusUnauthorized,
+			Message: "Unauthorized",
+			Payload: nil,
+		})
+		return
+	}
+
+	var req dtos.OAuthAuthorizationRequestDto
+
+	if !h.DecodeJSONBody(w, r, &req) {
+		return
+	}
+
+	req.UserID = userID
+
+	response, err := h.service.Authorize(r.Context(), req)
+
+	if err != nil {
+		h.Logger.Error("Failed to authorize app", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	h.ReturnJSONResponse(w, response)
+}
+
+// @Summary Issue tokens to an app
+// @Description Token endpoint of RFC 6749. Exchanges an authorization code and its PKCE verifier, or a refresh token, for a new access and refresh token pair. Refresh tokens are single-use, presenting one twice revokes every token of the grant. Confidential apps authenticate with HTTP Basic or client_id and client_secret in the form, public apps send only client_id. Answers with a bare token or error object instead of a StructuredResponse.
+// @Tags oauth
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param grant_type formData string true "authorization_code or refresh_token"
+// @Param code formData string false "Authorization code, for authorization_code"
+// @Param redirect_uri formData string false "Same redirect_uri as in the authorization request, for authorization_code"
+// @Param code_verifier formData string false "PKCE code verifier, for authorization_code"
+// @Param refresh_token formData string false "Refresh token, for refresh_token"
+// @Param scope formData string false "Fewer scopes than granted, for refresh_token"
+// @Param client_id formData string false "Client identifier, unless sent with HTTP Basic"
+// @Param client_secret formData string false "Client secret of confidential apps, unless sent with HTTP Basic"
+// @Success 200 {object} dtos.OAuthTokenResponseDto "Tokens issued"
+// @Failure 400 {object} dtos.OAuthErrorDto "Invalid request or grant"
+// @Failure 401 {object} dtos.OAuthErrorDto "Client authentication failed"
+// @Failure 500 {object} dtos.OAuthErrorDto "Internal server error"
+// @Router /oauth/token [post]
+func (h *OAuthHandler) Token(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Token request received")
+
+	if !h.parseForm(w, r) {
+		return
+	}
+
+	clientID, clientSecret := clientCredentials(r)
+
+	response, err := h.service.Token(r.Context(), dtos.OAuthTokenRequestDto{
+		GrantType:    r.PostForm.Get("grant_type"),
+		Code:         r.PostForm.Get("code"),
+		RedirectURI:  r.PostForm.Get("redirect_uri"),
+		CodeVerifier: r.PostForm.Get("code_verifier"),
+		RefreshToken: r.PostForm.Get("refresh_token"),
+		Scope:        r.PostForm.Get("scope"),
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+	})
+
+	if err != nil {
+		h.Logger.Error("Failed to issue OAuth token", zap.Error(err))
+	}
+
+	h.returnOAuthResponse(w, r, response)
+}
+
+// @Summary Introspect a token
+// @Description Introspection endpoint of RFC 7662. Tells the calling app whether one of its access or refresh tokens is active, with its scopes, user and expiry. Tokens of other apps are reported as inactive.
+// @Tags oauth
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param token formData string true "Access or refresh token"
+// @Param client_id formData string false "Client identifier, unless sent with HTTP Basic"
+// @Param client_secret formData string false "Client secret of confidential apps, unless sent with HTTP Basic"
+// @Success 200 {object} dtos.OAuthIntrospectionDto "Token state"
+// @Failure 401 {object} dtos.OAuthErrorDto "Client authentication failed"
+// @Failure 500 {object} dtos.OAuthErrorDto "Internal server error"
+// @Router /oauth/introspect [post]
+func (h *OAuthHandler) Introspect(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Introspect request received")
+
+	if !h.parseForm(w, r) {
+		return
+	}
+
+	clientID, clientSecret := clientCredentials(r)
+
+	response, err := h.service.Introspect(r.Context(), dtos.OAuthTokenReferenceDto{
+		Token:        r.PostForm.Get("token"),
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+	})
+
+	if err != nil {
+		h.Logger.Error("Failed to introspect OAuth token", zap.Error(err))
+	}
+
+	h.returnOAuthResponse(w, r, response)
+}
+
+// @Summary Revoke a token
+// @Description Revocation endpoint of RFC 7009. Revokes one of the calling app's tokens, a refresh token together with the access tokens of its grant. Unknown tokens are answered with 200 as well.
+// @Tags oauth
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param token formData string true "Access or refresh token"
+// @Param client_id formData string false "Client identifier, unless sent with HTTP Basic"
+// @Param client_secret formData string false "Client secret of confidential apps, unless sent with HTTP Basic"
+// @Success 200 "Token revoked"
+// @Failure 401 {object} dtos.OAuthErrorDto "Client authentication failed"
+// @Failure 503 {object} dtos.OAuthErrorDto "Revocation failed, try again"
+// @Router /oauth/revoke [post]
+func (h *OAuthHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("Revoke request received")
+
+	if !h.parseForm(w, r) {
+		return
+	}
+
+	clientID, clientSecret := clientCredentials(r)
+
+	response, err := h.service.Revoke(r.Context(), dtos.OAuthTokenReferenceDto{
+		Token:        r.PostForm.Get("token"),
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+	})
+
+	if err != nil {
+		h.Logger.Error("Failed to revoke OAuth token", zap.Error(err))
+	}
+
+	h.returnOAuthResponse(w, r, response)
+}
+
+// parseForm reads the form body of a token, introspection or revocation request,
+// responding with an invalid_request error when it cannot be parsed
+func (h *OAuthHandler) parseForm(w http.ResponseWriter, r *http.Request) bool {
+	if err := r.ParseForm(); err != nil {
+		h.Logger.Warn("Failed to parse OAuth form", zap.Error(err))
+		h.returnOAuthResponse(w, r, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusBadRequest,
+			Message: "Invalid form body",
+			Payload: dtos.OAuthErrorDto{Error: "invalid_request", ErrorDescription: "Invalid form body"},
+		})
+		return false
+	}
+	return true
+}
+
+// returnOAuthResponse answers with the bare payload instead of a StructuredResponse, as
+// OAuth2 clients expect. Token responses must never be cached.
+func (h *OAuthHandler) returnOAuthResponse(w http.ResponseWriter, r *http.Request, response dtos.StructuredResponse) {
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Pragma", "no-cache")
+
+	if response.Status == http.StatusUnauthorized {
+		if _, _, ok := r.BasicAuth(); ok {
+			w.Header().Set("WWW-Authenticate", `Basic realm="todo-api"`)
+		}
+	}
+
+	if response.Payload == nil {
+		w.WriteHeader(response.Status)
+		return
+	}
+
+	responseJSON, err := json.Marshal(response.Payload)
+	if err != nil {
+		h.Logger.Error("Failed to marshal OAuth response", zap.Error(err))
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("Internal server error"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(response.Status)
+	w.Write(responseJSON)
+}
+
+// clientCredentials returns the client ID and secret from HTTP Basic authentication or, when
+// the header is missing, from the form
+func clientCredentials(r *http.Request) (string, string) {
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		return r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+
+	// RFC 6749 form-encodes the credentials before putting them in the header
+	if decoded, err := url.QueryUnescape(clientID); err == nil {
+		clientID = decoded
+	}
+	if decoded, err := url.QueryUnescape(clientSecret); err == nil {
+		clientSecret = decoded
+	}
+	return clientID, clientSecret
+}
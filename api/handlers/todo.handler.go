@@ -2,10 +2,13 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 	"todo-api/internal/dtos"
+	"todo-api/internal/models"
 	"todo-api/internal/services"
-	"todo-api/internal/utils"
 
 	"go.uber.org/zap"
 )
@@ -25,14 +28,16 @@ func NewTodoHandler(logger *zap.Logger) *TodoHandler {
 }
 
 // @Summary Get all Todo Items
-// @Description Get all Todo Items from the database
+// @Description Deprecated alias of GET /todos. Responses carry Deprecation, Sunset and Link headers.
 // @Tags todo
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Success 200 {object} dtos.StructuredResponse "Todo items retrieved successfully"
 // @Failure 401 {object} dtos.StructuredResponse "Unauthorized"
+// @Failure 403 {object} dtos.StructuredResponse "Forbidden"
 // @Failure 500 {object} dtos.StructuredResponse "Internal server error"
+// @Deprecated
 // @Router /todo/get-todos [get]
 func (h *TodoHandler) GetTodoItems(w http.ResponseWriter, r *http.Request) {
 	h.Logger.Info("GetTodoItems request received")
@@ -58,7 +63,7 @@ func (h *TodoHandler) GetTodoItems(w http.ResponseWriter, r *http.Request) {
 }
 
 // @Summary Create a new Todo Item
-// @Description Create a new Todo Item with the provided details
+// @Description Deprecated alias of POST /todos, which answers 201. Responses carry Deprecation, Sunset and Link headers.
 // @Tags todo
 // @Accept json
 // @Produce json
@@ -66,7 +71,9 @@ func (h *TodoHandler) GetTodoItems(w http.ResponseWriter, r *http.Request) {
 // @Param todo body dtos.CreateTodoItemDto true "Todo item data"
 // @Success 200 {object} dtos.StructuredResponse "Todo item created successfully"
 // @Failure 401 {object} dtos.StructuredResponse "Unauthorized"
+// @Failure 403 {object} dtos.StructuredResponse "Forbidden"
 // @Failure 500 {object} dtos.StructuredResponse "Internal server error"
+// @Deprecated
 // @Router /todo/create-todo-item [post]
 func (h *TodoHandler) CreateTodoItem(w http.ResponseWriter, r *http.Request) {
 	h.Logger.Info("CreateTodoItem request received")
@@ -85,20 +92,6 @@ func (h *TodoHandler) CreateTodoItem(w http.ResponseWriter, r *http.Request) {
 	}
 	defer r.Body.Close()
 
-	userID, err := utils.GetUserIDFromContext(r.Context())
-	if err != nil {
-		h.Logger.Error("Failed to get user ID from context", zap.Error(err))
-		h.ReturnJSONResponse(w, dtos.StructuredResponse{
-			Success: false,
-			Status:  http.StatusInternalServerError,
-			Message: err.Error(),
-			Payload: nil,
-		})
-		return
-	}
-
-	req.UserID = userID
-
 	h.Logger.Debug("Creating todo item", zap.String("title", req.Title))
 	response, err := h.service.CreateTodoItem(r.Context(), req)
 
@@ -118,7 +111,7 @@ func (h *TodoHandler) CreateTodoItem(w http.ResponseWriter, r *http.Request) {
 }
 
 // @Summary Create a new Todo Note
-// @Description Create a new Todo Note for an existing Todo Item
+// @Description Deprecated alias of POST /todos/{id}/notes, which answers 201. Responses carry Deprecation, Sunset and Link headers.
 // @Tags todo
 // @Accept json
 // @Produce json
@@ -126,7 +119,10 @@ func (h *TodoHandler) CreateTodoItem(w http.ResponseWriter, r *http.Request) {
 // @Param todo body dtos.CreateTodoNoteDto true "Todo note data"
 // @Success 200 {object} dtos.StructuredResponse "Todo note created successfully"
 // @Failure 401 {object} dtos.StructuredResponse "Unauthorized"
+// @Failure 403 {object} dtos.StructuredResponse "Forbidden"
+// @Failure 404 {object} dtos.StructuredResponse "Todo item not found"
 // @Failure 500 {object} dtos.StructuredResponse "Internal server error"
+// @Deprecated
 // @Router /todo/create-todo-note [post]
 func (h *TodoHandler) CreateTodoNote(w http.ResponseWriter, r *http.Request) {
 	h.Logger.Info("CreateTodoNote request received")
@@ -164,7 +160,7 @@ func (h *TodoHandler) CreateTodoNote(w http.ResponseWriter, r *http.Request) {
 }
 
 // @Summary Update an existing Todo Item
-// @Description Update an existing Todo Item with the provided details
+// @Description Deprecated alias of PUT /todos/{id}. Responses carry Deprecation, Sunset and Link headers.
 // @Tags todo
 // @Accept json
 // @Produce json
@@ -172,7 +168,10 @@ func (h *TodoHandler) CreateTodoNote(w http.ResponseWriter, r *http.Request) {
 // @Param todo body dtos.UpdateTodoItemDto true "Todo item update data"
 // @Success 200 {object} dtos.StructuredResponse "Todo item updated successfully"
 // @Failure 401 {object} dtos.StructuredResponse "Unauthorized"
+// @Failure 403 {object} dtos.StructuredResponse "Forbidden"
+// @Failure 404 {object} dtos.StructuredResponse "Todo item not found"
 // @Failure 500 {object} dtos.StructuredResponse "Internal server error"
+// @Deprecated
 // @Router /todo/update-todo-item [put]
 func (h *TodoHandler) UpdateTodoItem(w http.ResponseWriter, r *http.Request) {
 	h.Logger.Info("UpdateTodoItem request received")
@@ -191,21 +190,6 @@ func (h *TodoHandler) UpdateTodoItem(w http.ResponseWriter, r *http.Request) {
 	}
 	defer r.Body.Close()
 
-	userID, err := utils.GetUserIDFromContext(r.Context())
-
-	if err != nil {
-		h.Logger.Error("Failed to get user ID from context", zap.Error(err))
-		h.ReturnJSONResponse(w, dtos.StructuredResponse{
-			Success: false,
-			Status:  http.StatusInternalServerError,
-			Message: err.Error(),
-			Payload: nil,
-		})
-		return
-	}
-
-	req.UserID = userID
-
 	h.Logger.Debug("Updating todo item", zap.Uint("id", req.ID))
 	response, err := h.service.UpdateTodoItem(r.Context(), req)
 
@@ -225,7 +209,7 @@ func (h *TodoHandler) UpdateTodoItem(w http.ResponseWriter, r *http.Request) {
 }
 
 // @Summary Delete an existing Todo Item
-// @Description Delete an existing Todo Item by ID
+// @Description Deprecated alias of DELETE /todos/{id}, which answers 204. Responses carry Deprecation, Sunset and Link headers.
 // @Tags todo
 // @Accept json
 // @Produce json
@@ -233,7 +217,10 @@ func (h *TodoHandler) UpdateTodoItem(w http.ResponseWriter, r *http.Request) {
 // @Param todo body dtos.DeleteTodoItemDto true "Todo item deletion data"
 // @Success 200 {object} dtos.StructuredResponse "Todo item deleted successfully"
 // @Failure 401 {object} dtos.StructuredResponse "Unauthorized"
+// @Failure 403 {object} dtos.StructuredResponse "Forbidden"
+// @Failure 404 {object} dtos.StructuredResponse "Todo item not found"
 // @Failure 500 {object} dtos.StructuredResponse "Internal server error"
+// @Deprecated
 // @Router /todo/delete-todo-item [delete]
 func (h *TodoHandler) DeleteTodoItem(w http.ResponseWriter, r *http.Request) {
 	h.Logger.Info("DeleteTodoItem request received")
@@ -269,3 +256,467 @@ func (h *TodoHandler) DeleteTodoItem(w http.ResponseWriter, r *http.Request) {
 	h.Logger.Info("Todo item deleted successfully")
 	h.ReturnJSONResponse(w, response)
 }
+
+// @Summary List Todo Items
+// @Description List the todo items of the current user with their notes, one page at a time. Pass the nextCursor of a page as cursor, with the same sort and filters, to get the next one.
+// @Tags todos
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Items per page, at most TODO_MAX_PAGE_SIZE" default(20)
+// @Param cursor query string false "nextCursor of the previous page"
+// @Param sort query string false "id, title, createdAt, updatedAt or dueAt, prefixed with - for descending order. Items without a due date come last." default(id)
+// @Param isCompleted query bool false "Only completed or only open items"
+// @Param createdAfter query string false "Only items created after this time (RFC 3339 or YYYY-MM-DD)"
+// @Param createdBefore query string false "Only items created before this time (RFC 3339 or YYYY-MM-DD)"
+// @Param updatedAfter query string false "Only items updated after this time (RFC 3339 or YYYY-MM-DD)"
+// @Param updatedBefore query string false "Only items updated before this time (RFC 3339 or YYYY-MM-DD)"
+// @Param title query string false "Text the title must contain, ignoring case"
+// @Param includeTotal query bool false "Count the matching items across all pages"
+// @Param view query string false "Smart view computed in the user's time zone" Enums(overdue, today, next7days, nodate)
+// @Success 200 {object} dtos.StructuredResponse{payload=dtos.TodoItemListDto} "Todo items retrieved successfully"
+// @Failure 400 {object} dtos.StructuredResponse "Invalid query parameter, sort, view or cursor"
+// @Failure 401 {object} dtos.StructuredResponse "Unauthorized"
+// @Failure 403 {object} dtos.StructuredResponse "Forbidden"
+// @Failure 500 {object} dtos.StructuredResponse "Internal server error"
+// @Router /todos [get]
+func (h *TodoHandler) ListTodoItems(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("ListTodoItems request received")
+
+	req, ok := h.parseListTodoItemsParams(w, r)
+	if !ok {
+		return
+	}
+
+	response, err := h.service.ListTodoItems(r.Context(), req)
+
+	if err != nil {
+		h.Logger.Error("Failed to get todo items", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	h.ReturnJSONResponse(w, response)
+}
+
+// @Summary Search Todo Items
+// @Description Search the titles, descriptions and notes of the current user's todo items, most relevant first. Every word must match, "quoted phrases" must match as consecutive words and a word ending in * matches every word starting with it. Words are stemmed, so "buying" finds "buy". Matching words are wrapped in <mark> tags in the highlights and snippets, the rest of the text is not HTML escaped.
+// @Tags todos
+// @Produce json
+// @Security BearerAuth
+// @Param q query string true "Search text, at most 200 characters"
+// @Param page query int false "Page number, starting at 1" default(1)
+// @Param pageSize query int false "Results per page, at most 100" default(20)
+// @Success 200 {object} dtos.StructuredResponse{payload=dtos.TodoSearchResultsDto} "Todo items found successfully"
+// @Failure 400 {object} dtos.StructuredResponse "Missing or invalid q, page or pageSize"
+// @Failure 401 {object} dtos.StructuredResponse "Unauthorized"
+// @Failure 403 {object} dtos.StructuredResponse "Forbidden"
+// @Failure 500 {object} dtos.StructuredResponse "Internal server error"
+// @Router /todos/search [get]
+func (h *TodoHandler) SearchTodoItems(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("SearchTodoItems request received")
+
+	page, pageSize, ok := h.ParsePageParams(w, r)
+	if !ok {
+		return
+	}
+
+	req := dtos.SearchTodoItemsDto{
+		Query:    r.URL.Query().Get("q"),
+		Page:     page,
+		PageSize: pageSize,
+	}
+
+	response, err := h.service.SearchTodoItems(r.Context(), req)
+
+	if err != nil {
+		h.Logger.Error("Failed to search todo items", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	h.ReturnJSONResponse(w, response)
+}
+
+// @Summary Create a Todo Item
+// @Description Create a todo item for the current user. The Location header names the new item.
+// @Tags todos
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param todo body dtos.CreateTodoItemDto true "Todo item data"
+// @Success 201 {object} dtos.StructuredResponse "Todo item created successfully"
+// @Failure 400 {object} dtos.StructuredResponse "Validation failed, or invalid due date or reminders"
+// @Failure 401 {object} dtos.StructuredResponse "Unauthorized"
+// @Failure 403 {object} dtos.StructuredResponse "Forbidden"
+// @Failure 500 {object} dtos.StructuredResponse "Internal server error"
+// @Router /todos [post]
+func (h *TodoHandler) CreateTodo(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("CreateTodo request received")
+
+	var req dtos.CreateTodoItemDto
+	if !h.DecodeJSONBody(w, r, &req) {
+		return
+	}
+
+	response, err := h.service.CreateTodoItem(r.Context(), req)
+
+	if err != nil {
+		h.Logger.Error("Failed to create todo item", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	if todoItem, ok := response.Payload.(models.TodoItem); ok && response.Success {
+		w.Header().Set("Location", fmt.Sprintf("/api/v1/todos/%d", todoItem.ID))
+		response.Status = http.StatusCreated
+	}
+
+	h.ReturnJSONResponse(w, response)
+}
+
+// @Summary Get a Todo Item
+// @Description Get one of the current user's todo items with its notes
+// @Tags todos
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Todo item ID"
+// @Success 200 {object} dtos.StructuredResponse "Todo item retrieved successfully"
+// @Failure 400 {object} dtos.StructuredResponse "Invalid id"
+// @Failure 401 {object} dtos.StructuredResponse "Unauthorized"
+// @Failure 403 {object} dtos.StructuredResponse "Forbidden"
+// @Failure 404 {object} dtos.StructuredResponse "Todo item not found"
+// @Failure 500 {object} dtos.StructuredResponse "Internal server error"
+// @Router /todos/{id} [get]
+func (h *TodoHandler) GetTodo(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("GetTodo request received")
+
+	id, ok := h.ParseIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	response, err := h.service.GetTodoItem(r.Context(), id)
+
+	if err != nil {
+		h.Logger.Error("Failed to get todo item", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	h.ReturnJSONResponse(w, response)
+}
+
+// @Summary Update a Todo Item
+// @Description Change some fields of one of the current user's todo items, the fields that are left out keep their value
+// @Tags todos
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Todo item ID"
+// @Param todo body dtos.PatchTodoItemDto true "Fields to change"
+// @Success 200 {object} dtos.StructuredResponse "Todo item updated successfully"
+// @Failure 400 {object} dtos.StructuredResponse "Validation failed, or invalid due date or reminders"
+// @Failure 401 {object} dtos.StructuredResponse "Unauthorized"
+// @Failure 403 {object} dtos.StructuredResponse "Forbidden"
+// @Failure 404 {object} dtos.StructuredResponse "Todo item not found"
+// @Failure 500 {object} dtos.StructuredResponse "Internal server error"
+// @Router /todos/{id} [patch]
+func (h *TodoHandler) PatchTodo(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("PatchTodo request received")
+
+	id, ok := h.ParseIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req dtos.PatchTodoItemDto
+	if !h.DecodeJSONBody(w, r, &req) {
+		return
+	}
+	req.ID = id
+
+	response, err := h.service.PatchTodoItem(r.Context(), req)
+
+	if err != nil {
+		h.Logger.Error("Failed to update todo item", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	h.ReturnJSONResponse(w, response)
+}
+
+// @Summary Replace a Todo Item
+// @Description Replace every field of one of the current user's todo items, a due date that is left out is removed
+// @Tags todos
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Todo item ID"
+// @Param todo body dtos.ReplaceTodoItemDto true "New state of the todo item"
+// @Success 200 {object} dtos.StructuredResponse "Todo item updated successfully"
+// @Failure 400 {object} dtos.StructuredResponse "Validation failed, or invalid due date or reminders"
+// @Failure 401 {object} dtos.StructuredResponse "Unauthorized"
+// @Failure 403 {object} dtos.StructuredResponse "Forbidden"
+// @Failure 404 {object} dtos.StructuredResponse "Todo item not found"
+// @Failure 500 {object} dtos.StructuredResponse "Internal server error"
+// @Router /todos/{id} [put]
+func (h *TodoHandler) ReplaceTodo(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("ReplaceTodo request received")
+
+	id, ok := h.ParseIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req dtos.ReplaceTodoItemDto
+	if !h.DecodeJSONBody(w, r, &req) {
+		return
+	}
+
+	response, err := h.service.UpdateTodoItem(r.Context(), dtos.UpdateTodoItemDto{
+		ID:          id,
+		Title:       req.Title,
+		Description: req.Description,
+		IsCompleted: req.IsCompleted,
+		Schedule:    &req.TodoScheduleDto,
+	})
+
+	if err != nil {
+		h.Logger.Error("Failed to update todo item", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	h.ReturnJSONResponse(w, response)
+}
+
+// @Summary Delete a Todo Item
+// @Description Delete one of the current user's todo items together with its notes
+// @Tags todos
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Todo item ID"
+// @Success 204 "Todo item deleted"
+// @Failure 400 {object} dtos.StructuredResponse "Invalid id"
+// @Failure 401 {object} dtos.StructuredResponse "Unauthorized"
+// @Failure 403 {object} dtos.StructuredResponse "Forbidden"
+// @Failure 404 {object} dtos.StructuredResponse "Todo item not found"
+// @Failure 500 {object} dtos.StructuredResponse "Internal server error"
+// @Router /todos/{id} [delete]
+func (h *TodoHandler) DeleteTodo(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("DeleteTodo request received")
+
+	id, ok := h.ParseIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	response, err := h.service.DeleteTodoItem(r.Context(), dtos.DeleteTodoItemDto{ID: id})
+
+	if err != nil {
+		h.Logger.Error("Failed to delete todo item", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	if response.Success {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	h.ReturnJSONResponse(w, response)
+}
+
+// @Summary List the Notes of a Todo Item
+// @Description List the notes of one of the current user's todo items, oldest first
+// @Tags todos
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Todo item ID"
+// @Success 200 {object} dtos.StructuredResponse "Todo notes retrieved successfully"
+// @Failure 400 {object} dtos.StructuredResponse "Invalid id"
+// @Failure 401 {object} dtos.StructuredResponse "Unauthorized"
+// @Failure 403 {object} dtos.StructuredResponse "Forbidden"
+// @Failure 404 {object} dtos.StructuredResponse "Todo item not found"
+// @Failure 500 {object} dtos.StructuredResponse "Internal server error"
+// @Router /todos/{id}/notes [get]
+func (h *TodoHandler) ListTodoNotes(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("ListTodoNotes request received")
+
+	id, ok := h.ParseIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	response, err := h.service.GetTodoNotes(r.Context(), id)
+
+	if err != nil {
+		h.Logger.Error("Failed to get todo notes", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	h.ReturnJSONResponse(w, response)
+}
+
+// @Summary Add a Note to a Todo Item
+// @Description Add a note to one of the current user's todo items
+// @Tags todos
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Todo item ID"
+// @Param note body dtos.AddTodoNoteDto true "Note data"
+// @Success 201 {object} dtos.StructuredResponse "Todo note created successfully"
+// @Failure 400 {object} dtos.StructuredResponse "Validation failed"
+// @Failure 401 {object} dtos.StructuredResponse "Unauthorized"
+// @Failure 403 {object} dtos.StructuredResponse "Forbidden"
+// @Failure 404 {object} dtos.StructuredResponse "Todo item not found"
+// @Failure 500 {object} dtos.StructuredResponse "Internal server error"
+// @Router /todos/{id}/notes [post]
+func (h *TodoHandler) AddTodoNote(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("AddTodoNote request received")
+
+	id, ok := h.ParseIDParam(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req dtos.AddTodoNoteDto
+	if !h.DecodeJSONBody(w, r, &req) {
+		return
+	}
+
+	response, err := h.service.CreateTodoNote(r.Context(), dtos.CreateTodoNoteDto{
+		TodoItemID: id,
+		Note:       req.Note,
+	})
+
+	if err != nil {
+		h.Logger.Error("Failed to create todo note", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	if response.Success {
+		response.Status = http.StatusCreated
+	}
+
+	h.ReturnJSONResponse(w, response)
+}
+
+// parseListTodoItemsParams reads the query parameters of GET /todos, responding with 400 when
+// one cannot be parsed. Sort, view and cursor are checked by the repository.
+func (h *TodoHandler) parseListTodoItemsParams(w http.ResponseWriter, r *http.Request) (dtos.ListTodoItemsDto, bool) {
+	query := r.URL.Query()
+	req := dtos.ListTodoItemsDto{
+		Cursor: query.Get("cursor"),
+		Sort:   query.Get("sort"),
+		Title:  query.Get("title"),
+		View:   query.Get("view"),
+	}
+
+	invalid := func(name string) (dtos.ListTodoItemsDto, bool) {
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusBadRequest,
+			Message: "Invalid " + name,
+			Payload: nil,
+		})
+		return req, false
+	}
+
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 {
+			return invalid("limit")
+		}
+		req.Limit = limit
+	}
+
+	if value := query.Get("isCompleted"); value != "" {
+		isCompleted, err := strconv.ParseBool(value)
+		if err != nil {
+			return invalid("isCompleted")
+		}
+		req.IsCompleted = &isCompleted
+	}
+
+	if value := query.Get("includeTotal"); value != "" {
+		includeTotal, err := strconv.ParseBool(value)
+		if err != nil {
+			return invalid("includeTotal")
+		}
+		req.IncludeTotal = includeTotal
+	}
+
+	for _, param := range []struct {
+		name   string
+		target **time.Time
+	}{
+		{"createdAfter", &req.CreatedAfter},
+		{"createdBefore", &req.CreatedBefore},
+		{"updatedAfter", &req.UpdatedAfter},
+		{"updatedBefore", &req.UpdatedBefore},
+	} {
+		if value := query.Get(param.name); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				// A plain date stands for midnight UTC
+				parsed, err = time.Parse(time.DateOnly, value)
+			}
+			if err != nil {
+				return invalid(param.name)
+			}
+			*param.target = &parsed
+		}
+	}
+
+	return req, true
+}
@@ -0,0 +1,326 @@
+package handlers
+
+import (
+	"net/http"
+	"todo-api/internal/dtos"
+	"todo-api/internal/services"
+	"todo-api/internal/utils"
+
+	"go.uber.org/zap"
+)
+
+type UserHandler struct {
+	BaseHandler
+	service *services.UserService
+}
+
+func NewUserHandler(logger *zap.Logger) *UserHandler {
+	return &UserHandler{
+		BaseHandler: BaseHandler{
+			Logger: logger,
+		},
+		service: services.NewUserService(logger),
+	}
+}
+
+// @Summary Get the current user
+// @Description Return the profile of the logged in user
+// @Tags me
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dtos.StructuredResponse{payload=dtos.UserDto} "Profile retrieved successfully"
+// @Failure 401 {object} dtos.StructuredResponse "Unauthorized"
+// @Failure 404 {object} dtos.StructuredResponse "User not found"
+// @Failure 500 {object} dtos.StructuredResponse "Internal server error"
+// @Router /me [get]
+func (h *UserHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("GetProfile request received")
+
+	userID, err := utils.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.Logger.Error("Failed to get user ID from context", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	response, err := h.service.GetProfile(r.Context(), userID)
+
+	if err != nil {
+		h.Logger.Error("Failed to get profile", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	h.ReturnJSONResponse(w, response)
+}
+
+// @Summary Update the current user's profile
+// @Description Change the name, bio, avatar URL or time zone. Fields that are left out keep their value.
+// @Tags me
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param profile body dtos.UpdateProfileDto true "Profile fields to change"
+// @Success 200 {object} dtos.StructuredResponse{payload=dtos.UserDto} "Profile updated successfully"
+// @Failure 400 {object} dtos.StructuredResponse "Invalid profile field"
+// @Failure 401 {object} dtos.StructuredResponse "Unauthorized"
+// @Failure 500 {object} dtos.StructuredResponse "Internal server error"
+// @Router /me [patch]
+func (h *UserHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("UpdateProfile request received")
+
+	var req dtos.UpdateProfileDto
+
+	if !h.DecodeJSONBody(w, r, &req) {
+		return
+	}
+
+	userID, err := utils.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.Logger.Error("Failed to get user ID from context", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	req.UserID = userID
+
+	response, err := h.service.UpdateProfile(r.Context(), req)
+
+	if err != nil {
+		h.Logger.Error("Failed to update profile", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	h.ReturnJSONResponse(w, response)
+}
+
+// @Summary Change the current user's password
+// @Description Set a new password after checking the current one. Every other session is logged out, the one making the request stays logged in.
+// @Tags me
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param passwords body dtos.ChangePasswordDto true "Current and new password"
+// @Success 200 {object} dtos.StructuredResponse "Password changed successfully"
+// @Failure 400 {object} dtos.StructuredResponse{payload=dtos.ValidationErrorsDto} "New password does not meet the policy"
+// @Failure 401 {object} dtos.StructuredResponse "Current password is incorrect"
+// @Failure 500 {object} dtos.StructuredResponse "Internal server error"
+// @Router /me/password [put]
+func (h *UserHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("ChangePassword request received")
+
+	var req dtos.ChangePasswordDto
+
+	if !h.DecodeJSONBody(w, r, &req) {
+		return
+	}
+
+	claims, err := utils.GetClaimsFromContext(r.Context())
+	if err != nil {
+		h.Logger.Error("Failed to get claims from context", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	req.UserID = claims.UserID
+	req.SessionID = claims.SessionID
+
+	response, err := h.service.ChangePassword(r.Context(), req)
+
+	if err != nil {
+		h.Logger.Error("Failed to change password", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	h.ReturnJSONResponse(w, response)
+}
+
+// @Summary Change the current user's email address
+// @Description Send a confirmation link to the new address. The account keeps its current address until the link is opened, see /auth/confirm-email-change.
+// @Tags me
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param email body dtos.ChangeEmailDto true "New email address and password"
+// @Success 202 {object} dtos.StructuredResponse "Confirmation link sent to the new address"
+// @Failure 400 {object} dtos.StructuredResponse "Invalid email address"
+// @Failure 401 {object} dtos.StructuredResponse "Password is incorrect"
+// @Failure 409 {object} dtos.StructuredResponse "Email address is already in use"
+// @Failure 500 {object} dtos.StructuredResponse "Internal server error"
+// @Router /me/email [put]
+func (h *UserHandler) ChangeEmail(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("ChangeEmail request received")
+
+	var req dtos.ChangeEmailDto
+
+	if !h.DecodeJSONBody(w, r, &req) {
+		return
+	}
+
+	userID, err := utils.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.Logger.Error("Failed to get user ID from context", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	req.UserID = userID
+
+	response, err := h.service.ChangeEmail(r.Context(), req)
+
+	if err != nil {
+		h.Logger.Error("Failed to change email", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	h.ReturnJSONResponse(w, response)
+}
+
+// @Summary List the current user's security events
+// @Description Return the logins, password changes, token changes and other security events of the account, newest first. Events older than SECURITY_EVENT_RETENTION are not kept.
+// @Tags me
+// @Produce json
+// @Security BearerAuth
+// @Param type query string false "Only return events of this type, e.g. login.failed"
+// @Param page query int false "Page number, starting at 1" default(1)
+// @Param pageSize query int false "Events per page, at most 100" default(20)
+// @Success 200 {object} dtos.StructuredResponse{payload=dtos.SecurityEventListDto} "Security events retrieved successfully"
+// @Failure 400 {object} dtos.StructuredResponse "Invalid page or pageSize"
+// @Failure 401 {object} dtos.StructuredResponse "Unauthorized"
+// @Failure 500 {object} dtos.StructuredResponse "Internal server error"
+// @Router /me/security-events [get]
+func (h *UserHandler) GetSecurityEvents(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("GetSecurityEvents request received")
+
+	page, pageSize, ok := h.ParsePageParams(w, r)
+	if !ok {
+		return
+	}
+
+	userID, err := utils.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.Logger.Error("Failed to get user ID from context", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	response, err := h.service.GetSecurityEvents(r.Context(), dtos.ListSecurityEventsDto{
+		UserID:   userID,
+		Type:     r.URL.Query().Get("type"),
+		Page:     page,
+		PageSize: pageSize,
+	})
+
+	if err != nil {
+		h.Logger.Error("Failed to get security events", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	h.ReturnJSONResponse(w, response)
+}
+
+// @Summary Delete the current user's account
+// @Description Permanently delete the account with its todo items, notes, sessions and tokens. Requires the password unless the account was created through single sign-on.
+// @Tags me
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param confirmation body dtos.DeleteAccountDto false "Current password"
+// @Success 200 {object} dtos.StructuredResponse "Account deleted successfully"
+// @Failure 401 {object} dtos.StructuredResponse "Password is incorrect"
+// @Failure 409 {object} dtos.StructuredResponse "Cannot delete the last administrator"
+// @Failure 500 {object} dtos.StructuredResponse "Internal server error"
+// @Router /me [delete]
+func (h *UserHandler) DeleteAccount(w http.ResponseWriter, r *http.Request) {
+	h.Logger.Info("DeleteAccount request received")
+
+	var req dtos.DeleteAccountDto
+
+	// The body is optional for accounts without a password
+	if r.ContentLength != 0 && !h.DecodeJSONBody(w, r, &req) {
+		return
+	}
+
+	userID, err := utils.GetUserIDFromContext(r.Context())
+	if err != nil {
+		h.Logger.Error("Failed to get user ID from context", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	req.UserID = userID
+
+	response, err := h.service.DeleteAccount(r.Context(), req)
+
+	if err != nil {
+		h.Logger.Error("Failed to delete account", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	h.ReturnJSONResponse(w, response)
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"todo-api/config"
+	"todo-api/internal/dtos"
+	"todo-api/internal/models"
+	"todo-api/internal/utils"
+
+	"go.uber.org/zap"
+)
+
+type WellKnownHandler struct {
+	BaseHandler
+}
+
+func NewWellKnownHandler(logger *zap.Logger) *WellKnownHandler {
+	return &WellKnownHandler{
+		BaseHandler: BaseHandler{
+			Logger: logger,
+		},
+	}
+}
+
+// GetJWKS serves the public keys that verify access tokens. It lives outside /api/v1 and
+// answers with a bare JWK Set instead of a StructuredResponse, as JWT libraries expect.
+func (h *WellKnownHandler) GetJWKS(w http.ResponseWriter, r *http.Request) {
+	keys, err := utils.GetKeyring()
+	if err != nil {
+		h.Logger.Error("Failed to load keyring", zap.Error(err))
+		h.ReturnJSONResponse(w, dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
+			Payload: nil,
+		})
+		return
+	}
+
+	responseJSON, err := json.Marshal(keys.JWKS())
+	if err != nil {
+		h.Logger.Error("Failed to marshal JWKS", zap.Error(err))
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("Internal server error"))
+		return
+	}
+
+	// Verifiers may cache the keys, a new key should be published before it becomes active
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(responseJSON)
+}
+
+// GetOAuthMetadata serves the RFC 8414 metadata of the OAuth2 authorization server, so apps
+// can find its endpoints. Like the JWKS it is a bare JSON document.
+func (h *WellKnownHandler) GetOAuthMetadata(w http.ResponseWriter, r *http.Request) {
+	oauthConfig := config.GetConfig().OAuthServer
+
+	scopes := make([]string, 0, len(models.OAuthScopes))
+	for scope := range models.OAuthScopes {
+		scopes = append(scopes, scope)
+	}
+	slices.Sort(scopes)
+
+	responseJSON, err := json.Marshal(dtos.OAuthServerMetadataDto{
+		Issuer:                            oauthConfig.IssuerURL,
+		AuthorizationEndpoint:             oauthConfig.ConsentURL,
+		TokenEndpoint:                     oauthConfig.IssuerURL + "/api/v1/oauth/token",
+		IntrospectionEndpoint:             oauthConfig.IssuerURL + "/api/v1/oauth/introspect",
+		RevocationEndpoint:                oauthConfig.IssuerURL + "/api/v1/oauth/revoke",
+		ScopesSupported:                   scopes,
+		ResponseTypesSupported:            []string{"code"},
+		GrantTypesSupported:               []string{"authorization_code", "refresh_token"},
+		CodeChallengeMethodsSupported:     []string{"S256"},
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "none"},
+	})
+	if err != nil {
+		h.Logger.Error("Failed to marshal OAuth metadata", zap.Error(err))
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("Internal server error"))
+		return
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(responseJSON)
+}
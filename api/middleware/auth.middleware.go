@@ -2,47 +2,154 @@ package middleware
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strings"
+	"todo-api/config"
 	"todo-api/internal/dtos"
+	"todo-api/internal/models"
+	"todo-api/internal/repositories"
 	"todo-api/internal/utils"
 
 	"go.uber.org/zap"
 )
 
-// AuthMiddleware is a middleware that checks if the request has a valid JWT token
+// AuthMiddleware is a middleware that checks if the request has a valid JWT token,
+// personal access token or OAuth2 access token, or an access token cookie in the cookie session mode
 func AuthMiddleware(logger *zap.Logger) func(next http.Handler) http.Handler {
+	revocations := repositories.NewRevocationRepository(logger)
+	personalAccessTokens := repositories.NewPersonalAccessTokenRepository(logger)
+	oauthTokens := repositories.NewOAuthRepository(logger)
+	sessions := repositories.NewSessionRepository(logger)
+	securityEvents := repositories.NewSecurityEventRepository(logger)
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Security events recorded further down the chain name the client
+			r = r.WithContext(utils.SetClientInfoInContext(r.Context(), utils.NewClientInfo(r)))
+
+			var tokenString string
+			fromCookie := false
+
 			// Get the Authorization header
 			authHeader := r.Header.Get("Authorization")
 			if authHeader == "" {
-				logger.Warn("Missing Authorization header")
-				respondWithError(w, "Unauthorized", http.StatusUnauthorized)
-				return
-			}
+				// Browsers in the cookie session mode send the access token as a cookie instead
+				cookie, err := r.Cookie(utils.AccessTokenCookie)
+				if err != nil || !config.GetConfig().SessionCookie.Enabled {
+					logger.Warn("Missing Authorization header")
+					respondWithError(w, "Unauthorized", http.StatusUnauthorized)
+					return
+				}
+				tokenString = cookie.Value
+				fromCookie = true
+			} else {
+				// Check if the Authorization header has the Bearer prefix
+				if !strings.HasPrefix(authHeader, "Bearer ") {
+					logger.Warn("Invalid Authorization header format")
+					respondWithError(w, "Invalid Authorization header format", http.StatusUnauthorized)
+					return
+				}
 
-			// Check if the Authorization header has the Bearer prefix
-			if !strings.HasPrefix(authHeader, "Bearer ") {
-				logger.Warn("Invalid Authorization header format")
-				respondWithError(w, "Invalid Authorization header format", http.StatusUnauthorized)
-				return
+				// Extract the token
+				tokenString = strings.TrimPrefix(authHeader, "Bearer ")
 			}
 
-			// Extract the token
-			tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+			var claims *utils.JWTClaims
 
-			// Validate the token
-			claims, err := utils.ValidateToken(tokenString)
-			if err != nil {
-				logger.Warn("Invalid token", zap.Error(err))
-				respondWithError(w, "Invalid token", http.StatusUnauthorized)
-				return
+			// Personal access tokens are never stored in cookies
+			if !fromCookie && strings.HasPrefix(tokenString, utils.PersonalAccessTokenPrefix) {
+				// Personal access tokens are revoked individually, not by logout
+				var err error
+				claims, err = personalAccessTokens.Authenticate(r.Context(), tokenString, utils.GetClientIP(r))
+				if errors.Is(err, repositories.ErrInvalidPersonalAccessToken) {
+					logger.Warn("Invalid personal access token")
+					respondWithError(w, "Invalid token", http.StatusUnauthorized)
+					return
+				}
+				if err != nil {
+					logger.Error("Failed to authenticate personal access token", zap.Error(err))
+					respondWithError(w, "Internal Server Error", http.StatusInternalServerError)
+					return
+				}
+			} else if !fromCookie && strings.HasPrefix(tokenString, utils.OAuthAccessTokenPrefix) {
+				// Tokens of third-party apps are looked up like personal access tokens
+				var err error
+				claims, err = oauthTokens.Authenticate(r.Context(), tokenString)
+				if errors.Is(err, repositories.ErrInvalidOAuthToken) {
+					logger.Warn("Invalid OAuth access token")
+					respondWithError(w, "Invalid token", http.StatusUnauthorized)
+					return
+				}
+				if err != nil {
+					logger.Error("Failed to authenticate OAuth access token", zap.Error(err))
+					respondWithError(w, "Internal Server Error", http.StatusInternalServerError)
+					return
+				}
+			} else {
+				// Validate the token
+				var err error
+				claims, err = utils.ValidateToken(tokenString)
+				if err != nil {
+					logger.Warn("Invalid token", zap.Error(err))
+					respondWithError(w, "Invalid token", http.StatusUnauthorized)
+					return
+				}
+
+				// Reject tokens that were logged out, whose session was revoked or that were invalidated by a logout-all
+				revoked, err := revocations.IsRevoked(r.Context(), claims)
+				if err != nil {
+					logger.Error("Failed to check token revocation", zap.Error(err))
+					respondWithError(w, "Internal Server Error", http.StatusInternalServerError)
+					return
+				}
+				if revoked {
+					logger.Warn("Revoked token used", zap.Uint("userId", claims.UserID), zap.String("jti", claims.ID))
+					securityEvents.Record(r.Context(), models.SecurityEvent{
+						UserID:  claims.UserID,
+						Type:    models.SecurityEventRevokedTokenUsed,
+						Details: r.Method + " " + r.URL.Path,
+					})
+					respondWithError(w, "Token has been revoked", http.StatusUnauthorized)
+					return
+				}
+
+				// The browser attaches cookies to requests started by any site, so writes must
+				// prove they come from the web app by echoing the session's CSRF token
+				if fromCookie && !hasValidCSRFToken(r, claims) {
+					logger.Warn("Missing or invalid CSRF token", zap.Uint("userId", claims.UserID), zap.String("path", r.URL.Path))
+					respondWithError(w, "Invalid CSRF token", http.StatusForbidden)
+					return
+				}
+
+				if claims.SessionID != 0 {
+					sessions.TouchSession(r.Context(), claims.SessionID, utils.GetClientIP(r))
+				}
 			}
 
-			// Add the user ID to the request context
+			// Everything done on behalf of another user has to be traceable to the administrator
+			if claims.Actor != nil {
+				logger.Info("Impersonated request",
+					zap.Uint("actorId", claims.Actor.UserID),
+					zap.String("actorEmail", claims.Actor.Email),
+					zap.Uint("userId", claims.UserID),
+					zap.String("jti", claims.ID),
+					zap.String("method", r.Method),
+					zap.String("path", r.URL.Path),
+					zap.String("ip", utils.GetClientIP(r)),
+				)
+				securityEvents.Record(r.Context(), models.SecurityEvent{
+					UserID:  claims.UserID,
+					Type:    models.SecurityEventImpersonatedRequest,
+					Details: r.Method + " " + r.URL.Path,
+					ActorID: &claims.Actor.UserID,
+				})
+			}
+
+			// Add the user ID and claims to the request context
 			ctx := r.Context()
 			ctx = utils.SetUserIDInContext(ctx, claims.UserID)
+			ctx = utils.SetClaimsInContext(ctx, claims)
 			r = r.WithContext(ctx)
 
 			// Call the next handler
@@ -51,6 +158,63 @@ func AuthMiddleware(logger *zap.Logger) func(next http.Handler) http.Handler {
 	}
 }
 
+// RequireSessionToken rejects requests authenticated with a personal access token, an OAuth2
+// access token or an impersonation token. Account management such as creating new tokens needs
+// a real login, otherwise a narrowly scoped token could mint itself an unrestricted one, and an
+// administrator acting as a user could take over the account.
+func RequireSessionToken(logger *zap.Logger) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, err := utils.GetClaimsFromContext(r.Context())
+			if err == nil && claims.TokenType == utils.TokenTypePersonalAccessToken {
+				logger.Warn("Personal access token used for account management", zap.Uint("userId", claims.UserID), zap.String("path", r.URL.Path))
+				respondWithError(w, "This endpoint cannot be used with a personal access token", http.StatusForbidden)
+				return
+			}
+			if err == nil && claims.TokenType == utils.TokenTypeOAuth {
+				logger.Warn("OAuth access token used for account management", zap.Uint("userId", claims.UserID), zap.String("clientId", claims.ClientID), zap.String("path", r.URL.Path))
+				respondWithError(w, "This endpoint cannot be used with an OAuth access token", http.StatusForbidden)
+				return
+			}
+			if err == nil && claims.TokenType == utils.TokenTypeImpersonation {
+				logger.Warn("Impersonation token used for account management", zap.Uint("userId", claims.UserID), zap.Uint("actorId", claims.Actor.UserID), zap.String("path", r.URL.Path))
+				respondWithError(w, "This endpoint cannot be used while impersonating a user", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ReadOnlyMiddleware rejects state-changing requests made with a read-only token,
+// which is what users get while their email address is unverified
+func ReadOnlyMiddleware(logger *zap.Logger) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, err := utils.GetClaimsFromContext(r.Context())
+			if err == nil && claims.ReadOnly && !isSafeMethod(r.Method) {
+				logger.Warn("Write attempted with read-only token", zap.Uint("userId", claims.UserID), zap.String("path", r.URL.Path))
+				respondWithError(w, "Email address must be verified before making changes", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// hasValidCSRFToken reports whether a request authenticated with the access token cookie may go
+// ahead: reads always may, writes only with the CSRF token of the access token's session
+func hasValidCSRFToken(r *http.Request, claims *utils.JWTClaims) bool {
+	return isSafeMethod(r.Method) || utils.ValidCSRFToken(r.Header.Get(utils.CSRFHeader), utils.CSRFToken(claims.SessionID))
+}
+
+// isSafeMethod reports whether the HTTP method does not modify state
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
 // Helper function to respond with an error
 func respondWithError(w http.ResponseWriter, message string, statusCode int) {
 	w.Header().Set("Content-Type", "application/json")
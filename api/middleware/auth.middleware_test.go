@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"todo-api/config"
+	"todo-api/internal/utils"
+)
+
+func TestHasValidCSRFToken(t *testing.T) {
+	previous := config.GetConfig().EncryptionKey
+	config.GetConfig().EncryptionKey = "csrf-test-key"
+	t.Cleanup(func() {
+		config.GetConfig().EncryptionKey = previous
+	})
+
+	session := &utils.JWTClaims{SessionID: 7}
+	withoutSession := &utils.JWTClaims{TokenType: utils.TokenTypeImpersonation}
+
+	tests := []struct {
+		name      string
+		method    string
+		csrfToken string
+		claims    *utils.JWTClaims
+		want      bool
+	}{
+		{"read without token", http.MethodGet, "", session, true},
+		{"write with the session's token", http.MethodPost, utils.CSRFToken(7), session, true},
+		{"write without token", http.MethodDelete, "", session, false},
+		{"write with another session's token", http.MethodPut, utils.CSRFToken(8), session, false},
+		{"write without a session", http.MethodPost, utils.CSRFToken(0), withoutSession, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, "/api/v1/todos", nil)
+			if tt.csrfToken != "" {
+				r.Header.Set(utils.CSRFHeader, tt.csrfToken)
+			}
+
+			if got := hasValidCSRFToken(r, tt.claims); got != tt.want {
+				t.Errorf("hasValidCSRFToken = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"todo-api/config"
+)
+
+// Deprecated announces that the routes it wraps are going away: the Deprecation header of
+// RFC 9745 and the Sunset header of RFC 8594 carry the dates of LegacyRoutesConfig that are set,
+// and a Link header names the route that replaces them. The headers are set before the request is
+// handled, so they are also present on error responses.
+func Deprecated(successor string) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			legacyRoutes := config.GetConfig().LegacyRoutes
+
+			if !legacyRoutes.DeprecatedAt.IsZero() {
+				w.Header().Set("Deprecation", fmt.Sprintf("@%d", legacyRoutes.DeprecatedAt.Unix()))
+			}
+			if !legacyRoutes.SunsetAt.IsZero() {
+				w.Header().Set("Sunset", legacyRoutes.SunsetAt.UTC().Format(http.TimeFormat))
+			}
+			w.Header().Add("Link", fmt.Sprintf(`<%s>; rel="successor-version"`, successor))
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
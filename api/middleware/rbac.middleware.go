@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"net/http"
+	"todo-api/internal/utils"
+
+	"go.uber.org/zap"
+)
+
+// RequireRole only lets requests through when the token carries at least one of the roles.
+// It must run after AuthMiddleware, e.g. on a router returned by routes.ApplyAuthMiddleware.
+func RequireRole(logger *zap.Logger, roles ...string) func(next http.Handler) http.Handler {
+	return requireClaims(logger, func(claims *utils.JWTClaims) bool {
+		for _, role := range roles {
+			if claims.HasRole(role) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// RequirePermission only lets requests through when the token carries every permission.
+// It must run after AuthMiddleware, e.g. on a router returned by routes.ApplyAuthMiddleware.
+func RequirePermission(logger *zap.Logger, permissions ...string) func(next http.Handler) http.Handler {
+	return requireClaims(logger, func(claims *utils.JWTClaims) bool {
+		for _, permission := range permissions {
+			if !claims.HasPermission(permission) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+func requireClaims(logger *zap.Logger, allowed func(claims *utils.JWTClaims) bool) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, err := utils.GetClaimsFromContext(r.Context())
+			if err != nil {
+				logger.Warn("Authorization check without authenticated user", zap.String("path", r.URL.Path))
+				respondWithError(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			if !allowed(claims) {
+				logger.Warn("Access denied",
+					zap.Uint("userId", claims.UserID),
+					zap.Strings("roles", claims.Roles),
+					zap.String("path", r.URL.Path),
+				)
+				respondWithError(w, "Forbidden", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
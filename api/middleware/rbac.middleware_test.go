@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"todo-api/internal/models"
+	"todo-api/internal/utils"
+
+	"go.uber.org/zap"
+)
+
+func TestRequireRoleAndPermission(t *testing.T) {
+	user := &utils.JWTClaims{
+		Roles:       []string{models.RoleUser},
+		Permissions: []string{models.PermissionTodosRead, models.PermissionTodosWrite},
+	}
+	admin := &utils.JWTClaims{
+		Roles:       []string{models.RoleUser, models.RoleAdmin},
+		Permissions: []string{models.PermissionTodosRead, models.PermissionTodosWrite, models.PermissionUsersRead, models.PermissionUsersWrite},
+	}
+
+	tests := []struct {
+		name       string
+		middleware func(next http.Handler) http.Handler
+		claims     *utils.JWTClaims
+		want       int
+	}{
+		{"role held", RequireRole(zap.NewNop(), models.RoleAdmin), admin, http.StatusOK},
+		{"one of the roles held", RequireRole(zap.NewNop(), models.RoleAdmin, models.RoleUser), user, http.StatusOK},
+		{"role missing", RequireRole(zap.NewNop(), models.RoleAdmin), user, http.StatusForbidden},
+		{"every permission held", RequirePermission(zap.NewNop(), models.PermissionTodosRead, models.PermissionTodosWrite), user, http.StatusOK},
+		{"one permission missing", RequirePermission(zap.NewNop(), models.PermissionTodosRead, models.PermissionUsersWrite), user, http.StatusForbidden},
+		{"not authenticated", RequirePermission(zap.NewNop(), models.PermissionTodosRead), nil, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := tt.middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/admin/roles", nil)
+			if tt.claims != nil {
+				r = r.WithContext(utils.SetClaimsInContext(r.Context(), tt.claims))
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package routes
+
+import (
+	"net/http"
+	"todo-api/api/handlers"
+	"todo-api/api/middleware"
+	"todo-api/internal/models"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+func HandleAdminRoutes(api *mux.Router, logger *zap.Logger) {
+	adminHandler := handlers.NewAdminHandler(logger)
+
+	// Every admin route requires an authenticated administrator
+	adminRouter := ApplyAuthMiddleware(api, logger)
+	adminRouter.Use(middleware.RequireRole(logger, models.RoleAdmin))
+
+	adminRouter.Handle("/roles",
+		middleware.RequirePermission(logger, models.PermissionUsersRead)(http.HandlerFunc(adminHandler.GetRoles)),
+	).Methods(http.MethodGet)
+	adminRouter.Handle("/users/{id:[0-9]+}/roles",
+		middleware.RequirePermission(logger, models.PermissionUsersWrite)(http.HandlerFunc(adminHandler.SetUserRoles)),
+	).Methods(http.MethodPut)
+
+	adminRouter.Handle("/users",
+		middleware.RequirePermission(logger, models.PermissionUsersRead)(http.HandlerFunc(adminHandler.ListUsers)),
+	).Methods(http.MethodGet)
+	adminRouter.Handle("/users",
+		middleware.RequirePermission(logger, models.PermissionUsersWrite)(http.HandlerFunc(adminHandler.CreateUser)),
+	).Methods(http.MethodPost)
+	adminRouter.Handle("/users/{id:[0-9]+}",
+		middleware.RequirePermission(logger, models.PermissionUsersRead)(http.HandlerFunc(adminHandler.GetUser)),
+	).Methods(http.MethodGet)
+	adminRouter.Handle("/users/{id:[0-9]+}/security-events",
+		middleware.RequirePermission(logger, models.PermissionUsersRead)(http.HandlerFunc(adminHandler.GetUserSecurityEvents)),
+	).Methods(http.MethodGet)
+	adminRouter.Handle("/users/{id:[0-9]+}/disable",
+		middleware.RequirePermission(logger, models.PermissionUsersWrite)(http.HandlerFunc(adminHandler.DisableUser)),
+	).Methods(http.MethodPost)
+	adminRouter.Handle("/users/{id:[0-9]+}/enable",
+		middleware.RequirePermission(logger, models.PermissionUsersWrite)(http.HandlerFunc(adminHandler.EnableUser)),
+	).Methods(http.MethodPost)
+	adminRouter.Handle("/users/{id:[0-9]+}/reset-password",
+		middleware.RequirePermission(logger, models.PermissionUsersWrite)(http.HandlerFunc(adminHandler.ResetUserPassword)),
+	).Methods(http.MethodPost)
+	adminRouter.Handle("/users/{id:[0-9]+}/logout",
+		middleware.RequirePermission(logger, models.PermissionUsersWrite)(http.HandlerFunc(adminHandler.ForceLogout)),
+	).Methods(http.MethodPost)
+
+	adminRouter.Handle("/invite-codes",
+		middleware.RequirePermission(logger, models.PermissionUsersRead)(http.HandlerFunc(adminHandler.GetInviteCodes)),
+	).Methods(http.MethodGet)
+	adminRouter.Handle("/invite-codes",
+		middleware.RequirePermission(logger, models.PermissionUsersWrite)(http.HandlerFunc(adminHandler.CreateInviteCode)),
+	).Methods(http.MethodPost)
+	adminRouter.Handle("/invite-codes/{id:[0-9]+}",
+		middleware.RequirePermission(logger, models.PermissionUsersWrite)(http.HandlerFunc(adminHandler.RevokeInviteCode)),
+	).Methods(http.MethodDelete)
+
+	adminRouter.Handle("/oauth-clients",
+		middleware.RequirePermission(logger, models.PermissionClientsRead)(http.HandlerFunc(adminHandler.GetOAuthClients)),
+	).Methods(http.MethodGet)
+	adminRouter.Handle("/oauth-clients",
+		middleware.RequirePermission(logger, models.PermissionClientsWrite)(http.HandlerFunc(adminHandler.CreateOAuthClient)),
+	).Methods(http.MethodPost)
+	adminRouter.Handle("/oauth-clients/{id:[0-9]+}",
+		middleware.RequirePermission(logger, models.PermissionClientsWrite)(http.HandlerFunc(adminHandler.DeleteOAuthClient)),
+	).Methods(http.MethodDelete)
+
+	// Acting as someone else needs a real login, a leaked personal access token must not be enough
+	adminRouter.Handle("/users/{id:[0-9]+}/impersonate",
+		middleware.RequireSessionToken(logger)(
+			middleware.RequirePermission(logger, models.PermissionUsersImpersonate)(http.HandlerFunc(adminHandler.ImpersonateUser)),
+		),
+	).Methods(http.MethodPost)
+}
@@ -4,6 +4,8 @@ import (
 	"net/http"
 
 	"todo-api/api/handlers"
+	"todo-api/api/middleware"
+	"todo-api/config"
 
 	"github.com/gorilla/mux"
 	"go.uber.org/zap"
@@ -15,4 +17,47 @@ func HandleAuthRoutes(api *mux.Router, logger *zap.Logger) {
 
 	api.HandleFunc("/register", authHandler.RegisterUser).Methods(http.MethodPost)
 	api.HandleFunc("/login", authHandler.LoginUser).Methods(http.MethodPost)
+	api.HandleFunc("/login/2fa", authHandler.LoginWithTwoFactor).Methods(http.MethodPost)
+	api.HandleFunc("/refresh", authHandler.RefreshToken).Methods(http.MethodPost)
+	api.HandleFunc("/forgot-password", authHandler.ForgotPassword).Methods(http.MethodPost)
+	api.HandleFunc("/reset-password", authHandler.ResetPassword).Methods(http.MethodPost)
+	api.HandleFunc("/verify-email", authHandler.VerifyEmail).Methods(http.MethodPost)
+	api.HandleFunc("/resend-verification", authHandler.ResendVerification).Methods(http.MethodPost)
+	api.HandleFunc("/confirm-email-change", authHandler.ConfirmEmailChange).Methods(http.MethodPost)
+
+	// Single sign-on is opt-in, without it the routes do not exist
+	if config.GetConfig().OIDC.Enabled {
+		api.HandleFunc("/oidc/login", authHandler.StartOIDCLogin).Methods(http.MethodGet)
+		api.HandleFunc("/oidc/callback", authHandler.CompleteOIDCLogin).Methods(http.MethodGet)
+	}
+
+	// Passwordless login is opt-in as well
+	if config.GetConfig().MagicLink.Enabled {
+		api.HandleFunc("/magic-link", authHandler.RequestMagicLink).Methods(http.MethodPost)
+		api.HandleFunc("/magic-link/verify", authHandler.LoginWithMagicLink).Methods(http.MethodPost)
+	}
+
+	// Logging out only gives up access, so read-only tokens of unverified users may use it too
+	sessionRouter := api.NewRoute().Subrouter()
+	sessionRouter.Use(middleware.AuthMiddleware(logger))
+	sessionRouter.HandleFunc("/logout", authHandler.Logout).Methods(http.MethodPost)
+	sessionRouter.HandleFunc("/logout-all", authHandler.LogoutAll).Methods(http.MethodPost)
+
+	// Sessions belong to real logins, and like logout revoking them is allowed with read-only tokens
+	deviceRouter := api.NewRoute().Subrouter()
+	deviceRouter.Use(middleware.AuthMiddleware(logger))
+	deviceRouter.Use(middleware.RequireSessionToken(logger))
+	deviceRouter.HandleFunc("/sessions", authHandler.GetSessions).Methods(http.MethodGet)
+	deviceRouter.HandleFunc("/sessions", authHandler.RevokeOtherSessions).Methods(http.MethodDelete)
+	deviceRouter.HandleFunc("/sessions/{id:[0-9]+}", authHandler.RevokeSession).Methods(http.MethodDelete)
+
+	// Personal access tokens and two-factor settings can only be managed from a real login
+	tokenRouter := ApplyAuthMiddleware(api, logger)
+	tokenRouter.Use(middleware.RequireSessionToken(logger))
+	tokenRouter.HandleFunc("/tokens", authHandler.CreatePersonalAccessToken).Methods(http.MethodPost)
+	tokenRouter.HandleFunc("/tokens", authHandler.GetPersonalAccessTokens).Methods(http.MethodGet)
+	tokenRouter.HandleFunc("/tokens/{id:[0-9]+}", authHandler.RevokePersonalAccessToken).Methods(http.MethodDelete)
+	tokenRouter.HandleFunc("/2fa/enroll", authHandler.EnrollTwoFactor).Methods(http.MethodPost)
+	tokenRouter.HandleFunc("/2fa/confirm", authHandler.ConfirmTwoFactor).Methods(http.MethodPost)
+	tokenRouter.HandleFunc("/2fa/disable", authHandler.DisableTwoFactor).Methods(http.MethodPost)
 }
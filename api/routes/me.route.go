@@ -0,0 +1,34 @@
+package routes
+
+import (
+	"net/http"
+
+	"todo-api/api/handlers"
+	"todo-api/api/middleware"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+func HandleMeRoutes(api *mux.Router, logger *zap.Logger) {
+
+	userHandler := handlers.NewUserHandler(logger)
+
+	// Reading the profile is allowed with personal access tokens, e.g. to check whose token it is
+	profileRouter := ApplyAuthMiddleware(api, logger)
+	profileRouter.HandleFunc("", userHandler.GetProfile).Methods(http.MethodGet)
+
+	// Changing the account needs a real login
+	accountRouter := ApplyAuthMiddleware(api, logger)
+	accountRouter.Use(middleware.RequireSessionToken(logger))
+	accountRouter.HandleFunc("", userHandler.UpdateProfile).Methods(http.MethodPatch)
+	accountRouter.HandleFunc("/password", userHandler.ChangePassword).Methods(http.MethodPut)
+	accountRouter.HandleFunc("/security-events", userHandler.GetSecurityEvents).Methods(http.MethodGet)
+
+	// Unverified users may fix a mistyped address or delete the account, so read-only tokens are accepted here
+	unverifiedRouter := api.NewRoute().Subrouter()
+	unverifiedRouter.Use(middleware.AuthMiddleware(logger))
+	unverifiedRouter.Use(middleware.RequireSessionToken(logger))
+	unverifiedRouter.HandleFunc("/email", userHandler.ChangeEmail).Methods(http.MethodPut)
+	unverifiedRouter.HandleFunc("", userHandler.DeleteAccount).Methods(http.MethodDelete)
+}
@@ -7,9 +7,11 @@ import (
 	"go.uber.org/zap"
 )
 
-// ApplyAuthMiddleware applies the authentication middleware to a router
+// ApplyAuthMiddleware applies the authentication middleware to a router.
+// Read-only tokens are limited to safe methods on the returned router.
 func ApplyAuthMiddleware(router *mux.Router, logger *zap.Logger) *mux.Router {
 	protectedRouter := router.NewRoute().Subrouter()
 	protectedRouter.Use(middleware.AuthMiddleware(logger))
+	protectedRouter.Use(middleware.ReadOnlyMiddleware(logger))
 	return protectedRouter
 }
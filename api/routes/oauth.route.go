@@ -0,0 +1,32 @@
+package routes
+
+import (
+	"net/http"
+
+	"todo-api/api/handlers"
+	"todo-api/api/middleware"
+	"todo-api/config"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+func HandleOAuthRoutes(api *mux.Router, logger *zap.Logger) {
+	// The authorization server is opt-in, without it the routes do not exist
+	if !config.GetConfig().OAuthServer.Enabled {
+		return
+	}
+
+	oauthHandler := handlers.NewOAuthHandler(logger)
+
+	// Apps authenticate themselves on these, not the user
+	api.HandleFunc("/token", oauthHandler.Token).Methods(http.MethodPost)
+	api.HandleFunc("/introspect", oauthHandler.Introspect).Methods(http.MethodPost)
+	api.HandleFunc("/revoke", oauthHandler.Revoke).Methods(http.MethodPost)
+
+	// Only the user, logged in for real, can let an app in
+	consentRouter := ApplyAuthMiddleware(api, logger)
+	consentRouter.Use(middleware.RequireSessionToken(logger))
+	consentRouter.HandleFunc("/authorize", oauthHandler.GetAuthorization).Methods(http.MethodGet)
+	consentRouter.HandleFunc("/authorize", oauthHandler.Authorize).Methods(http.MethodPost)
+}
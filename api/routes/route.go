@@ -10,14 +10,35 @@ func SetupRoutes(router *mux.Router, logger *zap.Logger) {
 	// Create API v1 subrouter
 	api := router.PathPrefix("/api/v1").Subrouter()
 
-	// Create todo subrouter and register routes
+	// Create todos subrouter and register the todo resources
+	todosRouter := api.PathPrefix("/todos").Subrouter()
+	HandleTodoRoutes(todosRouter, logger)
+
+	// The RPC style todo routes stay as deprecated aliases. They are registered after /todos,
+	// whose paths also start with /todo.
 	todoRouter := api.PathPrefix("/todo").Subrouter()
-	HandleTodoRoutes(todoRouter, logger)
+	HandleLegacyTodoRoutes(todoRouter, logger)
 
 	// Create auth subrouter and register routes
 	authRouter := api.PathPrefix("/auth").Subrouter()
 	HandleAuthRoutes(authRouter, logger)
 
+	// Create me subrouter for the current user's account and register routes
+	meRouter := api.PathPrefix("/me").Subrouter()
+	HandleMeRoutes(meRouter, logger)
+
+	// Create admin subrouter and register routes
+	adminRouter := api.PathPrefix("/admin").Subrouter()
+	HandleAdminRoutes(adminRouter, logger)
+
+	// Create OAuth2 authorization server subrouter and register routes
+	oauthRouter := api.PathPrefix("/oauth").Subrouter()
+	HandleOAuthRoutes(oauthRouter, logger)
+
+	// Discovery documents live at the root, where other services look for them
+	wellKnownRouter := router.PathPrefix("/.well-known").Subrouter()
+	HandleWellKnownRoutes(wellKnownRouter, logger)
+
 	router.PathPrefix("/swagger/").Handler(httpSwagger.Handler(
 		httpSwagger.URL("/swagger/doc.json"), // The URL pointing to API definition
 		httpSwagger.DeepLinking(true),
@@ -3,26 +3,55 @@ package routes
 import (
 	"net/http"
 	"todo-api/api/handlers"
+	"todo-api/api/middleware"
+	"todo-api/internal/models"
 
 	"github.com/gorilla/mux"
 	"go.uber.org/zap"
 )
 
+// HandleTodoRoutes registers the todo resources, /todos and /todos/{id} with their notes
 func HandleTodoRoutes(api *mux.Router, logger *zap.Logger) {
 	// Debug route to confirm this handler is being registered
 	logger.Info("Todo routes registered")
 
-	// Root todo endpoint
 	todoHandler := handlers.NewTodoHandler(logger)
 
-	// Public routes (if any)
-	// No public routes for now
-
 	// Protected routes (require authentication)
+	// Each route also requires the permission matching its action, which is how
+	// personal access token scopes are enforced
+	protectedRouter := ApplyAuthMiddleware(api, logger)
+	canRead := middleware.RequirePermission(logger, models.PermissionTodosRead)
+	canWrite := middleware.RequirePermission(logger, models.PermissionTodosWrite)
+
+	protectedRouter.Handle("", canRead(http.HandlerFunc(todoHandler.ListTodoItems))).Methods(http.MethodGet)
+	protectedRouter.Handle("", canWrite(http.HandlerFunc(todoHandler.CreateTodo))).Methods(http.MethodPost)
+	protectedRouter.Handle("/search", canRead(http.HandlerFunc(todoHandler.SearchTodoItems))).Methods(http.MethodGet)
+	protectedRouter.Handle("/{id:[0-9]+}", canRead(http.HandlerFunc(todoHandler.GetTodo))).Methods(http.MethodGet)
+	protectedRouter.Handle("/{id:[0-9]+}", canWrite(http.HandlerFunc(todoHandler.PatchTodo))).Methods(http.MethodPatch)
+	protectedRouter.Handle("/{id:[0-9]+}", canWrite(http.HandlerFunc(todoHandler.ReplaceTodo))).Methods(http.MethodPut)
+	protectedRouter.Handle("/{id:[0-9]+}", canWrite(http.HandlerFunc(todoHandler.DeleteTodo))).Methods(http.MethodDelete)
+	protectedRouter.Handle("/{id:[0-9]+}/notes", canRead(http.HandlerFunc(todoHandler.ListTodoNotes))).Methods(http.MethodGet)
+	protectedRouter.Handle("/{id:[0-9]+}/notes", canWrite(http.HandlerFunc(todoHandler.AddTodoNote))).Methods(http.MethodPost)
+}
+
+// HandleLegacyTodoRoutes registers the RPC style routes that came before the /todos resources.
+// They are kept as aliases and announce their replacement in the Deprecation, Sunset and Link headers.
+func HandleLegacyTodoRoutes(api *mux.Router, logger *zap.Logger) {
+	logger.Info("Legacy todo routes registered")
+
+	todoHandler := handlers.NewTodoHandler(logger)
+
+	// Runs before authentication, so rejected requests are told about the replacement too
+	api.Use(middleware.Deprecated("/api/v1/todos"))
+
 	protectedRouter := ApplyAuthMiddleware(api, logger)
-	protectedRouter.HandleFunc("/get-todos", todoHandler.GetTodoItems).Methods(http.MethodGet)
-	protectedRouter.HandleFunc("/create-todo-item", todoHandler.CreateTodoItem).Methods(http.MethodPost)
-	protectedRouter.HandleFunc("/create-todo-note", todoHandler.CreateTodoNote).Methods(http.MethodPost)
-	protectedRouter.HandleFunc("/update-todo-item", todoHandler.UpdateTodoItem).Methods(http.MethodPut)
-	protectedRouter.HandleFunc("/delete-todo-item", todoHandler.DeleteTodoItem).Methods(http.MethodDelete)
+	canRead := middleware.RequirePermission(logger, models.PermissionTodosRead)
+	canWrite := middleware.RequirePermission(logger, models.PermissionTodosWrite)
+
+	protectedRouter.Handle("/get-todos", canRead(http.HandlerFunc(todoHandler.GetTodoItems))).Methods(http.MethodGet)
+	protectedRouter.Handle("/create-todo-item", canWrite(http.HandlerFunc(todoHandler.CreateTodoItem))).Methods(http.MethodPost)
+	protectedRouter.Handle("/create-todo-note", canWrite(http.HandlerFunc(todoHandler.CreateTodoNote))).Methods(http.MethodPost)
+	protectedRouter.Handle("/update-todo-item", canWrite(http.HandlerFunc(todoHandler.UpdateTodoItem))).Methods(http.MethodPut)
+	protectedRouter.Handle("/delete-todo-item", canWrite(http.HandlerFunc(todoHandler.DeleteTodoItem))).Methods(http.MethodDelete)
 }
@@ -0,0 +1,135 @@
+package routes
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+	"todo-api/database"
+	"todo-api/internal/dtos"
+	"todo-api/internal/models"
+	"todo-api/internal/repositories"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openTestDB connects to the Postgres database named by TEST_DATABASE_URL and migrates it.
+// The test is skipped when the variable is not set.
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+		TranslateError: true,
+		Logger:         logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to connect to the test database: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	database.DB = db
+	if err := database.Migrate(); err != nil {
+		t.Fatalf("failed to migrate the test database: %v", err)
+	}
+
+	return db
+}
+
+func TestTodoRoutesEnforceTokenScopes(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+
+	// An administrator, so the test shows that unscoped tokens do not carry the admin permissions
+	verifiedAt := time.Now()
+	user := models.User{
+		Email:           fmt.Sprintf("scopes-%d@example.com", time.Now().UnixNano()),
+		Name:            "scopes",
+		PasswordHash:    "unused",
+		EmailVerifiedAt: &verifiedAt,
+	}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Delete(&models.User{}, user.ID)
+	})
+	var roles []models.Role
+	if err := db.Where("name IN ?", []string{models.RoleUser, models.RoleAdmin}).Find(&roles).Error; err != nil {
+		t.Fatalf("failed to find roles: %v", err)
+	}
+	if err := db.Model(&user).Association("Roles").Append(&roles); err != nil {
+		t.Fatalf("failed to assign roles: %v", err)
+	}
+
+	tokens := repositories.NewPersonalAccessTokenRepository(zap.NewNop())
+	createToken := func(t *testing.T, scopes ...string) string {
+		t.Helper()
+
+		response, err := tokens.CreateToken(ctx, dtos.CreatePersonalAccessTokenDto{Name: "test", Scopes: scopes, UserID: user.ID})
+		if err != nil || response.Status != http.StatusCreated {
+			t.Fatalf("CreateToken = %d %s, %v", response.Status, response.Message, err)
+		}
+		return response.Payload.(dtos.CreatedPersonalAccessTokenDto).Token
+	}
+
+	router := mux.NewRouter()
+	api := router.PathPrefix("/api/v1").Subrouter()
+	HandleTodoRoutes(api.PathPrefix("/todos").Subrouter(), zap.NewNop())
+	HandleAdminRoutes(api.PathPrefix("/admin").Subrouter(), zap.NewNop())
+
+	readToken := createToken(t, models.PermissionTodosRead)
+	unscopedToken := createToken(t)
+	adminToken := createToken(t, models.PermissionUsersRead)
+
+	tests := []struct {
+		name   string
+		token  string
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{"read scope lists todos", readToken, http.MethodGet, "/api/v1/todos", "", http.StatusOK},
+		{"read scope searches todos", readToken, http.MethodGet, "/api/v1/todos/search?q=milk", "", http.StatusOK},
+		{"read scope cannot create", readToken, http.MethodPost, "/api/v1/todos", `{"title": "Buy milk"}`, http.StatusForbidden},
+		{"read scope cannot delete", readToken, http.MethodDelete, "/api/v1/todos/1", "", http.StatusForbidden},
+		{"read scope cannot add notes", readToken, http.MethodPost, "/api/v1/todos/1/notes", `{"content": "2%"}`, http.StatusForbidden},
+		{"unscoped token lists todos", unscopedToken, http.MethodGet, "/api/v1/todos", "", http.StatusOK},
+		{"unscoped token creates todos", unscopedToken, http.MethodPost, "/api/v1/todos", `{"title": "Buy milk"}`, http.StatusCreated},
+		{"unscoped token of an administrator cannot list users", unscopedToken, http.MethodGet, "/api/v1/admin/users", "", http.StatusForbidden},
+		{"admin scope lists users", adminToken, http.MethodGet, "/api/v1/admin/users", "", http.StatusOK},
+		{"admin scope cannot list todos", adminToken, http.MethodGet, "/api/v1/todos", "", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			r.Header.Set("Authorization", "Bearer "+tt.token)
+			r.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, r)
+
+			if w.Code != tt.want {
+				t.Errorf("%s %s = %d %s, want %d", tt.method, tt.path, w.Code, w.Body.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"net/http"
+	"todo-api/api/handlers"
+	"todo-api/config"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+func HandleWellKnownRoutes(router *mux.Router, logger *zap.Logger) {
+	wellKnownHandler := handlers.NewWellKnownHandler(logger)
+
+	router.HandleFunc("/jwks.json", wellKnownHandler.GetJWKS).Methods(http.MethodGet)
+
+	if config.GetConfig().OAuthServer.Enabled {
+		router.HandleFunc("/oauth-authorization-server", wellKnownHandler.GetOAuthMetadata).Methods(http.MethodGet)
+	}
+}
@@ -3,15 +3,40 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	Database  DatabaseConfig
-	Server    ServerConfig
-	JWTSecret string
-	Env       string
+	Database        DatabaseConfig
+	Server          ServerConfig
+	Auth            AuthConfig
+	LoginProtection LoginProtectionConfig
+	PasswordHashing PasswordHashingConfig
+	PasswordPolicy  PasswordPolicyConfig
+	Mail            MailConfig
+	BootstrapAdmin  BootstrapAdminConfig
+	OIDC            OIDCConfig
+	MagicLink       MagicLinkConfig
+	SessionCookie   SessionCookieConfig
+	CORS            CORSConfig
+	SecurityEvents  SecurityEventsConfig
+	BackgroundJobs  BackgroundJobsConfig
+	Registration    RegistrationConfig
+	OAuthServer     OAuthServerConfig
+	LegacyRoutes    LegacyRoutesConfig
+	TodoList        TodoListConfig
+	JWT             JWTConfig
+	JWTSecret       string
+	// Key for secrets stored encrypted in the database, such as TOTP secrets.
+	// Kept separate from JWT_SECRET so rotating signing keys does not lose data.
+	EncryptionKey string
+	Env           string
+	// Public URL of the web app, used to build links sent by email
+	AppURL string
 }
 
 type DatabaseConfig struct {
@@ -26,6 +51,224 @@ type DatabaseConfig struct {
 
 type ServerConfig struct {
 	Port string
+	// Trust X-Forwarded-For / X-Real-IP, only enable behind a reverse proxy that sets them
+	TrustProxyHeaders bool
+	// Number of reverse proxies in front of the server, each appending to X-Forwarded-For
+	TrustedProxyHops int
+}
+
+// AuthConfig holds the token lifetimes used by the authentication flow
+type AuthConfig struct {
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	// How often each instance reloads the token revocation list from the database
+	RevocationSyncInterval time.Duration
+	PasswordResetTTL       time.Duration
+	// Reset links that can be requested per email address and per client IP within PasswordResetRateWindow
+	PasswordResetEmailLimit int
+	PasswordResetIPLimit    int
+	PasswordResetRateWindow time.Duration
+	EmailVerificationTTL    time.Duration
+	// "block" refuses logins until the email is verified,
+	// "restricted" issues read-only tokens instead
+	EmailVerificationMode string
+	// Lifetime of the challenge token returned by login when two-factor authentication is on
+	TwoFactorChallengeTTL time.Duration
+	// Minimum time between two writes of a session's last-seen time
+	SessionTouchInterval time.Duration
+	// Lifetime of the tokens administrators get to act as another user, they cannot be refreshed
+	ImpersonationTTL time.Duration
+}
+
+// Values accepted by EMAIL_VERIFICATION_MODE
+const (
+	EmailVerificationBlock      = "block"
+	EmailVerificationRestricted = "restricted"
+)
+
+// LoginProtectionConfig holds the brute-force protection thresholds for login.
+// Every failure delays the next attempt exponentially from DelayBase up to MaxDelay,
+// reaching a threshold within FailureWindow locks the account or IP for LockoutDuration.
+type LoginProtectionConfig struct {
+	// "memory" for a single instance, "postgres" to share attempts across a cluster
+	Store                   string
+	FailureWindow           time.Duration
+	AccountFailureThreshold int
+	IPFailureThreshold      int
+	LockoutDuration         time.Duration
+	DelayBase               time.Duration
+	MaxDelay                time.Duration
+}
+
+// JWTConfig holds the access token signing keys besides JWTSecret.
+// Tokens are signed with the key ActiveKeyID from KeysDir, or with JWTSecret (HS256) when it is empty.
+// Every other key in KeysDir and every previous secret is retired: it still verifies tokens but never signs.
+// JWTSecret only verifies access tokens while it signs them, it always keys the emailed action tokens.
+type JWTConfig struct {
+	// Directory of PEM encoded RSA or Ed25519 keys, the file name without .pem is the key ID.
+	// Public-only keys can be kept there to verify tokens after the private key is destroyed.
+	KeysDir     string
+	ActiveKeyID string
+	// HMAC secrets that were used as JWT_SECRET before, kept while their tokens expire
+	PreviousSecrets []string
+}
+
+// OIDCConfig configures single sign-on with an external OpenID Connect provider
+type OIDCConfig struct {
+	Enabled bool
+	// Issuer identifier, the discovery document is read from IssuerURL/.well-known/openid-configuration
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	// Must point at /api/v1/auth/oidc/callback and be registered with the provider
+	RedirectURL string
+	Scopes      []string
+	// Create accounts for unknown verified emails instead of refusing them
+	AutoProvision bool
+	// How long the user may take at the provider before the login attempt expires
+	StateTTL time.Duration
+}
+
+// MagicLinkConfig configures passwordless login with links sent by email
+type MagicLinkConfig struct {
+	Enabled bool
+	// Lifetime of a link, it can be used once
+	TTL time.Duration
+	// Links that can be requested per email address and per client IP within RateWindow
+	EmailLimit int
+	IPLimit    int
+	RateWindow time.Duration
+}
+
+// SessionCookieConfig configures the cookie session mode for browser clients. Clients that opt in
+// receive their tokens as HttpOnly cookies instead of in the response body, and must send the
+// CSRF token in a header with every state-changing request.
+type SessionCookieConfig struct {
+	Enabled bool
+	// Only send the cookies over HTTPS, disable for local development over plain HTTP
+	Secure bool
+	// "lax", "strict" or "none"; "none" is needed when the web app runs on another site and requires Secure
+	SameSite string
+	// Domain attribute of the cookies, empty for the API host only
+	Domain string
+}
+
+// CORSConfig lists the browser origins allowed to call the API. Credentialed requests,
+// which the cookie session mode relies on, cannot be combined with a wildcard origin.
+type CORSConfig struct {
+	AllowedOrigins []string
+	// How long browsers may cache a preflight response
+	MaxAge time.Duration
+}
+
+// SecurityEventsConfig configures the per-account log of logins, password changes and other security events
+type SecurityEventsConfig struct {
+	// How long events are kept, zero keeps them forever
+	Retention time.Duration
+}
+
+// BackgroundJobsConfig sizes the queue of work done after responding, such as sending emails
+type BackgroundJobsConfig struct {
+	Workers int
+	// Jobs that can wait for a worker, further jobs are dropped
+	QueueSize int
+	// Each job is cancelled after this long
+	Timeout time.Duration
+}
+
+// RegistrationConfig controls who may create an account, through /auth/register or the first
+// single sign-on login. Administrators can create accounts in every mode.
+type RegistrationConfig struct {
+	// "open", "invite" or "closed", see the Registration* constants
+	Mode string
+	// Email domains that may register, such as example.com. Empty allows every domain.
+	AllowedDomains []string
+}
+
+// Values accepted by REGISTRATION_MODE
+const (
+	// Anyone may register
+	RegistrationOpen = "open"
+	// Registration needs an invite code created by an administrator
+	RegistrationInvite = "invite"
+	// Only administrators create accounts
+	RegistrationClosed = "closed"
+)
+
+// OAuthServerConfig configures the OAuth2 authorization server that lets third-party apps
+// access a user's todos with the authorization code grant and PKCE
+type OAuthServerConfig struct {
+	Enabled bool
+	// Public URL of the API, named as the issuer in the metadata document
+	IssuerURL string
+	// Page of the web app that shows the consent screen, it is the authorization endpoint apps send users to
+	ConsentURL string
+	// Lifetime of an authorization code, it can be exchanged once
+	AuthorizationCodeTTL time.Duration
+	AccessTokenTTL       time.Duration
+	RefreshTokenTTL      time.Duration
+}
+
+// LegacyRoutesConfig dates the deprecation of the RPC style todo routes such as /todo/get-todos,
+// which are kept as aliases of the /todos resource routes until their sunset
+type LegacyRoutesConfig struct {
+	// Sent in the Deprecation header when set, only the operator knows when their clients were told
+	DeprecatedAt time.Time
+	// Sent in the Sunset header when set, the routes may be removed after it
+	SunsetAt time.Time
+}
+
+// TodoListConfig sizes the pages of GET /todos
+type TodoListConfig struct {
+	// Items per page when the request does not name a limit
+	DefaultPageSize int
+	// Largest limit a request may ask for, larger ones are lowered to it
+	MaxPageSize int
+}
+
+// PasswordHashingConfig selects the algorithm for new password hashes and its cost.
+// Hashes with weaker settings are upgraded the next time their owner logs in.
+type PasswordHashingConfig struct {
+	// "argon2id" or "bcrypt"
+	Algorithm string
+	// Argon2id memory in KiB, passes and threads, checked by password.ValidateConfig
+	Argon2Memory      int
+	Argon2Iterations  int
+	Argon2Parallelism int
+	BcryptCost        int
+}
+
+// PasswordPolicyConfig holds the rules new passwords must follow
+type PasswordPolicyConfig struct {
+	MinLength        int
+	MaxLength        int
+	RequireUppercase bool
+	RequireLowercase bool
+	RequireDigit     bool
+	RequireSymbol    bool
+	// File with one known breached password per line, such as a common passwords list.
+	// Passwords on the list are rejected regardless of case.
+	BreachedListFile string
+}
+
+// BootstrapAdminConfig describes the first administrator. On startup, when no user has the
+// admin role, the user with this email is promoted, or created when a password is set.
+type BootstrapAdminConfig struct {
+	Email    string
+	Password string
+	Name     string
+}
+
+// MailConfig selects and configures the outgoing mail transport
+type MailConfig struct {
+	// "smtp" or "log"; the log driver also writes .eml files when OutputDir is set
+	Driver       string
+	From         string
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	OutputDir    string
 }
 
 func (d *DatabaseConfig) GetDatabaseString() string {
@@ -36,6 +279,8 @@ func LoadConfig() (*Config, error) {
 
 	godotenv.Load()
 
+	appURL := getEnv("APP_URL", "http://localhost:3000")
+
 	return &Config{
 		Database: DatabaseConfig{
 			Host:     getEnv("DB_HOST", "localhost"),
@@ -46,10 +291,127 @@ func LoadConfig() (*Config, error) {
 			SSLMode:  getEnv("DB_SSL_MODE", "disable"),
 		},
 		Server: ServerConfig{
-			Port: getEnv("PORT", "8080"),
+			Port:              getEnv("PORT", "8080"),
+			TrustProxyHeaders: getEnvBool("TRUST_PROXY_HEADERS", false),
+			TrustedProxyHops:  getEnvInt("TRUSTED_PROXY_HOPS", 1),
+		},
+		Auth: AuthConfig{
+			AccessTokenTTL:          getEnvDuration("ACCESS_TOKEN_TTL", 15*time.Minute),
+			RefreshTokenTTL:         getEnvDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour),
+			RevocationSyncInterval:  getEnvDuration("REVOCATION_SYNC_INTERVAL", 30*time.Second),
+			PasswordResetTTL:        getEnvDuration("PASSWORD_RESET_TTL", time.Hour),
+			PasswordResetEmailLimit: getEnvInt("PASSWORD_RESET_EMAIL_LIMIT", 3),
+			PasswordResetIPLimit:    getEnvInt("PASSWORD_RESET_IP_LIMIT", 10),
+			PasswordResetRateWindow: getEnvDuration("PASSWORD_RESET_RATE_WINDOW", 15*time.Minute),
+			EmailVerificationTTL:    getEnvDuration("EMAIL_VERIFICATION_TTL", 48*time.Hour),
+			EmailVerificationMode:   getEnv("EMAIL_VERIFICATION_MODE", "restricted"),
+			TwoFactorChallengeTTL:   getEnvDuration("TWO_FACTOR_CHALLENGE_TTL", 5*time.Minute),
+			SessionTouchInterval:    getEnvDuration("SESSION_TOUCH_INTERVAL", time.Minute),
+			ImpersonationTTL:        getEnvDuration("IMPERSONATION_TTL", 15*time.Minute),
+		},
+		LoginProtection: LoginProtectionConfig{
+			Store:                   getEnv("LOGIN_ATTEMPT_STORE", "memory"),
+			FailureWindow:           getEnvDuration("LOGIN_FAILURE_WINDOW", 15*time.Minute),
+			AccountFailureThreshold: getEnvInt("LOGIN_ACCOUNT_FAILURE_THRESHOLD", 5),
+			IPFailureThreshold:      getEnvInt("LOGIN_IP_FAILURE_THRESHOLD", 20),
+			LockoutDuration:         getEnvDuration("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
+			DelayBase:               getEnvDuration("LOGIN_DELAY_BASE", time.Second),
+			MaxDelay:                getEnvDuration("LOGIN_MAX_DELAY", 30*time.Second),
+		},
+		MagicLink: MagicLinkConfig{
+			Enabled:    getEnvBool("MAGIC_LINK_ENABLED", false),
+			TTL:        getEnvDuration("MAGIC_LINK_TTL", 15*time.Minute),
+			EmailLimit: getEnvInt("MAGIC_LINK_EMAIL_LIMIT", 3),
+			IPLimit:    getEnvInt("MAGIC_LINK_IP_LIMIT", 10),
+			RateWindow: getEnvDuration("MAGIC_LINK_RATE_WINDOW", 15*time.Minute),
+		},
+		SessionCookie: SessionCookieConfig{
+			Enabled:  getEnvBool("SESSION_COOKIES_ENABLED", false),
+			Secure:   getEnvBool("SESSION_COOKIE_SECURE", true),
+			SameSite: getEnv("SESSION_COOKIE_SAMESITE", "lax"),
+			Domain:   getEnv("SESSION_COOKIE_DOMAIN", ""),
+		},
+		CORS: CORSConfig{
+			AllowedOrigins: getEnvListDefault("CORS_ALLOWED_ORIGINS", []string{appURL}),
+			MaxAge:         getEnvDuration("CORS_MAX_AGE", 5*time.Minute),
+		},
+		SecurityEvents: SecurityEventsConfig{
+			Retention: getEnvDuration("SECURITY_EVENT_RETENTION", 90*24*time.Hour),
+		},
+		BackgroundJobs: BackgroundJobsConfig{
+			Workers:   getEnvInt("BACKGROUND_WORKERS", 4),
+			QueueSize: getEnvInt("BACKGROUND_QUEUE_SIZE", 100),
+			Timeout:   getEnvDuration("BACKGROUND_JOB_TIMEOUT", 30*time.Second),
+		},
+		Registration: RegistrationConfig{
+			Mode:           strings.ToLower(getEnv("REGISTRATION_MODE", RegistrationOpen)),
+			AllowedDomains: getEnvList("REGISTRATION_ALLOWED_DOMAINS"),
+		},
+		OAuthServer: OAuthServerConfig{
+			Enabled:              getEnvBool("OAUTH_SERVER_ENABLED", false),
+			IssuerURL:            strings.TrimSuffix(getEnv("OAUTH_ISSUER_URL", "http://localhost:8080"), "/"),
+			ConsentURL:           getEnv("OAUTH_CONSENT_URL", appURL+"/oauth/authorize"),
+			AuthorizationCodeTTL: getEnvDuration("OAUTH_CODE_TTL", time.Minute),
+			AccessTokenTTL:       getEnvDuration("OAUTH_ACCESS_TOKEN_TTL", time.Hour),
+			RefreshTokenTTL:      getEnvDuration("OAUTH_REFRESH_TOKEN_TTL", 30*24*time.Hour),
 		},
-		JWTSecret: getEnv("JWT_SECRET", "your-256-bit-secret"),
-		Env:       getEnv("ENV", "development"),
+		LegacyRoutes: LegacyRoutesConfig{
+			DeprecatedAt: getEnvDate("LEGACY_TODO_ROUTES_DEPRECATED_AT", time.Time{}),
+			SunsetAt:     getEnvDate("LEGACY_TODO_ROUTES_SUNSET_AT", time.Time{}),
+		},
+		TodoList: TodoListConfig{
+			DefaultPageSize: getEnvInt("TODO_DEFAULT_PAGE_SIZE", 20),
+			MaxPageSize:     getEnvInt("TODO_MAX_PAGE_SIZE", 100),
+		},
+		PasswordHashing: PasswordHashingConfig{
+			Algorithm:         getEnv("PASSWORD_HASH_ALGORITHM", "argon2id"),
+			Argon2Memory:      getEnvInt("ARGON2_MEMORY_KIB", 64*1024),
+			Argon2Iterations:  getEnvInt("ARGON2_ITERATIONS", 3),
+			Argon2Parallelism: getEnvInt("ARGON2_PARALLELISM", 2),
+			BcryptCost:        getEnvInt("BCRYPT_COST", 12),
+		},
+		PasswordPolicy: PasswordPolicyConfig{
+			MinLength:        getEnvInt("PASSWORD_MIN_LENGTH", 8),
+			MaxLength:        getEnvInt("PASSWORD_MAX_LENGTH", 128),
+			RequireUppercase: getEnvBool("PASSWORD_REQUIRE_UPPERCASE", false),
+			RequireLowercase: getEnvBool("PASSWORD_REQUIRE_LOWERCASE", false),
+			RequireDigit:     getEnvBool("PASSWORD_REQUIRE_DIGIT", false),
+			RequireSymbol:    getEnvBool("PASSWORD_REQUIRE_SYMBOL", false),
+			BreachedListFile: getEnv("PASSWORD_BREACHED_LIST_FILE", ""),
+		},
+		Mail: MailConfig{
+			Driver:       getEnv("MAIL_DRIVER", "log"),
+			From:         getEnv("MAIL_FROM", "no-reply@todo-api.local"),
+			SMTPHost:     getEnv("SMTP_HOST", "localhost"),
+			SMTPPort:     getEnv("SMTP_PORT", "587"),
+			SMTPUsername: getEnv("SMTP_USERNAME", ""),
+			SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+			OutputDir:    getEnv("MAIL_OUTPUT_DIR", ""),
+		},
+		BootstrapAdmin: BootstrapAdminConfig{
+			Email:    getEnv("BOOTSTRAP_ADMIN_EMAIL", ""),
+			Password: getEnv("BOOTSTRAP_ADMIN_PASSWORD", ""),
+			Name:     getEnv("BOOTSTRAP_ADMIN_NAME", "Administrator"),
+		},
+		OIDC: OIDCConfig{
+			Enabled:       getEnvBool("OIDC_ENABLED", false),
+			IssuerURL:     getEnv("OIDC_ISSUER_URL", ""),
+			ClientID:      getEnv("OIDC_CLIENT_ID", ""),
+			ClientSecret:  getEnv("OIDC_CLIENT_SECRET", ""),
+			RedirectURL:   getEnv("OIDC_REDIRECT_URL", "http://localhost:8080/api/v1/auth/oidc/callback"),
+			Scopes:        getEnvListDefault("OIDC_SCOPES", []string{"openid", "email", "profile"}),
+			AutoProvision: getEnvBool("OIDC_AUTO_PROVISION", true),
+			StateTTL:      getEnvDuration("OIDC_STATE_TTL", 10*time.Minute),
+		},
+		JWT: JWTConfig{
+			KeysDir:         getEnv("JWT_KEYS_DIR", ""),
+			ActiveKeyID:     getEnv("JWT_ACTIVE_KID", ""),
+			PreviousSecrets: getEnvList("JWT_PREVIOUS_SECRETS"),
+		},
+		JWTSecret:     getEnv("JWT_SECRET", ""),
+		EncryptionKey: getEnv("ENCRYPTION_KEY", ""),
+		Env:           getEnv("ENV", "development"),
+		AppURL:        appURL,
 	}, nil
 }
 
@@ -61,6 +423,85 @@ func getEnv(key string, defaultValue string) string {
 	return defaultValue
 }
 
+// getEnvDuration reads a duration such as "15m" or "720h", falling back to the default when unset or invalid
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+
+	if value := os.Getenv(key); value != "" {
+		if duration, err := time.ParseDuration(value); err == nil {
+			return duration
+		}
+	}
+	return defaultValue
+}
+
+// getEnvDate reads a date such as "2027-04-30" in UTC, falling back to the default when unset or invalid
+func getEnvDate(key string, defaultValue time.Time) time.Time {
+
+	if value := os.Getenv(key); value != "" {
+		if date, err := time.Parse(time.DateOnly, value); err == nil {
+			return date
+		}
+	}
+	return defaultValue
+}
+
+// getEnvInt reads an integer, falling back to the default when unset or invalid
+func getEnvInt(key string, defaultValue int) int {
+
+	if value := os.Getenv(key); value != "" {
+		if number, err := strconv.Atoi(value); err == nil {
+			return number
+		}
+	}
+	return defaultValue
+}
+
+// getEnvBool reads a boolean such as "true" or "0", falling back to the default when unset or invalid
+func getEnvBool(key string, defaultValue bool) bool {
+
+	if value := os.Getenv(key); value != "" {
+		if boolean, err := strconv.ParseBool(value); err == nil {
+			return boolean
+		}
+	}
+	return defaultValue
+}
+
+// getEnvList reads a comma separated list, skipping empty entries
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// getEnvListDefault reads a comma separated list, falling back to the default when unset
+func getEnvListDefault(key string, defaultValue []string) []string {
+	if values := getEnvList(key); len(values) > 0 {
+		return values
+	}
+	return defaultValue
+}
+
+// placeholderSecrets were published as sample values, a server using one has no secret at all
+var placeholderSecrets = []string{"your-256-bit-secret", "your-encryption-key"}
+
+// InsecureSecret reports whether a secret is unset or one of the published sample values
+func InsecureSecret(secret string) bool {
+	if secret == "" {
+		return true
+	}
+	for _, placeholder := range placeholderSecrets {
+		if secret == placeholder {
+			return true
+		}
+	}
+	return false
+}
+
 // Global config instance
 var config *Config
 
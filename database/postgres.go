@@ -3,6 +3,8 @@ package database
 import (
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 	"todo-api/config"
 	"todo-api/internal/models"
 
@@ -13,15 +15,32 @@ import (
 var DB *gorm.DB
 
 var AllModels = []interface{}{
+	&models.Permission{},
+	&models.Role{},
 	&models.TodoItem{},
 	&models.TodoNote{},
 	&models.User{},
+	&models.Session{},
+	&models.RefreshToken{},
+	&models.RevokedToken{},
+	&models.PasswordResetToken{},
+	&models.MagicLink{},
+	&models.LoginAttempt{},
+	&models.PersonalAccessToken{},
+	&models.RecoveryCode{},
+	&models.ExternalIdentity{},
+	&models.SecurityEvent{},
+	&models.InviteCode{},
+	&models.OAuthClient{},
+	&models.OAuthAuthorizationCode{},
+	&models.OAuthToken{},
 }
 
 func InitDatabase(config *config.DatabaseConfig) error {
 	var err error
 	// Use := only for the first declaration, not for assigning to the global DB variable
-	DB, err = gorm.Open(postgres.Open(config.GetDatabaseString()), &gorm.Config{})
+	// TranslateError maps constraint violations to gorm.ErrDuplicatedKey and friends
+	DB, err = gorm.Open(postgres.Open(config.GetDatabaseString()), &gorm.Config{TranslateError: true})
 
 	if err != nil {
 		return err
@@ -44,6 +63,11 @@ func Migrate() error {
 		return errors.New("database is not initialized")
 	}
 
+	// Accounts created before email verification existed are treated as verified
+	backfillEmailVerification := !DB.Migrator().HasColumn(&models.User{}, "EmailVerifiedAt")
+	// Accounts created before roles existed get the default role
+	backfillUserRoles := !DB.Migrator().HasTable("UserRoles")
+
 	for _, model := range AllModels {
 		if err := DB.AutoMigrate(model); err != nil {
 			fmt.Printf("Failed to migrate model: %T, error: %v\n", model, err)
@@ -51,6 +75,82 @@ func Migrate() error {
 		}
 	}
 
+	if backfillEmailVerification {
+		if err := DB.Model(&models.User{}).Where(`"emailVerifiedAt" IS NULL`).Update("emailVerifiedAt", time.Now()).Error; err != nil {
+			return err
+		}
+	}
+
+	if err := normalizeUserEmails(); err != nil {
+		return err
+	}
+
+	if err := createTodoSearchColumns(); err != nil {
+		return err
+	}
+
+	// Security events used to be deleted with their account, they are now kept for the retention period
+	if DB.Migrator().HasConstraint(&models.SecurityEvent{}, "fk_SecurityEvents_user") {
+		if err := DB.Migrator().DropConstraint(&models.SecurityEvent{}, "fk_SecurityEvents_user"); err != nil {
+			return err
+		}
+	}
+
+	if err := SeedRoles(); err != nil {
+		return err
+	}
+
+	if backfillUserRoles {
+		return assignDefaultRole()
+	}
+
+	return nil
+}
+
+// normalizeUserEmails lowercases stored emails and makes them unique regardless of case.
+// It fails when two accounts differ only in case, those have to be merged by hand first.
+func normalizeUserEmails() error {
+	var duplicates []string
+	if err := DB.Model(&models.User{}).
+		Select("LOWER(email)").
+		Group("LOWER(email)").
+		Having("COUNT(*) > 1").
+		Pluck("LOWER(email)", &duplicates).Error; err != nil {
+		return err
+	}
+
+	if len(duplicates) > 0 {
+		return fmt.Errorf("accounts with emails differing only in case must be merged first: %s", strings.Join(duplicates, ", "))
+	}
+
+	if err := DB.Model(&models.User{}).Where("email <> LOWER(email)").Update("email", gorm.Expr("LOWER(email)")).Error; err != nil {
+		return err
+	}
+
+	return DB.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email_lower ON "Users" (LOWER(email))`).Error
+}
+
+// createTodoSearchColumns adds the full-text search vectors of todo items and notes. They are
+// generated columns, so Postgres keeps them up to date, and are left out of the models because
+// they cannot be written. Titles weigh more than descriptions, which weigh more than notes.
+func createTodoSearchColumns() error {
+	statements := []string{
+		fmt.Sprintf(`ALTER TABLE "TodoItems" ADD COLUMN IF NOT EXISTS "searchVector" tsvector GENERATED ALWAYS AS (
+			setweight(to_tsvector('%[1]s', COALESCE(title, '')), 'A') ||
+			setweight(to_tsvector('%[1]s', COALESCE(description, '')), 'B')
+		) STORED`, models.TodoSearchConfig),
+		fmt.Sprintf(`ALTER TABLE "TodoNotes" ADD COLUMN IF NOT EXISTS "searchVector" tsvector GENERATED ALWAYS AS (
+			setweight(to_tsvector('%[1]s', COALESCE(note, '')), 'C')
+		) STORED`, models.TodoSearchConfig),
+		`CREATE INDEX IF NOT EXISTS idx_todo_items_search ON "TodoItems" USING GIN ("searchVector")`,
+		`CREATE INDEX IF NOT EXISTS idx_todo_notes_search ON "TodoNotes" USING GIN ("searchVector")`,
+	}
+
+	for _, statement := range statements {
+		if err := DB.Exec(statement).Error; err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -0,0 +1,84 @@
+package database
+
+import (
+	"todo-api/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// defaultRolePermissions lists the built-in roles and the permissions they grant
+var defaultRolePermissions = map[string][]string{
+	models.RoleUser: {
+		models.PermissionTodosRead,
+		models.PermissionTodosWrite,
+	},
+	models.RoleAdmin: {
+		models.PermissionTodosRead,
+		models.PermissionTodosWrite,
+		models.PermissionUsersRead,
+		models.PermissionUsersWrite,
+		models.PermissionUsersImpersonate,
+		models.PermissionClientsRead,
+		models.PermissionClientsWrite,
+	},
+}
+
+var roleDescriptions = map[string]string{
+	models.RoleUser:  "Regular user managing their own todos",
+	models.RoleAdmin: "Administrator with access to user management",
+}
+
+var permissionDescriptions = map[string]string{
+	models.PermissionTodosRead:        "Read todo items and notes",
+	models.PermissionTodosWrite:       "Create, update and delete todo items and notes",
+	models.PermissionUsersRead:        "View user accounts",
+	models.PermissionUsersWrite:       "Manage user accounts and their roles",
+	models.PermissionUsersImpersonate: "Act as another user to investigate their problems",
+	models.PermissionClientsRead:      "View the apps registered with the OAuth2 authorization server",
+	models.PermissionClientsWrite:     "Register and delete OAuth2 apps",
+}
+
+// SeedRoles makes sure the built-in roles and permissions exist. It is idempotent and runs
+// on every startup.
+func SeedRoles() error {
+	return DB.Transaction(func(tx *gorm.DB) error {
+		for name, description := range permissionDescriptions {
+			permission := models.Permission{Name: name, Description: description}
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&permission).Error; err != nil {
+				return err
+			}
+		}
+
+		for roleName, permissionNames := range defaultRolePermissions {
+			role := models.Role{Name: roleName, Description: roleDescriptions[roleName]}
+			if err := tx.Where(models.Role{Name: roleName}).FirstOrCreate(&role).Error; err != nil {
+				return err
+			}
+
+			var permissions []models.Permission
+			if err := tx.Where("name IN ?", permissionNames).Find(&permissions).Error; err != nil {
+				return err
+			}
+
+			// Append only adds missing links, permissions granted by hand are kept
+			if err := tx.Model(&role).Association("Permissions").Append(&permissions); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// assignDefaultRole gives the "user" role to the accounts created before roles existed. It
+// runs once, when the roles are introduced, so an account an administrator later leaves
+// without roles stays that way.
+func assignDefaultRole() error {
+	return DB.Exec(`
+		INSERT INTO "UserRoles" (user_id, role_id)
+		SELECT u.id, r.id FROM "Users" u, "Roles" r
+		WHERE r.name = ? AND NOT EXISTS (SELECT 1 FROM "UserRoles" ur WHERE ur.user_id = u.id)`,
+		models.RoleUser,
+	).Error
+}
@@ -24,39 +24,51 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/auth/login": {
-            "post": {
-                "description": "Login a user with the provided credentials",
-                "consumes": [
-                    "application/json"
+        "/admin/invite-codes": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "List every invite code, newest first, with how often it was used. The codes themselves are not returned.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
-                ],
-                "summary": "Login a user",
-                "parameters": [
-                    {
-                        "description": "User login data",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dtos.LoginUserDto"
-                        }
-                    }
+                    "admin"
                 ],
+                "summary": "List invite codes",
                 "responses": {
                     "200": {
-                        "description": "User logged in successfully",
+                        "description": "Invite codes retrieved successfully",
                         "schema": {
-                            "$ref": "#/definitions/dtos.StructuredResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.StructuredResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "payload": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/dtos.InviteCodeDto"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "401": {
-                        "description": "Invalid credentials",
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/dtos.StructuredResponse"
                         }
@@ -68,11 +80,14 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/auth/register": {
+            },
             "post": {
-                "description": "Register a new user with the provided details",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a code that lets up to maxUses people register while REGISTRATION_MODE is invite. The code is only returned in this response.",
                 "consumes": [
                     "application/json"
                 ],
@@ -80,23 +95,53 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "admin"
                 ],
-                "summary": "Register a new user",
+                "summary": "Create an invite code",
                 "parameters": [
                     {
-                        "description": "User registration data",
-                        "name": "user",
+                        "description": "Invite code settings",
+                        "name": "inviteCode",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dtos.RegisterUserDto"
+                            "$ref": "#/definitions/dtos.CreateInviteCodeDto"
                         }
                     }
                 ],
                 "responses": {
                     "201": {
-                        "description": "User registered successfully",
+                        "description": "Invite code created successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.StructuredResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "payload": {
+                                            "$ref": "#/definitions/dtos.CreatedInviteCodeDto"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid maxUses or expiresInDays",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "$ref": "#/definitions/dtos.StructuredResponse"
                         }
@@ -110,38 +155,33 @@ const docTemplate = `{
                 }
             }
         },
-        "/todo/create-todo-item": {
-            "post": {
+        "/admin/invite-codes/{id}": {
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create a new Todo Item with the provided details",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Stop an invite code from registering further accounts. Accounts already created with it are kept.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "todo"
+                    "admin"
                 ],
-                "summary": "Create a new Todo Item",
+                "summary": "Revoke an invite code",
                 "parameters": [
                     {
-                        "description": "Todo item data",
-                        "name": "todo",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dtos.CreateTodoItemDto"
-                        }
+                        "type": "integer",
+                        "description": "Invite code ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Todo item created successfully",
+                        "description": "Invite code revoked successfully",
                         "schema": {
                             "$ref": "#/definitions/dtos.StructuredResponse"
                         }
@@ -152,6 +192,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/dtos.StructuredResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Invite code not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -161,14 +213,70 @@ const docTemplate = `{
                 }
             }
         },
-        "/todo/create-todo-note": {
+        "/admin/oauth-clients": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List every registered app, newest first. Client secrets are not returned.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List OAuth2 apps",
+                "responses": {
+                    "200": {
+                        "description": "Apps retrieved successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.StructuredResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "payload": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/dtos.OAuthClientDto"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    }
+                }
+            },
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Create a new Todo Note for an existing Todo Item",
+                "description": "Register a third-party app with the OAuth2 authorization server. Confidential apps get a client secret, which is only returned in this response. Redirect URIs must use https, or http on localhost.",
                 "consumes": [
                     "application/json"
                 ],
@@ -176,23 +284,41 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "todo"
+                    "admin"
                 ],
-                "summary": "Create a new Todo Note",
+                "summary": "Register an OAuth2 app",
                 "parameters": [
                     {
-                        "description": "Todo note data",
-                        "name": "todo",
+                        "description": "App to register",
+                        "name": "client",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dtos.CreateTodoNoteDto"
+                            "$ref": "#/definitions/dtos.CreateOAuthClientDto"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Todo note created successfully",
+                    "201": {
+                        "description": "App registered successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.StructuredResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "payload": {
+                                            "$ref": "#/definitions/dtos.CreatedOAuthClientDto"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid redirect URI or scope",
                         "schema": {
                             "$ref": "#/definitions/dtos.StructuredResponse"
                         }
@@ -203,6 +329,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/dtos.StructuredResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -212,38 +344,33 @@ const docTemplate = `{
                 }
             }
         },
-        "/todo/delete-todo-item": {
+        "/admin/oauth-clients/{id}": {
             "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Delete an existing Todo Item by ID",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Delete an app together with its pending authorization codes and every token issued to it",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "todo"
+                    "admin"
                 ],
-                "summary": "Delete an existing Todo Item",
+                "summary": "Delete an OAuth2 app",
                 "parameters": [
                     {
-                        "description": "Todo item deletion data",
-                        "name": "todo",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dtos.DeleteTodoItemDto"
-                        }
+                        "type": "integer",
+                        "description": "App ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Todo item deleted successfully",
+                        "description": "App deleted successfully",
                         "schema": {
                             "$ref": "#/definitions/dtos.StructuredResponse"
                         }
@@ -254,6 +381,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/dtos.StructuredResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "App not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -263,27 +402,24 @@ const docTemplate = `{
                 }
             }
         },
-        "/todo/get-todos": {
+        "/admin/roles": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Get all Todo Items from the database",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "List every role with the permissions it grants",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "todo"
+                    "admin"
                 ],
-                "summary": "Get all Todo Items",
+                "summary": "List roles",
                 "responses": {
                     "200": {
-                        "description": "Todo items retrieved successfully",
+                        "description": "Roles retrieved successfully",
                         "schema": {
                             "$ref": "#/definitions/dtos.StructuredResponse"
                         }
@@ -294,6 +430,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/dtos.StructuredResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -303,14 +445,95 @@ const docTemplate = `{
                 }
             }
         },
-        "/todo/update-todo-item": {
-            "put": {
+        "/admin/users": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List users ordered by ID, one page at a time. The search matches email addresses and names, ignoring case.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List users",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Text to look for in email and name",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number, starting at 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Users per page, at most 100",
+                        "name": "pageSize",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Users retrieved successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.StructuredResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "payload": {
+                                            "$ref": "#/definitions/dtos.UserListDto"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid page or pageSize",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Update an existing Todo Item with the provided details",
+                "description": "Create an account with the user role, whatever REGISTRATION_MODE and REGISTRATION_ALLOWED_DOMAINS allow. With a password the user is sent the usual verification email, without one they are emailed a link to choose a password, which also verifies their address.",
                 "consumes": [
                     "application/json"
                 ],
@@ -318,23 +541,41 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "todo"
+                    "admin"
                 ],
-                "summary": "Update an existing Todo Item",
+                "summary": "Create a user",
                 "parameters": [
                     {
-                        "description": "Todo item update data",
-                        "name": "todo",
+                        "description": "Account to create",
+                        "name": "user",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dtos.UpdateTodoItemDto"
+                            "$ref": "#/definitions/dtos.CreateUserDto"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Todo item updated successfully",
+                    "201": {
+                        "description": "User created successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.StructuredResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "payload": {
+                                            "$ref": "#/definitions/dtos.AdminUserDto"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body or password does not meet the policy",
                         "schema": {
                             "$ref": "#/definitions/dtos.StructuredResponse"
                         }
@@ -345,6 +586,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/dtos.StructuredResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Email already registered",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -353,130 +606,5473 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "dtos.CreateTodoItemDto": {
-            "description": "Data for creating a new todo item",
-            "type": "object",
-            "properties": {
-                "description": {
-                    "description": "Optional description with details (max 255 characters)\n@example Milk, eggs, bread, and cheese",
-                    "type": "string",
-                    "maxLength": 255,
-                    "example": "Milk, eggs, bread, and cheese"
-                },
-                "title": {
-                    "description": "Title of the todo item (3-255 characters)\n@example Buy groceries",
-                    "type": "string",
-                    "example": "Buy groceries"
-                },
-                "userId": {
-                    "description": "User ID associated with the todo item\n@example 1",
-                    "type": "integer",
-                    "example": 1
-                }
-            }
         },
-        "dtos.CreateTodoNoteDto": {
-            "description": "Data for creating a new note attached to a todo item",
+        "/admin/users/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Return a user with their status, roles and todo counts",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User retrieved successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.StructuredResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "payload": {
+                                            "$ref": "#/definitions/dtos.AdminUserDto"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{id}/disable": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Block the account: the user is logged out everywhere, cannot log in and their personal access tokens stop working until the account is enabled again",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Disable a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User disabled successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.StructuredResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "payload": {
+                                            "$ref": "#/definitions/dtos.AdminUserDto"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Cannot disable your own account",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{id}/enable": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Let a disabled user log in again",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Enable a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User enabled successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.StructuredResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "payload": {
+                                            "$ref": "#/definitions/dtos.AdminUserDto"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{id}/impersonate": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Issue a short-lived access token to act as the user. The token records the administrator in its act claim, every request made with it is logged, it cannot be refreshed and cannot be used for account management. Administrators cannot be impersonated.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Impersonate a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Impersonation token issued",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.StructuredResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "payload": {
+                                            "$ref": "#/definitions/dtos.ImpersonationTokenDto"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Cannot impersonate yourself",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden or the user is an administrator",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Account has been disabled",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{id}/logout": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "End every session of the user and invalidate all of their access tokens",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Log a user out",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User logged out from all devices",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{id}/reset-password": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove the user's password, log them out everywhere and email them a link to choose a new one",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Reset a user's password",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Password reset successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{id}/roles": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replace the roles of a user. Removing a role logs the user out so the change applies immediately.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Set the roles of a user",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Roles to assign",
+                        "name": "roles",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.SetUserRolesDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Roles updated successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.StructuredResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "payload": {
+                                            "$ref": "#/definitions/dtos.UserRolesDto"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Unknown role",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Cannot remove the last administrator",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{id}/security-events": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Return the logins, password changes, token changes and other security events of a user, newest first. Events older than SECURITY_EVENT_RETENTION are not kept.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List a user's security events",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only return events of this type, e.g. login.failed",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number, starting at 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Events per page, at most 100",
+                        "name": "pageSize",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Security events retrieved successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.StructuredResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "payload": {
+                                            "$ref": "#/definitions/dtos.SecurityEventListDto"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid page or pageSize",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/2fa/confirm": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Enable two-factor authentication with a code from the authenticator app. Returns ten single-use recovery codes, which are only shown once. Wrong codes count as failed logins.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "2fa"
+                ],
+                "summary": "Confirm two-factor enrollment",
+                "parameters": [
+                    {
+                        "description": "TOTP code",
+                        "name": "code",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.TwoFactorCodeDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Two-factor authentication enabled",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.StructuredResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "payload": {
+                                            "$ref": "#/definitions/dtos.RecoveryCodesDto"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid code",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Two-factor authentication is already enabled",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many failed login attempts",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.StructuredResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "payload": {
+                                            "$ref": "#/definitions/dtos.RateLimitedDto"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/2fa/disable": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Turn two-factor authentication off and delete the recovery codes. Requires the password and a TOTP or recovery code.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "2fa"
+                ],
+                "summary": "Disable two-factor authentication",
+                "parameters": [
+                    {
+                        "description": "Password and code",
+                        "name": "credentials",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.DisableTwoFactorDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Two-factor authentication disabled",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Two-factor authentication is not enabled",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid password or code",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/2fa/enroll": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Generate a TOTP secret for the current user. Two-factor authentication stays off until a code is confirmed with /auth/2fa/confirm.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "2fa"
+                ],
+                "summary": "Start two-factor enrollment",
+                "responses": {
+                    "200": {
+                        "description": "Enrollment started",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.StructuredResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "payload": {
+                                            "$ref": "#/definitions/dtos.TwoFactorEnrollmentDto"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Two-factor authentication is already enabled",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/confirm-email-change": {
+            "post": {
+                "description": "Switch the account to the new email address with the token from the link sent by PUT /me/email. The new address counts as verified.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Confirm a new email address",
+                "parameters": [
+                    {
+                        "description": "Confirmation token",
+                        "name": "token",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ConfirmEmailChangeDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Email changed successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid or expired confirmation link",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Email address is already in use",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/forgot-password": {
+            "post": {
+                "description": "Email a single-use password reset link. The response is the same whether or not the email belongs to an account. Requests are limited per email address and per client IP.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Request a password reset",
+                "parameters": [
+                    {
+                        "description": "Account email",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ForgotPasswordDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Password reset link sent if the account exists",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many password reset links requested",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.StructuredResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "payload": {
+                                            "$ref": "#/definitions/dtos.RateLimitedDto"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/login": {
+            "post": {
+                "description": "Login a user with the provided credentials. When two-factor authentication is enabled the payload is a challenge to complete at /auth/login/2fa instead of tokens.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Login a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Send cookie to receive the tokens as HttpOnly cookies instead of in the body",
+                        "name": "X-Session-Mode",
+                        "in": "header"
+                    },
+                    {
+                        "description": "User login data",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.LoginUserDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User logged in successfully, or dtos.TwoFactorChallengeDto when two-factor authentication is enabled",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.StructuredResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "payload": {
+                                            "$ref": "#/definitions/dtos.AuthTokensDto"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Email address has not been verified",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many failed login attempts",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.StructuredResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "payload": {
+                                            "$ref": "#/definitions/dtos.RateLimitedDto"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/login/2fa": {
+            "post": {
+                "description": "Exchange the challenge returned by /auth/login and a TOTP or recovery code for an access token and refresh token. Wrong codes count as failed logins.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "2fa"
+                ],
+                "summary": "Complete a two-factor login",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Send cookie to receive the tokens as HttpOnly cookies instead of in the body",
+                        "name": "X-Session-Mode",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Challenge token and code",
+                        "name": "login",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.TwoFactorLoginDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User logged in successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.StructuredResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "payload": {
+                                            "$ref": "#/definitions/dtos.AuthTokensDto"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or expired challenge or code",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many failed login attempts",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.StructuredResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "payload": {
+                                            "$ref": "#/definitions/dtos.RateLimitedDto"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/logout": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revoke the access token used for this request and end its session, which revokes the session's refresh tokens. If a refresh token is sent, its whole token family is revoked as well.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Logout",
+                "parameters": [
+                    {
+                        "description": "Refresh token to revoke",
+                        "name": "token",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.LogoutDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Logged out successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/logout-all": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Invalidate every access token and refresh token issued to the current user before now",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Logout from all devices",
+                "responses": {
+                    "200": {
+                        "description": "Logged out from all devices successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/magic-link": {
+            "post": {
+                "description": "Email a single-use login link. The link only works in the browser that requested it, which gets a cookie for that. The response is the same whether or not the email belongs to an account. Only available when MAGIC_LINK_ENABLED is set.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "magic-link"
+                ],
+                "summary": "Request a login link",
+                "parameters": [
+                    {
+                        "description": "Account email",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.RequestMagicLinkDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Login link sent if the account exists",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid email address",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.StructuredResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "payload": {
+                                            "$ref": "#/definitions/dtos.ValidationErrorsDto"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "429": {
+                        "description": "Too many login links requested",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.StructuredResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "payload": {
+                                            "$ref": "#/definitions/dtos.RateLimitedDto"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/magic-link/verify": {
+            "post": {
+                "description": "Exchange the token from a login link for an access token and refresh token. The request must come from the browser that asked for the link. When two-factor authentication is enabled the payload is a challenge to complete at /auth/login/2fa instead of tokens.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "magic-link"
+                ],
+                "summary": "Log in with a login link",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Send cookie to receive the tokens as HttpOnly cookies instead of in the body",
+                        "name": "X-Session-Mode",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Login link token",
+                        "name": "token",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MagicLinkLoginDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User logged in successfully, or dtos.TwoFactorChallengeDto when two-factor authentication is enabled",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.StructuredResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "payload": {
+                                            "$ref": "#/definitions/dtos.AuthTokensDto"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid or expired login link",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Link opened in another browser",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/oidc/callback": {
+            "get": {
+                "description": "Callback for the OpenID Connect provider. Verifies the login and returns an access token and refresh token. Users are matched by provider subject, then by verified email, and created when OIDC_AUTO_PROVISION is set. Users with two-factor authentication get a challenge to finish at /auth/login/2fa.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sso"
+                ],
+                "summary": "Complete single sign-on",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Authorization code",
+                        "name": "code",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "State from the login request",
+                        "name": "state",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User logged in successfully, or dtos.TwoFactorChallengeDto when two-factor authentication is enabled",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.StructuredResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "payload": {
+                                            "$ref": "#/definitions/dtos.AuthTokensDto"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid or expired single sign-on attempt",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Login refused by the provider or invalid ID token",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "No account for this identity",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/oidc/login": {
+            "get": {
+                "description": "Redirect the browser to the OpenID Connect provider. Only available when OIDC_ENABLED is set.",
+                "tags": [
+                    "sso"
+                ],
+                "summary": "Start single sign-on",
+                "responses": {
+                    "302": {
+                        "description": "Redirect to the identity provider"
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "502": {
+                        "description": "Identity provider is unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/refresh": {
+            "post": {
+                "description": "Exchange a refresh token for a new access token and refresh token. Each refresh token can only be used once; replaying a used token revokes every token issued from the same login.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Refresh an access token",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Send cookie to receive the tokens as HttpOnly cookies instead of in the body",
+                        "name": "X-Session-Mode",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Refresh token, left out in cookie session mode",
+                        "name": "token",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.RefreshTokenDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Token refreshed successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.StructuredResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "payload": {
+                                            "$ref": "#/definitions/dtos.AuthTokensDto"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid refresh token",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Refresh cookie sent without a valid CSRF token",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/register": {
+            "post": {
+                "description": "Register a new user with the provided details. The account starts unverified and a verification link is emailed to the user. Depending on REGISTRATION_MODE an invite code is required or registration is closed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Register a new user",
+                "parameters": [
+                    {
+                        "description": "User registration data",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.RegisterUserDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "User registered successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid fields or password does not meet the policy",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.StructuredResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "payload": {
+                                            "$ref": "#/definitions/dtos.ValidationErrorsDto"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "403": {
+                        "description": "Registration is closed, needs a valid invite code or the email domain is not allowed",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Email address is already registered",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.StructuredResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "payload": {
+                                            "$ref": "#/definitions/dtos.ValidationErrorsDto"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/resend-verification": {
+            "post": {
+                "description": "Send a new verification link. The response is the same whether or not the email belongs to an unverified account.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Resend the verification email",
+                "parameters": [
+                    {
+                        "description": "Account email",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResendVerificationDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Verification link sent if the account exists",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/reset-password": {
+            "post": {
+                "description": "Set a new password using the token from a password reset email. The token can only be used once and every existing session is logged out.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Reset a password",
+                "parameters": [
+                    {
+                        "description": "Reset token and new password",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ResetPasswordDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Password has been reset successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid or expired password reset token, or the password does not meet the policy",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/sessions": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the devices the current user is logged in on. The session making the request is marked as current.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sessions"
+                ],
+                "summary": "List sessions",
+                "responses": {
+                    "200": {
+                        "description": "Sessions retrieved successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.StructuredResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "payload": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/dtos.SessionDto"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Log out every device of the current user except the one making the request",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sessions"
+                ],
+                "summary": "Revoke all other sessions",
+                "responses": {
+                    "200": {
+                        "description": "Other sessions revoked successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/sessions/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Log out one of the current user's devices. Its refresh tokens stop working at once and its access tokens are rejected.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "sessions"
+                ],
+                "summary": "Revoke a session",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Session revoked successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Session not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/tokens": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the active personal access tokens of the current user, without their secrets",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tokens"
+                ],
+                "summary": "List personal access tokens",
+                "responses": {
+                    "200": {
+                        "description": "Tokens retrieved successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.StructuredResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "payload": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/dtos.PersonalAccessTokenDto"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a named API key for scripts and CI. It is sent as \"Authorization: Bearer tdp_...\" and can be limited to scopes and given an expiry. The token is only returned once.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tokens"
+                ],
+                "summary": "Create a personal access token",
+                "parameters": [
+                    {
+                        "description": "Token settings",
+                        "name": "token",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.CreatePersonalAccessTokenDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Token created successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.StructuredResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "payload": {
+                                            "$ref": "#/definitions/dtos.CreatedPersonalAccessTokenDto"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid scope",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Personal access tokens cannot create tokens",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/tokens/{id}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revoke one of the current user's personal access tokens",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "tokens"
+                ],
+                "summary": "Revoke a personal access token",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Token ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Token revoked successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Token not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/verify-email": {
+            "post": {
+                "description": "Confirm the user's email address with the signed token from the verification email",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Verify an email address",
+                "parameters": [
+                    {
+                        "description": "Verification token",
+                        "name": "token",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.VerifyEmailDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Email verified successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid or expired verification link",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/me": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Return the profile of the logged in user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "me"
+                ],
+                "summary": "Get the current user",
+                "responses": {
+                    "200": {
+                        "description": "Profile retrieved successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.StructuredResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "payload": {
+                                            "$ref": "#/definitions/dtos.UserDto"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Permanently delete the account with its todo items, notes, sessions and tokens. Requires the password unless the account was created through single sign-on.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "me"
+                ],
+                "summary": "Delete the current user's account",
+                "parameters": [
+                    {
+                        "description": "Current password",
+                        "name": "confirmation",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.DeleteAccountDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Account deleted successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Password is incorrect",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Cannot delete the last administrator",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Change the name, bio, avatar URL or time zone. Fields that are left out keep their value.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "me"
+                ],
+                "summary": "Update the current user's profile",
+                "parameters": [
+                    {
+                        "description": "Profile fields to change",
+                        "name": "profile",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.UpdateProfileDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Profile updated successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.StructuredResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "payload": {
+                                            "$ref": "#/definitions/dtos.UserDto"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid profile field",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/email": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Send a confirmation link to the new address. The account keeps its current address until the link is opened, see /auth/confirm-email-change.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "me"
+                ],
+                "summary": "Change the current user's email address",
+                "parameters": [
+                    {
+                        "description": "New email address and password",
+                        "name": "email",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ChangeEmailDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Confirmation link sent to the new address",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid email address",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Password is incorrect",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Email address is already in use",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/password": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Set a new password after checking the current one. Every other session is logged out, the one making the request stays logged in.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "me"
+                ],
+                "summary": "Change the current user's password",
+                "parameters": [
+                    {
+                        "description": "Current and new password",
+                        "name": "passwords",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ChangePasswordDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Password changed successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "New password does not meet the policy",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.StructuredResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "payload": {
+                                            "$ref": "#/definitions/dtos.ValidationErrorsDto"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Current password is incorrect",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/me/security-events": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Return the logins, password changes, token changes and other security events of the account, newest first. Events older than SECURITY_EVENT_RETENTION are not kept.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "me"
+                ],
+                "summary": "List the current user's security events",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only return events of this type, e.g. login.failed",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number, starting at 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Events per page, at most 100",
+                        "name": "pageSize",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Security events retrieved successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.StructuredResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "payload": {
+                                            "$ref": "#/definitions/dtos.SecurityEventListDto"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid page or pageSize",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/authorize": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Validate the query of an app's authorization request and describe it for the consent screen. The web app page at OAUTH_CONSENT_URL calls this with the query it received. When the app or redirect URI is unknown the user must be told, other problems come with a redirectUri payload that reports the error to the app.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "oauth"
+                ],
+                "summary": "Check an authorization request",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Must be code",
+                        "name": "response_type",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client identifier of the app",
+                        "name": "client_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Registered redirect URI, optional when the app has only one",
+                        "name": "redirect_uri",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Space separated scopes, all scopes of the app when empty",
+                        "name": "scope",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Opaque value returned to the app",
+                        "name": "state",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "PKCE code challenge",
+                        "name": "code_challenge",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Must be S256",
+                        "name": "code_challenge_method",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Authorization request is valid",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.StructuredResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "payload": {
+                                            "$ref": "#/definitions/dtos.OAuthConsentDto"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid authorization request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.StructuredResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "payload": {
+                                            "$ref": "#/definitions/dtos.OAuthRedirectDto"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not a session token",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Record the user's decision on the consent screen. The payload holds the URL to send the browser to, carrying an authorization code when the user approved and an access_denied error otherwise.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "oauth"
+                ],
+                "summary": "Answer an authorization request",
+                "parameters": [
+                    {
+                        "description": "Authorization request and the user's decision",
+                        "name": "authorization",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.OAuthAuthorizationRequestDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Redirect back to the app",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.StructuredResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "payload": {
+                                            "$ref": "#/definitions/dtos.OAuthRedirectDto"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid authorization request",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.StructuredResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "payload": {
+                                            "$ref": "#/definitions/dtos.OAuthRedirectDto"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not a session token or email not verified",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/introspect": {
+            "post": {
+                "description": "Introspection endpoint of RFC 7662. Tells the calling app whether one of its access or refresh tokens is active, with its scopes, user and expiry. Tokens of other apps are reported as inactive.",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "oauth"
+                ],
+                "summary": "Introspect a token",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access or refresh token",
+                        "name": "token",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client identifier, unless sent with HTTP Basic",
+                        "name": "client_id",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client secret of confidential apps, unless sent with HTTP Basic",
+                        "name": "client_secret",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Token state",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.OAuthIntrospectionDto"
+                        }
+                    },
+                    "401": {
+                        "description": "Client authentication failed",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.OAuthErrorDto"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.OAuthErrorDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/revoke": {
+            "post": {
+                "description": "Revocation endpoint of RFC 7009. Revokes one of the calling app's tokens, a refresh token together with the access tokens of its grant. Unknown tokens are answered with 200 as well.",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "oauth"
+                ],
+                "summary": "Revoke a token",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Access or refresh token",
+                        "name": "token",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client identifier, unless sent with HTTP Basic",
+                        "name": "client_id",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client secret of confidential apps, unless sent with HTTP Basic",
+                        "name": "client_secret",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Token revoked"
+                    },
+                    "401": {
+                        "description": "Client authentication failed",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.OAuthErrorDto"
+                        }
+                    },
+                    "503": {
+                        "description": "Revocation failed, try again",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.OAuthErrorDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/oauth/token": {
+            "post": {
+                "description": "Token endpoint of RFC 6749. Exchanges an authorization code and its PKCE verifier, or a refresh token, for a new access and refresh token pair. Refresh tokens are single-use, presenting one twice revokes every token of the grant. Confidential apps authenticate with HTTP Basic or client_id and client_secret in the form, public apps send only client_id. Answers with a bare token or error object instead of a StructuredResponse.",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "oauth"
+                ],
+                "summary": "Issue tokens to an app",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "authorization_code or refresh_token",
+                        "name": "grant_type",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Authorization code, for authorization_code",
+                        "name": "code",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Same redirect_uri as in the authorization request, for authorization_code",
+                        "name": "redirect_uri",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "PKCE code verifier, for authorization_code",
+                        "name": "code_verifier",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Refresh token, for refresh_token",
+                        "name": "refresh_token",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Fewer scopes than granted, for refresh_token",
+                        "name": "scope",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client identifier, unless sent with HTTP Basic",
+                        "name": "client_id",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client secret of confidential apps, unless sent with HTTP Basic",
+                        "name": "client_secret",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Tokens issued",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.OAuthTokenResponseDto"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request or grant",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.OAuthErrorDto"
+                        }
+                    },
+                    "401": {
+                        "description": "Client authentication failed",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.OAuthErrorDto"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.OAuthErrorDto"
+                        }
+                    }
+                }
+            }
+        },
+        "/todo/create-todo-item": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Deprecated alias of POST /todos, which answers 201. Responses carry Deprecation, Sunset and Link headers.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "todo"
+                ],
+                "summary": "Create a new Todo Item",
+                "deprecated": true,
+                "parameters": [
+                    {
+                        "description": "Todo item data",
+                        "name": "todo",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.CreateTodoItemDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Todo item created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/todo/create-todo-note": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Deprecated alias of POST /todos/{id}/notes, which answers 201. Responses carry Deprecation, Sunset and Link headers.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "todo"
+                ],
+                "summary": "Create a new Todo Note",
+                "deprecated": true,
+                "parameters": [
+                    {
+                        "description": "Todo note data",
+                        "name": "todo",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.CreateTodoNoteDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Todo note created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Todo item not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/todo/delete-todo-item": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Deprecated alias of DELETE /todos/{id}, which answers 204. Responses carry Deprecation, Sunset and Link headers.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "todo"
+                ],
+                "summary": "Delete an existing Todo Item",
+                "deprecated": true,
+                "parameters": [
+                    {
+                        "description": "Todo item deletion data",
+                        "name": "todo",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.DeleteTodoItemDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Todo item deleted successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Todo item not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/todo/get-todos": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Deprecated alias of GET /todos. Responses carry Deprecation, Sunset and Link headers.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "todo"
+                ],
+                "summary": "Get all Todo Items",
+                "deprecated": true,
+                "responses": {
+                    "200": {
+                        "description": "Todo items retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/todo/update-todo-item": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Deprecated alias of PUT /todos/{id}. Responses carry Deprecation, Sunset and Link headers.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "todo"
+                ],
+                "summary": "Update an existing Todo Item",
+                "deprecated": true,
+                "parameters": [
+                    {
+                        "description": "Todo item update data",
+                        "name": "todo",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.UpdateTodoItemDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Todo item updated successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Todo item not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/todos": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the todo items of the current user with their notes, one page at a time. Pass the nextCursor of a page as cursor, with the same sort and filters, to get the next one.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "todos"
+                ],
+                "summary": "List Todo Items",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Items per page, at most TODO_MAX_PAGE_SIZE",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "nextCursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "id",
+                        "description": "id, title, createdAt, updatedAt or dueAt, prefixed with - for descending order. Items without a due date come last.",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only completed or only open items",
+                        "name": "isCompleted",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only items created after this time (RFC 3339 or YYYY-MM-DD)",
+                        "name": "createdAfter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only items created before this time (RFC 3339 or YYYY-MM-DD)",
+                        "name": "createdBefore",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only items updated after this time (RFC 3339 or YYYY-MM-DD)",
+                        "name": "updatedAfter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only items updated before this time (RFC 3339 or YYYY-MM-DD)",
+                        "name": "updatedBefore",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Text the title must contain, ignoring case",
+                        "name": "title",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Count the matching items across all pages",
+                        "name": "includeTotal",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "overdue",
+                            "today",
+                            "next7days",
+                            "nodate"
+                        ],
+                        "type": "string",
+                        "description": "Smart view computed in the user's time zone",
+                        "name": "view",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Todo items retrieved successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.StructuredResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "payload": {
+                                            "$ref": "#/definitions/dtos.TodoItemListDto"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query parameter, sort, view or cursor",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a todo item for the current user. The Location header names the new item.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "todos"
+                ],
+                "summary": "Create a Todo Item",
+                "parameters": [
+                    {
+                        "description": "Todo item data",
+                        "name": "todo",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.CreateTodoItemDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Todo item created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation failed, or invalid due date or reminders",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/todos/search": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Search the titles, descriptions and notes of the current user's todo items, most relevant first. Every word must match, \"quoted phrases\" must match as consecutive words and a word ending in * matches every word starting with it. Words are stemmed, so \"buying\" finds \"buy\". Matching words are wrapped in \u003cmark\u003e tags in the highlights and snippets, the rest of the text is not HTML escaped.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "todos"
+                ],
+                "summary": "Search Todo Items",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search text, at most 200 characters",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number, starting at 1",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Results per page, at most 100",
+                        "name": "pageSize",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Todo items found successfully",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/dtos.StructuredResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "payload": {
+                                            "$ref": "#/definitions/dtos.TodoSearchResultsDto"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Missing or invalid q, page or pageSize",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/todos/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get one of the current user's todo items with its notes",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "todos"
+                ],
+                "summary": "Get a Todo Item",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Todo item ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Todo item retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid id",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Todo item not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replace every field of one of the current user's todo items, a due date that is left out is removed",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "todos"
+                ],
+                "summary": "Replace a Todo Item",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Todo item ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New state of the todo item",
+                        "name": "todo",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ReplaceTodoItemDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Todo item updated successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation failed, or invalid due date or reminders",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Todo item not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete one of the current user's todo items together with its notes",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "todos"
+                ],
+                "summary": "Delete a Todo Item",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Todo item ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Todo item deleted"
+                    },
+                    "400": {
+                        "description": "Invalid id",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Todo item not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Change some fields of one of the current user's todo items, the fields that are left out keep their value",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "todos"
+                ],
+                "summary": "Update a Todo Item",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Todo item ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to change",
+                        "name": "todo",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.PatchTodoItemDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Todo item updated successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation failed, or invalid due date or reminders",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Todo item not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/todos/{id}/notes": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the notes of one of the current user's todo items, oldest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "todos"
+                ],
+                "summary": "List the Notes of a Todo Item",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Todo item ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Todo notes retrieved successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid id",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Todo item not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Add a note to one of the current user's todo items",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "todos"
+                ],
+                "summary": "Add a Note to a Todo Item",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Todo item ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Note data",
+                        "name": "note",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.AddTodoNoteDto"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Todo note created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation failed",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Todo item not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.StructuredResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "dtos.AddTodoNoteDto": {
+            "description": "Content of a new note, the todo item is named in the path",
+            "type": "object",
+            "required": [
+                "note"
+            ],
+            "properties": {
+                "note": {
+                    "description": "Content of the note\n@example Don't forget to check expiration dates",
+                    "type": "string",
+                    "example": "Don't forget to check expiration dates"
+                }
+            }
+        },
+        "dtos.AdminUserDto": {
+            "description": "User account with its status",
+            "type": "object",
+            "properties": {
+                "disabledAt": {
+                    "description": "When the account was disabled, null while it is enabled",
+                    "type": "string"
+                },
+                "email": {
+                    "description": "Email address\n@example john.doe@example.com",
+                    "type": "string",
+                    "example": "john.doe@example.com"
+                },
+                "emailVerifiedAt": {
+                    "description": "When the email address was verified, null while it is unverified",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "User ID\n@example 1",
+                    "type": "integer",
+                    "example": 1
+                },
+                "name": {
+                    "description": "Full name\n@example John Doe",
+                    "type": "string",
+                    "example": "John Doe"
+                },
+                "roles": {
+                    "description": "Names of the user's roles\n@example [\"user\"]",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "user"
+                    ]
+                },
+                "todos": {
+                    "description": "Todo statistics, only included when a single user is requested",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dtos.TodoCountsDto"
+                        }
+                    ]
+                },
+                "twoFactorEnabled": {
+                    "description": "Whether two-factor authentication is enabled\n@example false",
+                    "type": "boolean",
+                    "example": false
+                }
+            }
+        },
+        "dtos.AuthTokensDto": {
+            "description": "Authenticated user together with an access token and a refresh token",
+            "type": "object",
+            "properties": {
+                "csrfToken": {
+                    "description": "Token to send in the X-CSRF-Token header, only in cookie session mode\n@example Jq0x3Hh6c2n1Xy8oQ4bR7tV5mW9zK2aL0sD6fG1jE3U",
+                    "type": "string",
+                    "example": "Jq0x3Hh6c2n1Xy8oQ4bR7tV5mW9zK2aL0sD6fG1jE3U"
+                },
+                "email": {
+                    "description": "User's email address\n@example john.doe@example.com",
+                    "type": "string",
+                    "example": "john.doe@example.com"
+                },
+                "expiresIn": {
+                    "description": "Lifetime of the access token in seconds\n@example 900",
+                    "type": "integer",
+                    "example": 900
+                },
+                "id": {
+                    "description": "User ID\n@example 1",
+                    "type": "integer",
+                    "example": 1
+                },
+                "name": {
+                    "description": "User's full name\n@example John Doe",
+                    "type": "string",
+                    "example": "John Doe"
+                },
+                "refreshToken": {
+                    "description": "Long-lived opaque refresh token, single use, left out in cookie session mode\n@example 3q2-7wF0bX9mY1c8Qk4Rz6Vt5Nn2Lp0Hs7Jd1Ga9Ke4",
+                    "type": "string",
+                    "example": "3q2-7wF0bX9mY1c8Qk4Rz6Vt5Nn2Lp0Hs7Jd1Ga9Ke4"
+                },
+                "token": {
+                    "description": "Short-lived JWT access token, left out in cookie session mode\n@example eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9...",
+                    "type": "string",
+                    "example": "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."
+                }
+            }
+        },
+        "dtos.ChangeEmailDto": {
+            "description": "New email address and the current password",
+            "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "description": "New email address, a confirmation link is sent to it\n@example john.new@example.com",
+                    "type": "string",
+                    "example": "john.new@example.com"
+                },
+                "password": {
+                    "description": "Current password\n@example SecureP@ssw0rd",
+                    "type": "string",
+                    "example": "SecureP@ssw0rd"
+                }
+            }
+        },
+        "dtos.ChangePasswordDto": {
+            "description": "Current password together with the new one",
+            "type": "object",
+            "required": [
+                "currentPassword",
+                "newPassword"
+            ],
+            "properties": {
+                "currentPassword": {
+                    "description": "Current password\n@example SecureP@ssw0rd",
+                    "type": "string",
+                    "example": "SecureP@ssw0rd"
+                },
+                "newPassword": {
+                    "description": "New password (min 8 characters)\n@example N3wSecureP@ssw0rd",
+                    "type": "string",
+                    "example": "N3wSecureP@ssw0rd"
+                }
+            }
+        },
+        "dtos.ConfirmEmailChangeDto": {
+            "description": "Token from the email sent to the new address",
+            "type": "object",
+            "required": [
+                "token"
+            ],
+            "properties": {
+                "token": {
+                    "description": "Signed token from the confirmation link\n@example eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9...",
+                    "type": "string",
+                    "example": "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."
+                }
+            }
+        },
+        "dtos.CreateInviteCodeDto": {
+            "description": "Number of uses, lifetime and note of a new invite code",
+            "type": "object",
+            "properties": {
+                "expiresInDays": {
+                    "description": "Lifetime in days, the code never expires when 0\n@example 7",
+                    "type": "integer",
+                    "example": 7
+                },
+                "maxUses": {
+                    "description": "How many accounts the code can create, 1 when left out\n@example 1",
+                    "type": "integer",
+                    "example": 1
+                },
+                "note": {
+                    "description": "Who or what the code is for\n@example New hires, October",
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "New hires, October"
+                }
+            }
+        },
+        "dtos.CreateOAuthClientDto": {
+            "description": "Name, redirect URIs and scopes of a new OAuth2 app",
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "confidential": {
+                    "description": "Whether the app can keep a client secret. Apps running in a browser or on a device cannot and rely on PKCE alone.\n@example true",
+                    "type": "boolean",
+                    "example": true
+                },
+                "name": {
+                    "description": "Name shown to users on the consent screen\n@example Todo Widgets",
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "Todo Widgets"
+                },
+                "redirectUris": {
+                    "description": "Where users are sent back after the consent screen, matched exactly\n@example [\"https://widgets.example.com/callback\"]",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "https://widgets.example.com/callback"
+                    ]
+                },
+                "scopes": {
+                    "description": "Scopes the app may ask for, all of them when empty\n@example [\"todos:read\"]",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "todos:read"
+                    ]
+                }
+            }
+        },
+        "dtos.CreatePersonalAccessTokenDto": {
+            "description": "Name, scopes and lifetime of a new personal access token",
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "expiresInDays": {
+                    "description": "Lifetime in days, the token never expires when 0\n@example 90",
+                    "type": "integer",
+                    "example": 90
+                },
+                "name": {
+                    "description": "Name to recognise the token by\n@example CI pipeline",
+                    "type": "string",
+                    "example": "CI pipeline"
+                },
+                "scopes": {
+                    "description": "Permissions the token is limited to, todos:read and todos:write when empty\n@example [\"todos:read\"]",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "todos:read"
+                    ]
+                }
+            }
+        },
+        "dtos.CreateTodoItemDto": {
+            "description": "Data for creating a new todo item",
+            "type": "object",
+            "required": [
+                "title"
+            ],
+            "properties": {
+                "allDay": {
+                    "description": "Whether the todo item is due on its date as a whole rather than at a time, needs dueAt\n@example false",
+                    "type": "boolean",
+                    "example": false
+                },
+                "description": {
+                    "description": "Optional description with details (max 255 characters)\n@example Milk, eggs, bread, and cheese",
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "Milk, eggs, bread, and cheese"
+                },
+                "dueAt": {
+                    "description": "When the todo item is due, with its UTC offset. For all-day items only the date counts.\n@example 2025-06-12T19:00:00+02:00",
+                    "type": "string",
+                    "example": "2025-06-12T19:00:00+02:00"
+                },
+                "reminderOffsets": {
+                    "description": "Minutes before the due time to remind the user (up to 5, 0-40320). All-day items count from the start of their day in the user's time zone.\n@example 15,60",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        15,
+                        60
+                    ]
+                },
+                "title": {
+                    "description": "Title of the todo item (3-255 characters)\n@example Buy groceries",
+                    "type": "string",
+                    "maxLength": 255,
+                    "minLength": 3,
+                    "example": "Buy groceries"
+                }
+            }
+        },
+        "dtos.CreateTodoNoteDto": {
+            "description": "Data for creating a new note attached to a todo item",
+            "type": "object",
+            "properties": {
+                "note": {
+                    "description": "Content of the note\n@example Don't forget to check expiration dates",
+                    "type": "string",
+                    "example": "Don't forget to check expiration dates"
+                },
+                "todoItemId": {
+                    "description": "ID of the todo item this note belongs to\n@example 1",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "dtos.CreateUserDto": {
+            "description": "Email, name and optional password of a new account",
+            "type": "object",
+            "required": [
+                "email",
+                "name"
+            ],
+            "properties": {
+                "email": {
+                    "description": "Email address, stored in lowercase\n@example jane.doe@example.com",
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "jane.doe@example.com"
+                },
+                "name": {
+                    "description": "Full name\n@example Jane Doe",
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "Jane Doe"
+                },
+                "password": {
+                    "description": "Initial password, when left out the user is emailed a link to choose one\n@example SecureP@ssw0rd",
+                    "type": "string",
+                    "example": "SecureP@ssw0rd"
+                }
+            }
+        },
+        "dtos.CreatedInviteCodeDto": {
+            "description": "New invite code including the code itself",
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "The code to hand out, store it now because it cannot be retrieved again\n@example inv_1a2b3c4d_Qk4Rz6Vt5Nn2Lp0Hs7Jd1Ga",
+                    "type": "string",
+                    "example": "inv_1a2b3c4d_Qk4Rz6Vt5Nn2Lp0Hs7Jd1Ga"
+                },
+                "createdAt": {
+                    "description": "When the code was created",
+                    "type": "string"
+                },
+                "createdById": {
+                    "description": "Administrator who created the code\n@example 1",
+                    "type": "integer",
+                    "example": 1
+                },
+                "expiresAt": {
+                    "description": "When the code expires, null when it never does",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Invite code ID\n@example 1",
+                    "type": "integer",
+                    "example": 1
+                },
+                "maxUses": {
+                    "description": "How many accounts the code can create\n@example 10",
+                    "type": "integer",
+                    "example": 10
+                },
+                "note": {
+                    "description": "Who or what the code is for\n@example New hires, October",
+                    "type": "string",
+                    "example": "New hires, October"
+                },
+                "prefix": {
+                    "description": "Visible beginning of the code\n@example inv_1a2b3c4d",
+                    "type": "string",
+                    "example": "inv_1a2b3c4d"
+                },
+                "revokedAt": {
+                    "description": "When the code was revoked, null while it is active",
+                    "type": "string"
+                },
+                "uses": {
+                    "description": "How many accounts were created with the code\n@example 3",
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "dtos.CreatedOAuthClientDto": {
+            "description": "New OAuth2 app including its client secret",
+            "type": "object",
+            "properties": {
+                "clientId": {
+                    "description": "Client identifier the app sends as client_id\n@example tdc_Qk4Rz6Vt5Nn2Lp0H",
+                    "type": "string",
+                    "example": "tdc_Qk4Rz6Vt5Nn2Lp0H"
+                },
+                "clientSecret": {
+                    "description": "Client secret of a confidential app, store it now because it cannot be retrieved again\n@example tds_3q2-7wF0bX9mY1c8Qk4Rz6Vt5Nn2Lp0Hs7Jd1Ga9Ke4",
+                    "type": "string",
+                    "example": "tds_3q2-7wF0bX9mY1c8Qk4Rz6Vt5Nn2Lp0Hs7Jd1Ga9Ke4"
+                },
+                "confidential": {
+                    "description": "Whether the app authenticates with a client secret\n@example true",
+                    "type": "boolean",
+                    "example": true
+                },
+                "createdAt": {
+                    "description": "When the app was registered",
+                    "type": "string"
+                },
+                "createdById": {
+                    "description": "Administrator who registered the app\n@example 1",
+                    "type": "integer",
+                    "example": 1
+                },
+                "id": {
+                    "description": "Internal ID\n@example 1",
+                    "type": "integer",
+                    "example": 1
+                },
+                "name": {
+                    "description": "Name shown to users on the consent screen\n@example Todo Widgets",
+                    "type": "string",
+                    "example": "Todo Widgets"
+                },
+                "redirectUris": {
+                    "description": "Registered redirect URIs\n@example [\"https://widgets.example.com/callback\"]",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "https://widgets.example.com/callback"
+                    ]
+                },
+                "scopes": {
+                    "description": "Scopes the app may ask for\n@example [\"todos:read\"]",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "todos:read"
+                    ]
+                }
+            }
+        },
+        "dtos.CreatedPersonalAccessTokenDto": {
+            "description": "New personal access token including its secret",
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "description": "When the token was created",
+                    "type": "string"
+                },
+                "expiresAt": {
+                    "description": "When the token expires, null when it never does",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Token ID\n@example 1",
+                    "type": "integer",
+                    "example": 1
+                },
+                "lastUsedAt": {
+                    "description": "When the token was last used",
+                    "type": "string"
+                },
+                "lastUsedIp": {
+                    "description": "IP address the token was last used from\n@example 203.0.113.7",
+                    "type": "string",
+                    "example": "203.0.113.7"
+                },
+                "name": {
+                    "description": "Name of the token\n@example CI pipeline",
+                    "type": "string",
+                    "example": "CI pipeline"
+                },
+                "prefix": {
+                    "description": "Visible beginning of the token\n@example tdp_1a2b3c4d",
+                    "type": "string",
+                    "example": "tdp_1a2b3c4d"
+                },
+                "scopes": {
+                    "description": "Permissions the token is limited to\n@example [\"todos:read\"]",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "todos:read"
+                    ]
+                },
+                "token": {
+                    "description": "The token itself, store it now because it cannot be retrieved again\n@example tdp_1a2b3c4d_3q2-7wF0bX9mY1c8Qk4Rz6Vt5Nn2Lp0Hs7Jd1Ga9Ke4",
+                    "type": "string",
+                    "example": "tdp_1a2b3c4d_3q2-7wF0bX9mY1c8Qk4Rz6Vt5Nn2Lp0Hs7Jd1Ga9Ke4"
+                }
+            }
+        },
+        "dtos.DeleteAccountDto": {
+            "description": "Current password, not needed for accounts without one",
+            "type": "object",
+            "properties": {
+                "password": {
+                    "description": "Current password\n@example SecureP@ssw0rd",
+                    "type": "string",
+                    "example": "SecureP@ssw0rd"
+                }
+            }
+        },
+        "dtos.DeleteTodoItemDto": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "description": "ID of the todo item to delete\n@example 1",
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "dtos.DisableTwoFactorDto": {
+            "description": "Password and a current TOTP or recovery code",
+            "type": "object",
+            "required": [
+                "code",
+                "password"
+            ],
+            "properties": {
+                "code": {
+                    "description": "TOTP code or unused recovery code\n@example 123456",
+                    "type": "string",
+                    "example": "123456"
+                },
+                "password": {
+                    "description": "User's password\n@example SecureP@ssw0rd",
+                    "type": "string",
+                    "example": "SecureP@ssw0rd"
+                }
+            }
+        },
+        "dtos.FieldError": {
+            "description": "A rejected request field and the reason",
+            "type": "object",
+            "properties": {
+                "field": {
+                    "description": "JSON name of the field\n@example password",
+                    "type": "string",
+                    "example": "password"
+                },
+                "message": {
+                    "description": "Reason the value was rejected\n@example Must be at least 8 characters",
+                    "type": "string",
+                    "example": "Must be at least 8 characters"
+                }
+            }
+        },
+        "dtos.ForgotPasswordDto": {
+            "description": "Email address of the account to recover",
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "description": "User's email address\n@example john.doe@example.com",
+                    "type": "string",
+                    "example": "john.doe@example.com"
+                }
+            }
+        },
+        "dtos.ImpersonationTokenDto": {
+            "description": "Short-lived access token for the impersonated user",
+            "type": "object",
+            "properties": {
+                "email": {
+                    "description": "Email address of the impersonated user\n@example jane.doe@example.com",
+                    "type": "string",
+                    "example": "jane.doe@example.com"
+                },
+                "expiresIn": {
+                    "description": "Lifetime of the token in seconds, it cannot be refreshed\n@example 900",
+                    "type": "integer",
+                    "example": 900
+                },
+                "token": {
+                    "description": "Access token acting as the user, it names the administrator in its act claim\n@example eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9...",
+                    "type": "string",
+                    "example": "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."
+                },
+                "userId": {
+                    "description": "ID of the impersonated user\n@example 7",
+                    "type": "integer",
+                    "example": 7
+                }
+            }
+        },
+        "dtos.InviteCodeDto": {
+            "description": "Invite code metadata",
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "description": "When the code was created",
+                    "type": "string"
+                },
+                "createdById": {
+                    "description": "Administrator who created the code\n@example 1",
+                    "type": "integer",
+                    "example": 1
+                },
+                "expiresAt": {
+                    "description": "When the code expires, null when it never does",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Invite code ID\n@example 1",
+                    "type": "integer",
+                    "example": 1
+                },
+                "maxUses": {
+                    "description": "How many accounts the code can create\n@example 10",
+                    "type": "integer",
+                    "example": 10
+                },
+                "note": {
+                    "description": "Who or what the code is for\n@example New hires, October",
+                    "type": "string",
+                    "example": "New hires, October"
+                },
+                "prefix": {
+                    "description": "Visible beginning of the code\n@example inv_1a2b3c4d",
+                    "type": "string",
+                    "example": "inv_1a2b3c4d"
+                },
+                "revokedAt": {
+                    "description": "When the code was revoked, null while it is active",
+                    "type": "string"
+                },
+                "uses": {
+                    "description": "How many accounts were created with the code\n@example 3",
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "dtos.LoginUserDto": {
+            "description": "Login credentials for authenticating a user",
+            "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "description": "User's email address\n@example john.doe@example.com",
+                    "type": "string",
+                    "example": "john.doe@example.com"
+                },
+                "password": {
+                    "description": "User's password\n@example SecureP@ssw0rd",
+                    "type": "string",
+                    "example": "SecureP@ssw0rd"
+                }
+            }
+        },
+        "dtos.LogoutDto": {
+            "description": "Refresh token to revoke together with the current access token",
+            "type": "object",
+            "properties": {
+                "refreshToken": {
+                    "description": "Refresh token issued with the current access token (optional, read from the cookie in cookie session mode)\n@example 3q2-7wF0bX9mY1c8Qk4Rz6Vt5Nn2Lp0Hs7Jd1Ga9Ke4",
+                    "type": "string",
+                    "example": "3q2-7wF0bX9mY1c8Qk4Rz6Vt5Nn2Lp0Hs7Jd1Ga9Ke4"
+                }
+            }
+        },
+        "dtos.MagicLinkLoginDto": {
+            "description": "Token from the login link",
+            "type": "object",
+            "required": [
+                "token"
+            ],
+            "properties": {
+                "token": {
+                    "description": "Signed token from the login link\n@example eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9...",
+                    "type": "string",
+                    "example": "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."
+                }
+            }
+        },
+        "dtos.OAuthAuthorizationRequestDto": {
+            "description": "Authorization request of an app, and the user's decision when posted",
+            "type": "object",
+            "properties": {
+                "approve": {
+                    "description": "The user's decision, only read when posting the consent\n@example true",
+                    "type": "boolean",
+                    "example": true
+                },
+                "client_id": {
+                    "description": "Client identifier of the app\n@example tdc_Qk4Rz6Vt5Nn2Lp0H",
+                    "type": "string",
+                    "example": "tdc_Qk4Rz6Vt5Nn2Lp0H"
+                },
+                "code_challenge": {
+                    "description": "PKCE code challenge\n@example E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM",
+                    "type": "string",
+                    "example": "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+                },
+                "code_challenge_method": {
+                    "description": "Must be \"S256\"\n@example S256",
+                    "type": "string",
+                    "example": "S256"
+                },
+                "redirect_uri": {
+                    "description": "One of the app's registered redirect URIs, may be left out when it has only one\n@example https://widgets.example.com/callback",
+                    "type": "string",
+                    "example": "https://widgets.example.com/callback"
+                },
+                "response_type": {
+                    "description": "Must be \"code\"\n@example code",
+                    "type": "string",
+                    "example": "code"
+                },
+                "scope": {
+                    "description": "Space separated scopes, every scope of the app when empty\n@example todos:read",
+                    "type": "string",
+                    "example": "todos:read"
+                },
+                "state": {
+                    "description": "Opaque value returned to the app unchanged\n@example af0ifjsldkj",
+                    "type": "string",
+                    "example": "af0ifjsldkj"
+                }
+            }
+        },
+        "dtos.OAuthClientDto": {
+            "description": "OAuth2 app",
+            "type": "object",
+            "properties": {
+                "clientId": {
+                    "description": "Client identifier the app sends as client_id\n@example tdc_Qk4Rz6Vt5Nn2Lp0H",
+                    "type": "string",
+                    "example": "tdc_Qk4Rz6Vt5Nn2Lp0H"
+                },
+                "confidential": {
+                    "description": "Whether the app authenticates with a client secret\n@example true",
+                    "type": "boolean",
+                    "example": true
+                },
+                "createdAt": {
+                    "description": "When the app was registered",
+                    "type": "string"
+                },
+                "createdById": {
+                    "description": "Administrator who registered the app\n@example 1",
+                    "type": "integer",
+                    "example": 1
+                },
+                "id": {
+                    "description": "Internal ID\n@example 1",
+                    "type": "integer",
+                    "example": 1
+                },
+                "name": {
+                    "description": "Name shown to users on the consent screen\n@example Todo Widgets",
+                    "type": "string",
+                    "example": "Todo Widgets"
+                },
+                "redirectUris": {
+                    "description": "Registered redirect URIs\n@example [\"https://widgets.example.com/callback\"]",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "https://widgets.example.com/callback"
+                    ]
+                },
+                "scopes": {
+                    "description": "Scopes the app may ask for\n@example [\"todos:read\"]",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "todos:read"
+                    ]
+                }
+            }
+        },
+        "dtos.OAuthConsentDto": {
+            "description": "App asking for access and the scopes it asks for",
+            "type": "object",
+            "properties": {
+                "clientId": {
+                    "description": "Client identifier of the app\n@example tdc_Qk4Rz6Vt5Nn2Lp0H",
+                    "type": "string",
+                    "example": "tdc_Qk4Rz6Vt5Nn2Lp0H"
+                },
+                "clientName": {
+                    "description": "Name of the app\n@example Todo Widgets",
+                    "type": "string",
+                    "example": "Todo Widgets"
+                },
+                "scopes": {
+                    "description": "Scopes the app asks for",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.OAuthScopeDto"
+                    }
+                }
+            }
+        },
+        "dtos.OAuthErrorDto": {
+            "description": "OAuth2 error code and description",
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "Error code such as invalid_grant\n@example invalid_grant",
+                    "type": "string",
+                    "example": "invalid_grant"
+                },
+                "error_description": {
+                    "description": "Human readable explanation\n@example Invalid authorization code",
+                    "type": "string",
+                    "example": "Invalid authorization code"
+                }
+            }
+        },
+        "dtos.OAuthIntrospectionDto": {
+            "description": "State of an access or refresh token",
+            "type": "object",
+            "properties": {
+                "active": {
+                    "description": "Whether the token can be used\n@example true",
+                    "type": "boolean",
+                    "example": true
+                },
+                "client_id": {
+                    "description": "App the token was issued to\n@example tdc_Qk4Rz6Vt5Nn2Lp0H",
+                    "type": "string",
+                    "example": "tdc_Qk4Rz6Vt5Nn2Lp0H"
+                },
+                "exp": {
+                    "description": "Expiry as a Unix timestamp\n@example 1735689600",
+                    "type": "integer",
+                    "example": 1735689600
+                },
+                "iat": {
+                    "description": "Issue time as a Unix timestamp\n@example 1735686000",
+                    "type": "integer",
+                    "example": 1735686000
+                },
+                "scope": {
+                    "description": "Space separated scopes of the token\n@example todos:read",
+                    "type": "string",
+                    "example": "todos:read"
+                },
+                "sub": {
+                    "description": "ID of the user\n@example 1",
+                    "type": "string",
+                    "example": "1"
+                },
+                "token_type": {
+                    "description": "\"access_token\" or \"refresh_token\"\n@example access_token",
+                    "type": "string",
+                    "example": "access_token"
+                },
+                "username": {
+                    "description": "Email address of the user\n@example john.doe@example.com",
+                    "type": "string",
+                    "example": "john.doe@example.com"
+                }
+            }
+        },
+        "dtos.OAuthRedirectDto": {
+            "description": "Redirect back to the app carrying the authorization code or an error",
+            "type": "object",
+            "properties": {
+                "redirectUri": {
+                    "description": "URL to send the browser to\n@example https://widgets.example.com/callback?code=Qk4Rz6Vt5Nn2Lp0Hs7Jd1Ga9Ke4\u0026state=af0ifjsldkj",
+                    "type": "string",
+                    "example": "https://widgets.example.com/callback?code=Qk4Rz6Vt5Nn2Lp0Hs7Jd1Ga9Ke4\u0026state=af0ifjsldkj"
+                }
+            }
+        },
+        "dtos.OAuthScopeDto": {
+            "description": "Scope and what it allows",
+            "type": "object",
+            "properties": {
+                "description": {
+                    "description": "What the scope allows the app to do\n@example Read your todo items and notes",
+                    "type": "string",
+                    "example": "Read your todo items and notes"
+                },
+                "name": {
+                    "description": "Scope name\n@example todos:read",
+                    "type": "string",
+                    "example": "todos:read"
+                }
+            }
+        },
+        "dtos.OAuthTokenResponseDto": {
+            "description": "Access token and refresh token issued to an app",
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "description": "Opaque access token, sent as a Bearer token\n@example tdo_3q2-7wF0bX9mY1c8Qk4Rz6Vt5Nn2Lp0Hs7Jd1Ga9Ke4",
+                    "type": "string",
+                    "example": "tdo_3q2-7wF0bX9mY1c8Qk4Rz6Vt5Nn2Lp0Hs7Jd1Ga9Ke4"
+                },
+                "expires_in": {
+                    "description": "Lifetime of the access token in seconds\n@example 3600",
+                    "type": "integer",
+                    "example": 3600
+                },
+                "refresh_token": {
+                    "description": "Single-use refresh token\n@example tdr_Jq0x3Hh6c2n1Xy8oQ4bR7tV5mW9zK2aL0sD6fG1jE3U",
+                    "type": "string",
+                    "example": "tdr_Jq0x3Hh6c2n1Xy8oQ4bR7tV5mW9zK2aL0sD6fG1jE3U"
+                },
+                "scope": {
+                    "description": "Space separated scopes granted\n@example todos:read",
+                    "type": "string",
+                    "example": "todos:read"
+                },
+                "token_type": {
+                    "description": "Always \"Bearer\"\n@example Bearer",
+                    "type": "string",
+                    "example": "Bearer"
+                }
+            }
+        },
+        "dtos.PatchTodoItemDto": {
+            "description": "Fields of a todo item to change, fields that are left out keep their value",
+            "type": "object",
+            "properties": {
+                "allDay": {
+                    "description": "Whether the todo item is due all day\n@example true",
+                    "type": "boolean",
+                    "example": true
+                },
+                "description": {
+                    "description": "New description (max 255 characters)\n@example Milk, eggs, bread, cheese, and cleaning supplies",
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "Milk, eggs, bread, cheese, and cleaning supplies"
+                },
+                "dueAt": {
+                    "description": "New due time, null removes the due date together with its reminders\n@example 2025-06-12T19:00:00+02:00",
+                    "type": "string",
+                    "format": "date-time",
+                    "example": "2025-06-12T19:00:00+02:00"
+                },
+                "isCompleted": {
+                    "description": "New completion status\n@example true",
+                    "type": "boolean",
+                    "example": true
+                },
+                "reminderOffsets": {
+                    "description": "New reminders in minutes before the due time, [] removes them\n@example 30",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        30
+                    ]
+                },
+                "title": {
+                    "description": "New title (3-255 characters)\n@example Buy groceries and household items",
+                    "type": "string",
+                    "maxLength": 255,
+                    "minLength": 3,
+                    "example": "Buy groceries and household items"
+                }
+            }
+        },
+        "dtos.PersonalAccessTokenDto": {
+            "description": "Personal access token metadata",
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "description": "When the token was created",
+                    "type": "string"
+                },
+                "expiresAt": {
+                    "description": "When the token expires, null when it never does",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Token ID\n@example 1",
+                    "type": "integer",
+                    "example": 1
+                },
+                "lastUsedAt": {
+                    "description": "When the token was last used",
+                    "type": "string"
+                },
+                "lastUsedIp": {
+                    "description": "IP address the token was last used from\n@example 203.0.113.7",
+                    "type": "string",
+                    "example": "203.0.113.7"
+                },
+                "name": {
+                    "description": "Name of the token\n@example CI pipeline",
+                    "type": "string",
+                    "example": "CI pipeline"
+                },
+                "prefix": {
+                    "description": "Visible beginning of the token\n@example tdp_1a2b3c4d",
+                    "type": "string",
+                    "example": "tdp_1a2b3c4d"
+                },
+                "scopes": {
+                    "description": "Permissions the token is limited to\n@example [\"todos:read\"]",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "todos:read"
+                    ]
+                }
+            }
+        },
+        "dtos.RateLimitedDto": {
+            "description": "Number of seconds to wait before retrying",
+            "type": "object",
+            "properties": {
+                "retryAfter": {
+                    "description": "Seconds until the next attempt is allowed\n@example 30",
+                    "type": "integer",
+                    "example": 30
+                }
+            }
+        },
+        "dtos.RecoveryCodesDto": {
+            "description": "One-time recovery codes",
+            "type": "object",
+            "properties": {
+                "recoveryCodes": {
+                    "description": "Each code can be used once instead of a TOTP code\n@example [\"k7m2p-x9q4r\",\"b3n8t-w5c6y\"]",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "k7m2p-x9q4r",
+                        "b3n8t-w5c6y"
+                    ]
+                }
+            }
+        },
+        "dtos.RefreshTokenDto": {
+            "description": "Refresh token issued by login or a previous refresh",
+            "type": "object",
+            "required": [
+                "refreshToken"
+            ],
+            "properties": {
+                "refreshToken": {
+                    "description": "Refresh token to exchange for a new token pair. In cookie session mode it is read from the cookie instead.\n@example 3q2-7wF0bX9mY1c8Qk4Rz6Vt5Nn2Lp0Hs7Jd1Ga9Ke4",
+                    "type": "string",
+                    "example": "3q2-7wF0bX9mY1c8Qk4Rz6Vt5Nn2Lp0Hs7Jd1Ga9Ke4"
+                }
+            }
+        },
+        "dtos.RegisterUserDto": {
+            "description": "Registration data for creating a new user account",
+            "type": "object",
+            "required": [
+                "confirmPassword",
+                "email",
+                "name",
+                "password"
+            ],
+            "properties": {
+                "confirmPassword": {
+                    "description": "Confirmation of the password\n@example SecureP@ssw0rd",
+                    "type": "string",
+                    "example": "SecureP@ssw0rd"
+                },
+                "email": {
+                    "description": "User's email address, stored in lowercase\n@example john.doe@example.com",
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "john.doe@example.com"
+                },
+                "inviteCode": {
+                    "description": "Invite code from an administrator, required when REGISTRATION_MODE is \"invite\"\n@example inv_1a2b3c4d_Qk4Rz6Vt5Nn2Lp0Hs7Jd1Ga",
+                    "type": "string",
+                    "example": "inv_1a2b3c4d_Qk4Rz6Vt5Nn2Lp0Hs7Jd1Ga"
+                },
+                "name": {
+                    "description": "User's full name\n@example John Doe",
+                    "type": "string",
+                    "maxLength": 100,
+                    "example": "John Doe"
+                },
+                "password": {
+                    "description": "User's password, must follow the password policy (min 8 characters by default)\n@example SecureP@ssw0rd",
+                    "type": "string",
+                    "example": "SecureP@ssw0rd"
+                }
+            }
+        },
+        "dtos.ReplaceTodoItemDto": {
+            "description": "Every field of a todo item, fields that are left out are reset",
+            "type": "object",
+            "required": [
+                "title"
+            ],
+            "properties": {
+                "allDay": {
+                    "description": "Whether the todo item is due on its date as a whole rather than at a time, needs dueAt\n@example false",
+                    "type": "boolean",
+                    "example": false
+                },
+                "description": {
+                    "description": "Description (max 255 characters)\n@example Milk, eggs, bread, cheese, and cleaning supplies",
+                    "type": "string",
+                    "maxLength": 255,
+                    "example": "Milk, eggs, bread, cheese, and cleaning supplies"
+                },
+                "dueAt": {
+                    "description": "When the todo item is due, with its UTC offset. For all-day items only the date counts.\n@example 2025-06-12T19:00:00+02:00",
+                    "type": "string",
+                    "example": "2025-06-12T19:00:00+02:00"
+                },
+                "isCompleted": {
+                    "description": "Completion status\n@example true",
+                    "type": "boolean",
+                    "example": true
+                },
+                "reminderOffsets": {
+                    "description": "Minutes before the due time to remind the user (up to 5, 0-40320). All-day items count from the start of their day in the user's time zone.\n@example 15,60",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        15,
+                        60
+                    ]
+                },
+                "title": {
+                    "description": "Title (3-255 characters)\n@example Buy groceries and household items",
+                    "type": "string",
+                    "maxLength": 255,
+                    "minLength": 3,
+                    "example": "Buy groceries and household items"
+                }
+            }
+        },
+        "dtos.RequestMagicLinkDto": {
+            "description": "Email address of the account to log in to",
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "description": "User's email address\n@example john.doe@example.com",
+                    "type": "string",
+                    "example": "john.doe@example.com"
+                }
+            }
+        },
+        "dtos.ResendVerificationDto": {
+            "description": "Email address of the account to verify",
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "description": "User's email address\n@example john.doe@example.com",
+                    "type": "string",
+                    "example": "john.doe@example.com"
+                }
+            }
+        },
+        "dtos.ResetPasswordDto": {
+            "description": "Reset token from the email together with the new password",
+            "type": "object",
+            "required": [
+                "confirmPassword",
+                "password",
+                "token"
+            ],
+            "properties": {
+                "confirmPassword": {
+                    "description": "Confirmation of the new password\n@example N3wSecureP@ssw0rd",
+                    "type": "string",
+                    "example": "N3wSecureP@ssw0rd"
+                },
+                "password": {
+                    "description": "New password (min 8 characters)\n@example N3wSecureP@ssw0rd",
+                    "type": "string",
+                    "example": "N3wSecureP@ssw0rd"
+                },
+                "token": {
+                    "description": "Token from the password reset email\n@example V1StGXR8_Z5jdHi6B-myT3q2-7wF0bX9mY1c8Qk4Rz6",
+                    "type": "string",
+                    "example": "V1StGXR8_Z5jdHi6B-myT3q2-7wF0bX9mY1c8Qk4Rz6"
+                }
+            }
+        },
+        "dtos.SecurityEventDto": {
+            "description": "Login, password change, token change or other security event",
+            "type": "object",
+            "properties": {
+                "actorId": {
+                    "description": "Administrator who caused the event, if any\n@example 1",
+                    "type": "integer",
+                    "example": 1
+                },
+                "createdAt": {
+                    "description": "When the event happened",
+                    "type": "string"
+                },
+                "details": {
+                    "description": "Additional context\n@example Wrong password",
+                    "type": "string",
+                    "example": "Wrong password"
+                },
+                "id": {
+                    "description": "Event ID\n@example 31",
+                    "type": "integer",
+                    "example": 31
+                },
+                "ipAddress": {
+                    "description": "IP address of the client\n@example 203.0.113.7",
+                    "type": "string",
+                    "example": "203.0.113.7"
+                },
+                "type": {
+                    "description": "Event type\n@example login.failed",
+                    "type": "string",
+                    "example": "login.failed"
+                },
+                "userAgent": {
+                    "description": "User agent of the client\n@example Mozilla/5.0 (Macintosh; Intel Mac OS X 14_5) AppleWebKit/605.1.15",
+                    "type": "string",
+                    "example": "Mozilla/5.0 (Macintosh; Intel Mac OS X 14_5) AppleWebKit/605.1.15"
+                }
+            }
+        },
+        "dtos.SecurityEventListDto": {
+            "description": "Page of security events, newest first, with the total number of matches",
+            "type": "object",
+            "properties": {
+                "events": {
+                    "description": "Events on this page",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.SecurityEventDto"
+                    }
+                },
+                "page": {
+                    "description": "Current page number\n@example 1",
+                    "type": "integer",
+                    "example": 1
+                },
+                "pageSize": {
+                    "description": "Events per page\n@example 20",
+                    "type": "integer",
+                    "example": 20
+                },
+                "total": {
+                    "description": "Events matching across all pages\n@example 57",
+                    "type": "integer",
+                    "example": 57
+                }
+            }
+        },
+        "dtos.SessionDto": {
+            "description": "Active login session",
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "description": "When the user logged in",
+                    "type": "string"
+                },
+                "current": {
+                    "description": "Whether this is the session making the request\n@example true",
+                    "type": "boolean",
+                    "example": true
+                },
+                "id": {
+                    "description": "Session ID\n@example 12",
+                    "type": "integer",
+                    "example": 12
+                },
+                "ipAddress": {
+                    "description": "Last known IP address\n@example 203.0.113.7",
+                    "type": "string",
+                    "example": "203.0.113.7"
+                },
+                "lastSeenAt": {
+                    "description": "Last request made with the session, updated about once a minute",
+                    "type": "string"
+                },
+                "userAgent": {
+                    "description": "User agent of the client that logged in\n@example Mozilla/5.0 (Macintosh; Intel Mac OS X 14_5) AppleWebKit/605.1.15",
+                    "type": "string",
+                    "example": "Mozilla/5.0 (Macintosh; Intel Mac OS X 14_5) AppleWebKit/605.1.15"
+                }
+            }
+        },
+        "dtos.SetUserRolesDto": {
+            "description": "Complete list of roles the user should have",
+            "type": "object",
+            "required": [
+                "roles"
+            ],
+            "properties": {
+                "roles": {
+                    "description": "Names of the roles to assign\n@example [\"user\",\"admin\"]",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "user",
+                        "admin"
+                    ]
+                }
+            }
+        },
+        "dtos.StructuredResponse": {
+            "description": "Standard response format containing success status, HTTP status code, message, and optional payload",
+            "type": "object",
+            "properties": {
+                "message": {
+                    "description": "Human-readable message\n@example Operation completed successfully",
+                    "type": "string",
+                    "example": "Operation completed successfully"
+                },
+                "payload": {
+                    "description": "Optional payload containing response data\n@example {\"id\":1,\"name\":\"Example Item\"}"
+                },
+                "status": {
+                    "description": "HTTP status code\n@example 200",
+                    "type": "integer",
+                    "example": 200
+                },
+                "success": {
+                    "description": "Whether the operation was successful\n@example true",
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "dtos.TodoCountsDto": {
+            "description": "Number of todo items by state",
+            "type": "object",
+            "properties": {
+                "completed": {
+                    "description": "Completed todo items\n@example 7",
+                    "type": "integer",
+                    "example": 7
+                },
+                "open": {
+                    "description": "Todo items still open\n@example 5",
+                    "type": "integer",
+                    "example": 5
+                },
+                "total": {
+                    "description": "Every todo item of the user\n@example 12",
+                    "type": "integer",
+                    "example": 12
+                }
+            }
+        },
+        "dtos.TodoItemDto": {
+            "description": "A todo item with all its details",
+            "type": "object",
+            "properties": {
+                "allDay": {
+                    "description": "Whether the todo item is due on its date as a whole rather than at a time\n@example false",
+                    "type": "boolean",
+                    "example": false
+                },
+                "createdAt": {
+                    "description": "When the todo item was created\n@example 2025-06-10T10:30:00Z",
+                    "type": "string",
+                    "example": "2025-06-10T10:30:00Z"
+                },
+                "description": {
+                    "description": "Optional description with details\n@example Milk, eggs, bread, and cheese",
+                    "type": "string",
+                    "example": "Milk, eggs, bread, and cheese"
+                },
+                "dueAt": {
+                    "description": "When the todo item is due, null when it has no date. All-day items are at midnight UTC of their date.\n@example 2025-06-12T17:00:00Z",
+                    "type": "string",
+                    "example": "2025-06-12T17:00:00Z"
+                },
+                "id": {
+                    "description": "Unique identifier\n@example 1",
+                    "type": "integer",
+                    "example": 1
+                },
+                "isCompleted": {
+                    "description": "Whether the todo item is completed\n@example false",
+                    "type": "boolean",
+                    "example": false
+                },
+                "notes": {
+                    "description": "Notes attached to the todo item, oldest first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.TodoNoteDto"
+                    }
+                },
+                "reminderOffsets": {
+                    "description": "Minutes before the due time at which to remind the user, smallest first\n@example 15,60",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        15,
+                        60
+                    ]
+                },
+                "title": {
+                    "description": "Title of the todo item\n@example Buy groceries",
+                    "type": "string",
+                    "example": "Buy groceries"
+                },
+                "updatedAt": {
+                    "description": "When the todo item was last updated\n@example 2025-06-10T10:30:00Z",
+                    "type": "string",
+                    "example": "2025-06-10T10:30:00Z"
+                }
+            }
+        },
+        "dtos.TodoItemListDto": {
+            "description": "Page of todo items with the cursor of the next page",
+            "type": "object",
+            "properties": {
+                "hasMore": {
+                    "description": "Whether more items follow this page\n@example true",
+                    "type": "boolean",
+                    "example": true
+                },
+                "items": {
+                    "description": "Todo items on this page",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.TodoItemDto"
+                    }
+                },
+                "nextCursor": {
+                    "description": "Pass as cursor to get the next page, null on the last page\n@example eyJzIjoiaWQiLCJ2IjoiMjAiLCJpZCI6MjB9",
+                    "type": "string",
+                    "example": "eyJzIjoiaWQiLCJ2IjoiMjAiLCJpZCI6MjB9"
+                },
+                "total": {
+                    "description": "Items matching the filters across all pages, only set when includeTotal=true\n@example 42",
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
+        "dtos.TodoNoteDto": {
+            "description": "A note with its todo item",
             "type": "object",
             "properties": {
+                "createdAt": {
+                    "description": "When the note was created\n@example 2025-06-10T10:30:00Z",
+                    "type": "string",
+                    "example": "2025-06-10T10:30:00Z"
+                },
+                "id": {
+                    "description": "Unique identifier\n@example 1",
+                    "type": "integer",
+                    "example": 1
+                },
                 "note": {
                     "description": "Content of the note\n@example Don't forget to check expiration dates",
                     "type": "string",
                     "example": "Don't forget to check expiration dates"
                 },
                 "todoItemId": {
-                    "description": "ID of the todo item this note belongs to\n@example 1",
+                    "description": "ID of the todo item the note belongs to\n@example 1",
                     "type": "integer",
                     "example": 1
+                },
+                "updatedAt": {
+                    "description": "When the note was last updated\n@example 2025-06-10T10:30:00Z",
+                    "type": "string",
+                    "example": "2025-06-10T10:30:00Z"
                 }
             }
         },
-        "dtos.DeleteTodoItemDto": {
+        "dtos.TodoSearchResultDto": {
+            "description": "Todo item matching a search with highlighted snippets",
             "type": "object",
             "properties": {
+                "createdAt": {
+                    "description": "When the todo item was created\n@example 2025-06-10T10:30:00Z",
+                    "type": "string",
+                    "example": "2025-06-10T10:30:00Z"
+                },
+                "description": {
+                    "description": "Description of the todo item\n@example Milk, eggs, bread, and cheese",
+                    "type": "string",
+                    "example": "Milk, eggs, bread, and cheese"
+                },
+                "descriptionSnippet": {
+                    "description": "Excerpt of the description around the matching words, when the description matches\n@example \u003cmark\u003eMilk\u003c/mark\u003e, eggs, bread, and cheese",
+                    "type": "string",
+                    "example": "\u003cmark\u003eMilk\u003c/mark\u003e, eggs, bread, and cheese"
+                },
                 "id": {
-                    "description": "ID of the todo item to delete\n@example 1",
+                    "description": "Unique identifier\n@example 1",
                     "type": "integer",
                     "example": 1
+                },
+                "isCompleted": {
+                    "description": "Whether the todo item is completed\n@example false",
+                    "type": "boolean",
+                    "example": false
+                },
+                "noteId": {
+                    "description": "Best matching note, when a note matches\n@example 3",
+                    "type": "integer",
+                    "example": 3
+                },
+                "noteSnippet": {
+                    "description": "Excerpt of that note around the matching words\n@example Don't forget to check expiration dates on the \u003cmark\u003emilk\u003c/mark\u003e",
+                    "type": "string",
+                    "example": "Don't forget to check expiration dates on the \u003cmark\u003emilk\u003c/mark\u003e"
+                },
+                "rank": {
+                    "description": "Relevance, higher is better\n@example 0.6079271",
+                    "type": "number",
+                    "example": 0.6079271
+                },
+                "title": {
+                    "description": "Title of the todo item\n@example Buy groceries",
+                    "type": "string",
+                    "example": "Buy groceries"
+                },
+                "titleHighlight": {
+                    "description": "Whole title with the matching words wrapped in \u003cmark\u003e tags. The rest of the text is not HTML escaped.\n@example Buy \u003cmark\u003egroceries\u003c/mark\u003e",
+                    "type": "string",
+                    "example": "Buy \u003cmark\u003egroceries\u003c/mark\u003e"
+                },
+                "updatedAt": {
+                    "description": "When the todo item was last updated\n@example 2025-06-10T10:30:00Z",
+                    "type": "string",
+                    "example": "2025-06-10T10:30:00Z"
                 }
             }
         },
-        "dtos.LoginUserDto": {
-            "description": "Login credentials for authenticating a user",
+        "dtos.TodoSearchResultsDto": {
+            "description": "Page of todo items matching a search, most relevant first, with the total number of matches",
+            "type": "object",
+            "properties": {
+                "page": {
+                    "description": "Current page number\n@example 1",
+                    "type": "integer",
+                    "example": 1
+                },
+                "pageSize": {
+                    "description": "Results per page\n@example 20",
+                    "type": "integer",
+                    "example": 20
+                },
+                "results": {
+                    "description": "Results on this page",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.TodoSearchResultDto"
+                    }
+                },
+                "total": {
+                    "description": "Todo items matching across all pages\n@example 3",
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "dtos.TwoFactorCodeDto": {
+            "description": "Six digit TOTP code",
             "type": "object",
             "required": [
-                "email",
-                "password"
+                "code"
             ],
             "properties": {
-                "email": {
-                    "description": "User's email address\n@example john.doe@example.com",
+                "code": {
+                    "description": "Current code from the authenticator app\n@example 123456",
                     "type": "string",
-                    "example": "john.doe@example.com"
+                    "example": "123456"
+                }
+            }
+        },
+        "dtos.TwoFactorEnrollmentDto": {
+            "description": "TOTP secret to add to an authenticator app",
+            "type": "object",
+            "properties": {
+                "otpauthUri": {
+                    "description": "otpauth:// URI, usually rendered as a QR code\n@example otpauth://totp/todo-api:john.doe%40example.com?algorithm=SHA1\u0026digits=6\u0026issuer=todo-api\u0026period=30\u0026secret=JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP",
+                    "type": "string",
+                    "example": "otpauth://totp/todo-api:john.doe%40example.com?algorithm=SHA1\u0026digits=6\u0026issuer=todo-api\u0026period=30\u0026secret=JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"
                 },
-                "password": {
-                    "description": "User's password\n@example SecureP@ssw0rd",
+                "secret": {
+                    "description": "Base32 encoded secret for manual entry\n@example JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP",
                     "type": "string",
-                    "example": "SecureP@ssw0rd"
+                    "example": "JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"
                 }
             }
         },
-        "dtos.RegisterUserDto": {
-            "description": "Registration data for creating a new user account",
+        "dtos.TwoFactorLoginDto": {
+            "description": "Challenge token from login and a TOTP or recovery code",
             "type": "object",
             "required": [
-                "confirmPassword",
-                "email",
-                "name",
-                "password"
+                "challengeToken",
+                "code"
             ],
             "properties": {
-                "confirmPassword": {
-                    "description": "Confirmation of the password\n@example SecureP@ssw0rd",
-                    "type": "string",
-                    "example": "SecureP@ssw0rd"
-                },
-                "email": {
-                    "description": "User's email address\n@example john.doe@example.com",
-                    "type": "string",
-                    "example": "john.doe@example.com"
-                },
-                "name": {
-                    "description": "User's full name\n@example John Doe",
+                "challengeToken": {
+                    "description": "Challenge token returned by /auth/login\n@example eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9...",
                     "type": "string",
-                    "example": "John Doe"
+                    "example": "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."
                 },
-                "password": {
-                    "description": "User's password (min 8 characters)\n@example SecureP@ssw0rd",
+                "code": {
+                    "description": "TOTP code or unused recovery code\n@example 123456",
                     "type": "string",
-                    "example": "SecureP@ssw0rd"
+                    "example": "123456"
                 }
             }
         },
-        "dtos.StructuredResponse": {
-            "description": "Standard response format containing success status, HTTP status code, message, and optional payload",
+        "dtos.UpdateProfileDto": {
+            "description": "New values for the profile fields to change",
             "type": "object",
             "properties": {
-                "message": {
-                    "description": "Human-readable message\n@example Operation completed successfully",
+                "avatarUrl": {
+                    "description": "http(s) URL of the profile picture, empty to remove it\n@example https://example.com/avatar.png",
                     "type": "string",
-                    "example": "Operation completed successfully"
+                    "example": "https://example.com/avatar.png"
                 },
-                "payload": {
-                    "description": "Optional payload containing response data\n@example {\"id\":1,\"name\":\"Example Item\"}"
+                "bio": {
+                    "description": "Short text about the user (max 500 characters)\n@example Getting things done",
+                    "type": "string",
+                    "example": "Getting things done"
                 },
-                "status": {
-                    "description": "HTTP status code\n@example 200",
-                    "type": "integer",
-                    "example": 200
+                "name": {
+                    "description": "Display name (1-100 characters)\n@example John Doe",
+                    "type": "string",
+                    "example": "John Doe"
                 },
-                "success": {
-                    "description": "Whether the operation was successful\n@example true",
-                    "type": "boolean",
-                    "example": true
+                "timezone": {
+                    "description": "IANA time zone name, \"today\" of the todo views starts at midnight in it\n@example Europe/Berlin",
+                    "type": "string",
+                    "example": "Europe/Berlin"
                 }
             }
         },
@@ -504,13 +6100,138 @@ const docTemplate = `{
                     "description": "Updated title (3-255 characters)\n@example Buy groceries and household items",
                     "type": "string",
                     "example": "Buy groceries and household items"
+                }
+            }
+        },
+        "dtos.UserDto": {
+            "description": "Account details of the logged in user",
+            "type": "object",
+            "properties": {
+                "avatarUrl": {
+                    "description": "URL of the profile picture",
+                    "type": "string",
+                    "example": "https://example.com/avatar.png"
+                },
+                "bio": {
+                    "description": "Short text about the user",
+                    "type": "string",
+                    "example": "Getting things done"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "john.doe@example.com"
+                },
+                "emailVerifiedAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "name": {
+                    "type": "string",
+                    "example": "John Doe"
+                },
+                "pendingEmail": {
+                    "description": "Address waiting for confirmation after a change of email, empty when there is none",
+                    "type": "string",
+                    "example": "john.new@example.com"
+                },
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "user"
+                    ]
+                },
+                "timezone": {
+                    "description": "IANA time zone used for the todo views",
+                    "type": "string",
+                    "example": "Europe/Berlin"
+                },
+                "twoFactorEnabled": {
+                    "type": "boolean",
+                    "example": false
+                }
+            }
+        },
+        "dtos.UserListDto": {
+            "description": "Page of users with the total number of matches",
+            "type": "object",
+            "properties": {
+                "page": {
+                    "description": "Current page number\n@example 1",
+                    "type": "integer",
+                    "example": 1
+                },
+                "pageSize": {
+                    "description": "Users per page\n@example 20",
+                    "type": "integer",
+                    "example": 20
+                },
+                "total": {
+                    "description": "Users matching the search across all pages\n@example 42",
+                    "type": "integer",
+                    "example": 42
+                },
+                "users": {
+                    "description": "Users on this page, ordered by ID",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.AdminUserDto"
+                    }
+                }
+            }
+        },
+        "dtos.UserRolesDto": {
+            "description": "Roles assigned to a user",
+            "type": "object",
+            "properties": {
+                "roles": {
+                    "description": "Names of the assigned roles\n@example [\"user\",\"admin\"]",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "user",
+                        "admin"
+                    ]
                 },
                 "userId": {
-                    "description": "User ID associated with the todo item\n@example 1",
+                    "description": "User ID\n@example 1",
                     "type": "integer",
                     "example": 1
                 }
             }
+        },
+        "dtos.ValidationErrorsDto": {
+            "description": "Every rejected field of the request",
+            "type": "object",
+            "properties": {
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.FieldError"
+                    }
+                }
+            }
+        },
+        "dtos.VerifyEmailDto": {
+            "description": "Token from the verification email",
+            "type": "object",
+            "required": [
+                "token"
+            ],
+            "properties": {
+                "token": {
+                    "description": "Signed token from the verification link\n@example eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9...",
+                    "type": "string",
+                    "example": "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."
+                }
+            }
         }
     },
     "securityDefinitions": {
@@ -0,0 +1,112 @@
+package dtos
+
+import "time"
+
+// ListUsersDto holds the query parameters of the admin user list
+type ListUsersDto struct {
+	// Case-insensitive text matched against email and name
+	Search string `json:"-"`
+	// 1-based page number
+	Page int `json:"-"`
+	// Users per page
+	PageSize int `json:"-"`
+}
+
+// CreateUserDto represents the data an administrator needs to create an account
+// @Description Email, name and optional password of a new account
+type CreateUserDto struct {
+	// Email address, stored in lowercase
+	// @example jane.doe@example.com
+	Email string `json:"email" binding:"required,email,max=255" example:"jane.doe@example.com"`
+	// Full name
+	// @example Jane Doe
+	Name string `json:"name" binding:"required,max=100" example:"Jane Doe"`
+	// Initial password, when left out the user is emailed a link to choose one
+	// @example SecureP@ssw0rd
+	Password string `json:"password,omitempty" example:"SecureP@ssw0rd"`
+
+	// Administrator creating the account, set by the handler
+	ActorID uint `json:"-"`
+}
+
+// AdminUserDto describes an account as administrators see it
+// @Description User account with its status
+type AdminUserDto struct {
+	// User ID
+	// @example 1
+	ID uint `json:"id" example:"1"`
+	// Email address
+	// @example john.doe@example.com
+	Email string `json:"email" example:"john.doe@example.com"`
+	// Full name
+	// @example John Doe
+	Name string `json:"name" example:"John Doe"`
+	// When the email address was verified, null while it is unverified
+	EmailVerifiedAt *time.Time `json:"emailVerifiedAt"`
+	// Whether two-factor authentication is enabled
+	// @example false
+	TwoFactorEnabled bool `json:"twoFactorEnabled" example:"false"`
+	// When the account was disabled, null while it is enabled
+	DisabledAt *time.Time `json:"disabledAt"`
+	// Names of the user's roles
+	// @example ["user"]
+	Roles []string `json:"roles" example:"user"`
+	// Todo statistics, only included when a single user is requested
+	Todos *TodoCountsDto `json:"todos,omitempty"`
+}
+
+// TodoCountsDto summarises the todo items of a user
+// @Description Number of todo items by state
+type TodoCountsDto struct {
+	// Every todo item of the user
+	// @example 12
+	Total int64 `json:"total" example:"12"`
+	// Completed todo items
+	// @example 7
+	Completed int64 `json:"completed" example:"7"`
+	// Todo items still open
+	// @example 5
+	Open int64 `json:"open" example:"5"`
+}
+
+// UserListDto is one page of the admin user list
+// @Description Page of users with the total number of matches
+type UserListDto struct {
+	// Users on this page, ordered by ID
+	Users []AdminUserDto `json:"users"`
+	// Current page number
+	// @example 1
+	Page int `json:"page" example:"1"`
+	// Users per page
+	// @example 20
+	PageSize int `json:"pageSize" example:"20"`
+	// Users matching the search across all pages
+	// @example 42
+	Total int64 `json:"total" example:"42"`
+}
+
+// AdminUserActionDto identifies the user an administrator acts on
+type AdminUserActionDto struct {
+	// ID of the user, taken from the path
+	UserID uint `json:"-"`
+	// Administrator making the request, set by the handler
+	ActorID    uint   `json:"-"`
+	ActorEmail string `json:"-"`
+}
+
+// ImpersonationTokenDto is returned when an administrator starts acting as a user
+// @Description Short-lived access token for the impersonated user
+type ImpersonationTokenDto struct {
+	// ID of the impersonated user
+	// @example 7
+	UserID uint `json:"userId" example:"7"`
+	// Email address of the impersonated user
+	// @example jane.doe@example.com
+	Email string `json:"email" example:"jane.doe@example.com"`
+	// Access token acting as the user, it names the administrator in its act claim
+	// @example eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9...
+	Token string `json:"token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	// Lifetime of the token in seconds, it cannot be refreshed
+	// @example 900
+	ExpiresIn int64 `json:"expiresIn" example:"900"`
+}
@@ -0,0 +1,29 @@
+package dtos
+
+// VerifyEmailDto represents the data needed to confirm an email address
+// @Description Token from the verification email
+type VerifyEmailDto struct {
+	// Signed token from the verification link
+	// @example eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9...
+	Token string `json:"token" binding:"required" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+}
+
+// ResendVerificationDto represents the data needed to request a new verification email
+// @Description Email address of the account to verify
+type ResendVerificationDto struct {
+	// User's email address
+	// @example john.doe@example.com
+	Email string `json:"email" binding:"required" example:"john.doe@example.com"`
+}
+
+// ConfirmEmailChangeDto represents the data needed to confirm a new email address
+// @Description Token from the email sent to the new address
+type ConfirmEmailChangeDto struct {
+	// Signed token from the confirmation link
+	// @example eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9...
+	Token string `json:"token" binding:"required" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+
+	// Client IP address and user agent, set by the handler
+	IPAddress string `json:"-"`
+	UserAgent string `json:"-"`
+}
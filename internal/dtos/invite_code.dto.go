@@ -0,0 +1,58 @@
+package dtos
+
+import "time"
+
+// CreateInviteCodeDto represents the data needed to create an invite code
+// @Description Number of uses, lifetime and note of a new invite code
+type CreateInviteCodeDto struct {
+	// How many accounts the code can create, 1 when left out
+	// @example 1
+	MaxUses int `json:"maxUses" example:"1"`
+	// Lifetime in days, the code never expires when 0
+	// @example 7
+	ExpiresInDays int `json:"expiresInDays" example:"7"`
+	// Who or what the code is for
+	// @example New hires, October
+	Note string `json:"note" binding:"max=255" example:"New hires, October"`
+
+	// Administrator creating the code, set by the handler
+	CreatedByID uint `json:"-"`
+}
+
+// InviteCodeDto describes an invite code without its secret
+// @Description Invite code metadata
+type InviteCodeDto struct {
+	// Invite code ID
+	// @example 1
+	ID uint `json:"id" example:"1"`
+	// Visible beginning of the code
+	// @example inv_1a2b3c4d
+	Prefix string `json:"prefix" example:"inv_1a2b3c4d"`
+	// Who or what the code is for
+	// @example New hires, October
+	Note string `json:"note" example:"New hires, October"`
+	// How many accounts the code can create
+	// @example 10
+	MaxUses int `json:"maxUses" example:"10"`
+	// How many accounts were created with the code
+	// @example 3
+	Uses int `json:"uses" example:"3"`
+	// When the code expires, null when it never does
+	ExpiresAt *time.Time `json:"expiresAt"`
+	// When the code was revoked, null while it is active
+	RevokedAt *time.Time `json:"revokedAt"`
+	// Administrator who created the code
+	// @example 1
+	CreatedByID *uint `json:"createdById" example:"1"`
+	// When the code was created
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// CreatedInviteCodeDto is returned once when a code is created, it is the only time the code is shown
+// @Description New invite code including the code itself
+type CreatedInviteCodeDto struct {
+	InviteCodeDto
+	// The code to hand out, store it now because it cannot be retrieved again
+	// @example inv_1a2b3c4d_Qk4Rz6Vt5Nn2Lp0Hs7Jd1Ga
+	Code string `json:"code" example:"inv_1a2b3c4d_Qk4Rz6Vt5Nn2Lp0Hs7Jd1Ga"`
+}
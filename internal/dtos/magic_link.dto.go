@@ -0,0 +1,32 @@
+package dtos
+
+// RequestMagicLinkDto represents the data needed to email a login link
+// @Description Email address of the account to log in to
+type RequestMagicLinkDto struct {
+	// User's email address
+	// @example john.doe@example.com
+	Email string `json:"email" binding:"required,email" example:"john.doe@example.com"`
+
+	// Client IP address and browser binding cookie, set by the handler
+	IPAddress     string `json:"-"`
+	BrowserCookie string `json:"-"`
+}
+
+// MagicLinkSentDto carries the browser binding nonce from the repository to the handler,
+// which stores it in a cookie. It is never part of a response body.
+type MagicLinkSentDto struct {
+	BrowserCookie string `json:"-"`
+}
+
+// MagicLinkLoginDto represents the data needed to log in with an emailed link
+// @Description Token from the login link
+type MagicLinkLoginDto struct {
+	// Signed token from the login link
+	// @example eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9...
+	Token string `json:"token" binding:"required" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+
+	// Browser binding cookie, client IP address and user agent, set by the handler
+	BrowserCookie string `json:"-"`
+	IPAddress     string `json:"-"`
+	UserAgent     string `json:"-"`
+}
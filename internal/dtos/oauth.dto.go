@@ -0,0 +1,223 @@
+package dtos
+
+import "time"
+
+// CreateOAuthClientDto represents the data needed to register a third-party app
+// @Description Name, redirect URIs and scopes of a new OAuth2 app
+type CreateOAuthClientDto struct {
+	// Name shown to users on the consent screen
+	// @example Todo Widgets
+	Name string `json:"name" binding:"required,max=100" example:"Todo Widgets"`
+	// Where users are sent back after the consent screen, matched exactly
+	// @example ["https://widgets.example.com/callback"]
+	RedirectURIs []string `json:"redirectUris" example:"https://widgets.example.com/callback"`
+	// Scopes the app may ask for, all of them when empty
+	// @example ["todos:read"]
+	Scopes []string `json:"scopes" example:"todos:read"`
+	// Whether the app can keep a client secret. Apps running in a browser or on a device cannot and rely on PKCE alone.
+	// @example true
+	Confidential bool `json:"confidential" example:"true"`
+
+	// Administrator registering the app, set by the handler
+	CreatedByID uint `json:"-"`
+}
+
+// OAuthClientDto describes a registered app without its secret
+// @Description OAuth2 app
+type OAuthClientDto struct {
+	// Internal ID
+	// @example 1
+	ID uint `json:"id" example:"1"`
+	// Client identifier the app sends as client_id
+	// @example tdc_Qk4Rz6Vt5Nn2Lp0H
+	ClientID string `json:"clientId" example:"tdc_Qk4Rz6Vt5Nn2Lp0H"`
+	// Name shown to users on the consent screen
+	// @example Todo Widgets
+	Name string `json:"name" example:"Todo Widgets"`
+	// Registered redirect URIs
+	// @example ["https://widgets.example.com/callback"]
+	RedirectURIs []string `json:"redirectUris" example:"https://widgets.example.com/callback"`
+	// Scopes the app may ask for
+	// @example ["todos:read"]
+	Scopes []string `json:"scopes" example:"todos:read"`
+	// Whether the app authenticates with a client secret
+	// @example true
+	Confidential bool `json:"confidential" example:"true"`
+	// Administrator who registered the app
+	// @example 1
+	CreatedByID *uint `json:"createdById" example:"1"`
+	// When the app was registered
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// CreatedOAuthClientDto is returned once when an app is registered, it is the only time the secret is shown
+// @Description New OAuth2 app including its client secret
+type CreatedOAuthClientDto struct {
+	OAuthClientDto
+	// Client secret of a confidential app, store it now because it cannot be retrieved again
+	// @example tds_3q2-7wF0bX9mY1c8Qk4Rz6Vt5Nn2Lp0Hs7Jd1Ga9Ke4
+	ClientSecret string `json:"clientSecret,omitempty" example:"tds_3q2-7wF0bX9mY1c8Qk4Rz6Vt5Nn2Lp0Hs7Jd1Ga9Ke4"`
+}
+
+// OAuthAuthorizationRequestDto carries the parameters an app sent to the consent screen.
+// The names are those of RFC 6749, so the web app can pass the query string on unchanged.
+// @Description Authorization request of an app, and the user's decision when posted
+type OAuthAuthorizationRequestDto struct {
+	// Must be "code"
+	// @example code
+	ResponseType string `json:"response_type" example:"code"`
+	// Client identifier of the app
+	// @example tdc_Qk4Rz6Vt5Nn2Lp0H
+	ClientID string `json:"client_id" example:"tdc_Qk4Rz6Vt5Nn2Lp0H"`
+	// One of the app's registered redirect URIs, may be left out when it has only one
+	// @example https://widgets.example.com/callback
+	RedirectURI string `json:"redirect_uri" example:"https://widgets.example.com/callback"`
+	// Space separated scopes, every scope of the app when empty
+	// @example todos:read
+	Scope string `json:"scope" example:"todos:read"`
+	// Opaque value returned to the app unchanged
+	// @example af0ifjsldkj
+	State string `json:"state" example:"af0ifjsldkj"`
+	// PKCE code challenge
+	// @example E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM
+	CodeChallenge string `json:"code_challenge" example:"E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"`
+	// Must be "S256"
+	// @example S256
+	CodeChallengeMethod string `json:"code_challenge_method" example:"S256"`
+	// The user's decision, only read when posting the consent
+	// @example true
+	Approve bool `json:"approve" example:"true"`
+
+	// ID of the user giving consent, set by the handler
+	UserID uint `json:"-"`
+}
+
+// OAuthConsentDto is what the consent screen shows
+// @Description App asking for access and the scopes it asks for
+type OAuthConsentDto struct {
+	// Client identifier of the app
+	// @example tdc_Qk4Rz6Vt5Nn2Lp0H
+	ClientID string `json:"clientId" example:"tdc_Qk4Rz6Vt5Nn2Lp0H"`
+	// Name of the app
+	// @example Todo Widgets
+	ClientName string `json:"clientName" example:"Todo Widgets"`
+	// Scopes the app asks for
+	Scopes []OAuthScopeDto `json:"scopes"`
+}
+
+// OAuthScopeDto describes a scope on the consent screen
+// @Description Scope and what it allows
+type OAuthScopeDto struct {
+	// Scope name
+	// @example todos:read
+	Name string `json:"name" example:"todos:read"`
+	// What the scope allows the app to do
+	// @example Read your todo items and notes
+	Description string `json:"description" example:"Read your todo items and notes"`
+}
+
+// OAuthRedirectDto tells the web app where to send the browser after the consent screen
+// @Description Redirect back to the app carrying the authorization code or an error
+type OAuthRedirectDto struct {
+	// URL to send the browser to
+	// @example https://widgets.example.com/callback?code=Qk4Rz6Vt5Nn2Lp0Hs7Jd1Ga9Ke4&state=af0ifjsldkj
+	RedirectURI string `json:"redirectUri" example:"https://widgets.example.com/callback?code=Qk4Rz6Vt5Nn2Lp0Hs7Jd1Ga9Ke4&state=af0ifjsldkj"`
+}
+
+// OAuthTokenRequestDto carries the form parameters of a token request
+type OAuthTokenRequestDto struct {
+	// "authorization_code" or "refresh_token"
+	GrantType string
+	// Parameters of the authorization_code grant
+	Code         string
+	RedirectURI  string
+	CodeVerifier string
+	// Parameters of the refresh_token grant, Scope may narrow the scopes of the refresh token
+	RefreshToken string
+	Scope        string
+	// Client credentials from the Authorization header or the form
+	ClientID     string
+	ClientSecret string
+}
+
+// OAuthTokenReferenceDto carries the form parameters of an introspection or revocation request
+type OAuthTokenReferenceDto struct {
+	Token string
+	// Client credentials from the Authorization header or the form
+	ClientID     string
+	ClientSecret string
+}
+
+// OAuthTokenResponseDto is the RFC 6749 token response
+// @Description Access token and refresh token issued to an app
+type OAuthTokenResponseDto struct {
+	// Opaque access token, sent as a Bearer token
+	// @example tdo_3q2-7wF0bX9mY1c8Qk4Rz6Vt5Nn2Lp0Hs7Jd1Ga9Ke4
+	AccessToken string `json:"access_token" example:"tdo_3q2-7wF0bX9mY1c8Qk4Rz6Vt5Nn2Lp0Hs7Jd1Ga9Ke4"`
+	// Always "Bearer"
+	// @example Bearer
+	TokenType string `json:"token_type" example:"Bearer"`
+	// Lifetime of the access token in seconds
+	// @example 3600
+	ExpiresIn int64 `json:"expires_in" example:"3600"`
+	// Single-use refresh token
+	// @example tdr_Jq0x3Hh6c2n1Xy8oQ4bR7tV5mW9zK2aL0sD6fG1jE3U
+	RefreshToken string `json:"refresh_token" example:"tdr_Jq0x3Hh6c2n1Xy8oQ4bR7tV5mW9zK2aL0sD6fG1jE3U"`
+	// Space separated scopes granted
+	// @example todos:read
+	Scope string `json:"scope" example:"todos:read"`
+}
+
+// OAuthErrorDto is the RFC 6749 error response
+// @Description OAuth2 error code and description
+type OAuthErrorDto struct {
+	// Error code such as invalid_grant
+	// @example invalid_grant
+	Error string `json:"error" example:"invalid_grant"`
+	// Human readable explanation
+	// @example Invalid authorization code
+	ErrorDescription string `json:"error_description,omitempty" example:"Invalid authorization code"`
+}
+
+// OAuthIntrospectionDto is the RFC 7662 introspection response, only Active is set for inactive tokens
+// @Description State of an access or refresh token
+type OAuthIntrospectionDto struct {
+	// Whether the token can be used
+	// @example true
+	Active bool `json:"active" example:"true"`
+	// Space separated scopes of the token
+	// @example todos:read
+	Scope string `json:"scope,omitempty" example:"todos:read"`
+	// App the token was issued to
+	// @example tdc_Qk4Rz6Vt5Nn2Lp0H
+	ClientID string `json:"client_id,omitempty" example:"tdc_Qk4Rz6Vt5Nn2Lp0H"`
+	// Email address of the user
+	// @example john.doe@example.com
+	Username string `json:"username,omitempty" example:"john.doe@example.com"`
+	// ID of the user
+	// @example 1
+	Subject string `json:"sub,omitempty" example:"1"`
+	// "access_token" or "refresh_token"
+	// @example access_token
+	TokenType string `json:"token_type,omitempty" example:"access_token"`
+	// Expiry as a Unix timestamp
+	// @example 1735689600
+	ExpiresAt int64 `json:"exp,omitempty" example:"1735689600"`
+	// Issue time as a Unix timestamp
+	// @example 1735686000
+	IssuedAt int64 `json:"iat,omitempty" example:"1735686000"`
+}
+
+// OAuthServerMetadataDto is the RFC 8414 authorization server metadata
+type OAuthServerMetadataDto struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	IntrospectionEndpoint             string   `json:"introspection_endpoint"`
+	RevocationEndpoint                string   `json:"revocation_endpoint"`
+	ScopesSupported                   []string `json:"scopes_supported"`
+	ResponseTypesSupported            []string `json:"response_types_supported"`
+	GrantTypesSupported               []string `json:"grant_types_supported"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+}
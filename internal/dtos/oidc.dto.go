@@ -0,0 +1,25 @@
+package dtos
+
+// OIDCRedirectDto is produced when a single sign-on login starts
+type OIDCRedirectDto struct {
+	// Provider URL the browser is redirected to
+	AuthorizationURL string `json:"authorizationUrl"`
+
+	// Encrypted state, nonce and PKCE verifier, stored in a cookie by the handler
+	StateCookie string `json:"-"`
+}
+
+// OIDCCallbackDto carries the parameters the provider sends back to the callback
+type OIDCCallbackDto struct {
+	Code  string
+	State string
+	// Error and ErrorDescription are set when the user cancelled or the provider refused
+	Error            string
+	ErrorDescription string
+
+	// Value of the state cookie set when the login started
+	StateCookie string
+	// Client IP address and user agent, recorded on the session
+	IPAddress string
+	UserAgent string
+}
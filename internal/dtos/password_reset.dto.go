@@ -0,0 +1,30 @@
+package dtos
+
+// ForgotPasswordDto represents the data needed to request a password reset email
+// @Description Email address of the account to recover
+type ForgotPasswordDto struct {
+	// User's email address
+	// @example john.doe@example.com
+	Email string `json:"email" binding:"required" example:"john.doe@example.com"`
+
+	// Client IP address, set by the handler
+	IPAddress string `json:"-"`
+}
+
+// ResetPasswordDto represents the data needed to set a new password with a reset token
+// @Description Reset token from the email together with the new password
+type ResetPasswordDto struct {
+	// Token from the password reset email
+	// @example V1StGXR8_Z5jdHi6B-myT3q2-7wF0bX9mY1c8Qk4Rz6
+	Token string `json:"token" binding:"required" example:"V1StGXR8_Z5jdHi6B-myT3q2-7wF0bX9mY1c8Qk4Rz6"`
+	// New password (min 8 characters)
+	// @example N3wSecureP@ssw0rd
+	Password string `json:"password" binding:"required" example:"N3wSecureP@ssw0rd"`
+	// Confirmation of the new password
+	// @example N3wSecureP@ssw0rd
+	ConfirmPassword string `json:"confirmPassword" binding:"required,eqfield=Password" example:"N3wSecureP@ssw0rd"`
+
+	// Client IP address and user agent, set by the handler
+	IPAddress string `json:"-"`
+	UserAgent string `json:"-"`
+}
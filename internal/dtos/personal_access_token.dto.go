@@ -0,0 +1,63 @@
+package dtos
+
+import "time"
+
+// CreatePersonalAccessTokenDto represents the data needed to create a personal access token
+// @Description Name, scopes and lifetime of a new personal access token
+type CreatePersonalAccessTokenDto struct {
+	// Name to recognise the token by
+	// @example CI pipeline
+	Name string `json:"name" binding:"required" example:"CI pipeline"`
+	// Permissions the token is limited to, todos:read and todos:write when empty
+	// @example ["todos:read"]
+	Scopes []string `json:"scopes" example:"todos:read"`
+	// Lifetime in days, the token never expires when 0
+	// @example 90
+	ExpiresInDays int `json:"expiresInDays" example:"90"`
+
+	// ID of the token owner, set by the handler
+	UserID uint `json:"-"`
+}
+
+// PersonalAccessTokenDto describes a personal access token without its secret
+// @Description Personal access token metadata
+type PersonalAccessTokenDto struct {
+	// Token ID
+	// @example 1
+	ID uint `json:"id" example:"1"`
+	// Name of the token
+	// @example CI pipeline
+	Name string `json:"name" example:"CI pipeline"`
+	// Visible beginning of the token
+	// @example tdp_1a2b3c4d
+	Prefix string `json:"prefix" example:"tdp_1a2b3c4d"`
+	// Permissions the token is limited to
+	// @example ["todos:read"]
+	Scopes []string `json:"scopes" example:"todos:read"`
+	// When the token expires, null when it never does
+	ExpiresAt *time.Time `json:"expiresAt"`
+	// When the token was last used
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+	// IP address the token was last used from
+	// @example 203.0.113.7
+	LastUsedIP string `json:"lastUsedIp" example:"203.0.113.7"`
+	// When the token was created
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// CreatedPersonalAccessTokenDto is returned once when a token is created, it is the only time the secret is shown
+// @Description New personal access token including its secret
+type CreatedPersonalAccessTokenDto struct {
+	PersonalAccessTokenDto
+	// The token itself, store it now because it cannot be retrieved again
+	// @example tdp_1a2b3c4d_3q2-7wF0bX9mY1c8Qk4Rz6Vt5Nn2Lp0Hs7Jd1Ga9Ke4
+	Token string `json:"token" example:"tdp_1a2b3c4d_3q2-7wF0bX9mY1c8Qk4Rz6Vt5Nn2Lp0Hs7Jd1Ga9Ke4"`
+}
+
+// RevokePersonalAccessTokenDto identifies the token to revoke
+type RevokePersonalAccessTokenDto struct {
+	// ID of the token, taken from the path
+	ID uint `json:"-"`
+	// ID of the token owner, set by the handler
+	UserID uint `json:"-"`
+}
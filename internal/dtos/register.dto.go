@@ -3,10 +3,10 @@ package dtos
 // RegisterUserDto represents the data needed to register a new user
 // @Description Registration data for creating a new user account
 type RegisterUserDto struct {
-	// User's email address
+	// User's email address, stored in lowercase
 	// @example john.doe@example.com
-	Email string `json:"email" binding:"required" example:"john.doe@example.com"`
-	// User's password (min 8 characters)
+	Email string `json:"email" binding:"required,email,max=255" example:"john.doe@example.com"`
+	// User's password, must follow the password policy (min 8 characters by default)
 	// @example SecureP@ssw0rd
 	Password string `json:"password" binding:"required" example:"SecureP@ssw0rd"`
 	// Confirmation of the password
@@ -14,7 +14,10 @@ type RegisterUserDto struct {
 	ConfirmPassword string `json:"confirmPassword" binding:"required,eqfield=Password" example:"SecureP@ssw0rd"`
 	// User's full name
 	// @example John Doe
-	Name string `json:"name" binding:"required" example:"John Doe"`
+	Name string `json:"name" binding:"required,max=100" example:"John Doe"`
+	// Invite code from an administrator, required when REGISTRATION_MODE is "invite"
+	// @example inv_1a2b3c4d_Qk4Rz6Vt5Nn2Lp0Hs7Jd1Ga
+	InviteCode string `json:"inviteCode,omitempty" example:"inv_1a2b3c4d_Qk4Rz6Vt5Nn2Lp0Hs7Jd1Ga"`
 }
 
 // LoginUserDto represents the data needed to login a user
@@ -26,4 +29,8 @@ type LoginUserDto struct {
 	// User's password
 	// @example SecureP@ssw0rd
 	Password string `json:"password" binding:"required" example:"SecureP@ssw0rd"`
+
+	// Client IP address and user agent, set by the handler
+	IPAddress string `json:"-"`
+	UserAgent string `json:"-"`
 }
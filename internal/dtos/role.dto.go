@@ -0,0 +1,23 @@
+package dtos
+
+// SetUserRolesDto represents the data needed to replace the roles of a user
+// @Description Complete list of roles the user should have
+type SetUserRolesDto struct {
+	// Names of the roles to assign
+	// @example ["user","admin"]
+	Roles []string `json:"roles" binding:"required" example:"user,admin"`
+
+	// ID of the user, taken from the path
+	UserID uint `json:"-"`
+}
+
+// UserRolesDto is the result of a role assignment
+// @Description Roles assigned to a user
+type UserRolesDto struct {
+	// User ID
+	// @example 1
+	UserID uint `json:"userId" example:"1"`
+	// Names of the assigned roles
+	// @example ["user","admin"]
+	Roles []string `json:"roles" example:"user,admin"`
+}
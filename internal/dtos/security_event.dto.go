@@ -0,0 +1,56 @@
+package dtos
+
+import "time"
+
+// ListSecurityEventsDto holds the user and query parameters of a security event listing
+type ListSecurityEventsDto struct {
+	// Owner of the events, the current user or the one in the admin path
+	UserID uint `json:"-"`
+	// Only return events of this type
+	Type string `json:"-"`
+	// 1-based page number
+	Page int `json:"-"`
+	// Events per page
+	PageSize int `json:"-"`
+}
+
+// SecurityEventDto describes something that happened to the security of an account
+// @Description Login, password change, token change or other security event
+type SecurityEventDto struct {
+	// Event ID
+	// @example 31
+	ID uint `json:"id" example:"31"`
+	// Event type
+	// @example login.failed
+	Type string `json:"type" example:"login.failed"`
+	// IP address of the client
+	// @example 203.0.113.7
+	IPAddress string `json:"ipAddress" example:"203.0.113.7"`
+	// User agent of the client
+	// @example Mozilla/5.0 (Macintosh; Intel Mac OS X 14_5) AppleWebKit/605.1.15
+	UserAgent string `json:"userAgent" example:"Mozilla/5.0 (Macintosh; Intel Mac OS X 14_5) AppleWebKit/605.1.15"`
+	// Additional context
+	// @example Wrong password
+	Details string `json:"details" example:"Wrong password"`
+	// Administrator who caused the event, if any
+	// @example 1
+	ActorID *uint `json:"actorId,omitempty" example:"1"`
+	// When the event happened
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// SecurityEventListDto is one page of a security event log
+// @Description Page of security events, newest first, with the total number of matches
+type SecurityEventListDto struct {
+	// Events on this page
+	Events []SecurityEventDto `json:"events"`
+	// Current page number
+	// @example 1
+	Page int `json:"page" example:"1"`
+	// Events per page
+	// @example 20
+	PageSize int `json:"pageSize" example:"20"`
+	// Events matching across all pages
+	// @example 57
+	Total int64 `json:"total" example:"57"`
+}
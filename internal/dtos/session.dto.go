@@ -0,0 +1,39 @@
+package dtos
+
+import "time"
+
+// SessionDto describes a device the user is logged in on
+// @Description Active login session
+type SessionDto struct {
+	// Session ID
+	// @example 12
+	ID uint `json:"id" example:"12"`
+	// User agent of the client that logged in
+	// @example Mozilla/5.0 (Macintosh; Intel Mac OS X 14_5) AppleWebKit/605.1.15
+	UserAgent string `json:"userAgent" example:"Mozilla/5.0 (Macintosh; Intel Mac OS X 14_5) AppleWebKit/605.1.15"`
+	// Last known IP address
+	// @example 203.0.113.7
+	IPAddress string `json:"ipAddress" example:"203.0.113.7"`
+	// When the user logged in
+	CreatedAt time.Time `json:"createdAt"`
+	// Last request made with the session, updated about once a minute
+	LastSeenAt time.Time `json:"lastSeenAt"`
+	// Whether this is the session making the request
+	// @example true
+	Current bool `json:"current" example:"true"`
+}
+
+// RevokeSessionDto identifies a session to log out
+type RevokeSessionDto struct {
+	// ID of the session, taken from the path
+	ID uint `json:"-"`
+	// ID of the current user, set by the handler
+	UserID uint `json:"-"`
+}
+
+// RevokeOtherSessionsDto identifies the session to keep when logging out every other device
+type RevokeOtherSessionsDto struct {
+	// ID of the current user and session, set by the handler
+	UserID           uint `json:"-"`
+	CurrentSessionID uint `json:"-"`
+}
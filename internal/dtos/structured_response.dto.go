@@ -16,3 +16,28 @@ type StructuredResponse struct {
 	// @example {"id":1,"name":"Example Item"}
 	Payload interface{} `json:"payload"`
 }
+
+// RateLimitedDto is the payload of 429 responses, the handler mirrors it in the Retry-After header
+// @Description Number of seconds to wait before retrying
+type RateLimitedDto struct {
+	// Seconds until the next attempt is allowed
+	// @example 30
+	RetryAfter int `json:"retryAfter" example:"30"`
+}
+
+// FieldError describes why one field of a request was rejected
+// @Description A rejected request field and the reason
+type FieldError struct {
+	// JSON name of the field
+	// @example password
+	Field string `json:"field" example:"password"`
+	// Reason the value was rejected
+	// @example Must be at least 8 characters
+	Message string `json:"message" example:"Must be at least 8 characters"`
+}
+
+// ValidationErrorsDto is the payload of responses rejecting request fields
+// @Description Every rejected field of the request
+type ValidationErrorsDto struct {
+	Errors []FieldError `json:"errors"`
+}
@@ -1,6 +1,9 @@
 package dtos
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // TodoItemDto represents a todo item in the system
 // @Description A todo item with all its details
@@ -23,6 +26,76 @@ type TodoItemDto struct {
 	// When the todo item was last updated
 	// @example 2025-06-10T10:30:00Z
 	UpdatedAt time.Time `json:"updatedAt" example:"2025-06-10T10:30:00Z"`
+	// When the todo item is due, null when it has no date. All-day items are at midnight UTC of their date.
+	// @example 2025-06-12T17:00:00Z
+	DueAt *time.Time `json:"dueAt" example:"2025-06-12T17:00:00Z"`
+	// Whether the todo item is due on its date as a whole rather than at a time
+	// @example false
+	AllDay bool `json:"allDay" example:"false"`
+	// Minutes before the due time at which to remind the user, smallest first
+	// @example 15,60
+	ReminderOffsets []int `json:"reminderOffsets" example:"15,60"`
+	// Notes attached to the todo item, oldest first
+	Notes []TodoNoteDto `json:"notes"`
+}
+
+// TodoNoteDto represents a note attached to a todo item
+// @Description A note with its todo item
+type TodoNoteDto struct {
+	// Unique identifier
+	// @example 1
+	ID uint `json:"id" example:"1"`
+	// ID of the todo item the note belongs to
+	// @example 1
+	TodoItemID uint `json:"todoItemId" example:"1"`
+	// Content of the note
+	// @example Don't forget to check expiration dates
+	Note string `json:"note" example:"Don't forget to check expiration dates"`
+	// When the note was created
+	// @example 2025-06-10T10:30:00Z
+	CreatedAt time.Time `json:"createdAt" example:"2025-06-10T10:30:00Z"`
+	// When the note was last updated
+	// @example 2025-06-10T10:30:00Z
+	UpdatedAt time.Time `json:"updatedAt" example:"2025-06-10T10:30:00Z"`
+}
+
+// ListTodoItemsDto holds the query parameters of GET /todos
+type ListTodoItemsDto struct {
+	// Items per page, the configured default when zero
+	Limit int `json:"-"`
+	// nextCursor of the previous page, empty for the first page
+	Cursor string `json:"-"`
+	// Sort field, optionally prefixed with "-" for descending order
+	Sort string `json:"-"`
+	// Only return completed or open items when set
+	IsCompleted *bool `json:"-"`
+	// Only return items created or updated strictly inside these bounds, when set
+	CreatedAfter  *time.Time `json:"-"`
+	CreatedBefore *time.Time `json:"-"`
+	UpdatedAfter  *time.Time `json:"-"`
+	UpdatedBefore *time.Time `json:"-"`
+	// Case-insensitive text the title must contain
+	Title string `json:"-"`
+	// Count the items matching the filters across all pages
+	IncludeTotal bool `json:"-"`
+	// Only return the items of a smart view: overdue, today, next7days or nodate
+	View string `json:"-"`
+}
+
+// TodoItemListDto is one page of todo items
+// @Description Page of todo items with the cursor of the next page
+type TodoItemListDto struct {
+	// Todo items on this page
+	Items []TodoItemDto `json:"items"`
+	// Pass as cursor to get the next page, null on the last page
+	// @example eyJzIjoiaWQiLCJ2IjoiMjAiLCJpZCI6MjB9
+	NextCursor *string `json:"nextCursor" example:"eyJzIjoiaWQiLCJ2IjoiMjAiLCJpZCI6MjB9"`
+	// Whether more items follow this page
+	// @example true
+	HasMore bool `json:"hasMore" example:"true"`
+	// Items matching the filters across all pages, only set when includeTotal=true
+	// @example 42
+	Total *int64 `json:"total,omitempty" example:"42"`
 }
 
 // GetTodoItemDto represents the data needed to retrieve a specific todo item
@@ -33,19 +106,30 @@ type GetTodoItemDto struct {
 	ID uint `json:"id" example:"1"`
 }
 
+// TodoScheduleDto holds when a todo item is due and when to remind the user of it
+type TodoScheduleDto struct {
+	// When the todo item is due, with its UTC offset. For all-day items only the date counts.
+	// @example 2025-06-12T19:00:00+02:00
+	DueAt *time.Time `json:"dueAt" example:"2025-06-12T19:00:00+02:00"`
+	// Whether the todo item is due on its date as a whole rather than at a time, needs dueAt
+	// @example false
+	AllDay bool `json:"allDay" example:"false"`
+	// Minutes before the due time to remind the user (up to 5, 0-40320). All-day items count from the start of their day in the user's time zone.
+	// @example 15,60
+	ReminderOffsets []int `json:"reminderOffsets" example:"15,60"`
+}
+
 // CreateTodoItemDto represents the data needed to create a new todo item
 // @Description Data for creating a new todo item
 type CreateTodoItemDto struct {
 	// Title of the todo item (3-255 characters)
 	// @example Buy groceries
-	Title string `json:"title" validate:"required;min=3;max=255" example:"Buy groceries"`
+	Title string `json:"title" binding:"required,min=3,max=255" example:"Buy groceries"`
 	// Optional description with details (max 255 characters)
 	// @example Milk, eggs, bread, and cheese
-	Description string `json:"description" validate:"max=255" example:"Milk, eggs, bread, and cheese"`
-
-	// User ID associated with the todo item
-	// @example 1
-	UserID uint `json:"userId" example:"1"`
+	Description string `json:"description" binding:"max=255" example:"Milk, eggs, bread, and cheese"`
+	// Optional due date and reminders
+	TodoScheduleDto
 }
 
 // UpdateTodoItemDto represents the data needed to update an existing todo item
@@ -64,9 +148,71 @@ type UpdateTodoItemDto struct {
 	// @example true
 	IsCompleted bool `json:"isCompleted" example:"true"`
 
-	// User ID associated with the todo item
-	// @example 1
-	UserID uint `json:"userId" example:"1"`
+	// New due date and reminders, set by PUT /todos/{id}. The legacy route leaves them unchanged.
+	Schedule *TodoScheduleDto `json:"-"`
+}
+
+// ReplaceTodoItemDto represents the full state of a todo item sent with PUT /todos/{id}
+// @Description Every field of a todo item, fields that are left out are reset
+type ReplaceTodoItemDto struct {
+	// Title (3-255 characters)
+	// @example Buy groceries and household items
+	Title string `json:"title" binding:"required,min=3,max=255" example:"Buy groceries and household items"`
+	// Description (max 255 characters)
+	// @example Milk, eggs, bread, cheese, and cleaning supplies
+	Description string `json:"description" binding:"max=255" example:"Milk, eggs, bread, cheese, and cleaning supplies"`
+	// Completion status
+	// @example true
+	IsCompleted bool `json:"isCompleted" example:"true"`
+	// Due date and reminders
+	TodoScheduleDto
+}
+
+// PatchTodoItemDto represents a partial update sent with PATCH /todos/{id}
+// @Description Fields of a todo item to change, fields that are left out keep their value
+type PatchTodoItemDto struct {
+	// New title (3-255 characters)
+	// @example Buy groceries and household items
+	Title *string `json:"title" binding:"min=3,max=255" example:"Buy groceries and household items"`
+	// New description (max 255 characters)
+	// @example Milk, eggs, bread, cheese, and cleaning supplies
+	Description *string `json:"description" binding:"max=255" example:"Milk, eggs, bread, cheese, and cleaning supplies"`
+	// New completion status
+	// @example true
+	IsCompleted *bool `json:"isCompleted" example:"true"`
+	// New due time, null removes the due date together with its reminders
+	// @example 2025-06-12T19:00:00+02:00
+	DueAt NullableTime `json:"dueAt" swaggertype:"string" format:"date-time" example:"2025-06-12T19:00:00+02:00"`
+	// Whether the todo item is due all day
+	// @example true
+	AllDay *bool `json:"allDay" example:"true"`
+	// New reminders in minutes before the due time, [] removes them
+	// @example 30
+	ReminderOffsets []int `json:"reminderOffsets" example:"30"`
+
+	// ID of the todo item, set by the handler from the path
+	ID uint `json:"-"`
+}
+
+// NullableTime is a time in a PATCH body that tells a field that was left out, where Set is
+// false, from an explicit null, where Set is true and Time is nil
+type NullableTime struct {
+	Set  bool
+	Time *time.Time
+}
+
+// UnmarshalJSON implements json.Unmarshaler, it is only called when the field is present
+func (n *NullableTime) UnmarshalJSON(data []byte) error {
+	n.Set = true
+	return json.Unmarshal(data, &n.Time)
+}
+
+// AddTodoNoteDto represents a note posted to /todos/{id}/notes
+// @Description Content of a new note, the todo item is named in the path
+type AddTodoNoteDto struct {
+	// Content of the note
+	// @example Don't forget to check expiration dates
+	Note string `json:"note" binding:"required" example:"Don't forget to check expiration dates"`
 }
 
 // CreateTodoNoteDto represents the data needed to create a note for a todo item
@@ -88,3 +234,67 @@ type DeleteTodoItemDto struct {
 	// @example 1
 	ID uint `json:"id" example:"1"`
 }
+
+// SearchTodoItemsDto holds the query parameters of GET /todos/search
+type SearchTodoItemsDto struct {
+	// Search text: words, "quoted phrases" and prefixes ending in *
+	Query string `json:"-"`
+	// 1-based page number
+	Page int `json:"-"`
+	// Results per page
+	PageSize int `json:"-"`
+}
+
+// TodoSearchResultDto is a todo item found by a search, with the matching text highlighted
+// @Description Todo item matching a search with highlighted snippets
+type TodoSearchResultDto struct {
+	// Unique identifier
+	// @example 1
+	ID uint `json:"id" example:"1"`
+	// Title of the todo item
+	// @example Buy groceries
+	Title string `json:"title" example:"Buy groceries"`
+	// Description of the todo item
+	// @example Milk, eggs, bread, and cheese
+	Description string `json:"description" example:"Milk, eggs, bread, and cheese"`
+	// Whether the todo item is completed
+	// @example false
+	IsCompleted bool `json:"isCompleted" example:"false"`
+	// When the todo item was created
+	// @example 2025-06-10T10:30:00Z
+	CreatedAt time.Time `json:"createdAt" example:"2025-06-10T10:30:00Z"`
+	// When the todo item was last updated
+	// @example 2025-06-10T10:30:00Z
+	UpdatedAt time.Time `json:"updatedAt" example:"2025-06-10T10:30:00Z"`
+	// Relevance, higher is better
+	// @example 0.6079271
+	Rank float64 `json:"rank" example:"0.6079271"`
+	// Whole title with the matching words wrapped in <mark> tags. The rest of the text is not HTML escaped.
+	// @example Buy <mark>groceries</mark>
+	TitleHighlight string `json:"titleHighlight" example:"Buy <mark>groceries</mark>"`
+	// Excerpt of the description around the matching words, when the description matches
+	// @example <mark>Milk</mark>, eggs, bread, and cheese
+	DescriptionSnippet string `json:"descriptionSnippet,omitempty" example:"<mark>Milk</mark>, eggs, bread, and cheese"`
+	// Best matching note, when a note matches
+	// @example 3
+	NoteID *uint `json:"noteId,omitempty" example:"3"`
+	// Excerpt of that note around the matching words
+	// @example Don't forget to check expiration dates on the <mark>milk</mark>
+	NoteSnippet string `json:"noteSnippet,omitempty" example:"Don't forget to check expiration dates on the <mark>milk</mark>"`
+}
+
+// TodoSearchResultsDto is one page of search results
+// @Description Page of todo items matching a search, most relevant first, with the total number of matches
+type TodoSearchResultsDto struct {
+	// Results on this page
+	Results []TodoSearchResultDto `json:"results"`
+	// Current page number
+	// @example 1
+	Page int `json:"page" example:"1"`
+	// Results per page
+	// @example 20
+	PageSize int `json:"pageSize" example:"20"`
+	// Todo items matching across all pages
+	// @example 3
+	Total int64 `json:"total" example:"3"`
+}
@@ -0,0 +1,54 @@
+package dtos
+
+// RefreshTokenDto represents the data needed to rotate a refresh token
+// @Description Refresh token issued by login or a previous refresh
+type RefreshTokenDto struct {
+	// Refresh token to exchange for a new token pair. In cookie session mode it is read from the cookie instead.
+	// @example 3q2-7wF0bX9mY1c8Qk4Rz6Vt5Nn2Lp0Hs7Jd1Ga9Ke4
+	RefreshToken string `json:"refreshToken" binding:"required" example:"3q2-7wF0bX9mY1c8Qk4Rz6Vt5Nn2Lp0Hs7Jd1Ga9Ke4"`
+
+	// Client IP address and user agent, set by the handler
+	IPAddress string `json:"-"`
+	UserAgent string `json:"-"`
+	// Set by the handler when the refresh token came from the cookie, the X-CSRF-Token header
+	// must then match the CSRF token of the refresh token's session
+	FromCookie bool   `json:"-"`
+	CSRFToken  string `json:"-"`
+}
+
+// AuthTokensDto is returned whenever the API issues a new token pair
+// @Description Authenticated user together with an access token and a refresh token
+type AuthTokensDto struct {
+	// User ID
+	// @example 1
+	ID uint `json:"id" example:"1"`
+	// User's email address
+	// @example john.doe@example.com
+	Email string `json:"email" example:"john.doe@example.com"`
+	// User's full name
+	// @example John Doe
+	Name string `json:"name" example:"John Doe"`
+	// Short-lived JWT access token, left out in cookie session mode
+	// @example eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9...
+	Token string `json:"token,omitempty" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	// Lifetime of the access token in seconds
+	// @example 900
+	ExpiresIn int64 `json:"expiresIn" example:"900"`
+	// Long-lived opaque refresh token, single use, left out in cookie session mode
+	// @example 3q2-7wF0bX9mY1c8Qk4Rz6Vt5Nn2Lp0Hs7Jd1Ga9Ke4
+	RefreshToken string `json:"refreshToken,omitempty" example:"3q2-7wF0bX9mY1c8Qk4Rz6Vt5Nn2Lp0Hs7Jd1Ga9Ke4"`
+	// Token to send in the X-CSRF-Token header, only in cookie session mode
+	// @example Jq0x3Hh6c2n1Xy8oQ4bR7tV5mW9zK2aL0sD6fG1jE3U
+	CSRFToken string `json:"csrfToken,omitempty" example:"Jq0x3Hh6c2n1Xy8oQ4bR7tV5mW9zK2aL0sD6fG1jE3U"`
+
+	// Login session the tokens belong to, set by the repository for the handler
+	SessionID uint `json:"-"`
+}
+
+// LogoutDto represents the optional data sent when logging out
+// @Description Refresh token to revoke together with the current access token
+type LogoutDto struct {
+	// Refresh token issued with the current access token (optional, read from the cookie in cookie session mode)
+	// @example 3q2-7wF0bX9mY1c8Qk4Rz6Vt5Nn2Lp0Hs7Jd1Ga9Ke4
+	RefreshToken string `json:"refreshToken" example:"3q2-7wF0bX9mY1c8Qk4Rz6Vt5Nn2Lp0Hs7Jd1Ga9Ke4"`
+}
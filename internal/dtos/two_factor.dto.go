@@ -0,0 +1,72 @@
+package dtos
+
+// TwoFactorEnrollmentDto is returned when enrollment starts
+// @Description TOTP secret to add to an authenticator app
+type TwoFactorEnrollmentDto struct {
+	// Base32 encoded secret for manual entry
+	// @example JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP
+	Secret string `json:"secret" example:"JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"`
+	// otpauth:// URI, usually rendered as a QR code
+	// @example otpauth://totp/todo-api:john.doe%40example.com?algorithm=SHA1&digits=6&issuer=todo-api&period=30&secret=JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP
+	OTPAuthURI string `json:"otpauthUri" example:"otpauth://totp/todo-api:john.doe%40example.com?algorithm=SHA1&digits=6&issuer=todo-api&period=30&secret=JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"`
+}
+
+// TwoFactorCodeDto carries a code from the authenticator app
+// @Description Six digit TOTP code
+type TwoFactorCodeDto struct {
+	// Current code from the authenticator app
+	// @example 123456
+	Code string `json:"code" binding:"required" example:"123456"`
+
+	// ID of the current user and client IP, set by the handler
+	UserID    uint   `json:"-"`
+	IPAddress string `json:"-"`
+}
+
+// DisableTwoFactorDto represents the data needed to turn two-factor authentication off
+// @Description Password and a current TOTP or recovery code
+type DisableTwoFactorDto struct {
+	// User's password
+	// @example SecureP@ssw0rd
+	Password string `json:"password" binding:"required" example:"SecureP@ssw0rd"`
+	// TOTP code or unused recovery code
+	// @example 123456
+	Code string `json:"code" binding:"required" example:"123456"`
+
+	// ID of the current user, set by the handler
+	UserID uint `json:"-"`
+}
+
+// RecoveryCodesDto lists freshly generated recovery codes, they are only shown once
+// @Description One-time recovery codes
+type RecoveryCodesDto struct {
+	// Each code can be used once instead of a TOTP code
+	// @example ["k7m2p-x9q4r","b3n8t-w5c6y"]
+	RecoveryCodes []string `json:"recoveryCodes" example:"k7m2p-x9q4r,b3n8t-w5c6y"`
+}
+
+// TwoFactorChallengeDto is returned by login instead of tokens when two-factor authentication is on
+// @Description Challenge to complete with /auth/login/2fa
+type TwoFactorChallengeDto struct {
+	// Always true, lets clients tell this payload apart from a token pair
+	// @example true
+	TwoFactorRequired bool `json:"twoFactorRequired" example:"true"`
+	// Short-lived token identifying the half-finished login
+	// @example eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9...
+	ChallengeToken string `json:"challengeToken" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+}
+
+// TwoFactorLoginDto represents the second step of a login with two-factor authentication
+// @Description Challenge token from login and a TOTP or recovery code
+type TwoFactorLoginDto struct {
+	// Challenge token returned by /auth/login
+	// @example eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9...
+	ChallengeToken string `json:"challengeToken" binding:"required" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	// TOTP code or unused recovery code
+	// @example 123456
+	Code string `json:"code" binding:"required" example:"123456"`
+
+	// Client IP address and user agent, set by the handler
+	IPAddress string `json:"-"`
+	UserAgent string `json:"-"`
+}
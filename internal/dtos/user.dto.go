@@ -1,7 +1,83 @@
 package dtos
 
+import "time"
+
+// UserDto is the profile of the current user
+// @Description Account details of the logged in user
 type UserDto struct {
-	ID    uint   `json:"id"`
-	Email string `json:"email"`
-	Name  string `json:"name"`
+	ID    uint   `json:"id" example:"1"`
+	Email string `json:"email" example:"john.doe@example.com"`
+	Name  string `json:"name" example:"John Doe"`
+	// Short text about the user
+	Bio string `json:"bio" example:"Getting things done"`
+	// URL of the profile picture
+	AvatarURL       string     `json:"avatarUrl" example:"https://example.com/avatar.png"`
+	EmailVerifiedAt *time.Time `json:"emailVerifiedAt"`
+	// Address waiting for confirmation after a change of email, empty when there is none
+	PendingEmail     string   `json:"pendingEmail,omitempty" example:"john.new@example.com"`
+	TwoFactorEnabled bool     `json:"twoFactorEnabled" example:"false"`
+	Roles            []string `json:"roles" example:"user"`
+	// IANA time zone used for the todo views
+	Timezone string `json:"timezone" example:"Europe/Berlin"`
+}
+
+// UpdateProfileDto represents the profile fields a user can change, omitted fields are left as they are
+// @Description New values for the profile fields to change
+type UpdateProfileDto struct {
+	// Display name (1-100 characters)
+	// @example John Doe
+	Name *string `json:"name,omitempty" example:"John Doe"`
+	// Short text about the user (max 500 characters)
+	// @example Getting things done
+	Bio *string `json:"bio,omitempty" example:"Getting things done"`
+	// http(s) URL of the profile picture, empty to remove it
+	// @example https://example.com/avatar.png
+	AvatarURL *string `json:"avatarUrl,omitempty" example:"https://example.com/avatar.png"`
+	// IANA time zone name, "today" of the todo views starts at midnight in it
+	// @example Europe/Berlin
+	Timezone *string `json:"timezone,omitempty" example:"Europe/Berlin"`
+
+	// ID of the current user, set by the handler
+	UserID uint `json:"-"`
+}
+
+// ChangePasswordDto represents the data needed to change the password of the current user
+// @Description Current password together with the new one
+type ChangePasswordDto struct {
+	// Current password
+	// @example SecureP@ssw0rd
+	CurrentPassword string `json:"currentPassword" binding:"required" example:"SecureP@ssw0rd"`
+	// New password (min 8 characters)
+	// @example N3wSecureP@ssw0rd
+	NewPassword string `json:"newPassword" binding:"required" example:"N3wSecureP@ssw0rd"`
+
+	// ID of the current user, set by the handler
+	UserID uint `json:"-"`
+	// Session of the request, it stays logged in while the others are revoked
+	SessionID uint `json:"-"`
+}
+
+// ChangeEmailDto represents the data needed to start a change of email address
+// @Description New email address and the current password
+type ChangeEmailDto struct {
+	// New email address, a confirmation link is sent to it
+	// @example john.new@example.com
+	Email string `json:"email" binding:"required" example:"john.new@example.com"`
+	// Current password
+	// @example SecureP@ssw0rd
+	Password string `json:"password" binding:"required" example:"SecureP@ssw0rd"`
+
+	// ID of the current user, set by the handler
+	UserID uint `json:"-"`
+}
+
+// DeleteAccountDto represents the confirmation needed to delete the current user's account
+// @Description Current password, not needed for accounts without one
+type DeleteAccountDto struct {
+	// Current password
+	// @example SecureP@ssw0rd
+	Password string `json:"password" example:"SecureP@ssw0rd"`
+
+	// ID of the current user, set by the handler
+	UserID uint `json:"-"`
 }
@@ -0,0 +1,154 @@
+// Package jobs runs work that has to happen after a response was sent, such as looking up an
+// account and emailing it a link, on a fixed number of workers fed by a bounded queue. A burst
+// of requests can neither start an unbounded number of goroutines nor hold the process open:
+// when the queue is full new jobs are dropped, and on shutdown the queue is drained until the
+// deadline and the jobs still running are cancelled.
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+	"todo-api/config"
+
+	"go.uber.org/zap"
+)
+
+// Job is one piece of background work. Its context is cancelled when the job runs longer than
+// the job timeout or the queue is shut down before the job finished.
+type Job func(ctx context.Context) error
+
+// ErrQueueFull is returned by Enqueue when every worker is busy and the queue has no room left
+var ErrQueueFull = errors.New("background job queue is full")
+
+// ErrQueueClosed is returned by Enqueue once Shutdown was called
+var ErrQueueClosed = errors.New("background job queue is closed")
+
+type namedJob struct {
+	name string
+	run  Job
+}
+
+// Queue hands jobs to its workers in the order they were enqueued
+type Queue struct {
+	logger  *zap.Logger
+	timeout time.Duration
+	jobs    chan namedJob
+	// ctx is the parent of every job context, cancelled when the shutdown deadline passes
+	ctx     context.Context
+	cancel  context.CancelFunc
+	workers sync.WaitGroup
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+var (
+	defaultQueue     *Queue
+	defaultQueueOnce sync.Once
+)
+
+// Default returns the queue of the process, built from the configuration on first use
+func Default() *Queue {
+	defaultQueueOnce.Do(func() {
+		jobsConfig := config.GetConfig().BackgroundJobs
+		defaultQueue = NewQueue(jobsConfig.Workers, jobsConfig.QueueSize, jobsConfig.Timeout, zap.L())
+	})
+	return defaultQueue
+}
+
+// NewQueue starts the workers of a queue holding up to size jobs that are waiting for a worker
+func NewQueue(workers int, size int, timeout time.Duration, logger *zap.Logger) *Queue {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	q := &Queue{
+		logger:  logger,
+		timeout: timeout,
+		jobs:    make(chan namedJob, size),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+
+	for i := 0; i < workers; i++ {
+		q.workers.Add(1)
+		go q.work()
+	}
+
+	return q
+}
+
+// Enqueue schedules the job without waiting for it. The name identifies the job in the logs.
+func (q *Queue) Enqueue(name string, job Job) error {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if q.closed {
+		q.logger.Warn("Background job dropped, the queue is closed", zap.String("job", name))
+		return ErrQueueClosed
+	}
+
+	select {
+	case q.jobs <- namedJob{name: name, run: job}:
+		return nil
+	default:
+		q.logger.Warn("Background job dropped, the queue is full", zap.String("job", name))
+		return ErrQueueFull
+	}
+}
+
+// Shutdown stops accepting jobs and waits for the queued ones to finish. When the context ends
+// first, the running jobs are cancelled and the ones still queued are dropped.
+func (q *Queue) Shutdown(ctx context.Context) error {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.jobs)
+	}
+	q.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		q.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		q.cancel()
+		return nil
+	case <-ctx.Done():
+		q.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+func (q *Queue) work() {
+	defer q.workers.Done()
+
+	for job := range q.jobs {
+		// Once the deadline passed the remaining jobs are only drained, not run
+		if q.ctx.Err() != nil {
+			q.logger.Warn("Background job dropped on shutdown", zap.String("job", job.name))
+			continue
+		}
+		q.run(job)
+	}
+}
+
+func (q *Queue) run(job namedJob) {
+	ctx, cancel := context.WithTimeout(q.ctx, q.timeout)
+	defer cancel()
+
+	// A panicking job must not take the worker, or the process, down with it
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			q.logger.Error("Background job panicked", zap.String("job", job.name), zap.Any("panic", recovered))
+		}
+	}()
+
+	if err := job.run(ctx); err != nil {
+		q.logger.Error("Background job failed", zap.String("job", job.name), zap.Error(err))
+	}
+}
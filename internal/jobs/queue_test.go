@@ -0,0 +1,120 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestQueueRunsJobs(t *testing.T) {
+	queue := NewQueue(2, 10, time.Second, zap.NewNop())
+
+	var ran atomic.Int32
+	for i := 0; i < 5; i++ {
+		if err := queue.Enqueue("count", func(ctx context.Context) error {
+			ran.Add(1)
+			return nil
+		}); err != nil {
+			t.Fatalf("Enqueue failed: %v", err)
+		}
+	}
+	// Failing and panicking jobs do not stop the workers
+	queue.Enqueue("fail", func(ctx context.Context) error { return errors.New("mail server down") })
+	queue.Enqueue("panic", func(ctx context.Context) error { panic("bug") })
+	queue.Enqueue("count", func(ctx context.Context) error {
+		ran.Add(1)
+		return nil
+	})
+
+	if err := queue.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	if got := ran.Load(); got != 6 {
+		t.Errorf("%d jobs ran, want 6", got)
+	}
+
+	if err := queue.Enqueue("late", func(ctx context.Context) error { return nil }); !errors.Is(err, ErrQueueClosed) {
+		t.Errorf("Enqueue after Shutdown = %v, want ErrQueueClosed", err)
+	}
+}
+
+func TestQueueDropsJobsWhenFull(t *testing.T) {
+	queue := NewQueue(1, 1, time.Second, zap.NewNop())
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	queue.Enqueue("block", func(ctx context.Context) error {
+		close(started)
+		<-release
+		return nil
+	})
+	<-started
+
+	if err := queue.Enqueue("waiting", func(ctx context.Context) error { return nil }); err != nil {
+		t.Fatalf("Enqueue into a free slot failed: %v", err)
+	}
+	if err := queue.Enqueue("dropped", func(ctx context.Context) error { return nil }); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Enqueue into a full queue = %v, want ErrQueueFull", err)
+	}
+
+	close(release)
+	queue.Shutdown(context.Background())
+}
+
+func TestQueueShutdownDeadlineCancelsJobs(t *testing.T) {
+	queue := NewQueue(1, 10, time.Minute, zap.NewNop())
+	started := make(chan struct{})
+
+	var cancelled, skipped atomic.Bool
+	queue.Enqueue("slow", func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		cancelled.Store(true)
+		return ctx.Err()
+	})
+	queue.Enqueue("queued", func(ctx context.Context) error {
+		skipped.Store(false)
+		return nil
+	})
+	skipped.Store(true)
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if err := queue.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown = %v, want the deadline error", err)
+	}
+	if !cancelled.Load() {
+		t.Error("running job was not cancelled at the deadline")
+	}
+	if !skipped.Load() {
+		t.Error("queued job ran after the deadline")
+	}
+}
+
+func TestQueueJobTimeout(t *testing.T) {
+	queue := NewQueue(1, 1, 10*time.Millisecond, zap.NewNop())
+
+	done := make(chan error, 1)
+	queue.Enqueue("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		done <- ctx.Err()
+		return ctx.Err()
+	})
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("job context ended with %v, want the job timeout", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("job was not cancelled after its timeout")
+	}
+
+	queue.Shutdown(context.Background())
+}
@@ -0,0 +1,48 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// LogMailer is meant for local development and tests. It logs every message and,
+// when an output directory is configured, also writes it there as an .eml file.
+type LogMailer struct {
+	from      string
+	outputDir string
+	logger    *zap.Logger
+	counter   atomic.Uint64
+}
+
+func NewLogMailer(from string, outputDir string, logger *zap.Logger) *LogMailer {
+	return &LogMailer{
+		from:      from,
+		outputDir: outputDir,
+		logger:    logger,
+	}
+}
+
+func (m *LogMailer) Send(ctx context.Context, message Message) error {
+	m.logger.Info("Email sent",
+		zap.String("to", message.To),
+		zap.String("subject", message.Subject),
+		zap.String("body", message.Body),
+	)
+
+	if m.outputDir == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(m.outputDir, 0o755); err != nil {
+		return err
+	}
+
+	fileName := fmt.Sprintf("%d-%d.eml", time.Now().UnixNano(), m.counter.Add(1))
+	return os.WriteFile(filepath.Join(m.outputDir, fileName), buildMessage(m.from, message), 0o644)
+}
@@ -0,0 +1,34 @@
+package mailer
+
+import (
+	"context"
+	"todo-api/config"
+
+	"go.uber.org/zap"
+)
+
+// Message is a plain text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends transactional emails such as password reset links
+type Mailer interface {
+	Send(ctx context.Context, message Message) error
+}
+
+// New returns the Mailer selected by MAIL_DRIVER. Unknown drivers fall back to the log mailer
+// so a misconfigured development environment never sends real email.
+func New(mailConfig config.MailConfig, logger *zap.Logger) Mailer {
+	switch mailConfig.Driver {
+	case "smtp":
+		return NewSMTPMailer(mailConfig)
+	case "log", "file", "":
+		return NewLogMailer(mailConfig.From, mailConfig.OutputDir, logger)
+	default:
+		logger.Warn("Unknown mail driver, falling back to log mailer", zap.String("driver", mailConfig.Driver))
+		return NewLogMailer(mailConfig.From, mailConfig.OutputDir, logger)
+	}
+}
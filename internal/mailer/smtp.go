@@ -0,0 +1,65 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+	"todo-api/config"
+)
+
+// SMTPMailer delivers messages through an SMTP relay. STARTTLS is used when the server offers it.
+type SMTPMailer struct {
+	host     string
+	port     string
+	username string
+	password string
+	from     string
+}
+
+func NewSMTPMailer(mailConfig config.MailConfig) *SMTPMailer {
+	return &SMTPMailer{
+		host:     mailConfig.SMTPHost,
+		port:     mailConfig.SMTPPort,
+		username: mailConfig.SMTPUsername,
+		password: mailConfig.SMTPPassword,
+		from:     mailConfig.From,
+	}
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, message Message) error {
+	var auth smtp.Auth
+	if m.username != "" {
+		auth = smtp.PlainAuth("", m.username, m.password, m.host)
+	}
+
+	// smtp.SendMail has no context support, so honour cancellation before dialing
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	addr := net.JoinHostPort(m.host, m.port)
+	if err := smtp.SendMail(addr, auth, m.from, []string{message.To}, buildMessage(m.from, message)); err != nil {
+		return fmt.Errorf("failed to send email to %s: %w", message.To, err)
+	}
+
+	return nil
+}
+
+// buildMessage renders the RFC 5322 representation of a plain text message
+func buildMessage(from string, message Message) []byte {
+	var builder strings.Builder
+
+	builder.WriteString("From: " + from + "\r\n")
+	builder.WriteString("To: " + message.To + "\r\n")
+	builder.WriteString("Subject: " + message.Subject + "\r\n")
+	builder.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	builder.WriteString("MIME-Version: 1.0\r\n")
+	builder.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	builder.WriteString("\r\n")
+	builder.WriteString(strings.ReplaceAll(message.Body, "\n", "\r\n"))
+
+	return []byte(builder.String())
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// ExternalIdentity links a user to an account at an OpenID Connect provider.
+// The provider's subject is stable while the email may change, so later logins match on it.
+type ExternalIdentity struct {
+	ID          uint       `gorm:"primaryKey;column:id" json:"id"`
+	UserID      uint       `gorm:"column:userId;not null;index" json:"userId"`
+	Issuer      string     `gorm:"column:issuer;size:255;not null;uniqueIndex:idx_external_identity_subject" json:"issuer"`
+	Subject     string     `gorm:"column:subject;size:255;not null;uniqueIndex:idx_external_identity_subject" json:"subject"`
+	Email       string     `gorm:"column:email;size:255" json:"email"`
+	LastLoginAt *time.Time `gorm:"column:lastLoginAt" json:"lastLoginAt"`
+	CreatedAt   time.Time  `gorm:"column:createdAt" json:"createdAt"`
+	User        *User      `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+func (ExternalIdentity) TableName() string {
+	return "ExternalIdentities"
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// InviteCode lets people register while REGISTRATION_MODE is "invite". Only the hash of the
+// code is stored, Prefix keeps a recognisable part of it so administrators can tell codes apart.
+type InviteCode struct {
+	ID        uint       `gorm:"primaryKey;column:id" json:"id"`
+	Prefix    string     `gorm:"column:prefix;size:32;not null" json:"prefix"`
+	CodeHash  string     `gorm:"column:codeHash;size:64;not null;uniqueIndex" json:"-"`
+	Note      string     `gorm:"column:note;size:255;not null;default:''" json:"note"`
+	MaxUses   int        `gorm:"column:maxUses;not null;default:1" json:"maxUses"`
+	Uses      int        `gorm:"column:uses;not null;default:0" json:"uses"`
+	ExpiresAt *time.Time `gorm:"column:expiresAt" json:"expiresAt"`
+	RevokedAt *time.Time `gorm:"column:revokedAt" json:"revokedAt"`
+	// Administrator who created the code, kept when their account is deleted
+	CreatedByID *uint     `gorm:"column:createdById" json:"createdById"`
+	CreatedAt   time.Time `gorm:"column:createdAt" json:"createdAt"`
+	CreatedBy   *User     `gorm:"foreignKey:CreatedByID;references:ID;constraint:OnDelete:SET NULL" json:"-"`
+}
+
+func (InviteCode) TableName() string {
+	return "InviteCodes"
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// LoginAttempt tracks failed logins for one throttling key, such as an account email or a client IP
+type LoginAttempt struct {
+	ID            uint       `gorm:"primaryKey;column:id" json:"id"`
+	Key           string     `gorm:"column:key;size:320;not null;uniqueIndex" json:"key"`
+	Failures      int        `gorm:"column:failures;not null;default:0" json:"failures"`
+	FirstFailedAt time.Time  `gorm:"column:firstFailedAt;not null" json:"firstFailedAt"`
+	BlockedUntil  *time.Time `gorm:"column:blockedUntil" json:"blockedUntil"`
+	UpdatedAt     time.Time  `gorm:"column:updatedAt;index" json:"updatedAt"`
+}
+
+func (LoginAttempt) TableName() string {
+	return "LoginAttempts"
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// MagicLink records an emailed login link so it can be used once and only from the
+// browser that asked for it
+type MagicLink struct {
+	ID     uint `gorm:"primaryKey;column:id" json:"id"`
+	UserID uint `gorm:"column:userId;not null;index" json:"userId"`
+	// SHA-256 of the signed link token
+	TokenHash string `gorm:"column:tokenHash;size:64;not null;uniqueIndex" json:"-"`
+	// SHA-256 of the nonce kept in a cookie of the requesting browser
+	BrowserHash string     `gorm:"column:browserHash;size:64;not null" json:"-"`
+	ExpiresAt   time.Time  `gorm:"column:expiresAt;not null;index" json:"expiresAt"`
+	UsedAt      *time.Time `gorm:"column:usedAt" json:"usedAt"`
+	CreatedAt   time.Time  `gorm:"column:createdAt" json:"createdAt"`
+	User        *User      `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+func (MagicLink) TableName() string {
+	return "MagicLinks"
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// OAuthAuthorizationCode is handed to an app once the user consents and exchanged for tokens.
+// Only the hash of the code is stored. Codes are single-use, UsedAt is kept so a second
+// exchange can be recognised and the tokens of the first one revoked.
+type OAuthAuthorizationCode struct {
+	ID       uint   `gorm:"primaryKey;column:id" json:"id"`
+	CodeHash string `gorm:"column:codeHash;size:64;not null;uniqueIndex" json:"-"`
+	ClientID uint   `gorm:"column:clientId;not null;index" json:"clientId"`
+	UserID   uint   `gorm:"column:userId;not null;index" json:"userId"`
+	// As sent in the authorization request, empty when the app left it out
+	RedirectURI string `gorm:"column:redirectUri;size:500;not null;default:''" json:"redirectUri"`
+	Scopes      string `gorm:"column:scopes;size:500;not null" json:"scopes"`
+	// S256 PKCE challenge, the token request must present the matching verifier
+	CodeChallenge string       `gorm:"column:codeChallenge;size:128;not null" json:"-"`
+	ExpiresAt     time.Time    `gorm:"column:expiresAt;not null;index" json:"expiresAt"`
+	UsedAt        *time.Time   `gorm:"column:usedAt" json:"usedAt"`
+	CreatedAt     time.Time    `gorm:"column:createdAt" json:"createdAt"`
+	Client        *OAuthClient `gorm:"foreignKey:ClientID;references:ID;constraint:OnDelete:CASCADE" json:"-"`
+	User          *User        `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+func (OAuthAuthorizationCode) TableName() string {
+	return "OAuthAuthorizationCodes"
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// OAuthScopes are the scopes third-party apps can ask for, with the text the consent screen
+// shows for each. They are permission names, so the todo routes enforce them like any other.
+var OAuthScopes = map[string]string{
+	PermissionTodosRead:  "Read your todo items and notes",
+	PermissionTodosWrite: "Create, update and delete your todo items and notes",
+}
+
+// OAuthClient is a third-party app registered with the OAuth2 authorization server. Public
+// clients such as mobile apps have no secret and rely on PKCE alone.
+type OAuthClient struct {
+	ID       uint   `gorm:"primaryKey;column:id" json:"id"`
+	ClientID string `gorm:"column:clientId;size:64;not null;uniqueIndex" json:"clientId"`
+	// Empty for public clients
+	SecretHash   string `gorm:"column:secretHash;size:64;not null;default:''" json:"-"`
+	Name         string `gorm:"column:name;size:100;not null" json:"name"`
+	RedirectURIs string `gorm:"column:redirectUris;size:2000;not null" json:"-"` // Space separated, matched exactly
+	Scopes       string `gorm:"column:scopes;size:500;not null" json:"-"`        // Space separated, the most the app can ask for
+	// Administrator who registered the app, kept when their account is deleted
+	CreatedByID *uint     `gorm:"column:createdById" json:"createdById"`
+	CreatedAt   time.Time `gorm:"column:createdAt" json:"createdAt"`
+	CreatedBy   *User     `gorm:"foreignKey:CreatedByID;references:ID;constraint:OnDelete:SET NULL" json:"-"`
+}
+
+func (OAuthClient) TableName() string {
+	return "OAuthClients"
+}
+
+// Confidential reports whether the client authenticates with a secret
+func (c OAuthClient) Confidential() bool {
+	return c.SecretHash != ""
+}
+
+// RedirectURIList returns the registered redirect URIs as a slice
+func (c OAuthClient) RedirectURIList() []string {
+	return strings.Fields(c.RedirectURIs)
+}
+
+// ScopeList returns the scopes the client may ask for as a slice
+func (c OAuthClient) ScopeList() []string {
+	return strings.Fields(c.Scopes)
+}
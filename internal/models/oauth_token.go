@@ -0,0 +1,35 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// OAuthToken is an access and refresh token pair issued to an app. Only their hashes are
+// stored. Refreshing revokes the pair and issues a new one with the same GrantID, the
+// authorization code the chain started from, so a stolen refresh token that is used twice
+// ends the whole chain.
+type OAuthToken struct {
+	ID               uint         `gorm:"primaryKey;column:id" json:"id"`
+	ClientID         uint         `gorm:"column:clientId;not null;index" json:"clientId"`
+	UserID           uint         `gorm:"column:userId;not null;index" json:"userId"`
+	GrantID          uint         `gorm:"column:grantId;not null;index" json:"grantId"`
+	AccessTokenHash  string       `gorm:"column:accessTokenHash;size:64;not null;uniqueIndex" json:"-"`
+	RefreshTokenHash string       `gorm:"column:refreshTokenHash;size:64;not null;uniqueIndex" json:"-"`
+	Scopes           string       `gorm:"column:scopes;size:500;not null" json:"-"` // Space separated
+	AccessExpiresAt  time.Time    `gorm:"column:accessExpiresAt;not null" json:"accessExpiresAt"`
+	RefreshExpiresAt time.Time    `gorm:"column:refreshExpiresAt;not null" json:"refreshExpiresAt"`
+	RevokedAt        *time.Time   `gorm:"column:revokedAt" json:"revokedAt"`
+	CreatedAt        time.Time    `gorm:"column:createdAt" json:"createdAt"`
+	Client           *OAuthClient `gorm:"foreignKey:ClientID;references:ID;constraint:OnDelete:CASCADE" json:"-"`
+	User             *User        `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+func (OAuthToken) TableName() string {
+	return "OAuthTokens"
+}
+
+// ScopeList returns the granted scopes as a slice
+func (t OAuthToken) ScopeList() []string {
+	return strings.Fields(t.Scopes)
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// PasswordResetToken is a single-use token emailed by the forgot password flow
+type PasswordResetToken struct {
+	ID        uint       `gorm:"primaryKey;column:id" json:"id"`
+	UserID    uint       `gorm:"column:userId;not null;index" json:"userId"`
+	TokenHash string     `gorm:"column:tokenHash;size:64;not null;uniqueIndex" json:"-"`
+	ExpiresAt time.Time  `gorm:"column:expiresAt;not null" json:"expiresAt"`
+	UsedAt    *time.Time `gorm:"column:usedAt" json:"usedAt"`
+	CreatedAt time.Time  `gorm:"column:createdAt" json:"createdAt"`
+	User      *User      `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+func (PasswordResetToken) TableName() string {
+	return "PasswordResetTokens"
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// DefaultPersonalAccessTokenScopes are the scopes of a token created without any. Other
+// permissions, such as the administrative ones, have to be asked for explicitly.
+var DefaultPersonalAccessTokenScopes = []string{PermissionTodosRead, PermissionTodosWrite}
+
+// PersonalAccessToken is a long-lived API key for scripts and CI. Only the hash of the
+// token is stored, Prefix keeps a recognisable part of it so users can tell tokens apart.
+type PersonalAccessToken struct {
+	ID         uint       `gorm:"primaryKey;column:id" json:"id"`
+	UserID     uint       `gorm:"column:userId;not null;index" json:"userId"`
+	Name       string     `gorm:"column:name;size:100;not null" json:"name"`
+	Prefix     string     `gorm:"column:prefix;size:32;not null" json:"prefix"`
+	TokenHash  string     `gorm:"column:tokenHash;size:64;not null;uniqueIndex" json:"-"`
+	Scopes     string     `gorm:"column:scopes;size:500;not null;default:''" json:"-"` // Space separated, empty means the default scopes
+	ExpiresAt  *time.Time `gorm:"column:expiresAt" json:"expiresAt"`
+	LastUsedAt *time.Time `gorm:"column:lastUsedAt" json:"lastUsedAt"`
+	LastUsedIP string     `gorm:"column:lastUsedIp;size:64" json:"lastUsedIp"`
+	RevokedAt  *time.Time `gorm:"column:revokedAt" json:"revokedAt"`
+	CreatedAt  time.Time  `gorm:"column:createdAt" json:"createdAt"`
+	User       *User      `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+func (PersonalAccessToken) TableName() string {
+	return "PersonalAccessTokens"
+}
+
+// ScopeList returns the scopes as a slice. Tokens stored without scopes, which used to get
+// every permission of their owner, get the default scopes.
+func (t PersonalAccessToken) ScopeList() []string {
+	if scopes := strings.Fields(t.Scopes); len(scopes) > 0 {
+		return scopes
+	}
+	return slices.Clone(DefaultPersonalAccessTokenScopes)
+}
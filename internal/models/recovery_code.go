@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// RecoveryCode is a one-time code that replaces a TOTP code when the authenticator is lost
+type RecoveryCode struct {
+	ID        uint       `gorm:"primaryKey;column:id" json:"id"`
+	UserID    uint       `gorm:"column:userId;not null;index" json:"userId"`
+	CodeHash  string     `gorm:"column:codeHash;size:64;not null" json:"-"`
+	UsedAt    *time.Time `gorm:"column:usedAt" json:"usedAt"`
+	CreatedAt time.Time  `gorm:"column:createdAt" json:"createdAt"`
+	User      *User      `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+func (RecoveryCode) TableName() string {
+	return "RecoveryCodes"
+}
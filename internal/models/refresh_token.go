@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// RefreshToken stores the hash of an issued refresh token. Tokens minted by rotating
+// one another share a FamilyID so a replayed token can revoke the whole chain.
+type RefreshToken struct {
+	ID        uint       `gorm:"primaryKey;column:id" json:"id"`
+	UserID    uint       `gorm:"column:userId;not null;index" json:"userId"`
+	FamilyID  string     `gorm:"column:familyId;size:64;not null;index" json:"familyId"`
+	SessionID *uint      `gorm:"column:sessionId;index" json:"sessionId"` // Nil for tokens issued before sessions were tracked
+	TokenHash string     `gorm:"column:tokenHash;size:64;not null;uniqueIndex" json:"-"`
+	ExpiresAt time.Time  `gorm:"column:expiresAt;not null" json:"expiresAt"`
+	UsedAt    *time.Time `gorm:"column:usedAt" json:"usedAt"`
+	RevokedAt *time.Time `gorm:"column:revokedAt" json:"revokedAt"`
+	CreatedAt time.Time  `gorm:"column:createdAt" json:"createdAt"`
+	User      *User      `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE" json:"-"`
+	Session   *Session   `gorm:"foreignKey:SessionID;references:ID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+func (RefreshToken) TableName() string {
+	return "RefreshTokens"
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// RevokedToken is a JWT that was explicitly invalidated before its expiry.
+// Rows can be removed once ExpiresAt has passed because the token is dead anyway.
+type RevokedToken struct {
+	ID        uint      `gorm:"primaryKey;column:id" json:"id"`
+	JTI       string    `gorm:"column:jti;size:64;not null;uniqueIndex" json:"jti"`
+	UserID    uint      `gorm:"column:userId;not null;index" json:"userId"`
+	ExpiresAt time.Time `gorm:"column:expiresAt;not null;index" json:"expiresAt"`
+	CreatedAt time.Time `gorm:"column:createdAt" json:"createdAt"`
+}
+
+func (RevokedToken) TableName() string {
+	return "RevokedTokens"
+}
@@ -0,0 +1,41 @@
+package models
+
+// Built-in roles
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// Built-in permissions, named "<resource>:<action>"
+const (
+	PermissionTodosRead  = "todos:read"
+	PermissionTodosWrite = "todos:write"
+	PermissionUsersRead  = "users:read"
+	PermissionUsersWrite = "users:write"
+	// Act as another user with a short-lived token
+	PermissionUsersImpersonate = "users:impersonate"
+	// Register the third-party apps that may use the OAuth2 authorization server
+	PermissionClientsRead  = "clients:read"
+	PermissionClientsWrite = "clients:write"
+)
+
+type Role struct {
+	ID          uint         `gorm:"primaryKey;column:id" json:"id"`
+	Name        string       `gorm:"column:name;size:64;not null;uniqueIndex" json:"name"`
+	Description string       `gorm:"column:description;size:255" json:"description"`
+	Permissions []Permission `gorm:"many2many:RolePermissions;constraint:OnDelete:CASCADE" json:"permissions"`
+}
+
+func (Role) TableName() string {
+	return "Roles"
+}
+
+type Permission struct {
+	ID          uint   `gorm:"primaryKey;column:id" json:"id"`
+	Name        string `gorm:"column:name;size:64;not null;uniqueIndex" json:"name"`
+	Description string `gorm:"column:description;size:255" json:"description"`
+}
+
+func (Permission) TableName() string {
+	return "Permissions"
+}
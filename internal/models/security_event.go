@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// Types of security events, named "<subject>.<what happened>"
+const (
+	SecurityEventLoginSucceeded       = "login.succeeded"
+	SecurityEventLoginFailed          = "login.failed"
+	SecurityEventPasswordChanged      = "password.changed"
+	SecurityEventPasswordReset        = "password.reset"
+	SecurityEventTokenCreated         = "token.created"
+	SecurityEventTokenRevoked         = "token.revoked"
+	SecurityEventRefreshTokenReused   = "token.reused"
+	SecurityEventRevokedTokenUsed     = "token.revoked_used"
+	SecurityEventEmailChangeRequested = "email.change_requested"
+	SecurityEventEmailChanged         = "email.changed"
+	SecurityEventTwoFactorEnabled     = "two_factor.enabled"
+	SecurityEventTwoFactorDisabled    = "two_factor.disabled"
+	SecurityEventAccountCreated       = "account.created"
+	SecurityEventAccountDisabled      = "account.disabled"
+	SecurityEventAccountEnabled       = "account.enabled"
+	SecurityEventAccountDeleted       = "account.deleted"
+	SecurityEventImpersonationStarted = "impersonation.started"
+	SecurityEventImpersonatedRequest  = "impersonation.request"
+)
+
+// SecurityEvent records something that happened to the security of an account, such as a
+// login or a password change. Events are kept for SECURITY_EVENT_RETENTION, also after the
+// account was deleted, so UserID has no foreign key to cascade from.
+type SecurityEvent struct {
+	ID        uint   `gorm:"primaryKey;column:id" json:"id"`
+	UserID    uint   `gorm:"column:userId;not null;index:idx_security_events_user_created,priority:1" json:"userId"`
+	Type      string `gorm:"column:type;size:64;not null" json:"type"`
+	IPAddress string `gorm:"column:ipAddress;size:64" json:"ipAddress"`
+	UserAgent string `gorm:"column:userAgent;size:512" json:"userAgent"`
+	// Short human readable context, such as the reason of a failed login
+	Details string `gorm:"column:details;size:500" json:"details"`
+	// Administrator who caused the event, set for admin actions and impersonated requests
+	ActorID   *uint     `gorm:"column:actorId" json:"actorId"`
+	CreatedAt time.Time `gorm:"column:createdAt;index;index:idx_security_events_user_created,priority:2" json:"createdAt"`
+}
+
+func (SecurityEvent) TableName() string {
+	return "SecurityEvents"
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// Session is one login on one device. Its refresh tokens and the access tokens minted
+// from them carry the session ID, so revoking the session logs that device out.
+type Session struct {
+	ID         uint      `gorm:"primaryKey;column:id" json:"id"`
+	UserID     uint      `gorm:"column:userId;not null;index" json:"userId"`
+	UserAgent  string    `gorm:"column:userAgent;size:512" json:"userAgent"`
+	IPAddress  string    `gorm:"column:ipAddress;size:64" json:"ipAddress"`
+	CreatedAt  time.Time `gorm:"column:createdAt" json:"createdAt"`
+	LastSeenAt time.Time `gorm:"column:lastSeenAt;not null" json:"lastSeenAt"`
+	// Pushed forward on every refresh, the session ends with its last refresh token
+	ExpiresAt time.Time  `gorm:"column:expiresAt;not null" json:"expiresAt"`
+	RevokedAt *time.Time `gorm:"column:revokedAt" json:"revokedAt"`
+	User      *User      `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+func (Session) TableName() string {
+	return "Sessions"
+}
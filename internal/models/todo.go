@@ -1,20 +1,83 @@
 package models
 
-import "time"
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TodoSearchConfig is the Postgres text search configuration of the search vectors of todo
+// items and notes. Queries must use the same one to find the stemmed words.
+const TodoSearchConfig = "english"
 
 type TodoItem struct {
 	ID          uint       `gorm:"primaryKey;column:id" json:"id"`
 	Title       string     `gorm:"size:255;not null;column:title" json:"title"`
 	Description string     `gorm:"size:255;null;column:description" json:"description"`
 	IsCompleted bool       `gorm:"default:false;column:isCompleted" json:"isCompleted"`
-	CreatedAt   time.Time  `gorm:"column:createdAt" json:"createdAt"`
-	UpdatedAt   time.Time  `gorm:"column:updatedAt" json:"updatedAt"`
+	CreatedAt   time.Time  `gorm:"column:createdAt;index:idx_todo_items_user_created,priority:2" json:"createdAt"`
+	UpdatedAt   time.Time  `gorm:"column:updatedAt;index:idx_todo_items_user_updated,priority:2" json:"updatedAt"`
 	Notes       []TodoNote `gorm:"foreignKey:TodoItemID;constraint:OnDelete:CASCADE" json:"notes,omitempty"`
-	UserID      uint       `gorm:"column:user_id" json:"userId" gorm:"not null"`
-	User        User       `gorm:"foreignKey:UserID;references:ID" json:"user"`
+	UserID      uint       `gorm:"column:user_id;index:idx_todo_items_user_created,priority:1;index:idx_todo_items_user_updated,priority:1;index:idx_todo_items_user_due,priority:1" json:"userId"`
+	User        *User      `gorm:"foreignKey:UserID;references:ID" json:"-"`
+
+	// When the item is due, null when it has no date. Items that are due all day store
+	// midnight UTC of their date, which is the same calendar day in every time zone.
+	DueAt           *time.Time      `gorm:"column:dueAt;index:idx_todo_items_user_due,priority:2" json:"dueAt"`
+	AllDay          bool            `gorm:"column:allDay;not null;default:false" json:"allDay"`
+	ReminderOffsets ReminderOffsets `gorm:"column:reminderOffsets;type:text;not null;default:''" json:"reminderOffsets"`
 }
 
 // TableName overrides the table name used by TodoItem to `todos`
 func (TodoItem) TableName() string {
 	return "TodoItems"
 }
+
+// ReminderOffsets are the minutes before the due time at which the user wants to be reminded,
+// smallest first. The column holds them separated by commas.
+type ReminderOffsets []int
+
+// Value implements driver.Valuer
+func (o ReminderOffsets) Value() (driver.Value, error) {
+	parts := make([]string, 0, len(o))
+	for _, offset := range o {
+		parts = append(parts, strconv.Itoa(offset))
+	}
+	return strings.Join(parts, ","), nil
+}
+
+// Scan implements sql.Scanner
+func (o *ReminderOffsets) Scan(value interface{}) error {
+	var text string
+	switch value := value.(type) {
+	case nil:
+	case string:
+		text = value
+	case []byte:
+		text = string(value)
+	default:
+		return fmt.Errorf("cannot scan %T into ReminderOffsets", value)
+	}
+
+	offsets := ReminderOffsets{}
+	for _, part := range strings.Split(text, ",") {
+		if part == "" {
+			continue
+		}
+		offset, err := strconv.Atoi(part)
+		if err != nil {
+			return err
+		}
+		offsets = append(offsets, offset)
+	}
+	*o = offsets
+	return nil
+}
+
+// MarshalJSON writes an empty list rather than null when there are no reminders
+func (o ReminderOffsets) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]int(append(ReminderOffsets{}, o...)))
+}
@@ -4,7 +4,7 @@ import "time"
 
 type TodoNote struct {
 	ID         uint      `gorm:"primaryKey;column:id" json:"id"`
-	TodoItemID uint      `gorm:"column:todoItemId;not null" json:"todoItemId"`
+	TodoItemID uint      `gorm:"column:todoItemId;not null;index" json:"todoItemId"`
 	Note       string    `gorm:"column:note;not null" json:"note"`
 	CreatedAt  time.Time `gorm:"column:createdAt" json:"createdAt"`
 	UpdatedAt  time.Time `gorm:"column:updatedAt" json:"updatedAt"`
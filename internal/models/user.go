@@ -1,13 +1,66 @@
 package models
 
+import "time"
+
 type User struct {
-	ID           uint       `gorm:"primaryKey;column:id" json:"id"`
-	Email        string     `gorm:"column:email;not null;unique" json:"email"`
-	Name         string     `gorm:"column:name;not null" json:"name"`
-	PasswordHash string     `gorm:"column:passwordHash;not null" json:"-"` // Using json:"-" to exclude from JSON responses
-	TodoItems    []TodoItem `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE" json:"todoItems,omitempty"`
+	ID              uint       `gorm:"primaryKey;column:id" json:"id"`
+	Email           string     `gorm:"column:email;not null;unique" json:"email"`
+	Name            string     `gorm:"column:name;not null" json:"name"`
+	Bio             string     `gorm:"column:bio;size:500;not null;default:''" json:"bio"`
+	AvatarURL       string     `gorm:"column:avatarUrl;size:500;not null;default:''" json:"avatarUrl"`
+	PasswordHash    string     `gorm:"column:passwordHash;not null" json:"-"` // Using json:"-" to exclude from JSON responses
+	EmailVerifiedAt *time.Time `gorm:"column:emailVerifiedAt" json:"emailVerifiedAt"`
+	// Address the user asked to switch to, it replaces Email once the link sent to it is opened
+	PendingEmail string `gorm:"column:pendingEmail;size:255" json:"-"`
+	// Set while an administrator has disabled the account, it cannot log in or use its tokens
+	DisabledAt *time.Time `gorm:"column:disabledAt" json:"disabledAt"`
+	// Tokens issued before this instant are rejected (set by "logout everywhere")
+	TokensRevokedAt *time.Time `gorm:"column:tokensRevokedAt" json:"-"`
+	// TOTP secret encrypted with utils.EncryptSecret, set during enrollment and kept while enabled
+	TOTPSecret         string     `gorm:"column:totpSecret;size:255" json:"-"`
+	TwoFactorEnabledAt *time.Time `gorm:"column:twoFactorEnabledAt" json:"twoFactorEnabledAt"`
+	// Last TOTP time step accepted, a code is never accepted twice
+	TOTPLastUsedStep int64      `gorm:"column:totpLastUsedStep;not null;default:0" json:"-"`
+	Timezone         string     `gorm:"column:timezone;size:64;not null;default:'UTC'" json:"timezone"` // IANA name, "today" of the todo views is computed in it
+	Roles            []Role     `gorm:"many2many:UserRoles;constraint:OnDelete:CASCADE" json:"roles,omitempty"`
+	TodoItems        []TodoItem `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE" json:"todoItems,omitempty"`
 }
 
 func (User) TableName() string {
 	return "Users"
 }
+
+// HasRole reports whether the user has the role, Roles must be preloaded
+func (u User) HasRole(name string) bool {
+	for _, role := range u.Roles {
+		if role.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// RoleNames returns the names of the user's roles, Roles must be preloaded
+func (u User) RoleNames() []string {
+	names := make([]string, 0, len(u.Roles))
+	for _, role := range u.Roles {
+		names = append(names, role.Name)
+	}
+	return names
+}
+
+// PermissionNames returns the distinct permissions granted by the user's roles,
+// Roles.Permissions must be preloaded
+func (u User) PermissionNames() []string {
+	seen := map[string]bool{}
+	names := []string{}
+	for _, role := range u.Roles {
+		for _, permission := range role.Permissions {
+			if !seen[permission.Name] {
+				seen[permission.Name] = true
+				names = append(names, permission.Name)
+			}
+		}
+	}
+	return names
+}
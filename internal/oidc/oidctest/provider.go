@@ -0,0 +1,241 @@
+// Package oidctest runs a minimal OpenID Connect provider for tests: discovery, JWKS and an
+// authorization code token endpoint that checks PKCE, with an RSA signing key that can be rotated.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Provider is a local identity provider. Its issuer is the URL of the test server.
+type Provider struct {
+	*httptest.Server
+	ClientID string
+
+	mu           sync.Mutex
+	keyID        string
+	key          *rsa.PrivateKey
+	codes        map[string]authorization
+	jwksRequests int
+}
+
+// authorization is a code issued by Authorize and not exchanged yet
+type authorization struct {
+	redirectURI   string
+	codeChallenge string
+	claims        jwt.MapClaims
+}
+
+// NewProvider starts a provider for the client, close it when the test ends
+func NewProvider(clientID string) *Provider {
+	p := &Provider{
+		ClientID: clientID,
+		codes:    map[string]authorization{},
+	}
+	p.RotateKey()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", p.serveDiscovery)
+	mux.HandleFunc("/jwks", p.serveJWKS)
+	mux.HandleFunc("/token", p.serveToken)
+	p.Server = httptest.NewServer(mux)
+
+	return p
+}
+
+// Issuer returns the issuer identifier, which is also the base URL of the discovery document
+func (p *Provider) Issuer() string {
+	return p.URL
+}
+
+// RotateKey replaces the signing key. The previous key is no longer published, so tokens it signed
+// stop verifying once clients reload the JWKS. It returns the ID of the new key.
+func (p *Provider) RotateKey() string {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(fmt.Sprintf("oidctest: failed to generate key: %v", err))
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.key = key
+	p.keyID = fmt.Sprintf("key-%d", time.Now().UnixNano())
+	return p.keyID
+}
+
+// PublicKey returns the current signing key
+func (p *Provider) PublicKey() *rsa.PublicKey {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return &p.key.PublicKey
+}
+
+// JWKSRequests returns how often the JWKS has been fetched
+func (p *Provider) JWKSRequests() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.jwksRequests
+}
+
+// Claims returns valid ID token claims for the subject, issued now to the client
+func (p *Provider) Claims(subject string, nonce string) jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss":   p.Issuer(),
+		"aud":   p.ClientID,
+		"sub":   subject,
+		"nonce": nonce,
+		"iat":   now.Unix(),
+		"exp":   now.Add(5 * time.Minute).Unix(),
+	}
+}
+
+// SignIDToken signs the claims with the current key using RS256
+func (p *Provider) SignIDToken(claims jwt.MapClaims) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = p.keyID
+
+	signed, err := token.SignedString(p.key)
+	if err != nil {
+		panic(fmt.Sprintf("oidctest: failed to sign ID token: %v", err))
+	}
+	return signed
+}
+
+// Authorize plays the user logging in at the provider: it checks the authorization request and
+// returns the code and state the provider would send to the redirect URI. The ID token issued for
+// the code gets the claims, with the nonce of the request unless the claims set their own.
+func (p *Provider) Authorize(authorizationURL string, claims jwt.MapClaims) (string, string, error) {
+	authURL, err := url.Parse(authorizationURL)
+	if err != nil {
+		return "", "", err
+	}
+
+	query := authURL.Query()
+	switch {
+	case authURL.Path != "/authorize":
+		return "", "", fmt.Errorf("unexpected authorization endpoint %q", authURL.Path)
+	case query.Get("response_type") != "code":
+		return "", "", errors.New("response_type must be code")
+	case query.Get("client_id") != p.ClientID:
+		return "", "", fmt.Errorf("unknown client %q", query.Get("client_id"))
+	case query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "":
+		return "", "", errors.New("an S256 code challenge is required")
+	case query.Get("state") == "" || query.Get("nonce") == "":
+		return "", "", errors.New("state and nonce are required")
+	}
+
+	idTokenClaims := jwt.MapClaims{}
+	for name, value := range claims {
+		idTokenClaims[name] = value
+	}
+	if _, ok := idTokenClaims["nonce"]; !ok {
+		idTokenClaims["nonce"] = query.Get("nonce")
+	}
+
+	code := randomString()
+
+	p.mu.Lock()
+	p.codes[code] = authorization{
+		redirectURI:   query.Get("redirect_uri"),
+		codeChallenge: query.Get("code_challenge"),
+		claims:        idTokenClaims,
+	}
+	p.mu.Unlock()
+
+	return code, query.Get("state"), nil
+}
+
+func (p *Provider) serveDiscovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"issuer":                 p.Issuer(),
+		"authorization_endpoint": p.URL + "/authorize",
+		"token_endpoint":         p.URL + "/token",
+		"jwks_uri":               p.URL + "/jwks",
+	})
+}
+
+func (p *Provider) serveJWKS(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	p.jwksRequests++
+	key := map[string]string{
+		"kty": "RSA",
+		"use": "sig",
+		"kid": p.keyID,
+		"n":   base64.RawURLEncoding.EncodeToString(p.key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(p.key.E)).Bytes()),
+	}
+	p.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"keys": []interface{}{key}})
+}
+
+func (p *Provider) serveToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.ParseForm() != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	clientID := r.PostForm.Get("client_id")
+	if username, _, ok := r.BasicAuth(); ok {
+		clientID, _ = url.QueryUnescape(username)
+	}
+	if clientID != p.ClientID {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	// Codes are single use, a failed exchange burns them too
+	p.mu.Lock()
+	code, ok := p.codes[r.PostForm.Get("code")]
+	delete(p.codes, r.PostForm.Get("code"))
+	p.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if r.PostForm.Get("grant_type") != "authorization_code" || !ok ||
+		r.PostForm.Get("redirect_uri") != code.redirectURI ||
+		base64.RawURLEncoding.EncodeToString(sum[:]) != code.codeChallenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": randomString(),
+		"token_type":   "Bearer",
+		"id_token":     p.SignIDToken(code.claims),
+		"expires_in":   300,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func randomString() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		panic(fmt.Sprintf("oidctest: failed to read random bytes: %v", err))
+	}
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
@@ -0,0 +1,189 @@
+package oidc
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"net/url"
+	"testing"
+	"time"
+	"todo-api/config"
+	"todo-api/internal/oidc/oidctest"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// newTestProvider starts a mock identity provider and a Provider configured for it
+func newTestProvider(t *testing.T, clientSecret string) (*Provider, *oidctest.Provider) {
+	t.Helper()
+
+	mock := oidctest.NewProvider("todo-api")
+	t.Cleanup(mock.Close)
+
+	provider := New(config.OIDCConfig{
+		IssuerURL:    mock.Issuer(),
+		ClientID:     mock.ClientID,
+		ClientSecret: clientSecret,
+		RedirectURL:  "https://api.example.com/api/v1/auth/oidc/callback",
+		Scopes:       []string{"openid", "email"},
+	}, mock.Client())
+
+	return provider, mock
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	provider, mock := newTestProvider(t, "")
+	ctx := context.Background()
+
+	publicKeyDER, err := x509.MarshalPKIXPublicKey(mock.PublicKey())
+	if err != nil {
+		t.Fatalf("failed to encode public key: %v", err)
+	}
+	publicKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKeyDER})
+
+	// with returns valid claims for the nonce "n-1" with the change applied
+	with := func(change func(jwt.MapClaims)) jwt.MapClaims {
+		claims := mock.Claims("alice", "n-1")
+		change(claims)
+		return claims
+	}
+	signWith := func(method jwt.SigningMethod, key interface{}) string {
+		token := jwt.NewWithClaims(method, mock.Claims("alice", "n-1"))
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Fatalf("failed to sign token: %v", err)
+		}
+		return signed
+	}
+
+	tests := []struct {
+		name    string
+		idToken string
+		nonce   string
+		wantErr bool
+	}{
+		{"valid", mock.SignIDToken(mock.Claims("alice", "n-1")), "n-1", false},
+		{"other issuer", mock.SignIDToken(with(func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" })), "n-1", true},
+		{"other audience", mock.SignIDToken(with(func(c jwt.MapClaims) { c["aud"] = "other-app" })), "n-1", true},
+		{"several audiences authorized to us", mock.SignIDToken(with(func(c jwt.MapClaims) {
+			c["aud"] = []string{"todo-api", "other-app"}
+			c["azp"] = "todo-api"
+		})), "n-1", false},
+		{"several audiences authorized to another client", mock.SignIDToken(with(func(c jwt.MapClaims) {
+			c["aud"] = []string{"todo-api", "other-app"}
+			c["azp"] = "other-app"
+		})), "n-1", true},
+		{"several audiences without azp", mock.SignIDToken(with(func(c jwt.MapClaims) { c["aud"] = []string{"todo-api", "other-app"} })), "n-1", true},
+		{"wrong nonce", mock.SignIDToken(mock.Claims("alice", "n-2")), "n-1", true},
+		{"no nonce expected", mock.SignIDToken(mock.Claims("alice", "")), "", true},
+		{"expired", mock.SignIDToken(with(func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Hour).Unix() })), "n-1", true},
+		{"no expiry", mock.SignIDToken(with(func(c jwt.MapClaims) { delete(c, "exp") })), "n-1", true},
+		{"no subject", mock.SignIDToken(mock.Claims("", "n-1")), "n-1", true},
+		// The public key used as an HMAC secret is the classic algorithm confusion attack
+		{"HS256 with the public key", signWith(jwt.SigningMethodHS256, publicKeyPEM), "n-1", true},
+		{"alg none", signWith(jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType), "n-1", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := provider.VerifyIDToken(ctx, tt.idToken, tt.nonce)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("VerifyIDToken error = %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && (claims.Subject != "alice" || claims.Issuer != mock.Issuer()) {
+				t.Errorf("claims = %s from %s, want alice from %s", claims.Subject, claims.Issuer, mock.Issuer())
+			}
+		})
+	}
+}
+
+func TestVerifyIDTokenKeyRotation(t *testing.T) {
+	provider, mock := newTestProvider(t, "")
+	ctx := context.Background()
+
+	oldToken := mock.SignIDToken(mock.Claims("alice", "n-1"))
+	if _, err := provider.VerifyIDToken(ctx, oldToken, "n-1"); err != nil {
+		t.Fatalf("VerifyIDToken failed: %v", err)
+	}
+
+	mock.RotateKey()
+	newToken := mock.SignIDToken(mock.Claims("alice", "n-1"))
+
+	// Unknown keys do not make every request hit the JWKS endpoint
+	if _, err := provider.VerifyIDToken(ctx, newToken, "n-1"); err == nil {
+		t.Fatal("token with an unknown key was accepted before the keys were reloaded")
+	}
+	if requests := mock.JWKSRequests(); requests != 1 {
+		t.Fatalf("JWKS fetched %d times, want 1", requests)
+	}
+
+	provider.keysFetchedAt = time.Now().Add(-keysRefreshInterval)
+
+	if _, err := provider.VerifyIDToken(ctx, newToken, "n-1"); err != nil {
+		t.Fatalf("token signed with the new key was rejected: %v", err)
+	}
+	if requests := mock.JWKSRequests(); requests != 2 {
+		t.Errorf("JWKS fetched %d times, want 2", requests)
+	}
+
+	// The retired key is no longer published
+	if _, err := provider.VerifyIDToken(ctx, oldToken, "n-1"); err == nil {
+		t.Error("token signed with the retired key was accepted")
+	}
+}
+
+func TestExchangeChecksPKCE(t *testing.T) {
+	clients := map[string]string{"public client": "", "confidential client": "s3cret"}
+
+	for name, clientSecret := range clients {
+		t.Run(name, func(t *testing.T) {
+			provider, mock := newTestProvider(t, clientSecret)
+			ctx := context.Background()
+
+			// authorize logs alice in at the provider, the nonce of the request goes into the ID token
+			authorize := func() string {
+				authorizationURL, err := provider.AuthCodeURL(ctx, "state-1", "n-1", "verifier-1")
+				if err != nil {
+					t.Fatalf("AuthCodeURL failed: %v", err)
+				}
+				if query := mustParseQuery(t, authorizationURL); query.Get("code_challenge") != CodeChallenge("verifier-1") {
+					t.Fatalf("code_challenge = %q, want the S256 challenge of the verifier", query.Get("code_challenge"))
+				}
+
+				claims := mock.Claims("alice", "")
+				delete(claims, "nonce")
+				code, state, err := mock.Authorize(authorizationURL, claims)
+				if err != nil || state != "state-1" {
+					t.Fatalf("Authorize = %q, %v, want the state back", state, err)
+				}
+				return code
+			}
+
+			code := authorize()
+			tokens, err := provider.Exchange(ctx, code, "verifier-1")
+			if err != nil {
+				t.Fatalf("Exchange failed: %v", err)
+			}
+			if _, err := provider.VerifyIDToken(ctx, tokens.IDToken, "n-1"); err != nil {
+				t.Errorf("ID token from the exchange was rejected: %v", err)
+			}
+
+			if _, err := provider.Exchange(ctx, code, "verifier-1"); err == nil {
+				t.Error("code was exchanged twice")
+			}
+			if _, err := provider.Exchange(ctx, authorize(), "another-verifier"); err == nil {
+				t.Error("code was exchanged with the wrong verifier")
+			}
+		})
+	}
+}
+
+func mustParseQuery(t *testing.T, rawURL string) url.Values {
+	t.Helper()
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		t.Fatalf("failed to parse %q: %v", rawURL, err)
+	}
+	return parsed.Query()
+}
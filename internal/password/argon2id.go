@@ -0,0 +1,136 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"math"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	argon2idPrefix     = "$argon2id$"
+	argon2idSaltLength = 16
+	argon2idKeyLength  = 32
+
+	// Upper bounds well above any sensible cost, so a mistyped setting or a tampered hash
+	// cannot make every login take minutes or gigabytes. Memory is in KiB.
+	argon2idMaxMemory     = 4 * 1024 * 1024
+	argon2idMaxIterations = 64
+	// Shortest salt and key the argon2 specification allows, in bytes
+	argon2idMinSaltLength = 8
+	argon2idMinKeyLength  = 4
+)
+
+// Argon2idHasher produces PHC formatted argon2id hashes:
+// $argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>
+type Argon2idHasher struct {
+	// Memory in KiB, number of passes and degree of parallelism
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+}
+
+func NewArgon2idHasher(memory uint32, iterations uint32, parallelism uint8) *Argon2idHasher {
+	return &Argon2idHasher{
+		Memory:      memory,
+		Iterations:  iterations,
+		Parallelism: parallelism,
+	}
+}
+
+func (h *Argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, argon2idSaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, h.Iterations, h.Memory, h.Parallelism, argon2idKeyLength)
+
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2idPrefix, argon2.Version, h.Memory, h.Iterations, h.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func (h *Argon2idHasher) Verify(password string, encodedHash string) (bool, error) {
+	params, salt, key, err := decodeArgon2id(encodedHash)
+	if err != nil {
+		return false, err
+	}
+
+	// Always derive with the parameters stored in the hash, not the configured ones
+	candidate := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, uint32(len(key)))
+
+	return subtle.ConstantTimeCompare(candidate, key) == 1, nil
+}
+
+func (h *Argon2idHasher) Recognizes(encodedHash string) bool {
+	return strings.HasPrefix(encodedHash, argon2idPrefix)
+}
+
+func (h *Argon2idHasher) NeedsRehash(encodedHash string) bool {
+	params, _, _, err := decodeArgon2id(encodedHash)
+	if err != nil {
+		return true
+	}
+	return params.Memory < h.Memory || params.Iterations < h.Iterations || params.Parallelism < h.Parallelism
+}
+
+// decodeArgon2id splits a PHC string into its parameters, salt and derived key
+func decodeArgon2id(encodedHash string) (Argon2idHasher, []byte, []byte, error) {
+	var params Argon2idHasher
+
+	// "", "argon2id", "v=19", "m=...,t=...,p=...", salt, hash
+	parts := strings.Split(encodedHash, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return params, nil, nil, ErrUnknownHashFormat
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, fmt.Errorf("unsupported argon2 version %q", parts[2])
+	}
+
+	// Scan into ints so that negative or oversized values are caught by the range check
+	var memory, iterations, parallelism int
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &iterations, &parallelism); err != nil {
+		return params, nil, nil, fmt.Errorf("invalid argon2 parameters: %w", err)
+	}
+	// argon2 panics on zero passes or threads, a stored hash must not bring down a login
+	if err := checkArgon2idParameters(memory, iterations, parallelism); err != nil {
+		return params, nil, nil, err
+	}
+	params = Argon2idHasher{Memory: uint32(memory), Iterations: uint32(iterations), Parallelism: uint8(parallelism)}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil || len(salt) < argon2idMinSaltLength {
+		return params, nil, nil, fmt.Errorf("invalid argon2 salt")
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) < argon2idMinKeyLength {
+		return params, nil, nil, fmt.Errorf("invalid argon2 hash")
+	}
+
+	return params, salt, key, nil
+}
+
+// checkArgon2idParameters reports parameters argon2 cannot use or that exceed the upper bounds.
+// argon2 needs at least 8 KiB of memory per thread.
+func checkArgon2idParameters(memory int, iterations int, parallelism int) error {
+	if parallelism < 1 || parallelism > math.MaxUint8 {
+		return fmt.Errorf("argon2 parallelism must be between 1 and %d, got %d", math.MaxUint8, parallelism)
+	}
+	if iterations < 1 || iterations > argon2idMaxIterations {
+		return fmt.Errorf("argon2 iterations must be between 1 and %d, got %d", argon2idMaxIterations, iterations)
+	}
+	if memory < 8*parallelism || memory > argon2idMaxMemory {
+		return fmt.Errorf("argon2 memory must be between %d KiB (8 per thread) and %d KiB, got %d", 8*parallelism, argon2idMaxMemory, memory)
+	}
+	return nil
+}
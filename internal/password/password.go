@@ -0,0 +1,96 @@
+package password
+
+import (
+	"errors"
+	"fmt"
+	"todo-api/config"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrUnknownHashFormat is returned for stored hashes that no supported algorithm recognizes
+var ErrUnknownHashFormat = errors.New("unknown password hash format")
+
+// Hasher is one password hashing algorithm
+type Hasher interface {
+	// Hash returns a self-describing encoded hash, including algorithm, parameters and salt
+	Hash(password string) (string, error)
+	// Verify reports whether the password matches an encoded hash of this algorithm
+	Verify(password string, encodedHash string) (bool, error)
+	// Recognizes reports whether the encoded hash was produced by this algorithm
+	Recognizes(encodedHash string) bool
+	// NeedsRehash reports whether the encoded hash uses weaker parameters than configured
+	NeedsRehash(encodedHash string) bool
+}
+
+// Manager hashes new passwords with the configured algorithm and verifies hashes of every
+// supported algorithm, so stored hashes keep working when the configuration changes
+type Manager struct {
+	preferred Hasher
+	hashers   []Hasher
+}
+
+// ValidateConfig reports a configuration New cannot use. It is checked at startup, an unknown
+// algorithm must not silently hash with another one and argon2 panics on zero threads or passes.
+func ValidateConfig(hashingConfig config.PasswordHashingConfig) error {
+	if hashingConfig.Algorithm != "argon2id" && hashingConfig.Algorithm != "bcrypt" {
+		return fmt.Errorf("PASSWORD_HASH_ALGORITHM must be argon2id or bcrypt, got %q", hashingConfig.Algorithm)
+	}
+
+	if err := checkArgon2idParameters(hashingConfig.Argon2Memory, hashingConfig.Argon2Iterations, hashingConfig.Argon2Parallelism); err != nil {
+		return err
+	}
+
+	// bcrypt quietly uses its default cost below the minimum and refuses to hash above the maximum
+	if hashingConfig.BcryptCost < bcrypt.MinCost || hashingConfig.BcryptCost > bcrypt.MaxCost {
+		return fmt.Errorf("BCRYPT_COST must be between %d and %d, got %d", bcrypt.MinCost, bcrypt.MaxCost, hashingConfig.BcryptCost)
+	}
+
+	return nil
+}
+
+// New returns a Manager preferring the algorithm selected by PASSWORD_HASH_ALGORITHM. The
+// configuration must have passed ValidateConfig.
+func New(hashingConfig config.PasswordHashingConfig) *Manager {
+	argon2idHasher := NewArgon2idHasher(uint32(hashingConfig.Argon2Memory), uint32(hashingConfig.Argon2Iterations), uint8(hashingConfig.Argon2Parallelism))
+	bcryptHasher := NewBcryptHasher(hashingConfig.BcryptCost)
+
+	preferred := Hasher(argon2idHasher)
+	if hashingConfig.Algorithm == "bcrypt" {
+		preferred = bcryptHasher
+	}
+
+	return &Manager{
+		preferred: preferred,
+		hashers:   []Hasher{argon2idHasher, bcryptHasher},
+	}
+}
+
+// Hash hashes a password with the preferred algorithm
+func (m *Manager) Hash(password string) (string, error) {
+	return m.preferred.Hash(password)
+}
+
+// Verify checks a password against a stored hash of any supported algorithm. needsRehash
+// is true when the password matched but the hash should be replaced by a fresh Hash.
+func (m *Manager) Verify(password string, encodedHash string) (matches bool, needsRehash bool, err error) {
+	// Accounts without a password, such as those created by single sign-on, never match
+	if encodedHash == "" {
+		return false, false, nil
+	}
+
+	for _, hasher := range m.hashers {
+		if !hasher.Recognizes(encodedHash) {
+			continue
+		}
+
+		matches, err := hasher.Verify(password, encodedHash)
+		if err != nil || !matches {
+			return false, false, err
+		}
+
+		return true, hasher != m.preferred || hasher.NeedsRehash(encodedHash), nil
+	}
+
+	return false, false, ErrUnknownHashFormat
+}
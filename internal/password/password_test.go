@@ -0,0 +1,163 @@
+package password
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"todo-api/config"
+)
+
+// testHashingConfig keeps the costs low so the tests stay fast
+var testHashingConfig = config.PasswordHashingConfig{
+	Algorithm:         "argon2id",
+	Argon2Memory:      1024,
+	Argon2Iterations:  1,
+	Argon2Parallelism: 1,
+	BcryptCost:        4,
+}
+
+func TestValidateConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(hashingConfig *config.PasswordHashingConfig)
+		valid  bool
+	}{
+		{"defaults", func(c *config.PasswordHashingConfig) {}, true},
+		{"bcrypt", func(c *config.PasswordHashingConfig) { c.Algorithm = "bcrypt" }, true},
+		{"unknown algorithm", func(c *config.PasswordHashingConfig) { c.Algorithm = "scrypt" }, false},
+		{"empty algorithm", func(c *config.PasswordHashingConfig) { c.Algorithm = "" }, false},
+		{"zero parallelism", func(c *config.PasswordHashingConfig) { c.Argon2Parallelism = 0 }, false},
+		{"parallelism 255", func(c *config.PasswordHashingConfig) { c.Argon2Parallelism, c.Argon2Memory = 255, 255*8 }, true},
+		{"parallelism 256", func(c *config.PasswordHashingConfig) { c.Argon2Parallelism, c.Argon2Memory = 256, 256*8 }, false},
+		{"zero iterations", func(c *config.PasswordHashingConfig) { c.Argon2Iterations = 0 }, false},
+		{"too many iterations", func(c *config.PasswordHashingConfig) { c.Argon2Iterations = argon2idMaxIterations + 1 }, false},
+		{"too little memory per thread", func(c *config.PasswordHashingConfig) { c.Argon2Parallelism, c.Argon2Memory = 4, 31 }, false},
+		{"too much memory", func(c *config.PasswordHashingConfig) { c.Argon2Memory = argon2idMaxMemory + 1 }, false},
+		{"negative memory", func(c *config.PasswordHashingConfig) { c.Argon2Memory = -1 }, false},
+		{"bcrypt cost too low", func(c *config.PasswordHashingConfig) { c.BcryptCost = 3 }, false},
+		{"bcrypt cost too high", func(c *config.PasswordHashingConfig) { c.BcryptCost = 32 }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hashingConfig := testHashingConfig
+			tt.modify(&hashingConfig)
+
+			if err := ValidateConfig(hashingConfig); (err == nil) != tt.valid {
+				t.Errorf("ValidateConfig() = %v, want valid %v", err, tt.valid)
+			}
+		})
+	}
+}
+
+func TestManagerHashAndVerify(t *testing.T) {
+	for _, algorithm := range []string{"argon2id", "bcrypt"} {
+		t.Run(algorithm, func(t *testing.T) {
+			hashingConfig := testHashingConfig
+			hashingConfig.Algorithm = algorithm
+			manager := New(hashingConfig)
+
+			hash, err := manager.Hash("correct horse battery staple")
+			if err != nil {
+				t.Fatalf("Hash failed: %v", err)
+			}
+
+			matches, needsRehash, err := manager.Verify("correct horse battery staple", hash)
+			if err != nil || !matches || needsRehash {
+				t.Errorf("Verify(right password) = %v, %v, %v, want a match without rehash", matches, needsRehash, err)
+			}
+
+			matches, _, err = manager.Verify("wrong password", hash)
+			if err != nil || matches {
+				t.Errorf("Verify(wrong password) = %v, %v, want no match", matches, err)
+			}
+		})
+	}
+}
+
+func TestManagerVerifyRehashes(t *testing.T) {
+	bcryptConfig := testHashingConfig
+	bcryptConfig.Algorithm = "bcrypt"
+	bcryptHash, err := New(bcryptConfig).Hash("secret password")
+	if err != nil {
+		t.Fatalf("Hash failed: %v", err)
+	}
+
+	weakHash, err := New(testHashingConfig).Hash("secret password")
+	if err != nil {
+		t.Fatalf("Hash failed: %v", err)
+	}
+
+	strongerConfig := testHashingConfig
+	strongerConfig.Argon2Iterations = 2
+	manager := New(strongerConfig)
+
+	for name, hash := range map[string]string{"other algorithm": bcryptHash, "weaker parameters": weakHash} {
+		matches, needsRehash, err := manager.Verify("secret password", hash)
+		if err != nil || !matches || !needsRehash {
+			t.Errorf("%s: Verify() = %v, %v, %v, want a match that needs a rehash", name, matches, needsRehash, err)
+		}
+	}
+}
+
+func TestManagerVerifyWithoutPassword(t *testing.T) {
+	manager := New(testHashingConfig)
+
+	matches, _, err := manager.Verify("", "")
+	if err != nil || matches {
+		t.Errorf("Verify against an empty hash = %v, %v, want no match and no error", matches, err)
+	}
+
+	if _, _, err := manager.Verify("password", "$scrypt$whatever"); !errors.Is(err, ErrUnknownHashFormat) {
+		t.Errorf("Verify against an unknown format = %v, want ErrUnknownHashFormat", err)
+	}
+}
+
+func TestDecodeArgon2id(t *testing.T) {
+	hash, err := New(testHashingConfig).Hash("password")
+	if err != nil {
+		t.Fatalf("Hash failed: %v", err)
+	}
+	parts := strings.Split(hash, "$")
+	salt, key := parts[4], parts[5]
+
+	params, _, _, err := decodeArgon2id(hash)
+	if err != nil {
+		t.Fatalf("decodeArgon2id(own hash) failed: %v", err)
+	}
+	if params.Memory != 1024 || params.Iterations != 1 || params.Parallelism != 1 {
+		t.Errorf("decoded parameters %+v, want m=1024,t=1,p=1", params)
+	}
+
+	tests := map[string]string{
+		"not argon2id":     "$argon2i$v=19$m=1024,t=1,p=1$" + salt + "$" + key,
+		"missing part":     "$argon2id$v=19$m=1024,t=1,p=1$" + salt,
+		"other version":    "$argon2id$v=16$m=1024,t=1,p=1$" + salt + "$" + key,
+		"zero parallelism": "$argon2id$v=19$m=1024,t=1,p=0$" + salt + "$" + key,
+		"parallelism 256":  "$argon2id$v=19$m=4096,t=1,p=256$" + salt + "$" + key,
+		"zero iterations":  "$argon2id$v=19$m=1024,t=0,p=1$" + salt + "$" + key,
+		"negative memory":  "$argon2id$v=19$m=-1,t=1,p=1$" + salt + "$" + key,
+		"huge memory":      "$argon2id$v=19$m=4294967295,t=1,p=1$" + salt + "$" + key,
+		"garbled params":   "$argon2id$v=19$t=1,m=1024,p=1$" + salt + "$" + key,
+		"short salt":       "$argon2id$v=19$m=1024,t=1,p=1$AAAA$" + key,
+		"empty key":        "$argon2id$v=19$m=1024,t=1,p=1$" + salt + "$",
+		"invalid base64":   "$argon2id$v=19$m=1024,t=1,p=1$" + salt + "$!!!!",
+	}
+
+	hasher := NewArgon2idHasher(1024, 1, 1)
+	for name, encodedHash := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, _, _, err := decodeArgon2id(encodedHash); err == nil {
+				t.Fatal("decodeArgon2id accepted the hash")
+			}
+
+			// Verify must return the error instead of letting argon2 panic
+			if matches, err := hasher.Verify("password", encodedHash); err == nil || matches {
+				t.Errorf("Verify() = %v, %v, want an error", matches, err)
+			}
+			if !hasher.NeedsRehash(encodedHash) {
+				t.Error("NeedsRehash() = false for a hash that cannot be decoded")
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package password
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"todo-api/config"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Policy decides whether a new password is acceptable
+type Policy struct {
+	config config.PasswordPolicyConfig
+	// Lowercased entries of the breached password list
+	breached map[string]struct{}
+}
+
+var (
+	policy     *Policy
+	policyErr  error
+	policyOnce sync.Once
+)
+
+// GetPolicy returns the policy built from the configuration, reading the breached list on first use
+func GetPolicy() (*Policy, error) {
+	policyOnce.Do(func() {
+		policy, policyErr = NewPolicy(config.GetConfig().PasswordPolicy)
+	})
+	return policy, policyErr
+}
+
+// NewPolicy creates a policy and loads its breached password list. Empty lines and lines
+// starting with # are skipped.
+func NewPolicy(policyConfig config.PasswordPolicyConfig) (*Policy, error) {
+	p := &Policy{
+		config:   policyConfig,
+		breached: map[string]struct{}{},
+	}
+
+	if policyConfig.BreachedListFile == "" {
+		return p, nil
+	}
+
+	file, err := os.Open(policyConfig.BreachedListFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open breached password list: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		p.breached[strings.ToLower(line)] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read breached password list: %w", err)
+	}
+
+	return p, nil
+}
+
+// Check returns every rule the password breaks, or nothing when it is acceptable.
+// userInputs are the email and name of the account, the password may not contain them.
+func (p *Policy) Check(password string, userInputs ...string) []string {
+	var problems []string
+
+	length := utf8.RuneCountInString(password)
+	if length < p.config.MinLength {
+		problems = append(problems, fmt.Sprintf("Must be at least %d characters", p.config.MinLength))
+	}
+	if p.config.MaxLength > 0 && length > p.config.MaxLength {
+		problems = append(problems, fmt.Sprintf("Must be at most %d characters", p.config.MaxLength))
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, char := range password {
+		switch {
+		case unicode.IsUpper(char):
+			hasUpper = true
+		case unicode.IsLower(char):
+			hasLower = true
+		case unicode.IsDigit(char):
+			hasDigit = true
+		case unicode.IsPunct(char) || unicode.IsSymbol(char) || unicode.IsSpace(char):
+			hasSymbol = true
+		}
+	}
+
+	if p.config.RequireUppercase && !hasUpper {
+		problems = append(problems, "Must contain an uppercase letter")
+	}
+	if p.config.RequireLowercase && !hasLower {
+		problems = append(problems, "Must contain a lowercase letter")
+	}
+	if p.config.RequireDigit && !hasDigit {
+		problems = append(problems, "Must contain a digit")
+	}
+	if p.config.RequireSymbol && !hasSymbol {
+		problems = append(problems, "Must contain a symbol")
+	}
+
+	lowered := strings.ToLower(password)
+
+	for _, input := range userInputs {
+		input = strings.ToLower(strings.TrimSpace(input))
+		// For an email only the part before the @ is worth guessing
+		if at := strings.LastIndex(input, "@"); at > 0 {
+			input = input[:at]
+		}
+		// Very short names would reject too many unrelated passwords
+		if utf8.RuneCountInString(input) >= 4 && strings.Contains(lowered, input) {
+			problems = append(problems, "Must not contain your name or email address")
+			break
+		}
+	}
+
+	if _, breached := p.breached[lowered]; breached {
+		problems = append(problems, "Is too common or has appeared in a data breach")
+	}
+
+	return problems
+}
@@ -0,0 +1,85 @@
+package password
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"todo-api/config"
+)
+
+func TestPolicyCheck(t *testing.T) {
+	breachedList := filepath.Join(t.TempDir(), "breached.txt")
+	if err := os.WriteFile(breachedList, []byte("# common passwords\n\nPassword1!\n  Qwerty-2024  \n"), 0o600); err != nil {
+		t.Fatalf("failed to write breached list: %v", err)
+	}
+
+	policy, err := NewPolicy(config.PasswordPolicyConfig{
+		MinLength:        8,
+		MaxLength:        16,
+		RequireUppercase: true,
+		RequireLowercase: true,
+		RequireDigit:     true,
+		RequireSymbol:    true,
+		BreachedListFile: breachedList,
+	})
+	if err != nil {
+		t.Fatalf("NewPolicy failed: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		password   string
+		userInputs []string
+		want       []string
+	}{
+		{"acceptable", "Tr0ub4dor&3", nil, nil},
+		{"too short", "Ab1!", nil, []string{"Must be at least 8 characters"}},
+		{"too long", "Tr0ub4dor&3Tr0ub4dor&3", nil, []string{"Must be at most 16 characters"}},
+		{"length counts characters", "Ünïcödé1!", nil, nil},
+		{"no uppercase", "tr0ub4dor&3", nil, []string{"Must contain an uppercase letter"}},
+		{"no lowercase", "TR0UB4DOR&3", nil, []string{"Must contain a lowercase letter"}},
+		{"no digit", "Troubador&x", nil, []string{"Must contain a digit"}},
+		{"no symbol", "Tr0ub4dor33", nil, []string{"Must contain a symbol"}},
+		{"space counts as symbol", "Tr0ub4dor 3", nil, nil},
+		{"contains the name", "Xalice-Wonder1!", []string{"alice@example.com", "Alice Liddell"}, []string{"Must not contain your name or email address"}},
+		{"contains the name in another case", "Liddell-Wonder1!", []string{"bob@example.com", "Liddell"}, []string{"Must not contain your name or email address"}},
+		{"short names are ignored", "Bob-Wonder1!", []string{"bob@example.com", "Bob"}, nil},
+		{"breached", "Password1!", nil, []string{"Is too common or has appeared in a data breach"}},
+		{"breached in another case", "pASSWORD1!", nil, []string{"Is too common or has appeared in a data breach"}},
+		{"breached entries are trimmed", "Qwerty-2024", nil, []string{"Is too common or has appeared in a data breach"}},
+		{"comments are not entries", "# common passwords", nil, []string{"Must be at most 16 characters", "Must contain an uppercase letter", "Must contain a digit"}},
+		{"every broken rule", "abc", []string{"abc@example.com"}, []string{
+			"Must be at least 8 characters",
+			"Must contain an uppercase letter",
+			"Must contain a digit",
+			"Must contain a symbol",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.Check(tt.password, tt.userInputs...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Check(%q) = %q, want %q", tt.password, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolicyCheckWithoutOptionalRules(t *testing.T) {
+	policy, err := NewPolicy(config.PasswordPolicyConfig{MinLength: 8})
+	if err != nil {
+		t.Fatalf("NewPolicy failed: %v", err)
+	}
+
+	// Without a maximum or character classes only the length counts
+	if got := policy.Check("aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"); got != nil {
+		t.Errorf("Check() = %q, want nothing", got)
+	}
+}
+
+func TestNewPolicyMissingBreachedList(t *testing.T) {
+	if _, err := NewPolicy(config.PasswordPolicyConfig{BreachedListFile: filepath.Join(t.TempDir(), "missing.txt")}); err == nil {
+		t.Error("NewPolicy accepted a breached list that does not exist")
+	}
+}
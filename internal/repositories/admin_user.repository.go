@@ -0,0 +1,517 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+	"todo-api/config"
+	"todo-api/database"
+	"todo-api/internal/dtos"
+	"todo-api/internal/models"
+	"todo-api/internal/password"
+	"todo-api/internal/utils"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Page sizes of the admin user list and the security event log
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// normalizePage applies the defaults and the upper limit to requested page parameters
+func normalizePage(page int, pageSize int) (int, int) {
+	if pageSize < 1 {
+		pageSize = defaultPageSize
+	}
+	return max(page, 1), min(pageSize, maxPageSize)
+}
+
+// AdminUserRepository holds the account management operations of administrators
+type AdminUserRepository struct {
+	DB             *gorm.DB
+	Logger         *zap.Logger
+	revocations    *RevocationRepository
+	passwordResets *PasswordResetRepository
+	securityEvents *SecurityEventRepository
+	auth           *AuthRepository
+}
+
+func NewAdminUserRepository(logger *zap.Logger) *AdminUserRepository {
+	return &AdminUserRepository{
+		DB:             database.GetDB(),
+		Logger:         logger,
+		revocations:    NewRevocationRepository(logger),
+		passwordResets: NewPasswordResetRepository(logger),
+		securityEvents: NewSecurityEventRepository(logger),
+		auth:           NewAuthRepository(logger),
+	}
+}
+
+// ListUsers returns a page of users ordered by ID, optionally filtered by email or name
+func (r *AdminUserRepository) ListUsers(ctx context.Context, listUsersDto dtos.ListUsersDto) (dtos.StructuredResponse, error) {
+	page, pageSize := normalizePage(listUsersDto.Page, listUsersDto.PageSize)
+
+	filtered := func() *gorm.DB {
+		query := r.DB.Model(&models.User{})
+		if search := strings.TrimSpace(listUsersDto.Search); search != "" {
+			pattern := "%" + escapeLikePattern(strings.ToLower(search)) + "%"
+			query = query.Where("(LOWER(email) LIKE ? OR LOWER(name) LIKE ?)", pattern, pattern)
+		}
+		return query
+	}
+
+	var total int64
+
+	if err := filtered().Count(&total).Error; err != nil {
+		r.Logger.Error("Failed to count users", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to retrieve users",
+			Payload: nil,
+		}, err
+	}
+
+	var users []models.User
+
+	if err := filtered().Preload("Roles").Order("id").
+		Limit(pageSize).Offset((page - 1) * pageSize).
+		Find(&users).Error; err != nil {
+		r.Logger.Error("Failed to retrieve users", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to retrieve users",
+			Payload: nil,
+		}, err
+	}
+
+	userDtos := make([]dtos.AdminUserDto, 0, len(users))
+	for _, user := range users {
+		userDtos = append(userDtos, toAdminUserDto(user))
+	}
+
+	return dtos.StructuredResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Users retrieved successfully",
+		Payload: dtos.UserListDto{
+			Users:    userDtos,
+			Page:     page,
+			PageSize: pageSize,
+			Total:    total,
+		},
+	}, nil
+}
+
+// GetUser returns a user together with the number of their todo items
+func (r *AdminUserRepository) GetUser(ctx context.Context, userID uint) (dtos.StructuredResponse, error) {
+	var user models.User
+
+	if err := r.DB.Preload("Roles").First(&user, userID).Error; err != nil {
+		return r.userLookupFailed(err, "Failed to retrieve user")
+	}
+
+	var todoCounts dtos.TodoCountsDto
+
+	if err := r.DB.Model(&models.TodoItem{}).
+		Select(`COUNT(*) AS total, COUNT(*) FILTER (WHERE "isCompleted") AS completed`).
+		Where("user_id = ?", user.ID).
+		Scan(&todoCounts).Error; err != nil {
+		r.Logger.Error("Failed to count todo items", zap.Uint("userId", user.ID), zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to retrieve user",
+			Payload: nil,
+		}, err
+	}
+	todoCounts.Open = todoCounts.Total - todoCounts.Completed
+
+	userDto := toAdminUserDto(user)
+	userDto.Todos = &todoCounts
+
+	return dtos.StructuredResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "User retrieved successfully",
+		Payload: userDto,
+	}, nil
+}
+
+// CreateUser creates an account with the user role whatever the registration mode. Without a
+// password the user is emailed a link to choose one, which also verifies their address.
+func (r *AdminUserRepository) CreateUser(ctx context.Context, createUserDto dtos.CreateUserDto) (dtos.StructuredResponse, error) {
+	email := utils.NormalizeEmail(createUserDto.Email)
+	name := strings.TrimSpace(createUserDto.Name)
+
+	user := models.User{
+		Email: email,
+		Name:  name,
+	}
+
+	if createUserDto.Password != "" {
+		policy, err := password.GetPolicy()
+		if err != nil {
+			r.Logger.Error("Failed to load password policy", zap.Error(err))
+			return dtos.StructuredResponse{
+				Success: false,
+				Status:  http.StatusInternalServerError,
+				Message: "Failed to create user",
+				Payload: nil,
+			}, err
+		}
+
+		if problems := policy.Check(createUserDto.Password, email, name); len(problems) > 0 {
+			return passwordPolicyResponse("password", problems), nil
+		}
+
+		user.PasswordHash, err = r.auth.passwords.Hash(createUserDto.Password)
+		if err != nil {
+			r.Logger.Error("Failed to hash password", zap.Error(err))
+			return dtos.StructuredResponse{
+				Success: false,
+				Status:  http.StatusInternalServerError,
+				Message: "Failed to create user",
+				Payload: nil,
+			}, err
+		}
+	}
+
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		var defaultRole models.Role
+		if err := tx.Where("name = ?", models.RoleUser).First(&defaultRole).Error; err != nil {
+			return err
+		}
+
+		user.Roles = []models.Role{defaultRole}
+
+		return tx.Omit("Roles.*").Create(&user).Error
+	})
+
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return emailTakenResponse(), nil
+	}
+
+	if err != nil {
+		r.Logger.Error("Failed to create user", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to create user",
+			Payload: nil,
+		}, err
+	}
+
+	r.Logger.Info("User created by administrator", zap.Uint("userId", user.ID), zap.Uint("actorId", createUserDto.ActorID))
+	r.recordAction(ctx, dtos.AdminUserActionDto{UserID: user.ID, ActorID: createUserDto.ActorID}, models.SecurityEventAccountCreated, "Created by an administrator")
+
+	// The account exists either way, a failed email can be repeated with a password reset
+	if user.PasswordHash == "" {
+		if err := r.passwordResets.sendResetLink(ctx, user,
+			"An administrator has created an account for you.",
+			"Once it has expired, you can ask for a new link on the login page with \"Forgot password\".",
+		); err != nil {
+			r.Logger.Error("Failed to send password link to new user", zap.Uint("userId", user.ID), zap.Error(err))
+		}
+	} else if err := r.auth.emailVerification.SendVerificationEmail(user); err != nil {
+		r.Logger.Error("Failed to send verification email", zap.Uint("userId", user.ID), zap.Error(err))
+	}
+
+	return dtos.StructuredResponse{
+		Success: true,
+		Status:  http.StatusCreated,
+		Message: "User created successfully",
+		Payload: toAdminUserDto(user),
+	}, nil
+}
+
+// DisableUser blocks the account: it is logged out everywhere, cannot log in and its personal
+// access tokens stop working until it is enabled again. Disabling an account twice is harmless.
+func (r *AdminUserRepository) DisableUser(ctx context.Context, actionDto dtos.AdminUserActionDto) (dtos.StructuredResponse, error) {
+	// Administrators would lock themselves out of the admin API
+	if actionDto.UserID == actionDto.ActorID {
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusConflict,
+			Message: "You cannot disable your own account",
+			Payload: nil,
+		}, nil
+	}
+
+	var user models.User
+
+	if err := r.DB.Preload("Roles").First(&user, actionDto.UserID).Error; err != nil {
+		return r.userLookupFailed(err, "Failed to disable user")
+	}
+
+	if user.DisabledAt == nil {
+		now := time.Now()
+		if err := r.DB.Model(&models.User{}).
+			Where(`id = ? AND "disabledAt" IS NULL`, user.ID).
+			Update("disabledAt", now).Error; err != nil {
+			r.Logger.Error("Failed to disable user", zap.Uint("userId", user.ID), zap.Error(err))
+			return dtos.StructuredResponse{
+				Success: false,
+				Status:  http.StatusInternalServerError,
+				Message: "Failed to disable user",
+				Payload: nil,
+			}, err
+		}
+		user.DisabledAt = &now
+	}
+
+	// Tokens that are already out would otherwise keep working until they expire. Personal
+	// access tokens and OAuth tokens are refused while the account is disabled and work again
+	// once it is enabled.
+	if err := r.revocations.RevokeLoginTokensForUser(ctx, user.ID); err != nil {
+		r.Logger.Error("Failed to revoke tokens of disabled user", zap.Uint("userId", user.ID), zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to disable user",
+			Payload: nil,
+		}, err
+	}
+
+	r.Logger.Warn("User disabled by administrator", zap.Uint("userId", user.ID), zap.Uint("actorId", actionDto.ActorID))
+	r.recordAction(ctx, actionDto, models.SecurityEventAccountDisabled, "Disabled by an administrator")
+
+	return dtos.StructuredResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "User disabled successfully",
+		Payload: toAdminUserDto(user),
+	}, nil
+}
+
+// EnableUser lifts a DisableUser. The user has to log in again, their personal access tokens work again.
+func (r *AdminUserRepository) EnableUser(ctx context.Context, actionDto dtos.AdminUserActionDto) (dtos.StructuredResponse, error) {
+	var user models.User
+
+	if err := r.DB.Preload("Roles").First(&user, actionDto.UserID).Error; err != nil {
+		return r.userLookupFailed(err, "Failed to enable user")
+	}
+
+	if err := r.DB.Model(&models.User{}).Where("id = ?", user.ID).Update("disabledAt", nil).Error; err != nil {
+		r.Logger.Error("Failed to enable user", zap.Uint("userId", user.ID), zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to enable user",
+			Payload: nil,
+		}, err
+	}
+	user.DisabledAt = nil
+
+	r.Logger.Info("User enabled by administrator", zap.Uint("userId", user.ID), zap.Uint("actorId", actionDto.ActorID))
+	r.recordAction(ctx, actionDto, models.SecurityEventAccountEnabled, "Enabled by an administrator")
+
+	return dtos.StructuredResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "User enabled successfully",
+		Payload: toAdminUserDto(user),
+	}, nil
+}
+
+// ResetPassword removes the user's password, logs them out everywhere and emails them a link
+// to choose a new one. Meant for accounts whose password may be known to someone else.
+func (r *AdminUserRepository) ResetPassword(ctx context.Context, actionDto dtos.AdminUserActionDto) (dtos.StructuredResponse, error) {
+	var user models.User
+
+	if err := r.DB.First(&user, actionDto.UserID).Error; err != nil {
+		return r.userLookupFailed(err, "Failed to reset password")
+	}
+
+	// An empty hash never matches, the account can only log in again through the link or single sign-on
+	if err := r.DB.Model(&models.User{}).Where("id = ?", user.ID).Update("passwordHash", "").Error; err != nil {
+		r.Logger.Error("Failed to clear password", zap.Uint("userId", user.ID), zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to reset password",
+			Payload: nil,
+		}, err
+	}
+
+	if err := r.revocations.RevokeAllForUser(ctx, user.ID); err != nil {
+		r.Logger.Error("Failed to revoke tokens after password reset", zap.Uint("userId", user.ID), zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to reset password",
+			Payload: nil,
+		}, err
+	}
+
+	if err := r.passwordResets.sendResetLink(ctx, user,
+		"An administrator has reset your password and logged you out, your old password no longer works.",
+		"If you did not expect this, please contact your administrator.",
+	); err != nil {
+		r.Logger.Error("Failed to create password reset link", zap.Uint("userId", user.ID), zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Password was reset but the link could not be sent",
+			Payload: nil,
+		}, err
+	}
+
+	r.Logger.Warn("Password reset by administrator", zap.Uint("userId", user.ID), zap.Uint("actorId", actionDto.ActorID))
+	r.recordAction(ctx, actionDto, models.SecurityEventPasswordReset, "Password removed by an administrator")
+
+	return dtos.StructuredResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Password reset, the user has been emailed a link to choose a new one",
+		Payload: nil,
+	}, nil
+}
+
+// ForceLogout ends every session of the user and invalidates all of their access tokens
+func (r *AdminUserRepository) ForceLogout(ctx context.Context, actionDto dtos.AdminUserActionDto) (dtos.StructuredResponse, error) {
+	var user models.User
+
+	if err := r.DB.First(&user, actionDto.UserID).Error; err != nil {
+		return r.userLookupFailed(err, "Failed to log out user")
+	}
+
+	if err := r.revocations.RevokeAllForUser(ctx, user.ID); err != nil {
+		r.Logger.Error("Failed to revoke tokens", zap.Uint("userId", user.ID), zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to log out user",
+			Payload: nil,
+		}, err
+	}
+
+	r.Logger.Warn("User logged out by administrator", zap.Uint("userId", user.ID), zap.Uint("actorId", actionDto.ActorID))
+	r.recordAction(ctx, actionDto, models.SecurityEventTokenRevoked, "Logged out from all devices by an administrator")
+
+	return dtos.StructuredResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "User logged out from all devices",
+		Payload: nil,
+	}, nil
+}
+
+// Impersonate issues a short-lived token to act as the user. The token names the administrator
+// in its act claim, so every request made with it is logged with both identities. Administrators
+// cannot be impersonated, which keeps the feature from being a way to borrow another admin's rights.
+func (r *AdminUserRepository) Impersonate(ctx context.Context, actionDto dtos.AdminUserActionDto) (dtos.StructuredResponse, error) {
+	if actionDto.UserID == actionDto.ActorID {
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusBadRequest,
+			Message: "You cannot impersonate yourself",
+			Payload: nil,
+		}, nil
+	}
+
+	var user models.User
+
+	if err := r.DB.Preload("Roles.Permissions").First(&user, actionDto.UserID).Error; err != nil {
+		return r.userLookupFailed(err, "Failed to impersonate user")
+	}
+
+	if user.HasRole(models.RoleAdmin) {
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusForbidden,
+			Message: "Administrators cannot be impersonated",
+			Payload: nil,
+		}, nil
+	}
+
+	if user.DisabledAt != nil {
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusConflict,
+			Message: "Account has been disabled",
+			Payload: nil,
+		}, nil
+	}
+
+	token, err := utils.GenerateImpersonationToken(user, utils.ActorClaim{
+		UserID: actionDto.ActorID,
+		Email:  actionDto.ActorEmail,
+	})
+	if err != nil {
+		r.Logger.Error("Failed to generate impersonation token", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to impersonate user",
+			Payload: nil,
+		}, err
+	}
+
+	r.Logger.Warn("Impersonation started", zap.Uint("userId", user.ID), zap.Uint("actorId", actionDto.ActorID), zap.String("actorEmail", actionDto.ActorEmail))
+	r.recordAction(ctx, actionDto, models.SecurityEventImpersonationStarted, "Impersonated by "+actionDto.ActorEmail)
+
+	return dtos.StructuredResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Impersonation token issued",
+		Payload: dtos.ImpersonationTokenDto{
+			UserID:    user.ID,
+			Email:     user.Email,
+			Token:     token,
+			ExpiresIn: int64(config.GetConfig().Auth.ImpersonationTTL.Seconds()),
+		},
+	}, nil
+}
+
+// recordAction adds an administrator's action to the security events of the affected user
+func (r *AdminUserRepository) recordAction(ctx context.Context, actionDto dtos.AdminUserActionDto, eventType string, details string) {
+	r.securityEvents.Record(ctx, models.SecurityEvent{
+		UserID:  actionDto.UserID,
+		Type:    eventType,
+		Details: details,
+		ActorID: &actionDto.ActorID,
+	})
+}
+
+func (r *AdminUserRepository) userLookupFailed(err error, message string) (dtos.StructuredResponse, error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusNotFound,
+			Message: "User not found",
+			Payload: nil,
+		}, nil
+	}
+
+	r.Logger.Error("Failed to find user", zap.Error(err))
+	return dtos.StructuredResponse{
+		Success: false,
+		Status:  http.StatusInternalServerError,
+		Message: message,
+		Payload: nil,
+	}, err
+}
+
+func toAdminUserDto(user models.User) dtos.AdminUserDto {
+	return dtos.AdminUserDto{
+		ID:               user.ID,
+		Email:            user.Email,
+		Name:             user.Name,
+		EmailVerifiedAt:  user.EmailVerifiedAt,
+		TwoFactorEnabled: user.TwoFactorEnabledAt != nil,
+		DisabledAt:       user.DisabledAt,
+		Roles:            user.RoleNames(),
+	}
+}
+
+// escapeLikePattern makes user input match literally inside a LIKE pattern
+func escapeLikePattern(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
@@ -0,0 +1,205 @@
+package repositories
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+	"todo-api/internal/dtos"
+	"todo-api/internal/models"
+	"todo-api/internal/utils"
+
+	"go.uber.org/zap"
+)
+
+func TestEscapeLikePattern(t *testing.T) {
+	tests := map[string]string{
+		"alice":      "alice",
+		"100%":       `100\%`,
+		"first_last": `first\_last`,
+		`back\slash`: `back\\slash`,
+		`%_\`:        `\%\_\\`,
+	}
+
+	for value, want := range tests {
+		if got := escapeLikePattern(value); got != want {
+			t.Errorf("escapeLikePattern(%q) = %q, want %q", value, got, want)
+		}
+	}
+}
+
+func TestNormalizePage(t *testing.T) {
+	tests := []struct {
+		page, pageSize         int
+		wantPage, wantPageSize int
+	}{
+		{0, 0, 1, defaultPageSize},
+		{-3, -1, 1, defaultPageSize},
+		{2, 50, 2, 50},
+		{5, maxPageSize + 1, 5, maxPageSize},
+	}
+
+	for _, tt := range tests {
+		if page, pageSize := normalizePage(tt.page, tt.pageSize); page != tt.wantPage || pageSize != tt.wantPageSize {
+			t.Errorf("normalizePage(%d, %d) = %d, %d, want %d, %d", tt.page, tt.pageSize, page, pageSize, tt.wantPage, tt.wantPageSize)
+		}
+	}
+}
+
+func TestAdminUserRepositoryRefusesOwnAccount(t *testing.T) {
+	// Actions on the administrator's own account are refused before any lookup, so no database is needed
+	repo := &AdminUserRepository{Logger: zap.NewNop()}
+	self := dtos.AdminUserActionDto{UserID: 1, ActorID: 1}
+
+	response, err := repo.DisableUser(context.Background(), self)
+	if err != nil || response.Status != http.StatusConflict {
+		t.Errorf("DisableUser(self) = %d, %v, want 409", response.Status, err)
+	}
+
+	response, err = repo.Impersonate(context.Background(), self)
+	if err != nil || response.Status != http.StatusBadRequest {
+		t.Errorf("Impersonate(self) = %d, %v, want 400", response.Status, err)
+	}
+}
+
+func TestAdminUserRepositoryDisableUser(t *testing.T) {
+	db := openTestDB(t)
+	auth := NewAuthRepository(zap.NewNop())
+	repo := NewAdminUserRepository(zap.NewNop())
+	repo.revocations = newTestRevocationRepository(t)
+	ctx := context.Background()
+
+	admin := createTestAccount(t, db, "admin")
+	user := createTestAccount(t, db, "alice")
+
+	login, err := auth.startSession(db, user, "test", "127.0.0.1")
+	if err != nil {
+		t.Fatalf("startSession failed: %v", err)
+	}
+	accessToken := createTestPersonalAccessToken(t, db, user)
+
+	action := dtos.AdminUserActionDto{UserID: user.ID, ActorID: admin.ID}
+
+	response, err := repo.DisableUser(ctx, action)
+	if err != nil || response.Status != http.StatusOK {
+		t.Fatalf("DisableUser = %d %s, %v, want 200", response.Status, response.Message, err)
+	}
+	if disabled, ok := response.Payload.(dtos.AdminUserDto); !ok || disabled.DisabledAt == nil {
+		t.Errorf("payload = %#v, want a disabled user", response.Payload)
+	}
+
+	// The refresh token handed out before is no longer accepted
+	response, err = auth.RefreshToken(ctx, dtos.RefreshTokenDto{RefreshToken: login.RefreshToken, UserAgent: "test", IPAddress: "127.0.0.1"})
+	if err != nil {
+		t.Fatalf("RefreshToken failed: %v", err)
+	}
+	if response.Status == http.StatusOK {
+		t.Error("refresh of a disabled account succeeded")
+	}
+
+	// The personal access token is only refused while the account is disabled
+	if personalAccessTokenRevoked(t, db, accessToken) {
+		t.Error("personal access token was revoked")
+	}
+
+	// Disabling twice is harmless
+	if response, err := repo.DisableUser(ctx, action); err != nil || response.Status != http.StatusOK {
+		t.Errorf("second DisableUser = %d, %v, want 200", response.Status, err)
+	}
+
+	if response, err := repo.EnableUser(ctx, action); err != nil || response.Status != http.StatusOK {
+		t.Fatalf("EnableUser = %d, %v, want 200", response.Status, err)
+	}
+
+	var stored models.User
+	if err := db.First(&stored, user.ID).Error; err != nil {
+		t.Fatalf("failed to load user: %v", err)
+	}
+	if stored.DisabledAt != nil {
+		t.Error("user is still disabled after EnableUser")
+	}
+}
+
+func TestAdminUserRepositoryForceLogout(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewAdminUserRepository(zap.NewNop())
+	repo.revocations = newTestRevocationRepository(t)
+	ctx := context.Background()
+
+	admin := createTestAccount(t, db, "admin")
+	user := createTestAccount(t, db, "alice")
+
+	login, err := NewAuthRepository(zap.NewNop()).startSession(db, user, "test", "127.0.0.1")
+	if err != nil {
+		t.Fatalf("startSession failed: %v", err)
+	}
+
+	response, err := repo.ForceLogout(ctx, dtos.AdminUserActionDto{UserID: user.ID, ActorID: admin.ID})
+	if err != nil || response.Status != http.StatusOK {
+		t.Fatalf("ForceLogout = %d, %v, want 200", response.Status, err)
+	}
+
+	var session models.Session
+	if err := db.First(&session, sessionIDOf(t, login)).Error; err != nil {
+		t.Fatalf("failed to load session: %v", err)
+	}
+	if session.RevokedAt == nil {
+		t.Error("session was not revoked")
+	}
+
+	response, err = repo.ForceLogout(ctx, dtos.AdminUserActionDto{UserID: user.ID + 1_000_000, ActorID: admin.ID})
+	if err != nil || response.Status != http.StatusNotFound {
+		t.Errorf("ForceLogout of an unknown user = %d, %v, want 404", response.Status, err)
+	}
+}
+
+func TestAdminUserRepositoryImpersonate(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewAdminUserRepository(zap.NewNop())
+	ctx := context.Background()
+
+	admin := createTestAccount(t, db, "admin")
+	assignTestRoles(t, db, admin, models.RoleUser, models.RoleAdmin)
+	otherAdmin := createTestAccount(t, db, "other-admin")
+	assignTestRoles(t, db, otherAdmin, models.RoleUser, models.RoleAdmin)
+	member := createTestAccount(t, db, "member")
+	assignTestRoles(t, db, member, models.RoleUser)
+
+	impersonate := func(t *testing.T, userID uint) dtos.StructuredResponse {
+		t.Helper()
+
+		response, err := repo.Impersonate(ctx, dtos.AdminUserActionDto{UserID: userID, ActorID: admin.ID, ActorEmail: admin.Email})
+		if err != nil {
+			t.Fatalf("Impersonate failed: %v", err)
+		}
+		return response
+	}
+
+	if response := impersonate(t, otherAdmin.ID); response.Status != http.StatusForbidden {
+		t.Errorf("impersonating an administrator = %d, want 403", response.Status)
+	}
+
+	response := impersonate(t, member.ID)
+	if response.Status != http.StatusOK {
+		t.Fatalf("impersonating a member = %d %s, want 200", response.Status, response.Message)
+	}
+
+	// The token acts as the member and names the administrator
+	claims, err := utils.ValidateToken(response.Payload.(dtos.ImpersonationTokenDto).Token)
+	if err != nil {
+		t.Fatalf("ValidateToken failed: %v", err)
+	}
+	if claims.UserID != member.ID || claims.TokenType != utils.TokenTypeImpersonation {
+		t.Errorf("claims = user %d type %q, want user %d type %q", claims.UserID, claims.TokenType, member.ID, utils.TokenTypeImpersonation)
+	}
+	if claims.Actor == nil || claims.Actor.UserID != admin.ID || claims.Actor.Email != admin.Email {
+		t.Errorf("actor = %+v, want administrator %d", claims.Actor, admin.ID)
+	}
+
+	if err := db.Model(&member).Update("disabledAt", time.Now()).Error; err != nil {
+		t.Fatalf("failed to disable member: %v", err)
+	}
+	if response := impersonate(t, member.ID); response.Status != http.StatusConflict {
+		t.Errorf("impersonating a disabled account = %d, want 409", response.Status)
+	}
+}
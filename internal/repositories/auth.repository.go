@@ -3,29 +3,92 @@ package repositories
 import (
 	"context"
 	"errors"
+	"fmt"
+	"math"
 	"net/http"
+	"strings"
+	"time"
+	"todo-api/config"
 	"todo-api/database"
 	"todo-api/internal/dtos"
+	"todo-api/internal/jobs"
+	"todo-api/internal/mailer"
 	"todo-api/internal/models"
+	"todo-api/internal/password"
 	"todo-api/internal/utils"
 
 	"go.uber.org/zap"
-	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
 type AuthRepository struct {
-	DB     *gorm.DB
-	Logger *zap.Logger
+	DB                *gorm.DB
+	Logger            *zap.Logger
+	mailer            mailer.Mailer
+	background        *jobs.Queue
+	revocations       *RevocationRepository
+	emailVerification *EmailVerificationRepository
+	loginAttempts     *LoginAttemptRepository
+	sessions          *SessionRepository
+	securityEvents    *SecurityEventRepository
+	passwords         *password.Manager
 }
 
 func NewAuthRepository(logger *zap.Logger) *AuthRepository {
-	return &AuthRepository{DB: database.GetDB(), Logger: logger}
+	return &AuthRepository{
+		DB:                database.GetDB(),
+		Logger:            logger,
+		mailer:            mailer.New(config.GetConfig().Mail, logger),
+		background:        jobs.Default(),
+		revocations:       NewRevocationRepository(logger),
+		emailVerification: NewEmailVerificationRepository(logger),
+		loginAttempts:     NewLoginAttemptRepository(logger),
+		sessions:          NewSessionRepository(logger),
+		securityEvents:    NewSecurityEventRepository(logger),
+		passwords:         password.New(config.GetConfig().PasswordHashing),
+	}
 }
 
 func (r *AuthRepository) RegisterUser(ctx context.Context, registerUserDto dtos.RegisterUserDto) (dtos.StructuredResponse, error) {
+	registerUserDto.Email = utils.NormalizeEmail(registerUserDto.Email)
+	registerUserDto.Name = strings.TrimSpace(registerUserDto.Name)
+	registerUserDto.InviteCode = strings.TrimSpace(registerUserDto.InviteCode)
+
+	if refusal := registrationRefusal(registerUserDto.Email, registerUserDto.InviteCode != ""); refusal != "" {
+		return registrationRefusedResponse(refusal), nil
+	}
+
+	policy, err := password.GetPolicy()
+	if err != nil {
+		r.Logger.Error("Failed to load password policy", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to register user",
+			Payload: nil,
+		}, err
+	}
+
+	if problems := policy.Check(registerUserDto.Password, registerUserDto.Email, registerUserDto.Name); len(problems) > 0 {
+		return passwordPolicyResponse("password", problems), nil
+	}
+
+	var existingCount int64
+	if err := r.DB.Model(&models.User{}).Where("email = ?", registerUserDto.Email).Count(&existingCount).Error; err != nil {
+		r.Logger.Error("Failed to check email availability", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to register user",
+			Payload: nil,
+		}, err
+	}
 
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(registerUserDto.Password), bcrypt.DefaultCost)
+	if existingCount > 0 {
+		return emailTakenResponse(), nil
+	}
+
+	hashedPassword, err := r.passwords.Hash(registerUserDto.Password)
 
 	if err != nil {
 		r.Logger.Error("Failed to hash password", zap.Error(err))
@@ -40,10 +103,38 @@ func (r *AuthRepository) RegisterUser(ctx context.Context, registerUserDto dtos.
 	user := models.User{
 		Email:        registerUserDto.Email,
 		Name:         registerUserDto.Name,
-		PasswordHash: string(hashedPassword),
+		PasswordHash: hashedPassword,
+	}
+
+	err = r.DB.Transaction(func(tx *gorm.DB) error {
+		// Codes are only needed, and only used up, in invite mode
+		if config.GetConfig().Registration.Mode == config.RegistrationInvite {
+			if err := consumeInviteCode(tx, registerUserDto.InviteCode); err != nil {
+				return err
+			}
+		}
+
+		var defaultRole models.Role
+		if err := tx.Where("name = ?", models.RoleUser).First(&defaultRole).Error; err != nil {
+			return err
+		}
+
+		user.Roles = []models.Role{defaultRole}
+
+		// Omit the role upsert, only the link in UserRoles is created
+		return tx.Omit("Roles.*").Create(&user).Error
+	})
+
+	if errors.Is(err, errInvalidInviteCode) {
+		return registrationRefusedResponse("Invalid, expired or used up invite code"), nil
+	}
+
+	// Another registration may have taken the address since the check above
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return emailTakenResponse(), nil
 	}
 
-	if err := r.DB.Create(&user).Error; err != nil {
+	if err != nil {
 		return dtos.StructuredResponse{
 			Success: false,
 			Status:  http.StatusInternalServerError,
@@ -52,31 +143,47 @@ func (r *AuthRepository) RegisterUser(ctx context.Context, registerUserDto dtos.
 		}, err
 	}
 
+	// Registration succeeded even if the email cannot be sent, the user can ask for a new link
+	if err := r.emailVerification.SendVerificationEmail(user); err != nil {
+		r.Logger.Error("Failed to send verification email", zap.Uint("userId", user.ID), zap.Error(err))
+	}
+
 	return dtos.StructuredResponse{
 		Success: true,
 		Status:  http.StatusCreated,
-		Message: "User registered successfully",
+		Message: "User registered successfully, please check your email to verify your account",
 		Payload: map[string]interface{}{
-			"id":    user.ID,
-			"email": user.Email,
-			"name":  user.Name,
+			"id":            user.ID,
+			"email":         user.Email,
+			"name":          user.Name,
+			"emailVerified": false,
 		},
 	}, nil
 }
 
 func (r *AuthRepository) LoginUser(ctx context.Context, loginUserDto dtos.LoginUserDto) (dtos.StructuredResponse, error) {
+	loginUserDto.Email = utils.NormalizeEmail(loginUserDto.Email)
+
+	// Refuse throttled emails and IPs before spending any time on password hashing
+	retryAfter, err := r.loginAttempts.RetryAfter(ctx, loginUserDto.Email, loginUserDto.IPAddress)
+	if err != nil {
+		r.Logger.Error("Failed to check login attempts", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to login",
+			Payload: nil,
+		}, err
+	}
+	if retryAfter > 0 {
+		return tooManyAttemptsResponse(retryAfter), nil
+	}
+
 	var user models.User
 
 	// Find the user by email
-	if err := r.DB.Where("email = ?", loginUserDto.Email).First(&user).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return dtos.StructuredResponse{
-				Success: false,
-				Status:  http.StatusUnauthorized,
-				Message: "Invalid email or password",
-				Payload: nil,
-			}, nil
-		}
+	err = r.DB.Where("email = ?", loginUserDto.Email).First(&user).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 		r.Logger.Error("Failed to find user", zap.Error(err))
 		return dtos.StructuredResponse{
 			Success: false,
@@ -87,20 +194,76 @@ func (r *AuthRepository) LoginUser(ctx context.Context, loginUserDto dtos.LoginU
 	}
 
 	// Compare the provided password with the stored hash
-	err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(loginUserDto.Password))
 	if err != nil {
+		return r.failedLogin(ctx, loginUserDto, user)
+	}
+
+	matches, needsRehash, err := r.passwords.Verify(loginUserDto.Password, user.PasswordHash)
+	if err != nil {
+		r.Logger.Warn("Failed to verify password hash", zap.Uint("userId", user.ID), zap.Error(err))
+	}
+	if !matches {
+		return r.failedLogin(ctx, loginUserDto, user)
+	}
+
+	// Upgrade legacy or weaker hashes while the plain password is at hand, a failure only delays the upgrade
+	if needsRehash {
+		r.rehashPassword(user, loginUserDto.Password)
+	}
+
+	if err := r.loginAttempts.RecordSuccess(ctx, loginUserDto.Email); err != nil {
+		r.Logger.Warn("Failed to reset login attempts", zap.Error(err))
+	}
+
+	if user.EmailVerifiedAt == nil && config.GetConfig().Auth.EmailVerificationMode == config.EmailVerificationBlock {
+		r.recordLogin(ctx, user, models.SecurityEventLoginFailed, "Email address not verified", loginUserDto.UserAgent, loginUserDto.IPAddress)
 		return dtos.StructuredResponse{
 			Success: false,
-			Status:  http.StatusUnauthorized,
-			Message: "Invalid email or password",
+			Status:  http.StatusForbidden,
+			Message: "Email address has not been verified",
 			Payload: nil,
 		}, nil
 	}
 
-	// Generate JWT token
-	token, err := utils.GenerateToken(user)
+	return r.finishLogin(ctx, user, "Password", loginUserDto.UserAgent, loginUserDto.IPAddress)
+}
+
+// finishLogin completes a login whose first factor was accepted: users with two-factor
+// authentication get a challenge, everyone else a new session and its tokens. method names
+// the first factor in the security event log.
+func (r *AuthRepository) finishLogin(ctx context.Context, user models.User, method string, userAgent string, ipAddress string) (dtos.StructuredResponse, error) {
+	if user.DisabledAt != nil {
+		r.recordLogin(ctx, user, models.SecurityEventLoginFailed, "Account disabled", userAgent, ipAddress)
+		return accountDisabledResponse(), nil
+	}
+
+	// The first factor alone is not enough, the client finishes the login at /auth/login/2fa
+	if user.TwoFactorEnabledAt != nil {
+		challengeToken, err := utils.GenerateActionToken(utils.PurposeTwoFactorChallenge, user, config.GetConfig().Auth.TwoFactorChallengeTTL)
+		if err != nil {
+			r.Logger.Error("Failed to generate two-factor challenge", zap.Error(err))
+			return dtos.StructuredResponse{
+				Success: false,
+				Status:  http.StatusInternalServerError,
+				Message: "Failed to login",
+				Payload: nil,
+			}, err
+		}
+
+		return dtos.StructuredResponse{
+			Success: true,
+			Status:  http.StatusOK,
+			Message: "Two-factor authentication required",
+			Payload: dtos.TwoFactorChallengeDto{
+				TwoFactorRequired: true,
+				ChallengeToken:    challengeToken,
+			},
+		}, nil
+	}
+
+	tokens, err := r.startSession(r.DB, user, userAgent, ipAddress)
 	if err != nil {
-		r.Logger.Error("Failed to generate token", zap.Error(err))
+		r.Logger.Error("Failed to issue tokens", zap.Error(err))
 		return dtos.StructuredResponse{
 			Success: false,
 			Status:  http.StatusInternalServerError,
@@ -109,15 +272,470 @@ func (r *AuthRepository) LoginUser(ctx context.Context, loginUserDto dtos.LoginU
 		}, err
 	}
 
+	r.recordLogin(ctx, user, models.SecurityEventLoginSucceeded, method, userAgent, ipAddress)
+
 	return dtos.StructuredResponse{
 		Success: true,
 		Status:  http.StatusOK,
 		Message: "Login successful",
-		Payload: map[string]interface{}{
-			"id":    user.ID,
-			"email": user.Email,
-			"name":  user.Name,
-			"token": token,
+		Payload: tokens,
+	}, nil
+}
+
+// recordLogin adds a login attempt to the user's security events. Login requests are not
+// authenticated, so the client is passed in rather than read from the context.
+func (r *AuthRepository) recordLogin(ctx context.Context, user models.User, eventType string, details string, userAgent string, ipAddress string) {
+	r.securityEvents.Record(ctx, models.SecurityEvent{
+		UserID:    user.ID,
+		Type:      eventType,
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+		Details:   details,
+	})
+}
+
+// rehashPassword replaces the stored hash with one from the preferred algorithm and parameters.
+// The update is conditional on the old hash so a concurrent password change is never overwritten.
+func (r *AuthRepository) rehashPassword(user models.User, plainPassword string) {
+	hashedPassword, err := r.passwords.Hash(plainPassword)
+	if err != nil {
+		r.Logger.Warn("Failed to rehash password", zap.Uint("userId", user.ID), zap.Error(err))
+		return
+	}
+
+	if err := r.DB.Model(&models.User{}).
+		Where(`id = ? AND "passwordHash" = ?`, user.ID, user.PasswordHash).
+		Update("passwordHash", hashedPassword).Error; err != nil {
+		r.Logger.Warn("Failed to store rehashed password", zap.Uint("userId", user.ID), zap.Error(err))
+		return
+	}
+
+	r.Logger.Info("Upgraded password hash", zap.Uint("userId", user.ID))
+}
+
+// failedLogin records a failed attempt and notifies the owner when it locks their account.
+// user is the zero value when no account matches the email.
+func (r *AuthRepository) failedLogin(ctx context.Context, loginUserDto dtos.LoginUserDto, user models.User) (dtos.StructuredResponse, error) {
+	accountLocked, err := r.loginAttempts.RecordFailure(ctx, loginUserDto.Email, loginUserDto.IPAddress)
+	if err != nil {
+		r.Logger.Error("Failed to record login attempt", zap.Error(err))
+	}
+
+	// Attempts against unknown emails have no account to log them to
+	if user.ID != 0 {
+		details := "Wrong password"
+		if accountLocked {
+			details = "Wrong password, account locked"
+		}
+		r.recordLogin(ctx, user, models.SecurityEventLoginFailed, details, loginUserDto.UserAgent, loginUserDto.IPAddress)
+	}
+
+	if accountLocked {
+		r.Logger.Warn("Account locked after repeated failed logins", zap.String("email", loginUserDto.Email))
+
+		if user.ID != 0 {
+			lockoutDuration := config.GetConfig().LoginProtection.LockoutDuration
+			message := mailer.Message{
+				To:      user.Email,
+				Subject: "Your account has been temporarily locked",
+				Body: fmt.Sprintf(
+					"Hi %s,\n\nWe locked your account for %s after several failed login attempts, the last one from %s.\n\nIf this was not you, we recommend resetting your password once the lock expires.\n",
+					user.Name, lockoutDuration, loginUserDto.IPAddress,
+				),
+			}
+
+			r.background.Enqueue("account lock email", func(ctx context.Context) error {
+				return r.mailer.Send(ctx, message)
+			})
+		}
+	}
+
+	return dtos.StructuredResponse{
+		Success: false,
+		Status:  http.StatusUnauthorized,
+		Message: "Invalid email or password",
+		Payload: nil,
+	}, nil
+}
+
+// tooManyAttemptsResponse builds the 429 response, the handler copies the payload into Retry-After
+func tooManyAttemptsResponse(retryAfter time.Duration) dtos.StructuredResponse {
+	return dtos.StructuredResponse{
+		Success: false,
+		Status:  http.StatusTooManyRequests,
+		Message: "Too many failed login attempts, please try again later",
+		Payload: dtos.RateLimitedDto{
+			RetryAfter: int(math.Ceil(retryAfter.Seconds())),
 		},
+	}
+}
+
+// accountDisabledResponse refuses a login to an account an administrator has disabled. It is only
+// returned once the credentials were accepted, so it does not reveal anything to a guesser.
+func accountDisabledResponse() dtos.StructuredResponse {
+	return dtos.StructuredResponse{
+		Success: false,
+		Status:  http.StatusForbidden,
+		Message: "Account has been disabled",
+		Payload: nil,
+	}
+}
+
+// RefreshToken rotates a refresh token: the presented token is marked as used and a new
+// token pair is issued in the same family. Presenting a token that was already used or
+// revoked is treated as theft and revokes every token in its family.
+func (r *AuthRepository) RefreshToken(ctx context.Context, refreshTokenDto dtos.RefreshTokenDto) (dtos.StructuredResponse, error) {
+	invalidResponse := dtos.StructuredResponse{
+		Success: false,
+		Status:  http.StatusUnauthorized,
+		Message: "Invalid refresh token",
+		Payload: nil,
+	}
+
+	if refreshTokenDto.RefreshToken == "" {
+		return invalidResponse, nil
+	}
+
+	var storedToken models.RefreshToken
+
+	if err := r.DB.Where(`"tokenHash" = ?`, utils.HashToken(refreshTokenDto.RefreshToken)).First(&storedToken).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return invalidResponse, nil
+		}
+		r.Logger.Error("Failed to find refresh token", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to refresh token",
+			Payload: nil,
+		}, err
+	}
+
+	// A refresh started by another site must not rotate the token nor trip the reuse detection
+	if refreshTokenDto.FromCookie && (storedToken.SessionID == nil ||
+		!utils.ValidCSRFToken(refreshTokenDto.CSRFToken, utils.CSRFToken(*storedToken.SessionID))) {
+		r.Logger.Warn("Refresh cookie sent without a valid CSRF token", zap.Uint("userId", storedToken.UserID))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusForbidden,
+			Message: "Invalid CSRF token",
+			Payload: nil,
+		}, nil
+	}
+
+	if storedToken.UsedAt != nil || storedToken.RevokedAt != nil {
+		r.Logger.Warn("Refresh token reuse detected, revoking token family",
+			zap.Uint("userId", storedToken.UserID),
+			zap.String("familyId", storedToken.FamilyID),
+		)
+		r.recordTokenReuse(ctx, storedToken, refreshTokenDto)
+		if err := r.revokeRefreshTokenFamily(ctx, storedToken); err != nil {
+			r.Logger.Error("Failed to revoke refresh token family", zap.Error(err))
+			return dtos.StructuredResponse{
+				Success: false,
+				Status:  http.StatusInternalServerError,
+				Message: "Failed to refresh token",
+				Payload: nil,
+			}, err
+		}
+		return invalidResponse, nil
+	}
+
+	if time.Now().After(storedToken.ExpiresAt) {
+		return invalidResponse, nil
+	}
+
+	var tokens dtos.AuthTokensDto
+	reused := false
+
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		// Only one request may consume the token, a concurrent replay loses this race
+		result := tx.Model(&models.RefreshToken{}).
+			Where(`id = ? AND "usedAt" IS NULL AND "revokedAt" IS NULL`, storedToken.ID).
+			Update("usedAt", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			reused = true
+			return nil
+		}
+
+		var user models.User
+		if err := tx.First(&user, storedToken.UserID).Error; err != nil {
+			return err
+		}
+
+		// Tokens issued before sessions were tracked get a session on their next refresh
+		if storedToken.SessionID == nil {
+			session, err := r.sessions.CreateSession(tx, user.ID, refreshTokenDto.UserAgent, refreshTokenDto.IPAddress)
+			if err != nil {
+				return err
+			}
+			storedToken.SessionID = &session.ID
+		} else if err := tx.Model(&models.Session{}).Where("id = ?", *storedToken.SessionID).Updates(map[string]interface{}{
+			"lastSeenAt": time.Now(),
+			"ipAddress":  refreshTokenDto.IPAddress,
+			"expiresAt":  time.Now().Add(config.GetConfig().Auth.RefreshTokenTTL),
+		}).Error; err != nil {
+			return err
+		}
+
+		var err error
+		tokens, err = r.issueTokens(tx, user, storedToken.FamilyID, *storedToken.SessionID)
+		return err
+	})
+
+	if err == nil && reused {
+		r.recordTokenReuse(ctx, storedToken, refreshTokenDto)
+		err = r.revokeRefreshTokenFamily(ctx, storedToken)
+		if err == nil {
+			return invalidResponse, nil
+		}
+	}
+
+	if err != nil {
+		r.Logger.Error("Failed to rotate refresh token", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to refresh token",
+			Payload: nil,
+		}, err
+	}
+
+	return dtos.StructuredResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Token refreshed successfully",
+		Payload: tokens,
+	}, nil
+}
+
+// recordTokenReuse logs a replayed refresh token, a sign that it was stolen
+func (r *AuthRepository) recordTokenReuse(ctx context.Context, storedToken models.RefreshToken, refreshTokenDto dtos.RefreshTokenDto) {
+	r.securityEvents.Record(ctx, models.SecurityEvent{
+		UserID:    storedToken.UserID,
+		Type:      models.SecurityEventRefreshTokenReused,
+		IPAddress: refreshTokenDto.IPAddress,
+		UserAgent: refreshTokenDto.UserAgent,
+		Details:   "Refresh token used twice, its session was revoked",
+	})
+}
+
+// Logout revokes the access token used for the request and, when provided, the
+// refresh token family it was issued with
+func (r *AuthRepository) Logout(ctx context.Context, logoutDto dtos.LogoutDto) (dtos.StructuredResponse, error) {
+	claims, err := utils.GetClaimsFromContext(ctx)
+	if err != nil {
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusUnauthorized,
+			Message: "Unauthorized",
+			Payload: nil,
+		}, nil
+	}
+
+	// Tokens minted before jti claims were introduced cannot be revoked individually
+	if claims.ID != "" {
+		if err := r.revocations.RevokeToken(ctx, claims.ID, claims.UserID, claims.ExpiresAt.Time); err != nil {
+			r.Logger.Error("Failed to revoke access token", zap.Error(err))
+			return dtos.StructuredResponse{
+				Success: false,
+				Status:  http.StatusInternalServerError,
+				Message: "Failed to logout",
+				Payload: nil,
+			}, err
+		}
+	}
+
+	// Ending the session also revokes its refresh tokens
+	if claims.SessionID != 0 {
+		if _, err := r.revocations.RevokeSessions(ctx, claims.UserID, []uint{claims.SessionID}); err != nil {
+			r.Logger.Error("Failed to revoke session", zap.Error(err))
+			return dtos.StructuredResponse{
+				Success: false,
+				Status:  http.StatusInternalServerError,
+				Message: "Failed to logout",
+				Payload: nil,
+			}, err
+		}
+	}
+
+	if logoutDto.RefreshToken != "" {
+		var storedToken models.RefreshToken
+		err := r.DB.Where(`"tokenHash" = ? AND "userId" = ?`, utils.HashToken(logoutDto.RefreshToken), claims.UserID).First(&storedToken).Error
+		if err == nil {
+			err = r.revokeRefreshTokenFamily(ctx, storedToken)
+		}
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			r.Logger.Error("Failed to revoke refresh token", zap.Error(err))
+			return dtos.StructuredResponse{
+				Success: false,
+				Status:  http.StatusInternalServerError,
+				Message: "Failed to logout",
+				Payload: nil,
+			}, err
+		}
+	}
+
+	r.securityEvents.Record(ctx, models.SecurityEvent{
+		UserID:  claims.UserID,
+		Type:    models.SecurityEventTokenRevoked,
+		Details: "Logged out",
+	})
+
+	return dtos.StructuredResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Logged out successfully",
+		Payload: nil,
+	}, nil
+}
+
+// LogoutAll invalidates every access and refresh token issued to the current user
+func (r *AuthRepository) LogoutAll(ctx context.Context) (dtos.StructuredResponse, error) {
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusUnauthorized,
+			Message: "Unauthorized",
+			Payload: nil,
+		}, nil
+	}
+
+	if err := r.revocations.RevokeAllForUser(ctx, userID); err != nil {
+		r.Logger.Error("Failed to revoke user tokens", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to logout",
+			Payload: nil,
+		}, err
+	}
+
+	r.securityEvents.Record(ctx, models.SecurityEvent{
+		UserID:  userID,
+		Type:    models.SecurityEventTokenRevoked,
+		Details: "Logged out from all devices",
+	})
+
+	return dtos.StructuredResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Logged out from all devices successfully",
+		Payload: nil,
+	}, nil
+}
+
+// startSession records a new login session for the client and issues its first token pair
+func (r *AuthRepository) startSession(db *gorm.DB, user models.User, userAgent string, ipAddress string) (dtos.AuthTokensDto, error) {
+	var tokens dtos.AuthTokensDto
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		session, err := r.sessions.CreateSession(tx, user.ID, userAgent, ipAddress)
+		if err != nil {
+			return err
+		}
+
+		tokens, err = r.issueTokens(tx, user, "", session.ID)
+		return err
+	})
+
+	return tokens, err
+}
+
+// issueTokens mints an access token and stores a new refresh token for the user's session.
+// An empty familyID starts a new refresh token family.
+func (r *AuthRepository) issueTokens(db *gorm.DB, user models.User, familyID string, sessionID uint) (dtos.AuthTokensDto, error) {
+	// Reload the roles so the claims reflect the current permissions
+	if err := db.Preload("Roles.Permissions").First(&user, user.ID).Error; err != nil {
+		return dtos.AuthTokensDto{}, err
+	}
+
+	accessToken, err := utils.GenerateToken(user, sessionID)
+	if err != nil {
+		return dtos.AuthTokensDto{}, err
+	}
+
+	if familyID == "" {
+		familyID, err = utils.GenerateRandomToken(24)
+		if err != nil {
+			return dtos.AuthTokensDto{}, err
+		}
+	}
+
+	refreshToken, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return dtos.AuthTokensDto{}, err
+	}
+
+	authConfig := config.GetConfig().Auth
+
+	storedToken := models.RefreshToken{
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		SessionID: &sessionID,
+		TokenHash: utils.HashToken(refreshToken),
+		ExpiresAt: time.Now().Add(authConfig.RefreshTokenTTL),
+	}
+
+	if err := db.Create(&storedToken).Error; err != nil {
+		return dtos.AuthTokensDto{}, err
+	}
+
+	return dtos.AuthTokensDto{
+		ID:           user.ID,
+		Email:        user.Email,
+		Name:         user.Name,
+		Token:        accessToken,
+		ExpiresIn:    int64(authConfig.AccessTokenTTL.Seconds()),
+		RefreshToken: refreshToken,
+		SessionID:    sessionID,
 	}, nil
 }
+
+// revokeRefreshTokenFamily revokes every refresh token that descends from the same login,
+// together with the session they belong to
+func (r *AuthRepository) revokeRefreshTokenFamily(ctx context.Context, storedToken models.RefreshToken) error {
+	if err := r.DB.Model(&models.RefreshToken{}).
+		Where(`"familyId" = ? AND "revokedAt" IS NULL`, storedToken.FamilyID).
+		Update("revokedAt", time.Now()).Error; err != nil {
+		return err
+	}
+
+	if storedToken.SessionID == nil {
+		return nil
+	}
+
+	_, err := r.revocations.RevokeSessions(ctx, storedToken.UserID, []uint{*storedToken.SessionID})
+	return err
+}
+
+// emailTakenResponse rejects a registration for an address that already has an account
+func emailTakenResponse() dtos.StructuredResponse {
+	return dtos.StructuredResponse{
+		Success: false,
+		Status:  http.StatusConflict,
+		Message: "Email address is already registered",
+		Payload: dtos.ValidationErrorsDto{
+			Errors: []dtos.FieldError{{Field: "email", Message: "Is already registered"}},
+		},
+	}
+}
+
+// passwordPolicyResponse reports the password policy rules a new password breaks
+func passwordPolicyResponse(field string, problems []string) dtos.StructuredResponse {
+	fieldErrors := make([]dtos.FieldError, 0, len(problems))
+	for _, problem := range problems {
+		fieldErrors = append(fieldErrors, dtos.FieldError{Field: field, Message: problem})
+	}
+
+	return dtos.StructuredResponse{
+		Success: false,
+		Status:  http.StatusBadRequest,
+		Message: "Password does not meet the requirements",
+		Payload: dtos.ValidationErrorsDto{Errors: fieldErrors},
+	}
+}
@@ -0,0 +1,298 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"todo-api/config"
+	"todo-api/internal/dtos"
+	"todo-api/internal/models"
+	"todo-api/internal/utils"
+
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestAuthRepositoryRefreshTokenReuseRevokesFamily(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewAuthRepository(zap.NewNop())
+	ctx := context.Background()
+	user := createTestAccount(t, db, "alice")
+
+	login, err := repo.startSession(db, user, "test", "127.0.0.1")
+	if err != nil {
+		t.Fatalf("startSession failed: %v", err)
+	}
+
+	refresh := func(refreshToken string) dtos.StructuredResponse {
+		t.Helper()
+
+		response, err := repo.RefreshToken(ctx, dtos.RefreshTokenDto{RefreshToken: refreshToken, UserAgent: "test", IPAddress: "127.0.0.1"})
+		if err != nil {
+			t.Fatalf("RefreshToken failed: %v", err)
+		}
+		return response
+	}
+
+	response := refresh(login.RefreshToken)
+	if response.Status != http.StatusOK {
+		t.Fatalf("first refresh = %d %s, want 200", response.Status, response.Message)
+	}
+	rotated := response.Payload.(dtos.AuthTokensDto)
+	if rotated.RefreshToken == login.RefreshToken || sessionIDOf(t, rotated) != sessionIDOf(t, login) {
+		t.Fatalf("refresh token was not rotated within the session")
+	}
+
+	// Presenting the used token again looks like theft, the whole family goes
+	if response := refresh(login.RefreshToken); response.Status != http.StatusUnauthorized {
+		t.Fatalf("replayed refresh = %d %s, want 401", response.Status, response.Message)
+	}
+	if response := refresh(rotated.RefreshToken); response.Status != http.StatusUnauthorized {
+		t.Errorf("refresh with the newest token of the family = %d %s, want 401", response.Status, response.Message)
+	}
+
+	var session models.Session
+	if err := db.First(&session, sessionIDOf(t, login)).Error; err != nil {
+		t.Fatalf("failed to load session: %v", err)
+	}
+	if session.RevokedAt == nil {
+		t.Error("session of the family was not revoked")
+	}
+
+	// The access token issued with the rotation dies with its session
+	claims, err := utils.ValidateToken(rotated.Token)
+	if err != nil {
+		t.Fatalf("ValidateToken failed: %v", err)
+	}
+	if revoked, err := repo.revocations.IsRevoked(ctx, claims); err != nil || !revoked {
+		t.Errorf("access token revoked = %v, %v, want true", revoked, err)
+	}
+
+	// Another login of the same user is not affected
+	other, err := repo.startSession(db, user, "test", "127.0.0.1")
+	if err != nil {
+		t.Fatalf("startSession failed: %v", err)
+	}
+	if response := refresh(other.RefreshToken); response.Status != http.StatusOK {
+		t.Errorf("refresh of another session = %d %s, want 200", response.Status, response.Message)
+	}
+}
+
+func TestAuthRepositoryRefreshTokenRejectsUnknownToken(t *testing.T) {
+	openTestDB(t)
+	repo := NewAuthRepository(zap.NewNop())
+
+	for _, refreshToken := range []string{"", "not-a-refresh-token"} {
+		response, err := repo.RefreshToken(context.Background(), dtos.RefreshTokenDto{RefreshToken: refreshToken})
+		if err != nil {
+			t.Fatalf("RefreshToken(%q) failed: %v", refreshToken, err)
+		}
+		if response.Status != http.StatusUnauthorized {
+			t.Errorf("RefreshToken(%q) = %d, want 401", refreshToken, response.Status)
+		}
+	}
+}
+
+func TestAuthRepositoryLogout(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewAuthRepository(zap.NewNop())
+	user := createTestAccount(t, db, "alice")
+
+	login, err := repo.startSession(db, user, "test", "127.0.0.1")
+	if err != nil {
+		t.Fatalf("startSession failed: %v", err)
+	}
+	claims, err := utils.ValidateToken(login.Token)
+	if err != nil {
+		t.Fatalf("ValidateToken failed: %v", err)
+	}
+	ctx := utils.SetClaimsInContext(context.Background(), claims)
+
+	response, err := repo.Logout(ctx, dtos.LogoutDto{RefreshToken: login.RefreshToken})
+	if err != nil || response.Status != http.StatusOK {
+		t.Fatalf("Logout = %d %s, %v, want 200", response.Status, response.Message, err)
+	}
+
+	if revoked, err := repo.revocations.IsRevoked(ctx, claims); err != nil || !revoked {
+		t.Errorf("access token revoked = %v, %v, want true", revoked, err)
+	}
+
+	var session models.Session
+	if err := db.First(&session, sessionIDOf(t, login)).Error; err != nil {
+		t.Fatalf("failed to load session: %v", err)
+	}
+	if session.RevokedAt == nil {
+		t.Error("session was not revoked")
+	}
+
+	response, err = repo.RefreshToken(ctx, dtos.RefreshTokenDto{RefreshToken: login.RefreshToken})
+	if err != nil {
+		t.Fatalf("RefreshToken failed: %v", err)
+	}
+	if response.Status != http.StatusUnauthorized {
+		t.Errorf("refresh after logout = %d, want 401", response.Status)
+	}
+}
+
+func TestAuthRepositoryLoginUserLockedOut(t *testing.T) {
+	// A locked account is refused before the user is looked up, so no database is needed
+	repo := &AuthRepository{
+		Logger:        zap.NewNop(),
+		loginAttempts: newTestLoginAttemptRepository(&memoryLoginAttemptStore{attempts: map[string]*memoryLoginAttempt{}}),
+	}
+	ctx := context.Background()
+	email := "alice@example.com"
+
+	for i := 0; i < testLoginProtection.AccountFailureThreshold; i++ {
+		if _, err := repo.loginAttempts.RecordFailure(ctx, email, "203.0.113.1"); err != nil {
+			t.Fatalf("RecordFailure failed: %v", err)
+		}
+	}
+
+	response, err := repo.LoginUser(ctx, dtos.LoginUserDto{Email: email, Password: "password", IPAddress: "198.51.100.7"})
+	if err != nil {
+		t.Fatalf("LoginUser failed: %v", err)
+	}
+	if response.Status != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want 429", response.Status)
+	}
+	retryAfter := response.Payload.(dtos.RateLimitedDto).RetryAfter
+	if maxWait := int(testLoginProtection.LockoutDuration.Seconds()); retryAfter <= 0 || retryAfter > maxWait {
+		t.Errorf("retryAfter = %d, want between 1 and %d", retryAfter, maxWait)
+	}
+}
+
+func TestAuthRepositoryLoginUpgradesLegacyHash(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewAuthRepository(zap.NewNop())
+	repo.passwords = testPasswords
+	ctx := context.Background()
+	user := createTestAccount(t, db, "alice")
+
+	legacyHash, err := bcrypt.GenerateFromPassword([]byte("correct horse battery staple"), 4)
+	if err != nil {
+		t.Fatalf("failed to hash password: %v", err)
+	}
+	if err := db.Model(&user).Update("passwordHash", string(legacyHash)).Error; err != nil {
+		t.Fatalf("failed to store legacy hash: %v", err)
+	}
+
+	// The upgraded hash must keep working, so log in twice
+	for i := 1; i <= 2; i++ {
+		response, err := repo.LoginUser(ctx, dtos.LoginUserDto{Email: user.Email, Password: "correct horse battery staple", IPAddress: "127.0.0.1"})
+		if err != nil || response.Status != http.StatusOK {
+			t.Fatalf("login %d = %d %s, %v, want 200", i, response.Status, response.Message, err)
+		}
+
+		var stored models.User
+		if err := db.First(&stored, user.ID).Error; err != nil {
+			t.Fatalf("failed to load user: %v", err)
+		}
+		if !strings.HasPrefix(stored.PasswordHash, "$argon2id$") {
+			t.Errorf("login %d: stored hash %.10s..., want an argon2id hash", i, stored.PasswordHash)
+		}
+	}
+}
+
+func TestAuthRepositoryRegisterDuplicateEmail(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewAuthRepository(zap.NewNop())
+	ctx := context.Background()
+
+	registration := &config.GetConfig().Registration
+	previous := *registration
+	registration.Mode, registration.AllowedDomains = config.RegistrationOpen, nil
+	t.Cleanup(func() { *registration = previous })
+
+	email := fmt.Sprintf("dave-%d@example.com", time.Now().UnixNano())
+	register := func(t *testing.T, email string) dtos.StructuredResponse {
+		t.Helper()
+
+		response, err := repo.RegisterUser(ctx, dtos.RegisterUserDto{
+			Email:           email,
+			Password:        "Unrelated-Passphrase-42",
+			ConfirmPassword: "Unrelated-Passphrase-42",
+			Name:            "Dave",
+		})
+		if err != nil {
+			t.Fatalf("RegisterUser failed: %v", err)
+		}
+		return response
+	}
+
+	if response := register(t, email); response.Status != http.StatusCreated {
+		t.Fatalf("first registration = %d %s, want 201", response.Status, response.Message)
+	}
+	t.Cleanup(func() {
+		db.Where("email = ?", email).Delete(&models.User{})
+	})
+
+	// Emails are unique regardless of case and surrounding spaces
+	response := register(t, " "+strings.ToUpper(email))
+	if response.Status != http.StatusConflict {
+		t.Fatalf("duplicate registration = %d %s, want 409", response.Status, response.Message)
+	}
+
+	want := dtos.ValidationErrorsDto{Errors: []dtos.FieldError{{Field: "email", Message: "Is already registered"}}}
+	if !reflect.DeepEqual(response.Payload, want) {
+		t.Errorf("duplicate registration payload = %+v, want %+v", response.Payload, want)
+	}
+}
+
+func TestAuthRepositoryRefreshCookieChecksCSRF(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewAuthRepository(zap.NewNop())
+	ctx := context.Background()
+	user := createTestAccount(t, db, "alice")
+
+	login, err := repo.startSession(db, user, "test", "127.0.0.1")
+	if err != nil {
+		t.Fatalf("startSession failed: %v", err)
+	}
+	other, err := repo.startSession(db, user, "test", "127.0.0.1")
+	if err != nil {
+		t.Fatalf("startSession failed: %v", err)
+	}
+
+	refresh := func(t *testing.T, csrfToken string) dtos.StructuredResponse {
+		t.Helper()
+
+		response, err := repo.RefreshToken(ctx, dtos.RefreshTokenDto{
+			RefreshToken: login.RefreshToken,
+			UserAgent:    "test",
+			IPAddress:    "127.0.0.1",
+			FromCookie:   true,
+			CSRFToken:    csrfToken,
+		})
+		if err != nil {
+			t.Fatalf("RefreshToken failed: %v", err)
+		}
+		return response
+	}
+
+	tests := []struct {
+		name      string
+		csrfToken string
+	}{
+		{"no CSRF token", ""},
+		{"CSRF token of another session", utils.CSRFToken(other.SessionID)},
+		{"token planted by another site", "planted"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if response := refresh(t, tt.csrfToken); response.Status != http.StatusForbidden {
+				t.Errorf("RefreshToken = %d %s, want 403", response.Status, response.Message)
+			}
+		})
+	}
+
+	// The refused requests did not use up the token
+	if response := refresh(t, utils.CSRFToken(login.SessionID)); response.Status != http.StatusOK {
+		t.Errorf("RefreshToken with the session's CSRF token = %d %s, want 200", response.Status, response.Message)
+	}
+}
@@ -0,0 +1,293 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+	"todo-api/config"
+	"todo-api/database"
+	"todo-api/internal/dtos"
+	"todo-api/internal/jobs"
+	"todo-api/internal/mailer"
+	"todo-api/internal/models"
+	"todo-api/internal/utils"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type EmailVerificationRepository struct {
+	DB             *gorm.DB
+	Logger         *zap.Logger
+	mailer         mailer.Mailer
+	background     *jobs.Queue
+	securityEvents *SecurityEventRepository
+}
+
+func NewEmailVerificationRepository(logger *zap.Logger) *EmailVerificationRepository {
+	return &EmailVerificationRepository{
+		DB:             database.GetDB(),
+		Logger:         logger,
+		mailer:         mailer.New(config.GetConfig().Mail, logger),
+		background:     jobs.Default(),
+		securityEvents: NewSecurityEventRepository(logger),
+	}
+}
+
+// SendVerificationEmail emails a signed verification link for the user's current address.
+// The email is sent by a background job, failures are only logged.
+func (r *EmailVerificationRepository) SendVerificationEmail(user models.User) error {
+	verificationTTL := config.GetConfig().Auth.EmailVerificationTTL
+
+	token, err := utils.GenerateActionToken(utils.PurposeEmailVerification, user, verificationTTL)
+	if err != nil {
+		return err
+	}
+
+	verificationLink := fmt.Sprintf("%s/verify-email?token=%s", config.GetConfig().AppURL, url.QueryEscape(token))
+	message := mailer.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nPlease confirm your email address by opening the link below:\n\n%s\n\nThe link expires in %s.\n",
+			user.Name, verificationLink, verificationTTL,
+		),
+	}
+
+	return r.background.Enqueue("verification email", func(ctx context.Context) error {
+		return r.mailer.Send(ctx, message)
+	})
+}
+
+// VerifyEmail marks the user's email as verified when the signed token is valid
+// and was issued for the address the account currently has
+func (r *EmailVerificationRepository) VerifyEmail(ctx context.Context, verifyEmailDto dtos.VerifyEmailDto) (dtos.StructuredResponse, error) {
+	invalidResponse := dtos.StructuredResponse{
+		Success: false,
+		Status:  http.StatusBadRequest,
+		Message: "Invalid or expired verification link",
+		Payload: nil,
+	}
+
+	claims, err := utils.ValidateActionToken(verifyEmailDto.Token, utils.PurposeEmailVerification)
+	if err != nil {
+		return invalidResponse, nil
+	}
+
+	var user models.User
+
+	if err := r.DB.First(&user, claims.UserID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return invalidResponse, nil
+		}
+		r.Logger.Error("Failed to find user", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to verify email",
+			Payload: nil,
+		}, err
+	}
+
+	// A link sent to a previous address must not verify the current one
+	if user.Email != claims.Email {
+		return invalidResponse, nil
+	}
+
+	if user.EmailVerifiedAt == nil {
+		if err := r.DB.Model(&user).Update("emailVerifiedAt", time.Now()).Error; err != nil {
+			r.Logger.Error("Failed to mark email as verified", zap.Error(err))
+			return dtos.StructuredResponse{
+				Success: false,
+				Status:  http.StatusInternalServerError,
+				Message: "Failed to verify email",
+				Payload: nil,
+			}, err
+		}
+	}
+
+	return dtos.StructuredResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Email verified successfully",
+		Payload: nil,
+	}, nil
+}
+
+// ResendVerification sends a fresh verification link. Like the forgot password flow it
+// responds the same way whether or not the account exists or is already verified.
+func (r *EmailVerificationRepository) ResendVerification(ctx context.Context, resendVerificationDto dtos.ResendVerificationDto) (dtos.StructuredResponse, error) {
+	response := dtos.StructuredResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "If an unverified account exists for this email, a verification link has been sent",
+		Payload: nil,
+	}
+
+	var user models.User
+
+	if err := r.DB.Where("email = ?", utils.NormalizeEmail(resendVerificationDto.Email)).First(&user).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			r.Logger.Error("Failed to find user", zap.Error(err))
+		}
+		return response, nil
+	}
+
+	if user.EmailVerifiedAt != nil {
+		return response, nil
+	}
+
+	if err := r.SendVerificationEmail(user); err != nil {
+		r.Logger.Error("Failed to send verification email", zap.Uint("userId", user.ID), zap.Error(err))
+	}
+
+	return response, nil
+}
+
+// SendEmailChangeVerification emails a signed confirmation link to the address the user
+// wants to switch to. Like SendVerificationEmail the email is sent in the background.
+func (r *EmailVerificationRepository) SendEmailChangeVerification(user models.User, newEmail string) error {
+	verificationTTL := config.GetConfig().Auth.EmailVerificationTTL
+
+	// The token carries the new address, so only the inbox that received it can confirm it
+	pendingUser := user
+	pendingUser.Email = newEmail
+
+	token, err := utils.GenerateActionToken(utils.PurposeEmailChange, pendingUser, verificationTTL)
+	if err != nil {
+		return err
+	}
+
+	confirmationLink := fmt.Sprintf("%s/confirm-email-change?token=%s", config.GetConfig().AppURL, url.QueryEscape(token))
+	message := mailer.Message{
+		To:      newEmail,
+		Subject: "Confirm your new email address",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nPlease confirm that you want to use this address for your account by opening the link below:\n\n%s\n\nThe link expires in %s. If you did not ask for this change, you can ignore this email.\n",
+			user.Name, confirmationLink, verificationTTL,
+		),
+	}
+
+	return r.background.Enqueue("email change confirmation", func(ctx context.Context) error {
+		return r.mailer.Send(ctx, message)
+	})
+}
+
+// ConfirmEmailChange replaces the user's email with the pending address the token was issued for.
+// The new address counts as verified since the link could only be opened from its inbox.
+func (r *EmailVerificationRepository) ConfirmEmailChange(ctx context.Context, confirmEmailChangeDto dtos.ConfirmEmailChangeDto) (dtos.StructuredResponse, error) {
+	invalidResponse := dtos.StructuredResponse{
+		Success: false,
+		Status:  http.StatusBadRequest,
+		Message: "Invalid or expired confirmation link",
+		Payload: nil,
+	}
+
+	claims, err := utils.ValidateActionToken(confirmEmailChangeDto.Token, utils.PurposeEmailChange)
+	if err != nil {
+		return invalidResponse, nil
+	}
+
+	var user models.User
+
+	if err := r.DB.First(&user, claims.UserID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return invalidResponse, nil
+		}
+		r.Logger.Error("Failed to find user", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to change email",
+			Payload: nil,
+		}, err
+	}
+
+	// A link for an address the user has since replaced with another request must not apply
+	if user.PendingEmail == "" || user.PendingEmail != claims.Email {
+		return invalidResponse, nil
+	}
+
+	// The address may have been taken by someone else since the change was requested
+	var existingCount int64
+	if err := r.DB.Model(&models.User{}).
+		Where("LOWER(email) = LOWER(?) AND id <> ?", claims.Email, user.ID).
+		Count(&existingCount).Error; err != nil {
+		r.Logger.Error("Failed to check email availability", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to change email",
+			Payload: nil,
+		}, err
+	}
+
+	if existingCount > 0 {
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusConflict,
+			Message: "Email address is already in use",
+			Payload: nil,
+		}, nil
+	}
+
+	oldEmail := user.Email
+
+	err = r.DB.Model(&user).Updates(map[string]interface{}{
+		"email":           claims.Email,
+		"pendingEmail":    "",
+		"emailVerifiedAt": time.Now(),
+	}).Error
+
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusConflict,
+			Message: "Email address is already in use",
+			Payload: nil,
+		}, nil
+	}
+
+	if err != nil {
+		r.Logger.Error("Failed to change email", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to change email",
+			Payload: nil,
+		}, err
+	}
+
+	r.securityEvents.Record(ctx, models.SecurityEvent{
+		UserID:    user.ID,
+		Type:      models.SecurityEventEmailChanged,
+		IPAddress: confirmEmailChangeDto.IPAddress,
+		UserAgent: confirmEmailChangeDto.UserAgent,
+		Details:   fmt.Sprintf("Changed from %s to %s", oldEmail, claims.Email),
+	})
+
+	// Tell the previous address, so an account takeover does not go unnoticed
+	notice := mailer.Message{
+		To:      oldEmail,
+		Subject: "Your email address was changed",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nThe email address of your account was changed to %s.\n\nIf you did not make this change, please contact support immediately.\n",
+			user.Name, claims.Email,
+		),
+	}
+
+	// The change is done either way, a dropped job is logged by the queue
+	r.background.Enqueue("email change notice", func(ctx context.Context) error {
+		return r.mailer.Send(ctx, notice)
+	})
+
+	return dtos.StructuredResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Email changed successfully",
+		Payload: nil,
+	}, nil
+}
@@ -0,0 +1,73 @@
+package repositories
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+	"todo-api/config"
+	"todo-api/internal/dtos"
+	"todo-api/internal/models"
+	"todo-api/internal/utils"
+
+	"go.uber.org/zap"
+)
+
+func TestEmailVerificationRepositoryVerifyEmail(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewEmailVerificationRepository(zap.NewNop())
+	ctx := context.Background()
+
+	user := createTestAccount(t, db, "alice")
+	if err := db.Model(&user).Update("emailVerifiedAt", nil).Error; err != nil {
+		t.Fatalf("failed to unverify user: %v", err)
+	}
+
+	verify := func(t *testing.T, token string) int {
+		t.Helper()
+
+		response, err := repo.VerifyEmail(ctx, dtos.VerifyEmailDto{Token: token})
+		if err != nil {
+			t.Fatalf("VerifyEmail failed: %v", err)
+		}
+		return response.Status
+	}
+
+	verificationTTL := config.GetConfig().Auth.EmailVerificationTTL
+
+	t.Run("invalid token", func(t *testing.T) {
+		if status := verify(t, "not-a-token"); status != http.StatusBadRequest {
+			t.Errorf("status = %d, want 400", status)
+		}
+	})
+
+	t.Run("link for a previous address", func(t *testing.T) {
+		previous := user
+		previous.Email = "previous-" + user.Email
+		token, err := utils.GenerateActionToken(utils.PurposeEmailVerification, previous, verificationTTL)
+		if err != nil {
+			t.Fatalf("GenerateActionToken failed: %v", err)
+		}
+		if status := verify(t, token); status != http.StatusBadRequest {
+			t.Errorf("status = %d, want 400", status)
+		}
+	})
+
+	t.Run("valid link", func(t *testing.T) {
+		token, err := utils.GenerateActionToken(utils.PurposeEmailVerification, user, verificationTTL)
+		if err != nil {
+			t.Fatalf("GenerateActionToken failed: %v", err)
+		}
+		if status := verify(t, token); status != http.StatusOK {
+			t.Fatalf("status = %d, want 200", status)
+		}
+
+		var stored models.User
+		if err := db.First(&stored, user.ID).Error; err != nil {
+			t.Fatalf("failed to load user: %v", err)
+		}
+		if stored.EmailVerifiedAt == nil || time.Since(*stored.EmailVerifiedAt) > time.Minute {
+			t.Errorf("emailVerifiedAt = %v, want now", stored.EmailVerifiedAt)
+		}
+	})
+}
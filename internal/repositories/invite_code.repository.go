@@ -0,0 +1,239 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+	"todo-api/config"
+	"todo-api/database"
+	"todo-api/internal/dtos"
+	"todo-api/internal/models"
+	"todo-api/internal/utils"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// errInvalidInviteCode is returned inside the registration transaction when the invite code is
+// unknown, revoked, expired or used up
+var errInvalidInviteCode = errors.New("invalid invite code")
+
+// Most accounts a single invite code can create
+const maxInviteCodeUses = 10000
+
+type InviteCodeRepository struct {
+	DB     *gorm.DB
+	Logger *zap.Logger
+}
+
+func NewInviteCodeRepository(logger *zap.Logger) *InviteCodeRepository {
+	return &InviteCodeRepository{
+		DB:     database.GetDB(),
+		Logger: logger,
+	}
+}
+
+// CreateInviteCode creates a code that registers up to MaxUses accounts. The code is only
+// returned here, the database keeps its hash.
+func (r *InviteCodeRepository) CreateInviteCode(ctx context.Context, createInviteCodeDto dtos.CreateInviteCodeDto) (dtos.StructuredResponse, error) {
+	maxUses := createInviteCodeDto.MaxUses
+	if maxUses == 0 {
+		maxUses = 1
+	}
+
+	if maxUses < 0 || maxUses > maxInviteCodeUses {
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusBadRequest,
+			Message: "maxUses must be between 1 and 10000",
+			Payload: nil,
+		}, nil
+	}
+
+	if createInviteCodeDto.ExpiresInDays < 0 {
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusBadRequest,
+			Message: "expiresInDays cannot be negative",
+			Payload: nil,
+		}, nil
+	}
+
+	code, prefix, err := utils.GenerateInviteCode()
+	if err != nil {
+		r.Logger.Error("Failed to generate invite code", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to create invite code",
+			Payload: nil,
+		}, err
+	}
+
+	inviteCode := models.InviteCode{
+		Prefix:      prefix,
+		CodeHash:    utils.HashToken(code),
+		Note:        strings.TrimSpace(createInviteCodeDto.Note),
+		MaxUses:     maxUses,
+		CreatedByID: &createInviteCodeDto.CreatedByID,
+	}
+
+	if createInviteCodeDto.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, createInviteCodeDto.ExpiresInDays)
+		inviteCode.ExpiresAt = &expiresAt
+	}
+
+	if err := r.DB.WithContext(ctx).Create(&inviteCode).Error; err != nil {
+		r.Logger.Error("Failed to store invite code", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to create invite code",
+			Payload: nil,
+		}, err
+	}
+
+	r.Logger.Info("Invite code created", zap.Uint("inviteCodeId", inviteCode.ID), zap.Uint("actorId", createInviteCodeDto.CreatedByID))
+
+	return dtos.StructuredResponse{
+		Success: true,
+		Status:  http.StatusCreated,
+		Message: "Invite code created successfully, copy it now as it will not be shown again",
+		Payload: dtos.CreatedInviteCodeDto{
+			InviteCodeDto: toInviteCodeDto(inviteCode),
+			Code:          code,
+		},
+	}, nil
+}
+
+// GetInviteCodes lists every invite code, newest first, including used up and revoked ones
+func (r *InviteCodeRepository) GetInviteCodes(ctx context.Context) (dtos.StructuredResponse, error) {
+	var inviteCodes []models.InviteCode
+
+	if err := r.DB.WithContext(ctx).Order(`"createdAt" DESC, id DESC`).Find(&inviteCodes).Error; err != nil {
+		r.Logger.Error("Failed to retrieve invite codes", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to retrieve invite codes",
+			Payload: nil,
+		}, err
+	}
+
+	inviteCodeDtos := make([]dtos.InviteCodeDto, 0, len(inviteCodes))
+	for _, inviteCode := range inviteCodes {
+		inviteCodeDtos = append(inviteCodeDtos, toInviteCodeDto(inviteCode))
+	}
+
+	return dtos.StructuredResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Invite codes retrieved successfully",
+		Payload: inviteCodeDtos,
+	}, nil
+}
+
+// RevokeInviteCode stops a code from registering further accounts
+func (r *InviteCodeRepository) RevokeInviteCode(ctx context.Context, inviteCodeID uint) (dtos.StructuredResponse, error) {
+	result := r.DB.WithContext(ctx).Model(&models.InviteCode{}).
+		Where(`id = ? AND "revokedAt" IS NULL`, inviteCodeID).
+		Update("revokedAt", time.Now())
+
+	if result.Error != nil {
+		r.Logger.Error("Failed to revoke invite code", zap.Error(result.Error))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to revoke invite code",
+			Payload: nil,
+		}, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusNotFound,
+			Message: "Invite code not found",
+			Payload: nil,
+		}, nil
+	}
+
+	return dtos.StructuredResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Invite code revoked successfully",
+		Payload: nil,
+	}, nil
+}
+
+// consumeInviteCode uses up one use of a code. It runs in the registration transaction, so the
+// use is given back when the account cannot be created, and the conditional update keeps
+// concurrent registrations from using a code more often than allowed.
+func consumeInviteCode(tx *gorm.DB, code string) error {
+	result := tx.Model(&models.InviteCode{}).
+		Where(`"codeHash" = ? AND "revokedAt" IS NULL AND ("expiresAt" IS NULL OR "expiresAt" > ?) AND uses < "maxUses"`,
+			utils.HashToken(code), time.Now()).
+		Update("uses", gorm.Expr("uses + 1"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errInvalidInviteCode
+	}
+	return nil
+}
+
+// registrationRefusal tells why an account may not be created for the email, or returns "" when
+// it may. withInviteCode reports whether the request carries a code, its validity is checked
+// when it is consumed.
+func registrationRefusal(email string, withInviteCode bool) string {
+	registrationConfig := config.GetConfig().Registration
+
+	switch registrationConfig.Mode {
+	case config.RegistrationOpen:
+	case config.RegistrationInvite:
+		if !withInviteCode {
+			return "An invite code is required to register"
+		}
+	default:
+		return "Registration is closed, ask an administrator to create your account"
+	}
+
+	if len(registrationConfig.AllowedDomains) > 0 {
+		_, domain, _ := strings.Cut(email, "@")
+		if !slices.ContainsFunc(registrationConfig.AllowedDomains, func(allowed string) bool {
+			return strings.EqualFold(strings.TrimPrefix(allowed, "@"), domain)
+		}) {
+			return "Registration is not open to email addresses at this domain"
+		}
+	}
+
+	return ""
+}
+
+// registrationRefusedResponse rejects a registration the configured mode does not allow
+func registrationRefusedResponse(message string) dtos.StructuredResponse {
+	return dtos.StructuredResponse{
+		Success: false,
+		Status:  http.StatusForbidden,
+		Message: message,
+		Payload: nil,
+	}
+}
+
+func toInviteCodeDto(inviteCode models.InviteCode) dtos.InviteCodeDto {
+	return dtos.InviteCodeDto{
+		ID:          inviteCode.ID,
+		Prefix:      inviteCode.Prefix,
+		Note:        inviteCode.Note,
+		MaxUses:     inviteCode.MaxUses,
+		Uses:        inviteCode.Uses,
+		ExpiresAt:   inviteCode.ExpiresAt,
+		RevokedAt:   inviteCode.RevokedAt,
+		CreatedByID: inviteCode.CreatedByID,
+		CreatedAt:   inviteCode.CreatedAt,
+	}
+}
@@ -0,0 +1,91 @@
+package repositories
+
+import (
+	"errors"
+	"testing"
+	"time"
+	"todo-api/config"
+	"todo-api/internal/models"
+	"todo-api/internal/utils"
+)
+
+func TestRegistrationRefusal(t *testing.T) {
+	registration := &config.GetConfig().Registration
+	previous := *registration
+	t.Cleanup(func() { *registration = previous })
+
+	tests := []struct {
+		name           string
+		mode           string
+		allowedDomains []string
+		email          string
+		withInviteCode bool
+		refused        bool
+	}{
+		{"open", config.RegistrationOpen, nil, "alice@example.com", false, false},
+		{"invite with code", config.RegistrationInvite, nil, "alice@example.com", true, false},
+		{"invite without code", config.RegistrationInvite, nil, "alice@example.com", false, true},
+		{"closed", config.RegistrationClosed, nil, "alice@example.com", true, true},
+		{"unknown mode", "", nil, "alice@example.com", true, true},
+		{"allowed domain", config.RegistrationOpen, []string{"example.com"}, "alice@example.com", false, false},
+		{"allowed domain with @ and another case", config.RegistrationOpen, []string{"other.org", "@Example.COM"}, "alice@example.com", false, false},
+		{"other domain", config.RegistrationOpen, []string{"example.com"}, "alice@example.org", false, true},
+		{"subdomain", config.RegistrationOpen, []string{"example.com"}, "alice@mail.example.com", false, true},
+		{"domain as suffix", config.RegistrationOpen, []string{"example.com"}, "alice@badexample.com", false, true},
+		{"invite code does not lift the allowlist", config.RegistrationInvite, []string{"example.com"}, "alice@example.org", true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registration.Mode = tt.mode
+			registration.AllowedDomains = tt.allowedDomains
+
+			if refusal := registrationRefusal(tt.email, tt.withInviteCode); (refusal != "") != tt.refused {
+				t.Errorf("registrationRefusal(%q, %v) = %q, want refused %v", tt.email, tt.withInviteCode, refusal, tt.refused)
+			}
+		})
+	}
+}
+
+func TestConsumeInviteCode(t *testing.T) {
+	db := openTestDB(t)
+
+	createCode := func(t *testing.T, maxUses int, expiresAt *time.Time, revokedAt *time.Time) string {
+		t.Helper()
+
+		code, prefix, err := utils.GenerateInviteCode()
+		if err != nil {
+			t.Fatalf("GenerateInviteCode failed: %v", err)
+		}
+		inviteCode := models.InviteCode{Prefix: prefix, CodeHash: utils.HashToken(code), MaxUses: maxUses, ExpiresAt: expiresAt, RevokedAt: revokedAt}
+		if err := db.Create(&inviteCode).Error; err != nil {
+			t.Fatalf("failed to store invite code: %v", err)
+		}
+		t.Cleanup(func() {
+			db.Delete(&models.InviteCode{}, inviteCode.ID)
+		})
+		return code
+	}
+
+	past, future := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+
+	twoUses := createCode(t, 2, &future, nil)
+	for i := 1; i <= 2; i++ {
+		if err := consumeInviteCode(db, twoUses); err != nil {
+			t.Fatalf("use %d of 2 = %v, want success", i, err)
+		}
+	}
+	if err := consumeInviteCode(db, twoUses); !errors.Is(err, errInvalidInviteCode) {
+		t.Errorf("third use of a two-use code = %v, want errInvalidInviteCode", err)
+	}
+
+	for name, code := range map[string]string{
+		"expired": createCode(t, 1, &past, nil),
+		"revoked": createCode(t, 1, nil, &past),
+		"unknown": "inv_00000000_unknown",
+	} {
+		if err := consumeInviteCode(db, code); !errors.Is(err, errInvalidInviteCode) {
+			t.Errorf("%s code = %v, want errInvalidInviteCode", name, err)
+		}
+	}
+}
@@ -0,0 +1,290 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"sync"
+	"time"
+	"todo-api/config"
+	"todo-api/database"
+	"todo-api/internal/models"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// LoginAttemptState is the throttling state of a single key
+type LoginAttemptState struct {
+	Failures     int
+	BlockedUntil time.Time
+}
+
+// LoginAttemptStore persists failed login counters. The memory store is enough for a
+// single instance, the postgres store shares counters between every instance of a cluster.
+type LoginAttemptStore interface {
+	// Get returns the state of the key, the zero state when nothing is recorded
+	Get(ctx context.Context, key string) (LoginAttemptState, error)
+	// RecordFailure counts a failure. Counting restarts when the first recorded failure
+	// is older than the window. It returns the number of failures in the current window.
+	RecordFailure(ctx context.Context, key string, window time.Duration) (int, error)
+	// Block rejects attempts for the key until the given time
+	Block(ctx context.Context, key string, until time.Time) error
+	// Reset forgets everything recorded for the key
+	Reset(ctx context.Context, key string) error
+}
+
+// LoginAttemptRepository applies the brute-force policy from config on top of a LoginAttemptStore
+type LoginAttemptRepository struct {
+	Logger *zap.Logger
+	store  LoginAttemptStore
+	policy config.LoginProtectionConfig
+}
+
+func NewLoginAttemptRepository(logger *zap.Logger) *LoginAttemptRepository {
+	policy := config.GetConfig().LoginProtection
+
+	var store LoginAttemptStore
+	if policy.Store == "postgres" {
+		store = &postgresLoginAttemptStore{DB: database.GetDB()}
+	} else {
+		store = sharedMemoryLoginAttemptStore
+	}
+
+	return &LoginAttemptRepository{
+		Logger: logger,
+		store:  store,
+		policy: policy,
+	}
+}
+
+// RetryAfter returns how long the caller has to wait before the email or IP may try again,
+// zero when the attempt is allowed
+func (r *LoginAttemptRepository) RetryAfter(ctx context.Context, email string, ipAddress string) (time.Duration, error) {
+	var wait time.Duration
+
+	for _, key := range []string{accountAttemptKey(email), ipAttemptKey(ipAddress)} {
+		state, err := r.store.Get(ctx, key)
+		if err != nil {
+			return 0, err
+		}
+		if remaining := time.Until(state.BlockedUntil); remaining > wait {
+			wait = remaining
+		}
+	}
+
+	return wait, nil
+}
+
+// RecordFailure counts a failed login for the email and IP and applies the progressive delay.
+// It reports whether this failure locked the account, so the owner can be notified exactly once.
+func (r *LoginAttemptRepository) RecordFailure(ctx context.Context, email string, ipAddress string) (bool, error) {
+	accountLocked, err := r.recordFailure(ctx, accountAttemptKey(email), r.policy.AccountFailureThreshold)
+	if err != nil {
+		return false, err
+	}
+
+	ipLocked, err := r.recordFailure(ctx, ipAttemptKey(ipAddress), r.policy.IPFailureThreshold)
+	if err != nil {
+		return false, err
+	}
+
+	if ipLocked {
+		r.Logger.Warn("Client IP locked out after repeated failed logins", zap.String("ip", ipAddress))
+	}
+
+	return accountLocked, nil
+}
+
+// RecordSuccess clears the account counter. The IP counter is left to expire on its own so
+// one valid account cannot be used to reset a credential stuffing run.
+func (r *LoginAttemptRepository) RecordSuccess(ctx context.Context, email string) error {
+	return r.store.Reset(ctx, accountAttemptKey(email))
+}
+
+// Throttle counts a request for the key and allows at most limit requests per window. It returns
+// how long the caller has to wait, zero when the request is allowed. Keys share the store with
+// login attempts, so callers use their own prefix.
+func (r *LoginAttemptRepository) Throttle(ctx context.Context, key string, limit int, window time.Duration) (time.Duration, error) {
+	state, err := r.store.Get(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	if remaining := time.Until(state.BlockedUntil); remaining > 0 {
+		return remaining, nil
+	}
+
+	requests, err := r.store.RecordFailure(ctx, key, window)
+	if err != nil {
+		return 0, err
+	}
+
+	if limit > 0 && requests >= limit {
+		return 0, r.store.Block(ctx, key, time.Now().Add(window))
+	}
+
+	return 0, nil
+}
+
+func (r *LoginAttemptRepository) recordFailure(ctx context.Context, key string, threshold int) (bool, error) {
+	failures, err := r.store.RecordFailure(ctx, key, r.policy.FailureWindow)
+	if err != nil {
+		return false, err
+	}
+
+	if threshold > 0 && failures >= threshold {
+		return failures == threshold, r.store.Block(ctx, key, time.Now().Add(r.policy.LockoutDuration))
+	}
+
+	return false, r.store.Block(ctx, key, time.Now().Add(r.progressiveDelay(failures)))
+}
+
+// progressiveDelay doubles the wait for every failure: DelayBase, 2x, 4x... capped at MaxDelay
+func (r *LoginAttemptRepository) progressiveDelay(failures int) time.Duration {
+	if failures <= 0 || r.policy.DelayBase <= 0 {
+		return 0
+	}
+
+	delay := float64(r.policy.DelayBase) * math.Pow(2, float64(failures-1))
+	if delay > float64(r.policy.MaxDelay) {
+		return r.policy.MaxDelay
+	}
+	return time.Duration(delay)
+}
+
+func accountAttemptKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+func ipAttemptKey(ipAddress string) string {
+	return "ip:" + ipAddress
+}
+
+// memoryLoginAttemptStore keeps counters in process memory
+type memoryLoginAttemptStore struct {
+	mu       sync.Mutex
+	attempts map[string]*memoryLoginAttempt
+}
+
+type memoryLoginAttempt struct {
+	failures      int
+	firstFailedAt time.Time
+	blockedUntil  time.Time
+	updatedAt     time.Time
+}
+
+var sharedMemoryLoginAttemptStore = &memoryLoginAttemptStore{
+	attempts: map[string]*memoryLoginAttempt{},
+}
+
+func (s *memoryLoginAttemptStore) Get(ctx context.Context, key string) (LoginAttemptState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempt, ok := s.attempts[key]
+	if !ok {
+		return LoginAttemptState{}, nil
+	}
+
+	return LoginAttemptState{Failures: attempt.failures, BlockedUntil: attempt.blockedUntil}, nil
+}
+
+func (s *memoryLoginAttemptStore) RecordFailure(ctx context.Context, key string, window time.Duration) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.pruneLocked(now, window)
+
+	attempt, ok := s.attempts[key]
+	if !ok || now.Sub(attempt.firstFailedAt) > window {
+		attempt = &memoryLoginAttempt{firstFailedAt: now}
+		s.attempts[key] = attempt
+	}
+
+	attempt.failures++
+	attempt.updatedAt = now
+
+	return attempt.failures, nil
+}
+
+func (s *memoryLoginAttemptStore) Block(ctx context.Context, key string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if attempt, ok := s.attempts[key]; ok {
+		attempt.blockedUntil = until
+		attempt.updatedAt = time.Now()
+	}
+
+	return nil
+}
+
+func (s *memoryLoginAttemptStore) Reset(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.attempts, key)
+
+	return nil
+}
+
+// pruneLocked drops entries that can no longer block anyone so memory does not grow forever
+func (s *memoryLoginAttemptStore) pruneLocked(now time.Time, window time.Duration) {
+	for key, attempt := range s.attempts {
+		if now.Sub(attempt.updatedAt) > window && now.After(attempt.blockedUntil) {
+			delete(s.attempts, key)
+		}
+	}
+}
+
+// postgresLoginAttemptStore keeps counters in the LoginAttempts table
+type postgresLoginAttemptStore struct {
+	DB *gorm.DB
+}
+
+func (s *postgresLoginAttemptStore) Get(ctx context.Context, key string) (LoginAttemptState, error) {
+	var attempt models.LoginAttempt
+
+	if err := s.DB.WithContext(ctx).Where("key = ?", key).First(&attempt).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return LoginAttemptState{}, nil
+		}
+		return LoginAttemptState{}, err
+	}
+
+	state := LoginAttemptState{Failures: attempt.Failures}
+	if attempt.BlockedUntil != nil {
+		state.BlockedUntil = *attempt.BlockedUntil
+	}
+
+	return state, nil
+}
+
+func (s *postgresLoginAttemptStore) RecordFailure(ctx context.Context, key string, window time.Duration) (int, error) {
+	now := time.Now()
+	var failures int
+
+	// A single upsert keeps the counter correct when several instances race on the same key
+	err := s.DB.WithContext(ctx).Raw(`
+		INSERT INTO "LoginAttempts" (key, failures, "firstFailedAt", "updatedAt")
+		VALUES (?, 1, ?, ?)
+		ON CONFLICT (key) DO UPDATE SET
+			failures = CASE WHEN "LoginAttempts"."firstFailedAt" < ? THEN 1 ELSE "LoginAttempts".failures + 1 END,
+			"firstFailedAt" = CASE WHEN "LoginAttempts"."firstFailedAt" < ? THEN EXCLUDED."firstFailedAt" ELSE "LoginAttempts"."firstFailedAt" END,
+			"updatedAt" = EXCLUDED."updatedAt"
+		RETURNING failures`,
+		key, now, now, now.Add(-window), now.Add(-window),
+	).Scan(&failures).Error
+
+	return failures, err
+}
+
+func (s *postgresLoginAttemptStore) Block(ctx context.Context, key string, until time.Time) error {
+	return s.DB.WithContext(ctx).Model(&models.LoginAttempt{}).Where("key = ?", key).Update("blockedUntil", until).Error
+}
+
+func (s *postgresLoginAttemptStore) Reset(ctx context.Context, key string) error {
+	return s.DB.WithContext(ctx).Where("key = ?", key).Delete(&models.LoginAttempt{}).Error
+}
@@ -0,0 +1,116 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+	"todo-api/config"
+
+	"go.uber.org/zap"
+)
+
+var testLoginProtection = config.LoginProtectionConfig{
+	FailureWindow:           time.Hour,
+	AccountFailureThreshold: 3,
+	IPFailureThreshold:      5,
+	LockoutDuration:         15 * time.Minute,
+	DelayBase:               time.Second,
+	MaxDelay:                4 * time.Second,
+}
+
+// newTestLoginAttemptRepository returns a repository with the given store and the test policy
+func newTestLoginAttemptRepository(store LoginAttemptStore) *LoginAttemptRepository {
+	return &LoginAttemptRepository{Logger: zap.NewNop(), store: store, policy: testLoginProtection}
+}
+
+func TestProgressiveDelay(t *testing.T) {
+	repo := newTestLoginAttemptRepository(nil)
+
+	for failures, want := range []time.Duration{0, time.Second, 2 * time.Second, 4 * time.Second, 4 * time.Second} {
+		if got := repo.progressiveDelay(failures); got != want {
+			t.Errorf("progressiveDelay(%d) = %s, want %s", failures, got, want)
+		}
+	}
+}
+
+// testLockout runs the lockout policy against a store, every store must behave the same
+func testLockout(t *testing.T, store LoginAttemptStore) {
+	repo := newTestLoginAttemptRepository(store)
+	ctx := context.Background()
+	suffix := time.Now().UnixNano()
+	email := fmt.Sprintf("Alice-%d@Example.com", suffix)
+	ipAddress := fmt.Sprintf("test-%d", suffix)
+	t.Cleanup(func() {
+		store.Reset(ctx, accountAttemptKey(email))
+		store.Reset(ctx, ipAttemptKey(ipAddress))
+	})
+
+	var lockedTimes int
+	for i := 1; i <= testLoginProtection.AccountFailureThreshold+1; i++ {
+		locked, err := repo.RecordFailure(ctx, email, ipAddress)
+		if err != nil {
+			t.Fatalf("RecordFailure failed: %v", err)
+		}
+		if locked {
+			lockedTimes++
+		}
+
+		wait, err := repo.RetryAfter(ctx, email, "another-ip")
+		if err != nil {
+			t.Fatalf("RetryAfter failed: %v", err)
+		}
+		if i < testLoginProtection.AccountFailureThreshold && wait > testLoginProtection.MaxDelay {
+			t.Errorf("after %d failures the wait is %s, want a progressive delay", i, wait)
+		}
+		if i >= testLoginProtection.AccountFailureThreshold && wait <= testLoginProtection.MaxDelay {
+			t.Errorf("after %d failures the wait is %s, want the lockout", i, wait)
+		}
+	}
+
+	// The owner is notified once, when the threshold is reached
+	if lockedTimes != 1 {
+		t.Errorf("locked reported %d times, want 1", lockedTimes)
+	}
+
+	// The email is compared without case and surrounding spaces
+	if wait, _ := repo.RetryAfter(ctx, " "+email, "another-ip"); wait == 0 {
+		t.Error("the locked account was allowed with a differently written email")
+	}
+
+	// A success clears the account, the IP keeps its delay
+	if err := repo.RecordSuccess(ctx, email); err != nil {
+		t.Fatalf("RecordSuccess failed: %v", err)
+	}
+	if wait, _ := repo.RetryAfter(ctx, email, "another-ip"); wait != 0 {
+		t.Errorf("wait after success = %s, want 0", wait)
+	}
+	if wait, _ := repo.RetryAfter(ctx, "bob@example.com", ipAddress); wait == 0 {
+		t.Error("the IP counter was reset by a success")
+	}
+}
+
+func TestLoginAttemptLockoutMemory(t *testing.T) {
+	testLockout(t, &memoryLoginAttemptStore{attempts: map[string]*memoryLoginAttempt{}})
+}
+
+func TestLoginAttemptLockoutPostgres(t *testing.T) {
+	testLockout(t, &postgresLoginAttemptStore{DB: openTestDB(t)})
+}
+
+func TestLoginAttemptThrottle(t *testing.T) {
+	repo := newTestLoginAttemptRepository(&memoryLoginAttemptStore{attempts: map[string]*memoryLoginAttempt{}})
+	ctx := context.Background()
+
+	for i := 1; i <= 3; i++ {
+		if wait, err := repo.Throttle(ctx, "test:throttle", 3, time.Minute); err != nil || wait != 0 {
+			t.Fatalf("request %d: wait = %s, %v, want allowed", i, wait, err)
+		}
+	}
+	if wait, err := repo.Throttle(ctx, "test:throttle", 3, time.Minute); err != nil || wait <= 0 {
+		t.Errorf("request over the limit: wait = %s, %v, want throttled", wait, err)
+	}
+	if wait, _ := repo.Throttle(ctx, "test:other", 3, time.Minute); wait != 0 {
+		t.Errorf("other key: wait = %s, want allowed", wait)
+	}
+}
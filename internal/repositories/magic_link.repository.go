@@ -0,0 +1,238 @@
+package repositories
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"time"
+	"todo-api/config"
+	"todo-api/database"
+	"todo-api/internal/dtos"
+	"todo-api/internal/jobs"
+	"todo-api/internal/mailer"
+	"todo-api/internal/models"
+	"todo-api/internal/utils"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type MagicLinkRepository struct {
+	DB            *gorm.DB
+	Logger        *zap.Logger
+	mailer        mailer.Mailer
+	background    *jobs.Queue
+	auth          *AuthRepository
+	loginAttempts *LoginAttemptRepository
+}
+
+func NewMagicLinkRepository(logger *zap.Logger) *MagicLinkRepository {
+	return &MagicLinkRepository{
+		DB:            database.GetDB(),
+		Logger:        logger,
+		mailer:        mailer.New(config.GetConfig().Mail, logger),
+		background:    jobs.Default(),
+		auth:          NewAuthRepository(logger),
+		loginAttempts: NewLoginAttemptRepository(logger),
+	}
+}
+
+// RequestLink emails a single-use login link when the account exists. Like the forgot password
+// flow it responds the same way either way. The link only works in the browser holding the
+// returned nonce, so a link forwarded or intercepted on its way cannot be used elsewhere.
+func (r *MagicLinkRepository) RequestLink(ctx context.Context, requestMagicLinkDto dtos.RequestMagicLinkDto) (dtos.StructuredResponse, error) {
+	magicLinkConfig := config.GetConfig().MagicLink
+	email := utils.NormalizeEmail(requestMagicLinkDto.Email)
+
+	// Limit both keys so the endpoint can neither flood one inbox nor be used to spam many
+	for _, limit := range []struct {
+		key   string
+		limit int
+	}{
+		{"magic-link:email:" + email, magicLinkConfig.EmailLimit},
+		{"magic-link:ip:" + requestMagicLinkDto.IPAddress, magicLinkConfig.IPLimit},
+	} {
+		retryAfter, err := r.loginAttempts.Throttle(ctx, limit.key, limit.limit, magicLinkConfig.RateWindow)
+		if err != nil {
+			r.Logger.Error("Failed to check magic link rate limit", zap.Error(err))
+			return dtos.StructuredResponse{
+				Success: false,
+				Status:  http.StatusInternalServerError,
+				Message: "Failed to send login link",
+				Payload: nil,
+			}, err
+		}
+		if retryAfter > 0 {
+			return dtos.StructuredResponse{
+				Success: false,
+				Status:  http.StatusTooManyRequests,
+				Message: "Too many login links requested, please try again later",
+				Payload: dtos.RateLimitedDto{
+					RetryAfter: int(math.Ceil(retryAfter.Seconds())),
+				},
+			}, nil
+		}
+	}
+
+	// Reuse the nonce of a browser that already asked, so its earlier links keep working
+	browserNonce := requestMagicLinkDto.BrowserCookie
+	if browserNonce == "" {
+		var err error
+		browserNonce, err = utils.GenerateRandomToken(32)
+		if err != nil {
+			r.Logger.Error("Failed to generate browser nonce", zap.Error(err))
+			return dtos.StructuredResponse{
+				Success: false,
+				Status:  http.StatusInternalServerError,
+				Message: "Failed to send login link",
+				Payload: nil,
+			}, err
+		}
+	}
+
+	// The browser gets its nonce whether or not the account exists
+	response := dtos.StructuredResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "If an account exists for this email, a login link has been sent",
+		Payload: dtos.MagicLinkSentDto{BrowserCookie: browserNonce},
+	}
+
+	// The rest depends on whether the account exists and happens after responding, so the
+	// response time does not tell either. A dropped job is logged by the queue.
+	r.background.Enqueue("login link", func(ctx context.Context) error {
+		return r.sendLoginLink(ctx, email, browserNonce)
+	})
+
+	return response, nil
+}
+
+// sendLoginLink stores a login link bound to the browser nonce and emails it, when an account
+// has the email. It runs as a background job of RequestLink.
+func (r *MagicLinkRepository) sendLoginLink(ctx context.Context, email string, browserNonce string) error {
+	magicLinkConfig := config.GetConfig().MagicLink
+
+	var user models.User
+
+	if err := r.DB.WithContext(ctx).Where("email = ?", email).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to find user: %w", err)
+	}
+
+	token, err := utils.GenerateActionToken(utils.PurposeMagicLink, user, magicLinkConfig.TTL)
+	if err != nil {
+		return fmt.Errorf("failed to generate magic link: %w", err)
+	}
+
+	magicLink := models.MagicLink{
+		UserID:      user.ID,
+		TokenHash:   utils.HashToken(token),
+		BrowserHash: utils.HashToken(browserNonce),
+		ExpiresAt:   time.Now().Add(magicLinkConfig.TTL),
+	}
+
+	if err := r.DB.WithContext(ctx).Create(&magicLink).Error; err != nil {
+		return fmt.Errorf("failed to store magic link: %w", err)
+	}
+
+	loginLink := fmt.Sprintf("%s/magic-link?token=%s", config.GetConfig().AppURL, url.QueryEscape(token))
+	message := mailer.Message{
+		To:      user.Email,
+		Subject: "Your login link",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nOpen the link below in the same browser you requested it from to log in:\n\n%s\n\nThe link expires in %s and can only be used once. If you did not ask for it, you can ignore this email.\n",
+			user.Name, loginLink, magicLinkConfig.TTL,
+		),
+	}
+
+	if err := r.mailer.Send(ctx, message); err != nil {
+		return fmt.Errorf("failed to send magic link email to user %d: %w", user.ID, err)
+	}
+
+	return nil
+}
+
+// Login exchanges a login link for tokens. The link must be unused, unexpired and opened in the
+// browser that requested it. Two-factor authentication still applies.
+func (r *MagicLinkRepository) Login(ctx context.Context, magicLinkLoginDto dtos.MagicLinkLoginDto) (dtos.StructuredResponse, error) {
+	invalidResponse := dtos.StructuredResponse{
+		Success: false,
+		Status:  http.StatusBadRequest,
+		Message: "Invalid or expired login link",
+		Payload: nil,
+	}
+
+	claims, err := utils.ValidateActionToken(magicLinkLoginDto.Token, utils.PurposeMagicLink)
+	if err != nil {
+		return invalidResponse, nil
+	}
+
+	var magicLink models.MagicLink
+
+	if err := r.DB.Preload("User").Where(`"tokenHash" = ?`, utils.HashToken(magicLinkLoginDto.Token)).First(&magicLink).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return invalidResponse, nil
+		}
+		r.Logger.Error("Failed to find magic link", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to login",
+			Payload: nil,
+		}, err
+	}
+
+	if magicLink.UsedAt != nil || time.Now().After(magicLink.ExpiresAt) || magicLink.User == nil {
+		return invalidResponse, nil
+	}
+
+	// A link sent to a previous address must not log in to the account once it has moved on
+	user := *magicLink.User
+	if user.ID != claims.UserID || user.Email != claims.Email {
+		return invalidResponse, nil
+	}
+
+	browserHash := utils.HashToken(magicLinkLoginDto.BrowserCookie)
+	if magicLinkLoginDto.BrowserCookie == "" || subtle.ConstantTimeCompare([]byte(browserHash), []byte(magicLink.BrowserHash)) != 1 {
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusForbidden,
+			Message: "Open the login link in the browser you requested it from",
+			Payload: nil,
+		}, nil
+	}
+
+	result := r.DB.Model(&models.MagicLink{}).
+		Where(`id = ? AND "usedAt" IS NULL`, magicLink.ID).
+		Update("usedAt", time.Now())
+	if result.Error != nil {
+		r.Logger.Error("Failed to consume magic link", zap.Error(result.Error))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to login",
+			Payload: nil,
+		}, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return invalidResponse, nil
+	}
+
+	// Opening the link proves the address works, as the verification link would
+	if user.EmailVerifiedAt == nil {
+		now := time.Now()
+		if err := r.DB.Model(&user).Update("emailVerifiedAt", now).Error; err != nil {
+			r.Logger.Warn("Failed to mark email as verified", zap.Uint("userId", user.ID), zap.Error(err))
+		} else {
+			user.EmailVerifiedAt = &now
+		}
+	}
+
+	return r.auth.finishLogin(ctx, user, "Magic link", magicLinkLoginDto.UserAgent, magicLinkLoginDto.IPAddress)
+}
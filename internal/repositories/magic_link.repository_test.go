@@ -0,0 +1,134 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+	"todo-api/config"
+	"todo-api/internal/dtos"
+	"todo-api/internal/jobs"
+	"todo-api/internal/models"
+	"todo-api/internal/utils"
+
+	"go.uber.org/zap"
+)
+
+func TestMagicLinkRepositoryRequestLinkThrottled(t *testing.T) {
+	magicLinkConfig := &config.GetConfig().MagicLink
+	previous := *magicLinkConfig
+	magicLinkConfig.EmailLimit = 1
+	magicLinkConfig.IPLimit = 5
+	magicLinkConfig.RateWindow = time.Hour
+	t.Cleanup(func() { *magicLinkConfig = previous })
+
+	// A throttled request is refused before the account is looked up, so no database is needed
+	repo := &MagicLinkRepository{
+		Logger:        zap.NewNop(),
+		loginAttempts: newTestLoginAttemptRepository(&memoryLoginAttemptStore{attempts: map[string]*memoryLoginAttempt{}}),
+	}
+	ctx := context.Background()
+
+	if wait, err := repo.loginAttempts.Throttle(ctx, "magic-link:email:alice@example.com", 1, time.Hour); err != nil || wait != 0 {
+		t.Fatalf("Throttle = %s, %v, want allowed", wait, err)
+	}
+
+	// The limit counts the address however it is written
+	response, err := repo.RequestLink(ctx, dtos.RequestMagicLinkDto{Email: " Alice@Example.com", IPAddress: "203.0.113.1"})
+	if err != nil {
+		t.Fatalf("RequestLink failed: %v", err)
+	}
+	if response.Status != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want 429", response.Status)
+	}
+	if limited, ok := response.Payload.(dtos.RateLimitedDto); !ok || limited.RetryAfter <= 0 {
+		t.Errorf("payload = %#v, want a retry delay", response.Payload)
+	}
+}
+
+func TestMagicLinkRepositoryRequestLinkQueuesEmail(t *testing.T) {
+	magicLinkConfig := &config.GetConfig().MagicLink
+	previous := *magicLinkConfig
+	magicLinkConfig.EmailLimit = 5
+	magicLinkConfig.IPLimit = 5
+	magicLinkConfig.RateWindow = time.Hour
+	t.Cleanup(func() { *magicLinkConfig = previous })
+
+	// Without workers the queued job stays put, so no database is needed
+	queue := jobs.NewQueue(0, 1, time.Second, zap.NewNop())
+	repo := &MagicLinkRepository{
+		Logger:        zap.NewNop(),
+		background:    queue,
+		loginAttempts: newTestLoginAttemptRepository(&memoryLoginAttemptStore{attempts: map[string]*memoryLoginAttempt{}}),
+	}
+
+	response, err := repo.RequestLink(context.Background(), dtos.RequestMagicLinkDto{
+		Email:         "alice@example.com",
+		IPAddress:     "203.0.113.1",
+		BrowserCookie: "earlier-nonce",
+	})
+	if err != nil {
+		t.Fatalf("RequestLink failed: %v", err)
+	}
+	if response.Status != http.StatusOK {
+		t.Fatalf("status %d, want %d", response.Status, http.StatusOK)
+	}
+	if sent, ok := response.Payload.(dtos.MagicLinkSentDto); !ok || sent.BrowserCookie != "earlier-nonce" {
+		t.Errorf("payload %#v, want the browser's earlier nonce", response.Payload)
+	}
+
+	// The single slot of the queue is taken by the login link job
+	if err := queue.Enqueue("probe", func(ctx context.Context) error { return nil }); !errors.Is(err, jobs.ErrQueueFull) {
+		t.Errorf("probe Enqueue = %v, want ErrQueueFull", err)
+	}
+}
+
+func TestMagicLinkRepositoryLogin(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewMagicLinkRepository(zap.NewNop())
+	ctx := context.Background()
+
+	user := createTestAccount(t, db, "alice")
+
+	token, err := utils.GenerateActionToken(utils.PurposeMagicLink, user, time.Hour)
+	if err != nil {
+		t.Fatalf("GenerateActionToken failed: %v", err)
+	}
+	magicLink := models.MagicLink{
+		UserID:      user.ID,
+		TokenHash:   utils.HashToken(token),
+		BrowserHash: utils.HashToken("browser-nonce"),
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}
+	if err := db.Create(&magicLink).Error; err != nil {
+		t.Fatalf("failed to store magic link: %v", err)
+	}
+
+	login := func(t *testing.T, browserCookie string) int {
+		t.Helper()
+
+		response, err := repo.Login(ctx, dtos.MagicLinkLoginDto{Token: token, BrowserCookie: browserCookie})
+		if err != nil {
+			t.Fatalf("Login failed: %v", err)
+		}
+		return response.Status
+	}
+
+	// Another browser cannot use the link, and trying does not use it up
+	if status := login(t, "other-nonce"); status != http.StatusForbidden {
+		t.Fatalf("other browser: status = %d, want 403", status)
+	}
+	if status := login(t, ""); status != http.StatusForbidden {
+		t.Fatalf("no browser cookie: status = %d, want 403", status)
+	}
+
+	if status := login(t, "browser-nonce"); status != http.StatusOK {
+		t.Fatalf("requesting browser: status = %d, want 200", status)
+	}
+
+	// The link works once
+	if status := login(t, "browser-nonce"); status != http.StatusBadRequest {
+		t.Errorf("second use: status = %d, want 400", status)
+	}
+}
@@ -0,0 +1,673 @@
+package repositories
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"todo-api/config"
+	"todo-api/database"
+	"todo-api/internal/dtos"
+	"todo-api/internal/models"
+	"todo-api/internal/utils"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidOAuthToken is returned when an OAuth2 access token is unknown, revoked or expired
+var ErrInvalidOAuthToken = errors.New("invalid OAuth access token")
+
+// errOAuthGrantReused is returned inside a token transaction when the authorization code or
+// refresh token was already exchanged by a concurrent request
+var errOAuthGrantReused = errors.New("authorization grant already used")
+
+// OAuth2 grant types supported by the token endpoint
+const (
+	grantTypeAuthorizationCode = "authorization_code"
+	grantTypeRefreshToken      = "refresh_token"
+)
+
+// OAuthRepository implements the OAuth2 authorization server: the consent screen, the token
+// endpoint with the authorization code grant and PKCE, introspection and revocation
+type OAuthRepository struct {
+	DB             *gorm.DB
+	Logger         *zap.Logger
+	securityEvents *SecurityEventRepository
+}
+
+func NewOAuthRepository(logger *zap.Logger) *OAuthRepository {
+	return &OAuthRepository{
+		DB:             database.GetDB(),
+		Logger:         logger,
+		securityEvents: NewSecurityEventRepository(logger),
+	}
+}
+
+// GetAuthorization checks an authorization request and describes it for the consent screen
+func (r *OAuthRepository) GetAuthorization(ctx context.Context, authorizationDto dtos.OAuthAuthorizationRequestDto) (dtos.StructuredResponse, error) {
+	client, scopes, refusal, err := r.checkAuthorizationRequest(ctx, authorizationDto)
+	if refusal != nil || err != nil {
+		return *refusal, err
+	}
+
+	scopeDtos := make([]dtos.OAuthScopeDto, 0, len(scopes))
+	for _, scope := range scopes {
+		scopeDtos = append(scopeDtos, dtos.OAuthScopeDto{Name: scope, Description: models.OAuthScopes[scope]})
+	}
+
+	return dtos.StructuredResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Authorization request is valid",
+		Payload: dtos.OAuthConsentDto{
+			ClientID:   client.ClientID,
+			ClientName: client.Name,
+			Scopes:     scopeDtos,
+		},
+	}, nil
+}
+
+// Authorize records the user's decision on the consent screen. Approval creates an
+// authorization code, either way the payload holds the URL that takes the browser back to the app.
+func (r *OAuthRepository) Authorize(ctx context.Context, authorizationDto dtos.OAuthAuthorizationRequestDto) (dtos.StructuredResponse, error) {
+	client, scopes, refusal, err := r.checkAuthorizationRequest(ctx, authorizationDto)
+	if refusal != nil || err != nil {
+		return *refusal, err
+	}
+
+	redirectURI := effectiveRedirectURI(client, authorizationDto.RedirectURI)
+
+	if !authorizationDto.Approve {
+		return dtos.StructuredResponse{
+			Success: true,
+			Status:  http.StatusOK,
+			Message: "Access denied",
+			Payload: dtos.OAuthRedirectDto{
+				RedirectURI: authorizationRedirect(redirectURI, url.Values{
+					"error":             {"access_denied"},
+					"error_description": {"The user denied access"},
+				}, authorizationDto.State),
+			},
+		}, nil
+	}
+
+	code, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		r.Logger.Error("Failed to generate authorization code", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to authorize app",
+			Payload: nil,
+		}, err
+	}
+
+	authorizationCode := models.OAuthAuthorizationCode{
+		CodeHash:      utils.HashToken(code),
+		ClientID:      client.ID,
+		UserID:        authorizationDto.UserID,
+		RedirectURI:   authorizationDto.RedirectURI,
+		Scopes:        strings.Join(scopes, " "),
+		CodeChallenge: authorizationDto.CodeChallenge,
+		ExpiresAt:     time.Now().Add(config.GetConfig().OAuthServer.AuthorizationCodeTTL),
+	}
+
+	if err := r.DB.WithContext(ctx).Create(&authorizationCode).Error; err != nil {
+		r.Logger.Error("Failed to store authorization code", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to authorize app",
+			Payload: nil,
+		}, err
+	}
+
+	// Expired codes are useless, used ones only matter for reuse detection while they are valid
+	if err := r.DB.WithContext(ctx).Where(`"expiresAt" < ?`, time.Now()).Delete(&models.OAuthAuthorizationCode{}).Error; err != nil {
+		r.Logger.Warn("Failed to delete expired authorization codes", zap.Error(err))
+	}
+
+	return dtos.StructuredResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "App authorized successfully",
+		Payload: dtos.OAuthRedirectDto{
+			RedirectURI: authorizationRedirect(redirectURI, url.Values{"code": {code}}, authorizationDto.State),
+		},
+	}, nil
+}
+
+// Token implements the token endpoint. The payload is a dtos.OAuthTokenResponseDto on success
+// and a dtos.OAuthErrorDto otherwise, as RFC 6749 prescribes.
+func (r *OAuthRepository) Token(ctx context.Context, tokenDto dtos.OAuthTokenRequestDto) (dtos.StructuredResponse, error) {
+	client, refusal, err := r.authenticateClient(ctx, tokenDto.ClientID, tokenDto.ClientSecret)
+	if refusal != nil || err != nil {
+		return *refusal, err
+	}
+
+	switch tokenDto.GrantType {
+	case grantTypeAuthorizationCode:
+		return r.exchangeAuthorizationCode(ctx, client, tokenDto)
+	case grantTypeRefreshToken:
+		return r.refreshAccessToken(ctx, client, tokenDto)
+	case "":
+		return oauthErrorResponse(http.StatusBadRequest, "invalid_request", "grant_type is required"), nil
+	default:
+		return oauthErrorResponse(http.StatusBadRequest, "unsupported_grant_type", "Only authorization_code and refresh_token are supported"), nil
+	}
+}
+
+// Introspect reports whether a token of the calling app can be used (RFC 7662). Tokens of
+// other apps are reported as inactive.
+func (r *OAuthRepository) Introspect(ctx context.Context, referenceDto dtos.OAuthTokenReferenceDto) (dtos.StructuredResponse, error) {
+	client, refusal, err := r.authenticateClient(ctx, referenceDto.ClientID, referenceDto.ClientSecret)
+	if refusal != nil || err != nil {
+		return *refusal, err
+	}
+
+	introspection := dtos.OAuthIntrospectionDto{Active: false}
+
+	token, isRefreshToken, err := r.findToken(ctx, client.ID, referenceDto.Token)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		r.Logger.Error("Failed to look up OAuth token", zap.Error(err))
+		return oauthErrorResponse(http.StatusInternalServerError, "server_error", "Failed to introspect token"), err
+	}
+
+	if err == nil {
+		expiresAt, tokenType := token.AccessExpiresAt, "access_token"
+		if isRefreshToken {
+			expiresAt, tokenType = token.RefreshExpiresAt, "refresh_token"
+		}
+
+		if token.RevokedAt == nil && time.Now().Before(expiresAt) && token.User != nil && token.User.DisabledAt == nil {
+			introspection = dtos.OAuthIntrospectionDto{
+				Active:    true,
+				Scope:     token.Scopes,
+				ClientID:  client.ClientID,
+				Username:  token.User.Email,
+				Subject:   strconv.FormatUint(uint64(token.UserID), 10),
+				TokenType: tokenType,
+				ExpiresAt: expiresAt.Unix(),
+				IssuedAt:  token.CreatedAt.Unix(),
+			}
+		}
+	}
+
+	return dtos.StructuredResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Token introspected",
+		Payload: introspection,
+	}, nil
+}
+
+// Revoke revokes a token of the calling app (RFC 7009). Revoking a refresh token also revokes
+// the rest of its chain. Unknown tokens are not an error, the app only learns the token is gone.
+func (r *OAuthRepository) Revoke(ctx context.Context, referenceDto dtos.OAuthTokenReferenceDto) (dtos.StructuredResponse, error) {
+	client, refusal, err := r.authenticateClient(ctx, referenceDto.ClientID, referenceDto.ClientSecret)
+	if refusal != nil || err != nil {
+		return *refusal, err
+	}
+
+	token, isRefreshToken, err := r.findToken(ctx, client.ID, referenceDto.Token)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		r.Logger.Error("Failed to look up OAuth token", zap.Error(err))
+		return oauthErrorResponse(http.StatusServiceUnavailable, "temporarily_unavailable", "Failed to revoke token"), err
+	}
+
+	if err == nil && token.RevokedAt == nil {
+		query := r.DB.WithContext(ctx).Model(&models.OAuthToken{}).Where(`"revokedAt" IS NULL`)
+		if isRefreshToken {
+			query = query.Where(`"clientId" = ? AND "grantId" = ?`, token.ClientID, token.GrantID)
+		} else {
+			query = query.Where("id = ?", token.ID)
+		}
+
+		if err := query.Update("revokedAt", time.Now()).Error; err != nil {
+			r.Logger.Error("Failed to revoke OAuth token", zap.Error(err))
+			return oauthErrorResponse(http.StatusServiceUnavailable, "temporarily_unavailable", "Failed to revoke token"), err
+		}
+
+		r.securityEvents.Record(ctx, models.SecurityEvent{
+			UserID:  token.UserID,
+			Type:    models.SecurityEventTokenRevoked,
+			Details: fmt.Sprintf("Access of app %q revoked by the app", client.Name),
+		})
+	}
+
+	return dtos.StructuredResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Token revoked",
+		Payload: nil,
+	}, nil
+}
+
+// Authenticate resolves an OAuth2 access token into claims equivalent to those of a JWT.
+// The permissions are the intersection of the granted scopes and the user's current
+// permissions, like for personal access tokens.
+func (r *OAuthRepository) Authenticate(ctx context.Context, accessToken string) (*utils.JWTClaims, error) {
+	var token models.OAuthToken
+
+	if err := r.DB.WithContext(ctx).Preload("User.Roles.Permissions").Preload("Client").
+		Where(`"accessTokenHash" = ?`, utils.HashToken(accessToken)).
+		First(&token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidOAuthToken
+		}
+		return nil, err
+	}
+
+	if token.RevokedAt != nil || time.Now().After(token.AccessExpiresAt) || token.User == nil || token.Client == nil {
+		return nil, ErrInvalidOAuthToken
+	}
+
+	if token.User.DisabledAt != nil {
+		return nil, ErrInvalidOAuthToken
+	}
+
+	user := *token.User
+	scopes := token.ScopeList()
+	permissions := slices.DeleteFunc(user.PermissionNames(), func(permission string) bool {
+		return !slices.Contains(scopes, permission)
+	})
+
+	return &utils.JWTClaims{
+		UserID:      user.ID,
+		Email:       user.Email,
+		Name:        user.Name,
+		ReadOnly:    user.EmailVerifiedAt == nil,
+		Roles:       user.RoleNames(),
+		Permissions: permissions,
+		TokenType:   utils.TokenTypeOAuth,
+		ClientID:    token.Client.ClientID,
+	}, nil
+}
+
+// checkAuthorizationRequest validates an authorization request and resolves its scopes. An
+// unknown client or redirect URI is reported to the user, since sending them to an unverified
+// URI would make the server an open redirector. Other problems are reported to the app through
+// the payload's redirect URI.
+func (r *OAuthRepository) checkAuthorizationRequest(ctx context.Context, authorizationDto dtos.OAuthAuthorizationRequestDto) (models.OAuthClient, []string, *dtos.StructuredResponse, error) {
+	var client models.OAuthClient
+
+	if err := r.DB.WithContext(ctx).Where(`"clientId" = ?`, authorizationDto.ClientID).First(&client).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return client, nil, &dtos.StructuredResponse{
+				Success: false,
+				Status:  http.StatusBadRequest,
+				Message: "Unknown client_id",
+				Payload: nil,
+			}, nil
+		}
+		r.Logger.Error("Failed to find OAuth client", zap.Error(err))
+		return client, nil, &dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to check authorization request",
+			Payload: nil,
+		}, err
+	}
+
+	redirectURI := effectiveRedirectURI(client, authorizationDto.RedirectURI)
+	if !slices.Contains(client.RedirectURIList(), redirectURI) {
+		return client, nil, &dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusBadRequest,
+			Message: "redirect_uri is not registered for this app",
+			Payload: nil,
+		}, nil
+	}
+
+	refuse := func(errorCode string, description string) (models.OAuthClient, []string, *dtos.StructuredResponse, error) {
+		return client, nil, &dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusBadRequest,
+			Message: description,
+			Payload: dtos.OAuthRedirectDto{
+				RedirectURI: authorizationRedirect(redirectURI, url.Values{
+					"error":             {errorCode},
+					"error_description": {description},
+				}, authorizationDto.State),
+			},
+		}, nil
+	}
+
+	if authorizationDto.ResponseType != "code" {
+		return refuse("unsupported_response_type", "response_type must be code")
+	}
+
+	// PKCE protects the code of public clients and stops code injection for all of them
+	if authorizationDto.CodeChallengeMethod != "S256" || len(authorizationDto.CodeChallenge) < 43 || len(authorizationDto.CodeChallenge) > 128 {
+		return refuse("invalid_request", "A code_challenge with code_challenge_method S256 is required")
+	}
+
+	scopes := strings.Fields(authorizationDto.Scope)
+	if len(scopes) == 0 {
+		scopes = client.ScopeList()
+	}
+	for _, scope := range scopes {
+		if !slices.Contains(client.ScopeList(), scope) {
+			return refuse("invalid_scope", fmt.Sprintf("Scope %q is not available to this app", scope))
+		}
+	}
+	slices.Sort(scopes)
+
+	return client, slices.Compact(scopes), nil, nil
+}
+
+// exchangeAuthorizationCode implements the authorization_code grant
+func (r *OAuthRepository) exchangeAuthorizationCode(ctx context.Context, client models.OAuthClient, tokenDto dtos.OAuthTokenRequestDto) (dtos.StructuredResponse, error) {
+	if tokenDto.Code == "" || tokenDto.CodeVerifier == "" {
+		return oauthErrorResponse(http.StatusBadRequest, "invalid_request", "code and code_verifier are required"), nil
+	}
+
+	var authorizationCode models.OAuthAuthorizationCode
+
+	if err := r.DB.WithContext(ctx).Preload("User").
+		Where(`"codeHash" = ? AND "clientId" = ?`, utils.HashToken(tokenDto.Code), client.ID).
+		First(&authorizationCode).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return oauthErrorResponse(http.StatusBadRequest, "invalid_grant", "Invalid authorization code"), nil
+		}
+		r.Logger.Error("Failed to find authorization code", zap.Error(err))
+		return oauthErrorResponse(http.StatusInternalServerError, "server_error", "Failed to issue token"), err
+	}
+
+	if authorizationCode.UsedAt != nil {
+		return r.grantReused(ctx, client, authorizationCode.UserID, authorizationCode.ID, "Authorization code used twice")
+	}
+
+	if time.Now().After(authorizationCode.ExpiresAt) {
+		return oauthErrorResponse(http.StatusBadRequest, "invalid_grant", "Authorization code has expired"), nil
+	}
+
+	if tokenDto.RedirectURI != authorizationCode.RedirectURI {
+		return oauthErrorResponse(http.StatusBadRequest, "invalid_grant", "redirect_uri does not match the authorization request"), nil
+	}
+
+	if !utils.VerifyPKCE(tokenDto.CodeVerifier, authorizationCode.CodeChallenge) {
+		return oauthErrorResponse(http.StatusBadRequest, "invalid_grant", "Invalid code_verifier"), nil
+	}
+
+	if authorizationCode.User == nil || authorizationCode.User.DisabledAt != nil {
+		return oauthErrorResponse(http.StatusBadRequest, "invalid_grant", "The account is not available"), nil
+	}
+
+	var tokenResponse dtos.OAuthTokenResponseDto
+
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Only one of several concurrent exchanges of the same code may succeed
+		result := tx.Model(&models.OAuthAuthorizationCode{}).
+			Where(`id = ? AND "usedAt" IS NULL`, authorizationCode.ID).
+			Update("usedAt", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errOAuthGrantReused
+		}
+
+		var err error
+		tokenResponse, err = issueOAuthTokens(tx, client.ID, authorizationCode.UserID, authorizationCode.ID, strings.Fields(authorizationCode.Scopes))
+		return err
+	})
+
+	if errors.Is(err, errOAuthGrantReused) {
+		return r.grantReused(ctx, client, authorizationCode.UserID, authorizationCode.ID, "Authorization code used twice")
+	}
+
+	if err != nil {
+		r.Logger.Error("Failed to issue OAuth tokens", zap.Error(err))
+		return oauthErrorResponse(http.StatusInternalServerError, "server_error", "Failed to issue token"), err
+	}
+
+	r.securityEvents.Record(ctx, models.SecurityEvent{
+		UserID:  authorizationCode.UserID,
+		Type:    models.SecurityEventTokenCreated,
+		Details: fmt.Sprintf("App %q authorized with scopes %s", client.Name, tokenResponse.Scope),
+	})
+
+	return dtos.StructuredResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Token issued",
+		Payload: tokenResponse,
+	}, nil
+}
+
+// refreshAccessToken implements the refresh_token grant. The refresh token is rotated, the new
+// pair may be limited to fewer scopes.
+func (r *OAuthRepository) refreshAccessToken(ctx context.Context, client models.OAuthClient, tokenDto dtos.OAuthTokenRequestDto) (dtos.StructuredResponse, error) {
+	if tokenDto.RefreshToken == "" {
+		return oauthErrorResponse(http.StatusBadRequest, "invalid_request", "refresh_token is required"), nil
+	}
+
+	var storedToken models.OAuthToken
+
+	if err := r.DB.WithContext(ctx).Preload("User").
+		Where(`"refreshTokenHash" = ? AND "clientId" = ?`, utils.HashToken(tokenDto.RefreshToken), client.ID).
+		First(&storedToken).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return oauthErrorResponse(http.StatusBadRequest, "invalid_grant", "Invalid refresh token"), nil
+		}
+		r.Logger.Error("Failed to find OAuth refresh token", zap.Error(err))
+		return oauthErrorResponse(http.StatusInternalServerError, "server_error", "Failed to issue token"), err
+	}
+
+	if storedToken.RevokedAt != nil {
+		return r.grantReused(ctx, client, storedToken.UserID, storedToken.GrantID, "Refresh token used twice")
+	}
+
+	if time.Now().After(storedToken.RefreshExpiresAt) {
+		return oauthErrorResponse(http.StatusBadRequest, "invalid_grant", "Refresh token has expired"), nil
+	}
+
+	if storedToken.User == nil || storedToken.User.DisabledAt != nil {
+		return oauthErrorResponse(http.StatusBadRequest, "invalid_grant", "The account is not available"), nil
+	}
+
+	scopes := storedToken.ScopeList()
+	if requested := strings.Fields(tokenDto.Scope); len(requested) > 0 {
+		for _, scope := range requested {
+			if !slices.Contains(scopes, scope) {
+				return oauthErrorResponse(http.StatusBadRequest, "invalid_scope", fmt.Sprintf("Scope %q was not granted", scope)), nil
+			}
+		}
+		scopes = requested
+	}
+
+	var tokenResponse dtos.OAuthTokenResponseDto
+
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.OAuthToken{}).
+			Where(`id = ? AND "revokedAt" IS NULL`, storedToken.ID).
+			Update("revokedAt", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errOAuthGrantReused
+		}
+
+		var err error
+		tokenResponse, err = issueOAuthTokens(tx, client.ID, storedToken.UserID, storedToken.GrantID, scopes)
+		return err
+	})
+
+	if errors.Is(err, errOAuthGrantReused) {
+		return r.grantReused(ctx, client, storedToken.UserID, storedToken.GrantID, "Refresh token used twice")
+	}
+
+	if err != nil {
+		r.Logger.Error("Failed to refresh OAuth tokens", zap.Error(err))
+		return oauthErrorResponse(http.StatusInternalServerError, "server_error", "Failed to issue token"), err
+	}
+
+	return dtos.StructuredResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Token issued",
+		Payload: tokenResponse,
+	}, nil
+}
+
+// grantReused revokes every token of a grant whose code or refresh token was presented twice.
+// One of the two requests came from someone who should not have it, and there is no telling which.
+func (r *OAuthRepository) grantReused(ctx context.Context, client models.OAuthClient, userID uint, grantID uint, reason string) (dtos.StructuredResponse, error) {
+	if err := r.DB.WithContext(ctx).Model(&models.OAuthToken{}).
+		Where(`"clientId" = ? AND "grantId" = ? AND "revokedAt" IS NULL`, client.ID, grantID).
+		Update("revokedAt", time.Now()).Error; err != nil {
+		r.Logger.Error("Failed to revoke reused OAuth grant", zap.Error(err))
+		return oauthErrorResponse(http.StatusInternalServerError, "server_error", "Failed to issue token"), err
+	}
+
+	r.Logger.Warn("OAuth grant reused", zap.String("clientId", client.ClientID), zap.Uint("userId", userID), zap.Uint("grantId", grantID))
+	r.securityEvents.Record(ctx, models.SecurityEvent{
+		UserID:  userID,
+		Type:    models.SecurityEventRefreshTokenReused,
+		Details: fmt.Sprintf("%s by app %q, its tokens were revoked", reason, client.Name),
+	})
+
+	return oauthErrorResponse(http.StatusBadRequest, "invalid_grant", "The grant has already been used"), nil
+}
+
+// authenticateClient checks the client credentials of a token, introspection or revocation
+// request. Confidential clients must send their secret, public clients only their ID.
+func (r *OAuthRepository) authenticateClient(ctx context.Context, clientID string, clientSecret string) (models.OAuthClient, *dtos.StructuredResponse, error) {
+	var client models.OAuthClient
+
+	if clientID == "" {
+		response := oauthErrorResponse(http.StatusUnauthorized, "invalid_client", "Client authentication is required")
+		return client, &response, nil
+	}
+
+	if err := r.DB.WithContext(ctx).Where(`"clientId" = ?`, clientID).First(&client).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			response := oauthErrorResponse(http.StatusUnauthorized, "invalid_client", "Client authentication failed")
+			return client, &response, nil
+		}
+		r.Logger.Error("Failed to find OAuth client", zap.Error(err))
+		response := oauthErrorResponse(http.StatusInternalServerError, "server_error", "Failed to authenticate client")
+		return client, &response, err
+	}
+
+	valid := clientSecret == ""
+	if client.Confidential() {
+		valid = subtle.ConstantTimeCompare([]byte(utils.HashToken(clientSecret)), []byte(client.SecretHash)) == 1
+	}
+
+	if !valid {
+		r.Logger.Warn("OAuth client authentication failed", zap.String("clientId", clientID))
+		response := oauthErrorResponse(http.StatusUnauthorized, "invalid_client", "Client authentication failed")
+		return client, &response, nil
+	}
+
+	return client, nil, nil
+}
+
+// findToken looks up an access or refresh token of the client, the prefix tells which it is
+func (r *OAuthRepository) findToken(ctx context.Context, clientID uint, token string) (models.OAuthToken, bool, error) {
+	var storedToken models.OAuthToken
+
+	column, isRefreshToken := `"accessTokenHash"`, false
+	if strings.HasPrefix(token, utils.OAuthRefreshTokenPrefix) {
+		column, isRefreshToken = `"refreshTokenHash"`, true
+	} else if !strings.HasPrefix(token, utils.OAuthAccessTokenPrefix) {
+		return storedToken, false, gorm.ErrRecordNotFound
+	}
+
+	err := r.DB.WithContext(ctx).Preload("User").
+		Where(column+` = ? AND "clientId" = ?`, utils.HashToken(token), clientID).
+		First(&storedToken).Error
+
+	return storedToken, isRefreshToken, err
+}
+
+// issueOAuthTokens creates a new access and refresh token pair within the transaction
+func issueOAuthTokens(tx *gorm.DB, clientID uint, userID uint, grantID uint, scopes []string) (dtos.OAuthTokenResponseDto, error) {
+	accessToken, err := utils.GeneratePrefixedToken(utils.OAuthAccessTokenPrefix, 32)
+	if err != nil {
+		return dtos.OAuthTokenResponseDto{}, err
+	}
+
+	refreshToken, err := utils.GeneratePrefixedToken(utils.OAuthRefreshTokenPrefix, 32)
+	if err != nil {
+		return dtos.OAuthTokenResponseDto{}, err
+	}
+
+	oauthConfig := config.GetConfig().OAuthServer
+	now := time.Now()
+
+	token := models.OAuthToken{
+		ClientID:         clientID,
+		UserID:           userID,
+		GrantID:          grantID,
+		AccessTokenHash:  utils.HashToken(accessToken),
+		RefreshTokenHash: utils.HashToken(refreshToken),
+		Scopes:           strings.Join(scopes, " "),
+		AccessExpiresAt:  now.Add(oauthConfig.AccessTokenTTL),
+		RefreshExpiresAt: now.Add(oauthConfig.RefreshTokenTTL),
+	}
+
+	if err := tx.Create(&token).Error; err != nil {
+		return dtos.OAuthTokenResponseDto{}, err
+	}
+
+	return dtos.OAuthTokenResponseDto{
+		AccessToken:  accessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(oauthConfig.AccessTokenTTL.Seconds()),
+		RefreshToken: refreshToken,
+		Scope:        token.Scopes,
+	}, nil
+}
+
+// effectiveRedirectURI returns the redirect URI of a request, which may be left out when the
+// app registered only one
+func effectiveRedirectURI(client models.OAuthClient, redirectURI string) string {
+	if registered := client.RedirectURIList(); redirectURI == "" && len(registered) == 1 {
+		return registered[0]
+	}
+	return redirectURI
+}
+
+// authorizationRedirect adds the response parameters and the state to the app's redirect URI,
+// keeping any query it already has
+func authorizationRedirect(redirectURI string, params url.Values, state string) string {
+	target, err := url.Parse(redirectURI)
+	if err != nil {
+		return redirectURI
+	}
+
+	query := target.Query()
+	for key, values := range params {
+		query[key] = values
+	}
+	if state != "" {
+		query.Set("state", state)
+	}
+	target.RawQuery = query.Encode()
+
+	return target.String()
+}
+
+// oauthErrorResponse builds an RFC 6749 error response
+func oauthErrorResponse(status int, errorCode string, description string) dtos.StructuredResponse {
+	return dtos.StructuredResponse{
+		Success: false,
+		Status:  status,
+		Message: description,
+		Payload: dtos.OAuthErrorDto{
+			Error:            errorCode,
+			ErrorDescription: description,
+		},
+	}
+}
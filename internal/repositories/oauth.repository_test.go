@@ -0,0 +1,179 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+	"todo-api/internal/dtos"
+	"todo-api/internal/models"
+
+	"go.uber.org/zap"
+)
+
+// Example verifier and challenge of RFC 7636 appendix B
+const (
+	testCodeVerifier  = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	testCodeChallenge = "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+)
+
+func TestEffectiveRedirectURI(t *testing.T) {
+	single := models.OAuthClient{RedirectURIs: "https://app.example.com/callback"}
+	several := models.OAuthClient{RedirectURIs: "https://app.example.com/callback https://app.example.com/other"}
+
+	tests := []struct {
+		name        string
+		client      models.OAuthClient
+		redirectURI string
+		want        string
+	}{
+		{"left out with one registered", single, "", "https://app.example.com/callback"},
+		{"left out with several registered", several, "", ""},
+		{"given", several, "https://app.example.com/other", "https://app.example.com/other"},
+	}
+
+	for _, tt := range tests {
+		if got := effectiveRedirectURI(tt.client, tt.redirectURI); got != tt.want {
+			t.Errorf("%s: effectiveRedirectURI = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAuthorizationRedirect(t *testing.T) {
+	got := authorizationRedirect("https://app.example.com/callback?tenant=7", url.Values{"code": {"abc"}}, "state-1")
+
+	redirect, err := url.Parse(got)
+	if err != nil {
+		t.Fatalf("invalid redirect %q: %v", got, err)
+	}
+	query := redirect.Query()
+	if redirect.Host != "app.example.com" || redirect.Path != "/callback" {
+		t.Errorf("redirect = %q, want the registered URI", got)
+	}
+	// The app's own query is kept next to the response parameters
+	if query.Get("tenant") != "7" || query.Get("code") != "abc" || query.Get("state") != "state-1" {
+		t.Errorf("redirect query = %v, want tenant, code and state", query)
+	}
+
+	if got := authorizationRedirect("https://app.example.com/callback", url.Values{"error": {"access_denied"}}, ""); got != "https://app.example.com/callback?error=access_denied" {
+		t.Errorf("redirect without state = %q", got)
+	}
+}
+
+func TestOAuthRepositoryAuthorizationCode(t *testing.T) {
+	db := openTestDB(t)
+	repo := &OAuthRepository{DB: db, Logger: zap.NewNop(), securityEvents: NewSecurityEventRepository(zap.NewNop())}
+	ctx := context.Background()
+
+	redirectURI := "https://app.example.com/callback"
+	client := createTestOAuthClient(t, db, redirectURI)
+	user := createTestAccount(t, db, "alice")
+
+	// authorize approves the request and returns the query the browser is sent back with
+	authorize := func(t *testing.T, method string) url.Values {
+		t.Helper()
+
+		response, err := repo.Authorize(ctx, dtos.OAuthAuthorizationRequestDto{
+			ResponseType:        "code",
+			ClientID:            client.ClientID,
+			RedirectURI:         redirectURI,
+			Scope:               models.PermissionTodosRead,
+			State:               "state-1",
+			CodeChallenge:       testCodeChallenge,
+			CodeChallengeMethod: method,
+			Approve:             true,
+			UserID:              user.ID,
+		})
+		if err != nil {
+			t.Fatalf("Authorize failed: %v", err)
+		}
+		redirect, err := url.Parse(response.Payload.(dtos.OAuthRedirectDto).RedirectURI)
+		if err != nil {
+			t.Fatalf("invalid redirect: %v", err)
+		}
+		if redirect.Query().Get("state") != "state-1" {
+			t.Errorf("state = %q, want state-1", redirect.Query().Get("state"))
+		}
+		return redirect.Query()
+	}
+
+	exchange := func(t *testing.T, code string, verifier string, redirect string) dtos.StructuredResponse {
+		t.Helper()
+
+		response, err := repo.Token(ctx, dtos.OAuthTokenRequestDto{
+			GrantType:    grantTypeAuthorizationCode,
+			Code:         code,
+			RedirectURI:  redirect,
+			CodeVerifier: verifier,
+			ClientID:     client.ClientID,
+		})
+		if err != nil {
+			t.Fatalf("Token failed: %v", err)
+		}
+		return response
+	}
+
+	oauthError := func(response dtos.StructuredResponse) string {
+		oauthErr, _ := response.Payload.(dtos.OAuthErrorDto)
+		return oauthErr.Error
+	}
+
+	t.Run("plain PKCE is refused", func(t *testing.T) {
+		query := authorize(t, "plain")
+		if query.Get("code") != "" || query.Get("error") != "invalid_request" {
+			t.Errorf("redirect query = %v, want invalid_request and no code", query)
+		}
+	})
+
+	t.Run("code is exchanged once with its verifier", func(t *testing.T) {
+		code := authorize(t, "S256").Get("code")
+		if code == "" {
+			t.Fatal("no code was issued")
+		}
+
+		if response := exchange(t, code, "wrong-verifier-wrong-verifier-wrong-verifier", redirectURI); oauthError(response) != "invalid_grant" {
+			t.Errorf("exchange with the wrong verifier = %d %v, want invalid_grant", response.Status, response.Payload)
+		}
+		if response := exchange(t, code, testCodeVerifier, redirectURI+"/other"); oauthError(response) != "invalid_grant" {
+			t.Errorf("exchange with another redirect_uri = %d %v, want invalid_grant", response.Status, response.Payload)
+		}
+
+		// Failed attempts do not burn the code of the app that holds the verifier
+		response := exchange(t, code, testCodeVerifier, redirectURI)
+		tokens, ok := response.Payload.(dtos.OAuthTokenResponseDto)
+		if response.Status != http.StatusOK || !ok {
+			t.Fatalf("exchange = %d %v, want tokens", response.Status, response.Payload)
+		}
+		if tokens.Scope != models.PermissionTodosRead {
+			t.Errorf("scope = %q, want %q", tokens.Scope, models.PermissionTodosRead)
+		}
+		if _, err := repo.Authenticate(ctx, tokens.AccessToken); err != nil {
+			t.Fatalf("Authenticate failed: %v", err)
+		}
+
+		// A second exchange means the code leaked, the tokens of the first one are revoked
+		if response := exchange(t, code, testCodeVerifier, redirectURI); oauthError(response) != "invalid_grant" {
+			t.Errorf("second exchange = %d %v, want invalid_grant", response.Status, response.Payload)
+		}
+		if _, err := repo.Authenticate(ctx, tokens.AccessToken); !errors.Is(err, ErrInvalidOAuthToken) {
+			t.Errorf("Authenticate after code reuse = %v, want ErrInvalidOAuthToken", err)
+		}
+	})
+
+	t.Run("code of another app", func(t *testing.T) {
+		code := authorize(t, "S256").Get("code")
+		other := createTestOAuthClient(t, db, redirectURI)
+
+		response, err := repo.Token(ctx, dtos.OAuthTokenRequestDto{
+			GrantType:    grantTypeAuthorizationCode,
+			Code:         code,
+			RedirectURI:  redirectURI,
+			CodeVerifier: testCodeVerifier,
+			ClientID:     other.ClientID,
+		})
+		if err != nil || oauthError(response) != "invalid_grant" {
+			t.Errorf("exchange by another app = %d %v %v, want invalid_grant", response.Status, response.Payload, err)
+		}
+	})
+}
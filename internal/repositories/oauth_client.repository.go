@@ -0,0 +1,242 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"todo-api/database"
+	"todo-api/internal/dtos"
+	"todo-api/internal/models"
+	"todo-api/internal/utils"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Most redirect URIs one app can register
+const maxOAuthRedirectURIs = 10
+
+type OAuthClientRepository struct {
+	DB     *gorm.DB
+	Logger *zap.Logger
+}
+
+func NewOAuthClientRepository(logger *zap.Logger) *OAuthClientRepository {
+	return &OAuthClientRepository{
+		DB:     database.GetDB(),
+		Logger: logger,
+	}
+}
+
+// CreateClient registers a third-party app. The secret of a confidential app is only returned
+// here, the database keeps its hash.
+func (r *OAuthClientRepository) CreateClient(ctx context.Context, createClientDto dtos.CreateOAuthClientDto) (dtos.StructuredResponse, error) {
+	if len(createClientDto.RedirectURIs) == 0 || len(createClientDto.RedirectURIs) > maxOAuthRedirectURIs {
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusBadRequest,
+			Message: fmt.Sprintf("Between 1 and %d redirect URIs are required", maxOAuthRedirectURIs),
+			Payload: nil,
+		}, nil
+	}
+
+	for _, redirectURI := range createClientDto.RedirectURIs {
+		if problem := redirectURIProblem(redirectURI); problem != "" {
+			return dtos.StructuredResponse{
+				Success: false,
+				Status:  http.StatusBadRequest,
+				Message: fmt.Sprintf("Invalid redirect URI %q: %s", redirectURI, problem),
+				Payload: nil,
+			}, nil
+		}
+	}
+
+	scopes := slices.Clone(createClientDto.Scopes)
+	if len(scopes) == 0 {
+		for scope := range models.OAuthScopes {
+			scopes = append(scopes, scope)
+		}
+	}
+	for _, scope := range scopes {
+		if _, ok := models.OAuthScopes[scope]; !ok {
+			return dtos.StructuredResponse{
+				Success: false,
+				Status:  http.StatusBadRequest,
+				Message: fmt.Sprintf("Invalid scope %q", scope),
+				Payload: nil,
+			}, nil
+		}
+	}
+	slices.Sort(scopes)
+	scopes = slices.Compact(scopes)
+
+	clientID, err := utils.GeneratePrefixedToken(utils.OAuthClientIDPrefix, 12)
+	if err != nil {
+		r.Logger.Error("Failed to generate client ID", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to register app",
+			Payload: nil,
+		}, err
+	}
+
+	client := models.OAuthClient{
+		ClientID:     clientID,
+		Name:         strings.TrimSpace(createClientDto.Name),
+		RedirectURIs: strings.Join(createClientDto.RedirectURIs, " "),
+		Scopes:       strings.Join(scopes, " "),
+		CreatedByID:  &createClientDto.CreatedByID,
+	}
+
+	var secret string
+	if createClientDto.Confidential {
+		secret, err = utils.GeneratePrefixedToken(utils.OAuthClientSecretPrefix, 32)
+		if err != nil {
+			r.Logger.Error("Failed to generate client secret", zap.Error(err))
+			return dtos.StructuredResponse{
+				Success: false,
+				Status:  http.StatusInternalServerError,
+				Message: "Failed to register app",
+				Payload: nil,
+			}, err
+		}
+		client.SecretHash = utils.HashToken(secret)
+	}
+
+	if err := r.DB.WithContext(ctx).Create(&client).Error; err != nil {
+		r.Logger.Error("Failed to store OAuth client", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to register app",
+			Payload: nil,
+		}, err
+	}
+
+	r.Logger.Info("OAuth client registered", zap.String("clientId", client.ClientID), zap.Uint("actorId", createClientDto.CreatedByID))
+
+	message := "App registered successfully"
+	if secret != "" {
+		message = "App registered successfully, copy the client secret now as it will not be shown again"
+	}
+
+	return dtos.StructuredResponse{
+		Success: true,
+		Status:  http.StatusCreated,
+		Message: message,
+		Payload: dtos.CreatedOAuthClientDto{
+			OAuthClientDto: toOAuthClientDto(client),
+			ClientSecret:   secret,
+		},
+	}, nil
+}
+
+// GetClients lists every registered app, newest first
+func (r *OAuthClientRepository) GetClients(ctx context.Context) (dtos.StructuredResponse, error) {
+	var clients []models.OAuthClient
+
+	if err := r.DB.WithContext(ctx).Order(`"createdAt" DESC, id DESC`).Find(&clients).Error; err != nil {
+		r.Logger.Error("Failed to retrieve OAuth clients", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to retrieve apps",
+			Payload: nil,
+		}, err
+	}
+
+	clientDtos := make([]dtos.OAuthClientDto, 0, len(clients))
+	for _, client := range clients {
+		clientDtos = append(clientDtos, toOAuthClientDto(client))
+	}
+
+	return dtos.StructuredResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Apps retrieved successfully",
+		Payload: clientDtos,
+	}, nil
+}
+
+// DeleteClient removes an app, its pending authorization codes and every token issued to it
+func (r *OAuthClientRepository) DeleteClient(ctx context.Context, clientID uint) (dtos.StructuredResponse, error) {
+	result := r.DB.WithContext(ctx).Delete(&models.OAuthClient{}, clientID)
+
+	if result.Error != nil {
+		r.Logger.Error("Failed to delete OAuth client", zap.Error(result.Error))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to delete app",
+			Payload: nil,
+		}, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusNotFound,
+			Message: "App not found",
+			Payload: nil,
+		}, nil
+	}
+
+	return dtos.StructuredResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "App deleted successfully",
+		Payload: nil,
+	}, nil
+}
+
+// redirectURIProblem tells why a redirect URI cannot be registered, or returns "" when it can.
+// Plain HTTP is only allowed for apps listening on the loopback interface, custom schemes
+// are allowed for native apps.
+func redirectURIProblem(redirectURI string) string {
+	if len(redirectURI) > 500 || strings.ContainsAny(redirectURI, " \t\r\n") {
+		return "must be at most 500 characters without spaces"
+	}
+
+	parsed, err := url.Parse(redirectURI)
+	if err != nil || !parsed.IsAbs() {
+		return "must be an absolute URL"
+	}
+	if parsed.Fragment != "" || strings.Contains(redirectURI, "#") {
+		return "must not contain a fragment"
+	}
+
+	switch parsed.Scheme {
+	// The web app navigates to the redirect URI, these would run in its origin
+	case "javascript", "data", "vbscript", "file":
+		return "scheme is not allowed"
+	case "https":
+		if parsed.Host == "" {
+			return "must name a host"
+		}
+	case "http":
+		host := parsed.Hostname()
+		if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+			return "http is only allowed for localhost, use https"
+		}
+	}
+
+	return ""
+}
+
+func toOAuthClientDto(client models.OAuthClient) dtos.OAuthClientDto {
+	return dtos.OAuthClientDto{
+		ID:           client.ID,
+		ClientID:     client.ClientID,
+		Name:         client.Name,
+		RedirectURIs: client.RedirectURIList(),
+		Scopes:       client.ScopeList(),
+		Confidential: client.Confidential(),
+		CreatedByID:  client.CreatedByID,
+		CreatedAt:    client.CreatedAt,
+	}
+}
@@ -0,0 +1,309 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+	"todo-api/config"
+	"todo-api/database"
+	"todo-api/internal/dtos"
+	"todo-api/internal/models"
+	"todo-api/internal/oidc"
+	"todo-api/internal/utils"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// errOIDCAccountNotAllowed is returned inside the linking transaction when no local account
+// may be used for the provider identity
+var errOIDCAccountNotAllowed = errors.New("no account for this identity")
+
+// errOIDCRegistrationClosed is returned inside the linking transaction when a new account would
+// be needed but the registration mode or domain allowlist does not allow one
+var errOIDCRegistrationClosed = errors.New("registration closed for this identity")
+
+// oidcLoginState is kept in an encrypted cookie between the redirect and the callback,
+// so nothing about a pending login has to be stored server side
+type oidcLoginState struct {
+	State        string    `json:"state"`
+	Nonce        string    `json:"nonce"`
+	CodeVerifier string    `json:"codeVerifier"`
+	ExpiresAt    time.Time `json:"expiresAt"`
+}
+
+type OIDCRepository struct {
+	DB       *gorm.DB
+	Logger   *zap.Logger
+	provider *oidc.Provider
+	auth     *AuthRepository
+}
+
+func NewOIDCRepository(logger *zap.Logger) *OIDCRepository {
+	return &OIDCRepository{
+		DB:       database.GetDB(),
+		Logger:   logger,
+		provider: oidc.New(config.GetConfig().OIDC, nil),
+		auth:     NewAuthRepository(logger),
+	}
+}
+
+// StartLogin creates the state, nonce and PKCE verifier of a new login and the provider URL to redirect to
+func (r *OIDCRepository) StartLogin(ctx context.Context) (dtos.StructuredResponse, error) {
+	var loginState oidcLoginState
+
+	for _, value := range []*string{&loginState.State, &loginState.Nonce, &loginState.CodeVerifier} {
+		token, err := utils.GenerateRandomToken(32)
+		if err != nil {
+			r.Logger.Error("Failed to generate OIDC state", zap.Error(err))
+			return dtos.StructuredResponse{
+				Success: false,
+				Status:  http.StatusInternalServerError,
+				Message: "Failed to start single sign-on",
+				Payload: nil,
+			}, err
+		}
+		*value = token
+	}
+	loginState.ExpiresAt = time.Now().Add(config.GetConfig().OIDC.StateTTL)
+
+	authorizationURL, err := r.provider.AuthCodeURL(ctx, loginState.State, loginState.Nonce, loginState.CodeVerifier)
+	if err != nil {
+		r.Logger.Error("Failed to reach identity provider", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusBadGateway,
+			Message: "Identity provider is unavailable",
+			Payload: nil,
+		}, nil
+	}
+
+	stateJSON, err := json.Marshal(loginState)
+	if err != nil {
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to start single sign-on",
+			Payload: nil,
+		}, err
+	}
+
+	stateCookie, err := utils.EncryptSecret(string(stateJSON))
+	if err != nil {
+		r.Logger.Error("Failed to encrypt OIDC state", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to start single sign-on",
+			Payload: nil,
+		}, err
+	}
+
+	return dtos.StructuredResponse{
+		Success: true,
+		Status:  http.StatusFound,
+		Message: "Redirecting to identity provider",
+		Payload: dtos.OIDCRedirectDto{
+			AuthorizationURL: authorizationURL,
+			StateCookie:      stateCookie,
+		},
+	}, nil
+}
+
+// CompleteLogin handles the provider callback: it checks the state, exchanges the code,
+// verifies the ID token and logs in the linked, matching or newly provisioned user
+func (r *OIDCRepository) CompleteLogin(ctx context.Context, callbackDto dtos.OIDCCallbackDto) (dtos.StructuredResponse, error) {
+	if callbackDto.Error != "" {
+		r.Logger.Info("Identity provider refused the login",
+			zap.String("error", callbackDto.Error),
+			zap.String("description", callbackDto.ErrorDescription),
+		)
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusUnauthorized,
+			Message: "Single sign-on was cancelled or refused",
+			Payload: nil,
+		}, nil
+	}
+
+	invalidResponse := dtos.StructuredResponse{
+		Success: false,
+		Status:  http.StatusBadRequest,
+		Message: "Invalid or expired single sign-on attempt, please try again",
+		Payload: nil,
+	}
+
+	loginState, ok := r.decodeLoginState(callbackDto.StateCookie)
+	if !ok || callbackDto.Code == "" || callbackDto.State != loginState.State {
+		return invalidResponse, nil
+	}
+
+	tokens, err := r.provider.Exchange(ctx, callbackDto.Code, loginState.CodeVerifier)
+	if err != nil {
+		r.Logger.Warn("Failed to exchange authorization code", zap.Error(err))
+		return invalidResponse, nil
+	}
+
+	claims, err := r.provider.VerifyIDToken(ctx, tokens.IDToken, loginState.Nonce)
+	if err != nil {
+		r.Logger.Warn("Rejected ID token", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusUnauthorized,
+			Message: "Invalid ID token",
+			Payload: nil,
+		}, nil
+	}
+
+	var user models.User
+
+	err = r.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		user, err = r.findOrProvisionUser(tx, claims)
+		return err
+	})
+
+	if errors.Is(err, errOIDCRegistrationClosed) {
+		return registrationRefusedResponse("No account is linked to this identity and registration is closed to it"), nil
+	}
+
+	if errors.Is(err, errOIDCAccountNotAllowed) {
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusForbidden,
+			Message: "No account is linked to this identity and a verified email is required to create one",
+			Payload: nil,
+		}, nil
+	}
+
+	if err != nil {
+		r.Logger.Error("Failed to link OIDC identity", zap.String("subject", claims.Subject), zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to login",
+			Payload: nil,
+		}, err
+	}
+
+	// The provider's own policy does not replace the second factor the user set up here
+	return r.auth.finishLogin(ctx, user, "Single sign-on", callbackDto.UserAgent, callbackDto.IPAddress)
+}
+
+// findOrProvisionUser resolves the local user for a verified ID token. A known subject wins,
+// then an account with the same verified email is linked, then a new account is created.
+func (r *OIDCRepository) findOrProvisionUser(tx *gorm.DB, claims *oidc.IDTokenClaims) (models.User, error) {
+	var user models.User
+	var identity models.ExternalIdentity
+	now := time.Now()
+
+	err := tx.Preload("User").Where("issuer = ? AND subject = ?", claims.Issuer, claims.Subject).First(&identity).Error
+	if err == nil && identity.User != nil {
+		if err := tx.Model(&identity).Updates(map[string]interface{}{
+			"lastLoginAt": now,
+			"email":       claims.Email,
+		}).Error; err != nil {
+			return user, err
+		}
+		return *identity.User, nil
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return user, err
+	}
+
+	// An unverified email could belong to anyone, never use it to pick an account
+	email := utils.NormalizeEmail(claims.Email)
+	if email == "" || !bool(claims.EmailVerified) {
+		return user, errOIDCAccountNotAllowed
+	}
+
+	err = tx.Where("email = ?", email).First(&user).Error
+
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		if !config.GetConfig().OIDC.AutoProvision {
+			return user, errOIDCAccountNotAllowed
+		}
+
+		// Single sign-on has no way to pass an invite code, so only open registration provisions
+		if registrationRefusal(email, false) != "" {
+			return user, errOIDCRegistrationClosed
+		}
+
+		var defaultRole models.Role
+		if err := tx.Where("name = ?", models.RoleUser).First(&defaultRole).Error; err != nil {
+			return user, err
+		}
+
+		name := strings.TrimSpace(claims.Name)
+		if name == "" {
+			name = email
+		}
+
+		// Provisioned users have no password, they can set one with the password reset flow
+		user = models.User{
+			Email:           email,
+			Name:            name,
+			EmailVerifiedAt: &now,
+			Roles:           []models.Role{defaultRole},
+		}
+		if err := tx.Omit("Roles.*").Create(&user).Error; err != nil {
+			return user, err
+		}
+
+		r.Logger.Info("Provisioned user from OIDC login", zap.Uint("userId", user.ID), zap.String("issuer", claims.Issuer))
+
+	case err != nil:
+		return user, err
+
+	default:
+		// The provider vouches for the address, which is as good as our own verification link
+		if user.EmailVerifiedAt == nil {
+			if err := tx.Model(&user).Update("emailVerifiedAt", now).Error; err != nil {
+				return user, err
+			}
+		}
+
+		r.Logger.Info("Linked OIDC identity to existing user", zap.Uint("userId", user.ID), zap.String("issuer", claims.Issuer))
+	}
+
+	identity = models.ExternalIdentity{
+		UserID:      user.ID,
+		Issuer:      claims.Issuer,
+		Subject:     claims.Subject,
+		Email:       email,
+		LastLoginAt: &now,
+	}
+	if err := tx.Create(&identity).Error; err != nil {
+		return user, err
+	}
+
+	return user, nil
+}
+
+// decodeLoginState decrypts the state cookie, tampered or expired cookies are rejected
+func (r *OIDCRepository) decodeLoginState(stateCookie string) (oidcLoginState, bool) {
+	var loginState oidcLoginState
+
+	if stateCookie == "" {
+		return loginState, false
+	}
+
+	stateJSON, err := utils.DecryptSecret(stateCookie)
+	if err != nil {
+		return loginState, false
+	}
+
+	if err := json.Unmarshal([]byte(stateJSON), &loginState); err != nil {
+		return loginState, false
+	}
+
+	if loginState.State == "" || time.Now().After(loginState.ExpiresAt) {
+		return loginState, false
+	}
+
+	return loginState, true
+}
@@ -0,0 +1,218 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+	"todo-api/config"
+	"todo-api/internal/dtos"
+	"todo-api/internal/models"
+	"todo-api/internal/oidc"
+	"todo-api/internal/oidc/oidctest"
+	"todo-api/internal/utils"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// newTestOIDCRepository returns a repository talking to a mock identity provider. The database
+// is only needed once the ID token is verified, so it may be nil for tests that fail before.
+func newTestOIDCRepository(t *testing.T, db *gorm.DB) (*OIDCRepository, *oidctest.Provider) {
+	t.Helper()
+
+	mock := oidctest.NewProvider("todo-api")
+	t.Cleanup(mock.Close)
+
+	oidcConfig := config.GetConfig().OIDC
+	oidcConfig.IssuerURL = mock.Issuer()
+	oidcConfig.ClientID = mock.ClientID
+	oidcConfig.ClientSecret = ""
+
+	repo := &OIDCRepository{
+		DB:       db,
+		Logger:   zap.NewNop(),
+		provider: oidc.New(oidcConfig, mock.Client()),
+	}
+	if db != nil {
+		repo.auth = NewAuthRepository(zap.NewNop())
+	}
+
+	return repo, mock
+}
+
+// startTestOIDCLogin starts a login and returns its redirect
+func startTestOIDCLogin(t *testing.T, repo *OIDCRepository) dtos.OIDCRedirectDto {
+	t.Helper()
+
+	response, err := repo.StartLogin(context.Background())
+	if err != nil || response.Status != http.StatusFound {
+		t.Fatalf("StartLogin = %d %s %v", response.Status, response.Message, err)
+	}
+	return response.Payload.(dtos.OIDCRedirectDto)
+}
+
+// completeTestOIDCLogin logs in at the mock provider with the claims and sends the callback back
+func completeTestOIDCLogin(t *testing.T, repo *OIDCRepository, mock *oidctest.Provider, claims jwt.MapClaims) dtos.StructuredResponse {
+	t.Helper()
+
+	redirect := startTestOIDCLogin(t, repo)
+	code, state, err := mock.Authorize(redirect.AuthorizationURL, claims)
+	if err != nil {
+		t.Fatalf("Authorize failed: %v", err)
+	}
+
+	response, err := repo.CompleteLogin(context.Background(), dtos.OIDCCallbackDto{Code: code, State: state, StateCookie: redirect.StateCookie})
+	if err != nil {
+		t.Fatalf("CompleteLogin failed: %v", err)
+	}
+	return response
+}
+
+func TestOIDCRepositoryStateCookie(t *testing.T) {
+	repo, mock := newTestOIDCRepository(t, nil)
+	redirect := startTestOIDCLogin(t, repo)
+
+	loginState, ok := repo.decodeLoginState(redirect.StateCookie)
+	if !ok {
+		t.Fatal("state cookie could not be read back")
+	}
+
+	authURL, err := url.Parse(redirect.AuthorizationURL)
+	if err != nil {
+		t.Fatalf("invalid authorization URL: %v", err)
+	}
+	query := authURL.Query()
+	if query.Get("state") != loginState.State || query.Get("nonce") != loginState.Nonce ||
+		query.Get("code_challenge") != oidc.CodeChallenge(loginState.CodeVerifier) {
+		t.Errorf("authorization URL %s does not match the state cookie", redirect.AuthorizationURL)
+	}
+
+	// Nothing readable about the login may travel in the cookie
+	for _, secret := range []string{loginState.State, loginState.Nonce, loginState.CodeVerifier} {
+		if secret == "" || strings.Contains(redirect.StateCookie, secret) {
+			t.Fatalf("state cookie %q is missing or shows %q", redirect.StateCookie, secret)
+		}
+	}
+
+	encrypt := func(loginState oidcLoginState) string {
+		stateJSON, _ := json.Marshal(loginState)
+		cookie, err := utils.EncryptSecret(string(stateJSON))
+		if err != nil {
+			t.Fatalf("failed to encrypt state: %v", err)
+		}
+		return cookie
+	}
+	expired := loginState
+	expired.ExpiresAt = time.Now().Add(-time.Second)
+	otherVerifier := loginState
+	otherVerifier.CodeVerifier = "another-verifier"
+
+	tests := []struct {
+		name        string
+		state       string
+		stateCookie string
+	}{
+		{"no cookie", loginState.State, ""},
+		{"other state", "another-state", redirect.StateCookie},
+		{"tampered cookie", loginState.State, redirect.StateCookie[:len(redirect.StateCookie)-4] + "AAAA"},
+		{"expired cookie", loginState.State, encrypt(expired)},
+		{"other code verifier", loginState.State, encrypt(otherVerifier)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _, err := mock.Authorize(redirect.AuthorizationURL, mock.Claims("alice", loginState.Nonce))
+			if err != nil {
+				t.Fatalf("Authorize failed: %v", err)
+			}
+
+			response, err := repo.CompleteLogin(context.Background(), dtos.OIDCCallbackDto{Code: code, State: tt.state, StateCookie: tt.stateCookie})
+			if err != nil || response.Status != http.StatusBadRequest {
+				t.Errorf("CompleteLogin = %d %s %v, want 400", response.Status, response.Message, err)
+			}
+		})
+	}
+}
+
+func TestOIDCRepositoryRejectsIDToken(t *testing.T) {
+	repo, mock := newTestOIDCRepository(t, nil)
+
+	tests := []struct {
+		name   string
+		change func(jwt.MapClaims)
+	}{
+		{"wrong nonce", func(claims jwt.MapClaims) { claims["nonce"] = "another-nonce" }},
+		{"wrong issuer", func(claims jwt.MapClaims) { claims["iss"] = "https://evil.example.com" }},
+		{"wrong audience", func(claims jwt.MapClaims) { claims["aud"] = "another-app" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := mock.Claims("alice", "")
+			delete(claims, "nonce")
+			tt.change(claims)
+
+			if response := completeTestOIDCLogin(t, repo, mock, claims); response.Status != http.StatusUnauthorized {
+				t.Errorf("CompleteLogin = %d %s, want 401", response.Status, response.Message)
+			}
+		})
+	}
+}
+
+func TestOIDCRepositoryCompleteLogin(t *testing.T) {
+	db := openTestDB(t)
+	repo, mock := newTestOIDCRepository(t, db)
+
+	// claims returns the ID token claims of the subject, the nonce comes from the login
+	claims := func(subject string, email string) jwt.MapClaims {
+		claims := mock.Claims(subject, "")
+		delete(claims, "nonce")
+		claims["email"] = email
+		claims["email_verified"] = true
+		return claims
+	}
+
+	t.Run("linked by verified email", func(t *testing.T) {
+		user := createTestAccount(t, db, "alice")
+		subject := fmt.Sprintf("subject-%d", time.Now().UnixNano())
+
+		response := completeTestOIDCLogin(t, repo, mock, claims(subject, user.Email))
+		if tokens, ok := response.Payload.(dtos.AuthTokensDto); response.Status != http.StatusOK || !ok || tokens.ID != user.ID {
+			t.Fatalf("CompleteLogin = %d %s %v, want tokens of user %d", response.Status, response.Message, response.Payload, user.ID)
+		}
+
+		// Later logins match on the subject even when the email changed at the provider
+		response = completeTestOIDCLogin(t, repo, mock, claims(subject, "renamed-"+user.Email))
+		if tokens, ok := response.Payload.(dtos.AuthTokensDto); response.Status != http.StatusOK || !ok || tokens.ID != user.ID {
+			t.Errorf("CompleteLogin = %d %s %v, want tokens of user %d", response.Status, response.Message, response.Payload, user.ID)
+		}
+	})
+
+	t.Run("unverified email is not linked", func(t *testing.T) {
+		user := createTestAccount(t, db, "bob")
+		unverified := claims(fmt.Sprintf("subject-%d", time.Now().UnixNano()), user.Email)
+		unverified["email_verified"] = false
+
+		if response := completeTestOIDCLogin(t, repo, mock, unverified); response.Status != http.StatusForbidden {
+			t.Errorf("CompleteLogin = %d %s, want 403", response.Status, response.Message)
+		}
+	})
+
+	t.Run("two-factor authentication still applies", func(t *testing.T) {
+		user := createTestAccount(t, db, "carol")
+		if err := db.Model(&models.User{}).Where("id = ?", user.ID).Update("twoFactorEnabledAt", time.Now()).Error; err != nil {
+			t.Fatalf("failed to enable two-factor authentication: %v", err)
+		}
+
+		response := completeTestOIDCLogin(t, repo, mock, claims(fmt.Sprintf("subject-%d", time.Now().UnixNano()), user.Email))
+		if challenge, ok := response.Payload.(dtos.TwoFactorChallengeDto); response.Status != http.StatusOK || !ok || !challenge.TwoFactorRequired {
+			t.Errorf("CompleteLogin = %d %s %v, want a two-factor challenge", response.Status, response.Message, response.Payload)
+		}
+	})
+}
@@ -0,0 +1,284 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"time"
+	"todo-api/config"
+	"todo-api/database"
+	"todo-api/internal/dtos"
+	"todo-api/internal/jobs"
+	"todo-api/internal/mailer"
+	"todo-api/internal/models"
+	"todo-api/internal/password"
+	"todo-api/internal/utils"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type PasswordResetRepository struct {
+	DB             *gorm.DB
+	Logger         *zap.Logger
+	mailer         mailer.Mailer
+	background     *jobs.Queue
+	revocations    *RevocationRepository
+	securityEvents *SecurityEventRepository
+	loginAttempts  *LoginAttemptRepository
+	passwords      *password.Manager
+}
+
+func NewPasswordResetRepository(logger *zap.Logger) *PasswordResetRepository {
+	return &PasswordResetRepository{
+		DB:             database.GetDB(),
+		Logger:         logger,
+		mailer:         mailer.New(config.GetConfig().Mail, logger),
+		background:     jobs.Default(),
+		revocations:    NewRevocationRepository(logger),
+		securityEvents: NewSecurityEventRepository(logger),
+		loginAttempts:  NewLoginAttemptRepository(logger),
+		passwords:      password.New(config.GetConfig().PasswordHashing),
+	}
+}
+
+// ForgotPassword emails a reset link when the account exists. The response is identical
+// either way so the endpoint cannot be used to discover registered emails. The lookup and the
+// link are handled by a background job after responding, so the response time does not tell either.
+func (r *PasswordResetRepository) ForgotPassword(ctx context.Context, forgotPasswordDto dtos.ForgotPasswordDto) (dtos.StructuredResponse, error) {
+	authConfig := config.GetConfig().Auth
+	email := utils.NormalizeEmail(forgotPasswordDto.Email)
+
+	// Limit both keys so the endpoint can neither flood one inbox nor be used to spam many
+	for _, limit := range []struct {
+		key   string
+		limit int
+	}{
+		{"password-reset:email:" + email, authConfig.PasswordResetEmailLimit},
+		{"password-reset:ip:" + forgotPasswordDto.IPAddress, authConfig.PasswordResetIPLimit},
+	} {
+		retryAfter, err := r.loginAttempts.Throttle(ctx, limit.key, limit.limit, authConfig.PasswordResetRateWindow)
+		if err != nil {
+			r.Logger.Error("Failed to check password reset rate limit", zap.Error(err))
+			return dtos.StructuredResponse{
+				Success: false,
+				Status:  http.StatusInternalServerError,
+				Message: "Failed to send password reset link",
+				Payload: nil,
+			}, err
+		}
+		if retryAfter > 0 {
+			return dtos.StructuredResponse{
+				Success: false,
+				Status:  http.StatusTooManyRequests,
+				Message: "Too many password reset links requested, please try again later",
+				Payload: dtos.RateLimitedDto{
+					RetryAfter: int(math.Ceil(retryAfter.Seconds())),
+				},
+			}, nil
+		}
+	}
+
+	// A dropped job is logged by the queue, the response has to be the same anyway
+	r.background.Enqueue("password reset link", func(ctx context.Context) error {
+		var user models.User
+
+		if err := r.DB.WithContext(ctx).Where("email = ?", email).First(&user).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return fmt.Errorf("failed to find user: %w", err)
+		}
+
+		message, err := r.createResetLink(ctx, user, "We received a request to reset your password.", "If you did not request a reset you can ignore this email.")
+		if err != nil {
+			return err
+		}
+		return r.mailer.Send(ctx, message)
+	})
+
+	return dtos.StructuredResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "If an account exists for this email, a password reset link has been sent",
+		Payload: nil,
+	}, nil
+}
+
+// sendResetLink creates a reset link for the user and queues the email. intro explains why the
+// email was sent, outro closes it.
+func (r *PasswordResetRepository) sendResetLink(ctx context.Context, user models.User, intro string, outro string) error {
+	message, err := r.createResetLink(ctx, user, intro, outro)
+	if err != nil {
+		return err
+	}
+
+	return r.background.Enqueue("password reset email", func(ctx context.Context) error {
+		return r.mailer.Send(ctx, message)
+	})
+}
+
+// createResetLink stores a new reset token for the user, invalidating earlier ones, and returns
+// the email with the link
+func (r *PasswordResetRepository) createResetLink(ctx context.Context, user models.User, intro string, outro string) (mailer.Message, error) {
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return mailer.Message{}, fmt.Errorf("failed to generate password reset token: %w", err)
+	}
+
+	resetTTL := config.GetConfig().Auth.PasswordResetTTL
+
+	err = r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Only the most recent link stays valid
+		if err := tx.Model(&models.PasswordResetToken{}).
+			Where(`"userId" = ? AND "usedAt" IS NULL`, user.ID).
+			Update("usedAt", time.Now()).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&models.PasswordResetToken{
+			UserID:    user.ID,
+			TokenHash: utils.HashToken(token),
+			ExpiresAt: time.Now().Add(resetTTL),
+		}).Error
+	})
+	if err != nil {
+		return mailer.Message{}, fmt.Errorf("failed to store password reset token: %w", err)
+	}
+
+	resetLink := fmt.Sprintf("%s/reset-password?token=%s", config.GetConfig().AppURL, url.QueryEscape(token))
+	return mailer.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\n%s Use the link below to choose a new password:\n\n%s\n\nThe link expires in %s. %s\n",
+			user.Name, intro, resetLink, resetTTL, outro,
+		),
+	}, nil
+}
+
+// ResetPassword consumes a reset token, sets the new password and logs the user out everywhere
+func (r *PasswordResetRepository) ResetPassword(ctx context.Context, resetPasswordDto dtos.ResetPasswordDto) (dtos.StructuredResponse, error) {
+	invalidResponse := dtos.StructuredResponse{
+		Success: false,
+		Status:  http.StatusBadRequest,
+		Message: "Invalid or expired password reset token",
+		Payload: nil,
+	}
+
+	if resetPasswordDto.Password != resetPasswordDto.ConfirmPassword {
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusBadRequest,
+			Message: "Passwords do not match",
+			Payload: nil,
+		}, nil
+	}
+
+	if resetPasswordDto.Token == "" {
+		return invalidResponse, nil
+	}
+
+	var resetToken models.PasswordResetToken
+
+	if err := r.DB.Preload("User").Where(`"tokenHash" = ?`, utils.HashToken(resetPasswordDto.Token)).First(&resetToken).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return invalidResponse, nil
+		}
+		r.Logger.Error("Failed to find password reset token", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to reset password",
+			Payload: nil,
+		}, err
+	}
+
+	if resetToken.UsedAt != nil || time.Now().After(resetToken.ExpiresAt) || resetToken.User == nil {
+		return invalidResponse, nil
+	}
+
+	policy, err := password.GetPolicy()
+	if err != nil {
+		r.Logger.Error("Failed to load password policy", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to reset password",
+			Payload: nil,
+		}, err
+	}
+
+	if problems := policy.Check(resetPasswordDto.Password, resetToken.User.Email, resetToken.User.Name); len(problems) > 0 {
+		return passwordPolicyResponse("password", problems), nil
+	}
+
+	hashedPassword, err := r.passwords.Hash(resetPasswordDto.Password)
+	if err != nil {
+		r.Logger.Error("Failed to hash password", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to reset password",
+			Payload: nil,
+		}, err
+	}
+
+	consumed := false
+
+	err = r.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.PasswordResetToken{}).
+			Where(`id = ? AND "usedAt" IS NULL`, resetToken.ID).
+			Update("usedAt", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		consumed = true
+
+		updates := map[string]interface{}{"passwordHash": hashedPassword}
+		// Opening the link proves the address works, as the verification link would
+		if resetToken.User.EmailVerifiedAt == nil {
+			updates["emailVerifiedAt"] = time.Now()
+		}
+
+		return tx.Model(&models.User{}).Where("id = ?", resetToken.UserID).Updates(updates).Error
+	})
+	if err != nil {
+		r.Logger.Error("Failed to reset password", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to reset password",
+			Payload: nil,
+		}, err
+	}
+
+	if !consumed {
+		return invalidResponse, nil
+	}
+
+	r.securityEvents.Record(ctx, models.SecurityEvent{
+		UserID:    resetToken.UserID,
+		Type:      models.SecurityEventPasswordReset,
+		IPAddress: resetPasswordDto.IPAddress,
+		UserAgent: resetPasswordDto.UserAgent,
+	})
+
+	// Whoever knew the old password must not keep a valid session
+	if err := r.revocations.RevokeAllForUser(ctx, resetToken.UserID); err != nil {
+		r.Logger.Error("Failed to revoke tokens after password reset", zap.Uint("userId", resetToken.UserID), zap.Error(err))
+	}
+
+	return dtos.StructuredResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Password has been reset successfully",
+		Payload: nil,
+	}, nil
+}
@@ -0,0 +1,145 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+	"todo-api/config"
+	"todo-api/internal/dtos"
+	"todo-api/internal/jobs"
+	"todo-api/internal/models"
+	"todo-api/internal/password"
+	"todo-api/internal/utils"
+
+	"go.uber.org/zap"
+)
+
+func TestPasswordResetRepositoryResetPasswordRejectsInput(t *testing.T) {
+	// Invalid input is refused before the token is looked up, so no database is needed
+	repo := &PasswordResetRepository{Logger: zap.NewNop()}
+
+	tests := []struct {
+		name  string
+		input dtos.ResetPasswordDto
+	}{
+		{"passwords differ", dtos.ResetPasswordDto{Token: "token", Password: "new-password", ConfirmPassword: "other-password"}},
+		{"no token", dtos.ResetPasswordDto{Password: "new-password", ConfirmPassword: "new-password"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := repo.ResetPassword(context.Background(), tt.input)
+			if err != nil {
+				t.Fatalf("ResetPassword failed: %v", err)
+			}
+			if response.Status != http.StatusBadRequest {
+				t.Errorf("status = %d, want 400", response.Status)
+			}
+		})
+	}
+}
+
+func TestPasswordResetRepositoryResetPassword(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewPasswordResetRepository(zap.NewNop())
+	user := createTestAccount(t, db, "alice")
+	ctx := context.Background()
+
+	createResetToken := func(t *testing.T, expiresAt time.Time) string {
+		t.Helper()
+
+		token, err := utils.GenerateRandomToken(32)
+		if err != nil {
+			t.Fatalf("GenerateRandomToken failed: %v", err)
+		}
+		resetToken := models.PasswordResetToken{UserID: user.ID, TokenHash: utils.HashToken(token), ExpiresAt: expiresAt}
+		if err := db.Create(&resetToken).Error; err != nil {
+			t.Fatalf("failed to create reset token: %v", err)
+		}
+		return token
+	}
+
+	reset := func(t *testing.T, token string) int {
+		t.Helper()
+
+		response, err := repo.ResetPassword(ctx, dtos.ResetPasswordDto{Token: token, Password: "new-password", ConfirmPassword: "new-password"})
+		if err != nil {
+			t.Fatalf("ResetPassword failed: %v", err)
+		}
+		return response.Status
+	}
+
+	t.Run("expired", func(t *testing.T) {
+		if status := reset(t, createResetToken(t, time.Now().Add(-time.Minute))); status != http.StatusBadRequest {
+			t.Errorf("status = %d, want 400", status)
+		}
+	})
+
+	t.Run("single use", func(t *testing.T) {
+		token := createResetToken(t, time.Now().Add(time.Hour))
+
+		if status := reset(t, token); status != http.StatusOK {
+			t.Fatalf("status = %d, want 200", status)
+		}
+
+		var stored models.User
+		if err := db.First(&stored, user.ID).Error; err != nil {
+			t.Fatalf("failed to load user: %v", err)
+		}
+		if matches, _, err := password.New(config.GetConfig().PasswordHashing).Verify("new-password", stored.PasswordHash); err != nil || !matches {
+			t.Errorf("password was not changed: %v, %v", matches, err)
+		}
+		if stored.TokensRevokedAt == nil {
+			t.Error("existing tokens were not revoked")
+		}
+
+		if status := reset(t, token); status != http.StatusBadRequest {
+			t.Errorf("second use: status = %d, want 400", status)
+		}
+	})
+}
+
+func TestPasswordResetRepositoryForgotPasswordThrottled(t *testing.T) {
+	authConfig := &config.GetConfig().Auth
+	previous := *authConfig
+	authConfig.PasswordResetEmailLimit = 2
+	authConfig.PasswordResetIPLimit = 3
+	authConfig.PasswordResetRateWindow = time.Hour
+	t.Cleanup(func() { *authConfig = previous })
+
+	// Without workers the queued lookups never run, so no database is needed
+	repo := &PasswordResetRepository{
+		Logger:        zap.NewNop(),
+		background:    jobs.NewQueue(0, 10, time.Second, zap.NewNop()),
+		loginAttempts: newTestLoginAttemptRepository(&memoryLoginAttemptStore{attempts: map[string]*memoryLoginAttempt{}}),
+	}
+	ctx := context.Background()
+
+	forgot := func(t *testing.T, email string, ipAddress string) int {
+		t.Helper()
+		response, err := repo.ForgotPassword(ctx, dtos.ForgotPasswordDto{Email: email, IPAddress: ipAddress})
+		if err != nil {
+			t.Fatalf("ForgotPassword failed: %v", err)
+		}
+		return response.Status
+	}
+
+	t.Run("per email", func(t *testing.T) {
+		for i, want := range []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests} {
+			// The limit applies to the normalized email, whatever the client sent
+			if got := forgot(t, "Alice@Example.com ", fmt.Sprintf("203.0.113.%d", i+1)); got != want {
+				t.Errorf("request %d: status %d, want %d", i+1, got, want)
+			}
+		}
+	})
+
+	t.Run("per IP", func(t *testing.T) {
+		for i, want := range []int{http.StatusOK, http.StatusOK, http.StatusOK, http.StatusTooManyRequests} {
+			if got := forgot(t, fmt.Sprintf("user-%d@example.com", i+1), "198.51.100.7"); got != want {
+				t.Errorf("request %d: status %d, want %d", i+1, got, want)
+			}
+		}
+	})
+}
@@ -0,0 +1,274 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+	"todo-api/database"
+	"todo-api/internal/dtos"
+	"todo-api/internal/models"
+	"todo-api/internal/utils"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// lastUsedUpdateInterval limits how often a busy token writes its usage back to the database
+const lastUsedUpdateInterval = time.Minute
+
+// ErrInvalidPersonalAccessToken is returned when a personal access token is unknown, revoked or expired
+var ErrInvalidPersonalAccessToken = errors.New("invalid personal access token")
+
+type PersonalAccessTokenRepository struct {
+	DB             *gorm.DB
+	Logger         *zap.Logger
+	securityEvents *SecurityEventRepository
+}
+
+func NewPersonalAccessTokenRepository(logger *zap.Logger) *PersonalAccessTokenRepository {
+	return &PersonalAccessTokenRepository{
+		DB:             database.GetDB(),
+		Logger:         logger,
+		securityEvents: NewSecurityEventRepository(logger),
+	}
+}
+
+func (r *PersonalAccessTokenRepository) CreateToken(ctx context.Context, createTokenDto dtos.CreatePersonalAccessTokenDto) (dtos.StructuredResponse, error) {
+	name := strings.TrimSpace(createTokenDto.Name)
+	if name == "" || len(name) > 100 {
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusBadRequest,
+			Message: "Name is required and must be at most 100 characters",
+			Payload: nil,
+		}, nil
+	}
+
+	if createTokenDto.ExpiresInDays < 0 {
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusBadRequest,
+			Message: "expiresInDays cannot be negative",
+			Payload: nil,
+		}, nil
+	}
+
+	var user models.User
+
+	if err := r.DB.Preload("Roles.Permissions").First(&user, createTokenDto.UserID).Error; err != nil {
+		r.Logger.Error("Failed to find user", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to create token",
+			Payload: nil,
+		}, err
+	}
+
+	// A token can never grant more than its owner has
+	permissions := user.PermissionNames()
+	for _, scope := range createTokenDto.Scopes {
+		if !slices.Contains(permissions, scope) {
+			return dtos.StructuredResponse{
+				Success: false,
+				Status:  http.StatusBadRequest,
+				Message: fmt.Sprintf("Invalid scope %q", scope),
+				Payload: nil,
+			}, nil
+		}
+	}
+
+	token, prefix, err := utils.GeneratePersonalAccessToken()
+	if err != nil {
+		r.Logger.Error("Failed to generate personal access token", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to create token",
+			Payload: nil,
+		}, err
+	}
+
+	// Administrative permissions are only granted when asked for
+	scopes := slices.Clone(createTokenDto.Scopes)
+	if len(scopes) == 0 {
+		scopes = slices.Clone(models.DefaultPersonalAccessTokenScopes)
+	}
+	slices.Sort(scopes)
+	scopes = slices.Compact(scopes)
+
+	accessToken := models.PersonalAccessToken{
+		UserID:    user.ID,
+		Name:      name,
+		Prefix:    prefix,
+		TokenHash: utils.HashToken(token),
+		Scopes:    strings.Join(scopes, " "),
+	}
+
+	if createTokenDto.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, createTokenDto.ExpiresInDays)
+		accessToken.ExpiresAt = &expiresAt
+	}
+
+	if err := r.DB.Create(&accessToken).Error; err != nil {
+		r.Logger.Error("Failed to store personal access token", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to create token",
+			Payload: nil,
+		}, err
+	}
+
+	r.securityEvents.Record(ctx, models.SecurityEvent{
+		UserID:  user.ID,
+		Type:    models.SecurityEventTokenCreated,
+		Details: fmt.Sprintf("Personal access token %q (%s) with scopes %s", name, prefix, accessToken.Scopes),
+	})
+
+	return dtos.StructuredResponse{
+		Success: true,
+		Status:  http.StatusCreated,
+		Message: "Token created successfully, copy it now as it will not be shown again",
+		Payload: dtos.CreatedPersonalAccessTokenDto{
+			PersonalAccessTokenDto: toPersonalAccessTokenDto(accessToken),
+			Token:                  token,
+		},
+	}, nil
+}
+
+func (r *PersonalAccessTokenRepository) GetTokens(ctx context.Context, userID uint) (dtos.StructuredResponse, error) {
+	var accessTokens []models.PersonalAccessToken
+
+	if err := r.DB.Where(`"userId" = ? AND "revokedAt" IS NULL`, userID).Order(`"createdAt" DESC`).Find(&accessTokens).Error; err != nil {
+		r.Logger.Error("Failed to retrieve personal access tokens", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to retrieve tokens",
+			Payload: nil,
+		}, err
+	}
+
+	tokenDtos := make([]dtos.PersonalAccessTokenDto, 0, len(accessTokens))
+	for _, accessToken := range accessTokens {
+		tokenDtos = append(tokenDtos, toPersonalAccessTokenDto(accessToken))
+	}
+
+	return dtos.StructuredResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Tokens retrieved successfully",
+		Payload: tokenDtos,
+	}, nil
+}
+
+func (r *PersonalAccessTokenRepository) RevokeToken(ctx context.Context, revokeTokenDto dtos.RevokePersonalAccessTokenDto) (dtos.StructuredResponse, error) {
+	result := r.DB.Model(&models.PersonalAccessToken{}).
+		Where(`id = ? AND "userId" = ? AND "revokedAt" IS NULL`, revokeTokenDto.ID, revokeTokenDto.UserID).
+		Update("revokedAt", time.Now())
+
+	if result.Error != nil {
+		r.Logger.Error("Failed to revoke personal access token", zap.Error(result.Error))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to revoke token",
+			Payload: nil,
+		}, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusNotFound,
+			Message: "Token not found",
+			Payload: nil,
+		}, nil
+	}
+
+	r.securityEvents.Record(ctx, models.SecurityEvent{
+		UserID:  revokeTokenDto.UserID,
+		Type:    models.SecurityEventTokenRevoked,
+		Details: fmt.Sprintf("Personal access token %d revoked", revokeTokenDto.ID),
+	})
+
+	return dtos.StructuredResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Token revoked successfully",
+		Payload: nil,
+	}, nil
+}
+
+// Authenticate resolves a personal access token into claims equivalent to those of a JWT.
+// The permissions are the intersection of the token scopes and the owner's current
+// permissions, so demoting a user also narrows their existing tokens.
+func (r *PersonalAccessTokenRepository) Authenticate(ctx context.Context, token string, ipAddress string) (*utils.JWTClaims, error) {
+	var accessToken models.PersonalAccessToken
+
+	if err := r.DB.WithContext(ctx).Preload("User.Roles.Permissions").
+		Where(`"tokenHash" = ?`, utils.HashToken(token)).
+		First(&accessToken).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidPersonalAccessToken
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if accessToken.RevokedAt != nil || (accessToken.ExpiresAt != nil && now.After(*accessToken.ExpiresAt)) || accessToken.User == nil {
+		return nil, ErrInvalidPersonalAccessToken
+	}
+
+	// Tokens of a disabled account work again once it is re-enabled
+	if accessToken.User.DisabledAt != nil {
+		return nil, ErrInvalidPersonalAccessToken
+	}
+
+	// Writing on every request would turn reads into writes, only record meaningful changes
+	if accessToken.LastUsedAt == nil || now.Sub(*accessToken.LastUsedAt) >= lastUsedUpdateInterval || accessToken.LastUsedIP != ipAddress {
+		if err := r.DB.WithContext(ctx).Model(&accessToken).Updates(map[string]interface{}{
+			"lastUsedAt": now,
+			"lastUsedIp": ipAddress,
+		}).Error; err != nil {
+			r.Logger.Warn("Failed to record personal access token usage", zap.Uint("tokenId", accessToken.ID), zap.Error(err))
+		}
+	}
+
+	user := *accessToken.User
+
+	return &utils.JWTClaims{
+		UserID:      user.ID,
+		Email:       user.Email,
+		Name:        user.Name,
+		ReadOnly:    user.EmailVerifiedAt == nil,
+		Roles:       user.RoleNames(),
+		Permissions: scopedPermissions(user.PermissionNames(), accessToken.ScopeList()),
+		TokenType:   utils.TokenTypePersonalAccessToken,
+	}, nil
+}
+
+func toPersonalAccessTokenDto(accessToken models.PersonalAccessToken) dtos.PersonalAccessTokenDto {
+	return dtos.PersonalAccessTokenDto{
+		ID:         accessToken.ID,
+		Name:       accessToken.Name,
+		Prefix:     accessToken.Prefix,
+		Scopes:     accessToken.ScopeList(),
+		ExpiresAt:  accessToken.ExpiresAt,
+		LastUsedAt: accessToken.LastUsedAt,
+		LastUsedIP: accessToken.LastUsedIP,
+		CreatedAt:  accessToken.CreatedAt,
+	}
+}
+
+// scopedPermissions narrows the owner's permissions to the scopes of a token
+func scopedPermissions(permissions []string, scopes []string) []string {
+	return slices.DeleteFunc(permissions, func(permission string) bool {
+		return !slices.Contains(scopes, permission)
+	})
+}
@@ -0,0 +1,228 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+	"todo-api/internal/dtos"
+	"todo-api/internal/models"
+	"todo-api/internal/utils"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func TestPersonalAccessTokenScopeList(t *testing.T) {
+	tests := []struct {
+		scopes string
+		want   []string
+	}{
+		{"", []string{models.PermissionTodosRead, models.PermissionTodosWrite}},
+		{"   ", []string{models.PermissionTodosRead, models.PermissionTodosWrite}},
+		{"todos:read", []string{models.PermissionTodosRead}},
+		{"users:read  todos:write", []string{models.PermissionUsersRead, models.PermissionTodosWrite}},
+	}
+
+	for _, tt := range tests {
+		if got := (models.PersonalAccessToken{Scopes: tt.scopes}).ScopeList(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ScopeList(%q) = %q, want %q", tt.scopes, got, tt.want)
+		}
+	}
+
+	// The defaults must not be changed through a returned list
+	scopes := (models.PersonalAccessToken{}).ScopeList()
+	scopes[0] = models.PermissionUsersWrite
+	if models.DefaultPersonalAccessTokenScopes[0] != models.PermissionTodosRead {
+		t.Error("ScopeList returned the default scopes without copying them")
+	}
+}
+
+func TestScopedPermissions(t *testing.T) {
+	permissions := []string{models.PermissionTodosRead, models.PermissionTodosWrite, models.PermissionUsersRead}
+
+	tests := []struct {
+		name   string
+		scopes []string
+		want   []string
+	}{
+		{"no scopes", nil, []string{}},
+		{"subset", []string{models.PermissionTodosRead}, []string{models.PermissionTodosRead}},
+		{"scope the owner lost", []string{models.PermissionTodosWrite, models.PermissionUsersWrite}, []string{models.PermissionTodosWrite}},
+		{"only scopes the owner lost", []string{models.PermissionUsersWrite}, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scopedPermissions(slices.Clone(permissions), tt.scopes); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("scopedPermissions(%q) = %q, want %q", tt.scopes, got, tt.want)
+			}
+		})
+	}
+}
+
+// assignTestRoles gives the user the named roles in addition to the ones it has
+func assignTestRoles(t *testing.T, db *gorm.DB, user models.User, roleNames ...string) {
+	t.Helper()
+
+	var roles []models.Role
+	if err := db.Where("name IN ?", roleNames).Find(&roles).Error; err != nil || len(roles) != len(roleNames) {
+		t.Fatalf("failed to find roles %v: %v", roleNames, err)
+	}
+	if err := db.Model(&user).Association("Roles").Append(&roles); err != nil {
+		t.Fatalf("failed to assign roles %v: %v", roleNames, err)
+	}
+}
+
+func TestPersonalAccessTokenRepositoryScopes(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewPersonalAccessTokenRepository(zap.NewNop())
+	ctx := context.Background()
+
+	admin := createTestAccount(t, db, "admin")
+	assignTestRoles(t, db, admin, models.RoleUser, models.RoleAdmin)
+	member := createTestAccount(t, db, "member")
+	assignTestRoles(t, db, member, models.RoleUser)
+
+	createToken := func(t *testing.T, user models.User, scopes ...string) dtos.StructuredResponse {
+		t.Helper()
+
+		response, err := repo.CreateToken(ctx, dtos.CreatePersonalAccessTokenDto{Name: "test", Scopes: scopes, UserID: user.ID})
+		if err != nil {
+			t.Fatalf("CreateToken failed: %v", err)
+		}
+		return response
+	}
+
+	permissionsOf := func(t *testing.T, user models.User, scopes ...string) []string {
+		t.Helper()
+
+		response := createToken(t, user, scopes...)
+		if response.Status != http.StatusCreated {
+			t.Fatalf("CreateToken = %d %s", response.Status, response.Message)
+		}
+		created := response.Payload.(dtos.CreatedPersonalAccessTokenDto)
+
+		claims, err := repo.Authenticate(ctx, created.Token, "127.0.0.1")
+		if err != nil {
+			t.Fatalf("Authenticate failed: %v", err)
+		}
+		return claims.Permissions
+	}
+
+	todoPermissions := []string{models.PermissionTodosRead, models.PermissionTodosWrite}
+
+	t.Run("unscoped token of an administrator gets the todo scopes", func(t *testing.T) {
+		response := createToken(t, admin)
+		if created, ok := response.Payload.(dtos.CreatedPersonalAccessTokenDto); !ok || !reflect.DeepEqual(created.Scopes, todoPermissions) {
+			t.Errorf("created token scopes = %+v, want %q", response.Payload, todoPermissions)
+		}
+
+		permissions := permissionsOf(t, admin)
+		if !reflect.DeepEqual(slices.Sorted(slices.Values(permissions)), todoPermissions) {
+			t.Errorf("permissions = %q, want %q", permissions, todoPermissions)
+		}
+	})
+
+	t.Run("administrative scopes are granted when asked for", func(t *testing.T) {
+		permissions := permissionsOf(t, admin, models.PermissionUsersRead)
+		if !reflect.DeepEqual(permissions, []string{models.PermissionUsersRead}) {
+			t.Errorf("permissions = %q, want only %s", permissions, models.PermissionUsersRead)
+		}
+	})
+
+	t.Run("scopes beyond the owner's permissions are refused", func(t *testing.T) {
+		if response := createToken(t, member, models.PermissionUsersRead); response.Status != http.StatusBadRequest {
+			t.Errorf("CreateToken = %d %s, want 400", response.Status, response.Message)
+		}
+	})
+
+	t.Run("tokens stored without scopes get the todo scopes", func(t *testing.T) {
+		token, prefix, err := utils.GeneratePersonalAccessToken()
+		if err != nil {
+			t.Fatalf("GeneratePersonalAccessToken failed: %v", err)
+		}
+		legacyToken := models.PersonalAccessToken{UserID: admin.ID, Name: "legacy", Prefix: prefix, TokenHash: utils.HashToken(token)}
+		if err := db.Create(&legacyToken).Error; err != nil {
+			t.Fatalf("failed to store token: %v", err)
+		}
+
+		claims, err := repo.Authenticate(ctx, token, "127.0.0.1")
+		if err != nil {
+			t.Fatalf("Authenticate failed: %v", err)
+		}
+		if !reflect.DeepEqual(slices.Sorted(slices.Values(claims.Permissions)), todoPermissions) {
+			t.Errorf("permissions = %q, want %q", claims.Permissions, todoPermissions)
+		}
+	})
+}
+
+func TestPersonalAccessTokenRepositoryAuthenticate(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewPersonalAccessTokenRepository(zap.NewNop())
+	ctx := context.Background()
+	user := createTestAccount(t, db, "alice")
+	assignTestRoles(t, db, user, models.RoleUser)
+
+	createToken := func(t *testing.T) dtos.CreatedPersonalAccessTokenDto {
+		t.Helper()
+
+		response, err := repo.CreateToken(ctx, dtos.CreatePersonalAccessTokenDto{Name: "ci", Scopes: []string{models.PermissionTodosRead}, UserID: user.ID})
+		if err != nil || response.Status != http.StatusCreated {
+			t.Fatalf("CreateToken = %d %s, %v", response.Status, response.Message, err)
+		}
+		return response.Payload.(dtos.CreatedPersonalAccessTokenDto)
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		created := createToken(t)
+
+		claims, err := repo.Authenticate(ctx, created.Token, "203.0.113.1")
+		if err != nil {
+			t.Fatalf("Authenticate failed: %v", err)
+		}
+		if claims.UserID != user.ID || claims.TokenType != utils.TokenTypePersonalAccessToken {
+			t.Errorf("claims = user %d type %q, want user %d type %q", claims.UserID, claims.TokenType, user.ID, utils.TokenTypePersonalAccessToken)
+		}
+
+		var stored models.PersonalAccessToken
+		if err := db.First(&stored, created.ID).Error; err != nil {
+			t.Fatalf("failed to load token: %v", err)
+		}
+		if stored.LastUsedAt == nil || stored.LastUsedIP != "203.0.113.1" {
+			t.Errorf("last use = %v from %q, want now from 203.0.113.1", stored.LastUsedAt, stored.LastUsedIP)
+		}
+	})
+
+	t.Run("revoked", func(t *testing.T) {
+		created := createToken(t)
+
+		response, err := repo.RevokeToken(ctx, dtos.RevokePersonalAccessTokenDto{ID: created.ID, UserID: user.ID})
+		if err != nil || response.Status != http.StatusOK {
+			t.Fatalf("RevokeToken = %d %s, %v", response.Status, response.Message, err)
+		}
+		if _, err := repo.Authenticate(ctx, created.Token, "203.0.113.1"); !errors.Is(err, ErrInvalidPersonalAccessToken) {
+			t.Errorf("Authenticate = %v, want ErrInvalidPersonalAccessToken", err)
+		}
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		created := createToken(t)
+
+		if err := db.Model(&models.PersonalAccessToken{}).Where("id = ?", created.ID).Update("expiresAt", time.Now().Add(-time.Minute)).Error; err != nil {
+			t.Fatalf("failed to expire token: %v", err)
+		}
+		if _, err := repo.Authenticate(ctx, created.Token, "203.0.113.1"); !errors.Is(err, ErrInvalidPersonalAccessToken) {
+			t.Errorf("Authenticate = %v, want ErrInvalidPersonalAccessToken", err)
+		}
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		if _, err := repo.Authenticate(ctx, "tdp_00000000_unknown", "203.0.113.1"); !errors.Is(err, ErrInvalidPersonalAccessToken) {
+			t.Errorf("Authenticate = %v, want ErrInvalidPersonalAccessToken", err)
+		}
+	})
+}
@@ -0,0 +1,325 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+	"time"
+	"todo-api/config"
+	"todo-api/database"
+	"todo-api/internal/models"
+	"todo-api/internal/utils"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// How often revoked tokens past their expiry are deleted
+const revokedTokenPurgeInterval = time.Hour
+
+// revocationCache mirrors the revocation tables in memory so the auth middleware
+// does not hit the database on every request. It is shared by every
+// RevocationRepository in the process and resynced periodically so revocations
+// made by other instances are picked up.
+type revocationCache struct {
+	mu           sync.RWMutex
+	syncMu       sync.Mutex           // held while the cache reloads, readers never wait for it
+	tokens       map[string]time.Time // jti -> token expiry
+	userCutoffs  map[uint]time.Time   // user ID -> tokens issued before this are invalid
+	sessions     map[uint]time.Time   // revoked session ID -> revocation time
+	lastSyncedAt time.Time
+}
+
+var sharedRevocationCache = &revocationCache{
+	tokens:      map[string]time.Time{},
+	userCutoffs: map[uint]time.Time{},
+	sessions:    map[uint]time.Time{},
+}
+
+type RevocationRepository struct {
+	DB     *gorm.DB
+	Logger *zap.Logger
+	cache  *revocationCache
+}
+
+func NewRevocationRepository(logger *zap.Logger) *RevocationRepository {
+	return &RevocationRepository{
+		DB:     database.GetDB(),
+		Logger: logger,
+		cache:  sharedRevocationCache,
+	}
+}
+
+// RevokeToken invalidates a single access token until it expires
+func (r *RevocationRepository) RevokeToken(ctx context.Context, jti string, userID uint, expiresAt time.Time) error {
+	revokedToken := models.RevokedToken{
+		JTI:       jti,
+		UserID:    userID,
+		ExpiresAt: expiresAt,
+	}
+
+	if err := r.DB.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&revokedToken).Error; err != nil {
+		return err
+	}
+
+	r.cache.mu.Lock()
+	r.cache.tokens[jti] = expiresAt
+	r.cache.mu.Unlock()
+
+	return nil
+}
+
+// RevokeAllForUser cuts every credential of the user: access tokens issued before now, sessions,
+// refresh tokens, personal access tokens and the tokens and pending codes of OAuth apps. It is
+// used when the account may be in the wrong hands, such as a password reset or "logout everywhere".
+func (r *RevocationRepository) RevokeAllForUser(ctx context.Context, userID uint) error {
+	return r.revokeForUser(ctx, userID, true)
+}
+
+// RevokeLoginTokensForUser invalidates the access tokens issued to the user before now and
+// revokes their sessions and refresh tokens. Personal access tokens and OAuth tokens are kept,
+// their permissions are read again on every request.
+func (r *RevocationRepository) RevokeLoginTokensForUser(ctx context.Context, userID uint) error {
+	return r.revokeForUser(ctx, userID, false)
+}
+
+func (r *RevocationRepository) revokeForUser(ctx context.Context, userID uint, apiCredentials bool) error {
+	now := time.Now()
+	cutoff := tokenCutoff(now)
+
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("id = ?", userID).Update("tokensRevokedAt", cutoff).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.Session{}).
+			Where(`"userId" = ? AND "revokedAt" IS NULL`, userID).
+			Update("revokedAt", now).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.RefreshToken{}).
+			Where(`"userId" = ? AND "revokedAt" IS NULL`, userID).
+			Update("revokedAt", now).Error; err != nil {
+			return err
+		}
+
+		if !apiCredentials {
+			return nil
+		}
+
+		if err := tx.Model(&models.PersonalAccessToken{}).
+			Where(`"userId" = ? AND "revokedAt" IS NULL`, userID).
+			Update("revokedAt", now).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.OAuthToken{}).
+			Where(`"userId" = ? AND "revokedAt" IS NULL`, userID).
+			Update("revokedAt", now).Error; err != nil {
+			return err
+		}
+
+		// A code the app has not exchanged yet would otherwise still turn into tokens
+		return tx.Model(&models.OAuthAuthorizationCode{}).
+			Where(`"userId" = ? AND "usedAt" IS NULL AND "expiresAt" > ?`, userID, now).
+			Update("expiresAt", now).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	r.cache.mu.Lock()
+	r.cache.userCutoffs[userID] = cutoff
+	r.cache.mu.Unlock()
+
+	return nil
+}
+
+// RevokeSessions logs out the given sessions of a user: their refresh tokens stop working
+// immediately and their access tokens are rejected by the middleware. It returns the number
+// of sessions that were still active.
+func (r *RevocationRepository) RevokeSessions(ctx context.Context, userID uint, sessionIDs []uint) (int64, error) {
+	if len(sessionIDs) == 0 {
+		return 0, nil
+	}
+
+	now := time.Now()
+	var revoked int64
+
+	err := r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Session{}).
+			Where(`id IN ? AND "userId" = ? AND "revokedAt" IS NULL`, sessionIDs, userID).
+			Update("revokedAt", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		revoked = result.RowsAffected
+
+		return tx.Model(&models.RefreshToken{}).
+			Where(`"sessionId" IN ? AND "userId" = ? AND "revokedAt" IS NULL`, sessionIDs, userID).
+			Update("revokedAt", now).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	r.cache.mu.Lock()
+	for _, sessionID := range sessionIDs {
+		r.cache.sessions[sessionID] = now
+	}
+	r.cache.mu.Unlock()
+
+	return revoked, nil
+}
+
+// IsRevoked reports whether the token described by the claims has been revoked,
+// individually, with its session or by a "logout everywhere" of its user
+func (r *RevocationRepository) IsRevoked(ctx context.Context, claims *utils.JWTClaims) (bool, error) {
+	if err := r.syncIfStale(ctx); err != nil {
+		return false, err
+	}
+
+	r.cache.mu.RLock()
+	defer r.cache.mu.RUnlock()
+
+	if _, revoked := r.cache.tokens[claims.ID]; revoked && claims.ID != "" {
+		return true, nil
+	}
+
+	if _, revoked := r.cache.sessions[claims.SessionID]; revoked && claims.SessionID != 0 {
+		return true, nil
+	}
+
+	if cutoff, ok := r.cache.userCutoffs[claims.UserID]; ok {
+		if claims.IssuedAt == nil || claims.IssuedAt.Time.Before(cutoff) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// syncIfStale reloads the cache from the database once the sync interval has elapsed. The rows
+// are loaded without holding the cache lock, so requests keep reading the cache and local
+// revocations keep writing to it while the queries run; the lock is only taken to swap the maps.
+func (r *RevocationRepository) syncIfStale(ctx context.Context) error {
+	interval := config.GetConfig().Auth.RevocationSyncInterval
+
+	r.cache.mu.RLock()
+	stale := time.Since(r.cache.lastSyncedAt) >= interval
+	r.cache.mu.RUnlock()
+
+	if !stale {
+		return nil
+	}
+
+	// Only one request loads, the others wait for its result instead of running the same queries
+	r.cache.syncMu.Lock()
+	defer r.cache.syncMu.Unlock()
+
+	r.cache.mu.RLock()
+	stale = time.Since(r.cache.lastSyncedAt) >= interval
+	r.cache.mu.RUnlock()
+
+	if !stale {
+		return nil
+	}
+
+	now := time.Now()
+	liveSince := now.Add(-config.GetConfig().Auth.AccessTokenTTL)
+
+	var revokedTokens []models.RevokedToken
+	if err := r.DB.WithContext(ctx).Where(`"expiresAt" >= ?`, now).Find(&revokedTokens).Error; err != nil {
+		return err
+	}
+
+	// Cutoffs older than the access token lifetime cannot affect any live token
+	var users []models.User
+	if err := r.DB.WithContext(ctx).Select("id", "tokensRevokedAt").
+		Where(`"tokensRevokedAt" > ?`, liveSince).
+		Find(&users).Error; err != nil {
+		return err
+	}
+
+	// Same for sessions, access tokens of older revoked sessions have expired
+	var sessions []models.Session
+	if err := r.DB.WithContext(ctx).Select("id", "revokedAt").
+		Where(`"revokedAt" > ?`, liveSince).
+		Find(&sessions).Error; err != nil {
+		return err
+	}
+
+	tokens := make(map[string]time.Time, len(revokedTokens))
+	for _, token := range revokedTokens {
+		tokens[token.JTI] = token.ExpiresAt
+	}
+
+	userCutoffs := make(map[uint]time.Time, len(users))
+	for _, user := range users {
+		userCutoffs[user.ID] = tokenCutoff(*user.TokensRevokedAt)
+	}
+
+	revokedSessions := make(map[uint]time.Time, len(sessions))
+	for _, session := range sessions {
+		revokedSessions[session.ID] = *session.RevokedAt
+	}
+
+	r.cache.mu.Lock()
+	defer r.cache.mu.Unlock()
+
+	// Revocations are never undone, so entries this instance added while the rows were loading
+	// are kept as long as they can still match a live token
+	for jti, expiresAt := range r.cache.tokens {
+		if _, ok := tokens[jti]; !ok && expiresAt.After(now) {
+			tokens[jti] = expiresAt
+		}
+	}
+	for userID, cutoff := range r.cache.userCutoffs {
+		if cutoff.After(userCutoffs[userID]) && cutoff.After(liveSince) {
+			userCutoffs[userID] = cutoff
+		}
+	}
+	for sessionID, revokedAt := range r.cache.sessions {
+		if _, ok := revokedSessions[sessionID]; !ok && revokedAt.After(liveSince) {
+			revokedSessions[sessionID] = revokedAt
+		}
+	}
+
+	r.cache.tokens = tokens
+	r.cache.userCutoffs = userCutoffs
+	r.cache.sessions = revokedSessions
+	r.cache.lastSyncedAt = now
+
+	return nil
+}
+
+// PurgeExpired deletes the revoked tokens that have expired, they can no longer be presented
+func (r *RevocationRepository) PurgeExpired(ctx context.Context) error {
+	return r.DB.WithContext(ctx).Where(`"expiresAt" < ?`, time.Now()).Delete(&models.RevokedToken{}).Error
+}
+
+// RunPurge purges expired revoked tokens every revokedTokenPurgeInterval until the context is
+// cancelled. It runs in the background, so the purge never delays a request.
+func (r *RevocationRepository) RunPurge(ctx context.Context) {
+	ticker := time.NewTicker(revokedTokenPurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.PurgeExpired(ctx); err != nil && ctx.Err() == nil {
+				r.Logger.Warn("Failed to purge expired revoked tokens", zap.Error(err))
+			}
+		}
+	}
+}
+
+// tokenCutoff cuts a revocation time to the whole second. The iat claim of a JWT has no
+// fraction of a second, so a token issued right after a revocation within the same second
+// would otherwise look older than it and be rejected, such as the login after a password reset.
+func tokenCutoff(revokedAt time.Time) time.Time {
+	return revokedAt.Truncate(time.Second)
+}
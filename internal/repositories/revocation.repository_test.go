@@ -0,0 +1,267 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+	"todo-api/internal/models"
+	"todo-api/internal/utils"
+
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+)
+
+// newTestRevocationRepository returns a repository with a cache of its own, so tests do not
+// see each other's revocations
+func newTestRevocationRepository(t *testing.T) *RevocationRepository {
+	t.Helper()
+
+	return &RevocationRepository{
+		DB:     openTestDB(t),
+		Logger: zap.NewNop(),
+		cache: &revocationCache{
+			tokens:      map[string]time.Time{},
+			userCutoffs: map[uint]time.Time{},
+			sessions:    map[uint]time.Time{},
+		},
+	}
+}
+
+func TestTokenCutoffDropsFractionOfSecond(t *testing.T) {
+	revokedAt := time.Date(2025, 6, 12, 10, 0, 0, 900_000_000, time.UTC)
+	issuedAt := jwt.NewNumericDate(revokedAt.Add(50 * time.Millisecond))
+
+	if cutoff := tokenCutoff(revokedAt); issuedAt.Time.Before(cutoff) {
+		t.Errorf("token issued at %s is before the cutoff %s", issuedAt.Time, cutoff)
+	}
+}
+
+func TestRevocationRepositoryRevokeToken(t *testing.T) {
+	repo := newTestRevocationRepository(t)
+	user := createTestAccount(t, repo.DB, "alice")
+	ctx := context.Background()
+
+	token, err := utils.GenerateToken(user, 0)
+	if err != nil {
+		t.Fatalf("GenerateToken failed: %v", err)
+	}
+	claims, err := utils.ValidateToken(token)
+	if err != nil {
+		t.Fatalf("ValidateToken failed: %v", err)
+	}
+	if revoked, err := repo.IsRevoked(ctx, claims); err != nil || revoked {
+		t.Fatalf("token revoked before logout = %v, %v, want false", revoked, err)
+	}
+
+	if err := repo.RevokeToken(ctx, claims.ID, user.ID, claims.ExpiresAt.Time); err != nil {
+		t.Fatalf("RevokeToken failed: %v", err)
+	}
+
+	// The revocation counts both from the cache and once reloaded from the database
+	for _, source := range []string{"cache", "database"} {
+		t.Run(source, func(t *testing.T) {
+			if source == "database" {
+				repo.cache.lastSyncedAt = time.Time{}
+			}
+
+			if revoked, err := repo.IsRevoked(ctx, claims); err != nil || !revoked {
+				t.Errorf("revoked token revoked = %v, %v, want true", revoked, err)
+			}
+
+			other := *claims
+			other.ID = "another-token"
+			if revoked, err := repo.IsRevoked(ctx, &other); err != nil || revoked {
+				t.Errorf("other token revoked = %v, %v, want false", revoked, err)
+			}
+		})
+	}
+}
+
+func TestRevocationRepositoryRevokeAllForUser(t *testing.T) {
+	repo := newTestRevocationRepository(t)
+	user := createTestAccount(t, repo.DB, "alice")
+	ctx := context.Background()
+
+	login, err := NewAuthRepository(zap.NewNop()).startSession(repo.DB, user, "test", "127.0.0.1")
+	if err != nil {
+		t.Fatalf("startSession failed: %v", err)
+	}
+	// Not bound to the session, so only the cutoff decides
+	token, err := utils.GenerateToken(user, 0)
+	if err != nil {
+		t.Fatalf("GenerateToken failed: %v", err)
+	}
+	claims, err := utils.ValidateToken(token)
+	if err != nil {
+		t.Fatalf("ValidateToken failed: %v", err)
+	}
+
+	if err := repo.RevokeAllForUser(ctx, user.ID); err != nil {
+		t.Fatalf("RevokeAllForUser failed: %v", err)
+	}
+
+	var refreshToken models.RefreshToken
+	if err := repo.DB.Where(`"tokenHash" = ?`, utils.HashToken(login.RefreshToken)).First(&refreshToken).Error; err != nil {
+		t.Fatalf("failed to load refresh token: %v", err)
+	}
+	if refreshToken.RevokedAt == nil {
+		t.Error("refresh token was not revoked")
+	}
+
+	var session models.Session
+	if err := repo.DB.First(&session, sessionIDOf(t, login)).Error; err != nil {
+		t.Fatalf("failed to load session: %v", err)
+	}
+	if session.RevokedAt == nil {
+		t.Error("session was not revoked")
+	}
+
+	var stored models.User
+	if err := repo.DB.Select("tokensRevokedAt").First(&stored, user.ID).Error; err != nil {
+		t.Fatalf("failed to read cutoff: %v", err)
+	}
+
+	for _, source := range []string{"cache", "database"} {
+		t.Run(source, func(t *testing.T) {
+			if source == "database" {
+				repo.cache.lastSyncedAt = time.Time{}
+			}
+
+			older := *claims
+			older.IssuedAt = jwt.NewNumericDate(stored.TokensRevokedAt.Add(-time.Second))
+			if revoked, err := repo.IsRevoked(ctx, &older); err != nil || !revoked {
+				t.Errorf("token issued before the cutoff revoked = %v, %v, want true", revoked, err)
+			}
+
+			newer := *claims
+			newer.IssuedAt = jwt.NewNumericDate(stored.TokensRevokedAt.Add(time.Second))
+			if revoked, err := repo.IsRevoked(ctx, &newer); err != nil || revoked {
+				t.Errorf("token issued after the cutoff revoked = %v, %v, want false", revoked, err)
+			}
+
+			// Issued like the login that follows a password reset, usually within the same second
+			token, err := utils.GenerateToken(user, 0)
+			if err != nil {
+				t.Fatalf("GenerateToken failed: %v", err)
+			}
+			fresh, err := utils.ValidateToken(token)
+			if err != nil {
+				t.Fatalf("ValidateToken failed: %v", err)
+			}
+			if revoked, err := repo.IsRevoked(ctx, fresh); err != nil || revoked {
+				t.Errorf("token issued right after the revocation revoked = %v, %v, want false", revoked, err)
+			}
+		})
+	}
+}
+
+func TestRevocationRepositoryRevokesAPICredentials(t *testing.T) {
+	repo := newTestRevocationRepository(t)
+	ctx := context.Background()
+	client := createTestOAuthClient(t, repo.DB, "https://app.example.com/callback")
+
+	// createOAuthToken gives the user an access and refresh token of the app
+	createOAuthToken := func(t *testing.T, user models.User) models.OAuthToken {
+		t.Helper()
+
+		suffix := fmt.Sprintf("%d", time.Now().UnixNano())
+		oauthToken := models.OAuthToken{
+			ClientID:         client.ID,
+			UserID:           user.ID,
+			GrantID:          1,
+			AccessTokenHash:  utils.HashToken("access" + suffix),
+			RefreshTokenHash: utils.HashToken("refresh" + suffix),
+			Scopes:           models.PermissionTodosRead,
+			AccessExpiresAt:  time.Now().Add(time.Hour),
+			RefreshExpiresAt: time.Now().Add(24 * time.Hour),
+		}
+		if err := repo.DB.Create(&oauthToken).Error; err != nil {
+			t.Fatalf("failed to create OAuth token: %v", err)
+		}
+		return oauthToken
+	}
+
+	oauthTokenRevoked := func(t *testing.T, oauthToken models.OAuthToken) bool {
+		t.Helper()
+
+		if err := repo.DB.First(&oauthToken, oauthToken.ID).Error; err != nil {
+			t.Fatalf("failed to reload OAuth token: %v", err)
+		}
+		return oauthToken.RevokedAt != nil
+	}
+
+	t.Run("revoke all", func(t *testing.T) {
+		user := createTestAccount(t, repo.DB, "alice")
+		accessToken := createTestPersonalAccessToken(t, repo.DB, user)
+		oauthToken := createOAuthToken(t, user)
+
+		if err := repo.RevokeAllForUser(ctx, user.ID); err != nil {
+			t.Fatalf("RevokeAllForUser failed: %v", err)
+		}
+		if patRevoked, oauthRevoked := personalAccessTokenRevoked(t, repo.DB, accessToken), oauthTokenRevoked(t, oauthToken); !patRevoked || !oauthRevoked {
+			t.Errorf("personal access token revoked = %v, OAuth token revoked = %v, want both", patRevoked, oauthRevoked)
+		}
+	})
+
+	t.Run("login tokens only", func(t *testing.T) {
+		user := createTestAccount(t, repo.DB, "bob")
+		accessToken := createTestPersonalAccessToken(t, repo.DB, user)
+		oauthToken := createOAuthToken(t, user)
+
+		if err := repo.RevokeLoginTokensForUser(ctx, user.ID); err != nil {
+			t.Fatalf("RevokeLoginTokensForUser failed: %v", err)
+		}
+		if patRevoked, oauthRevoked := personalAccessTokenRevoked(t, repo.DB, accessToken), oauthTokenRevoked(t, oauthToken); patRevoked || oauthRevoked {
+			t.Errorf("personal access token revoked = %v, OAuth token revoked = %v, want neither", patRevoked, oauthRevoked)
+		}
+	})
+}
+
+func TestRevocationRepositorySyncKeepsLocalRevocations(t *testing.T) {
+	repo := newTestRevocationRepository(t)
+	ctx := context.Background()
+
+	// Written to the cache by this instance after the rows were loaded, not in the database
+	local := fmt.Sprintf("local-%d", time.Now().UnixNano())
+	repo.cache.tokens[local] = time.Now().Add(time.Hour)
+	repo.cache.tokens["expired"] = time.Now().Add(-time.Second)
+
+	if err := repo.syncIfStale(ctx); err != nil {
+		t.Fatalf("syncIfStale failed: %v", err)
+	}
+
+	if revoked, err := repo.IsRevoked(ctx, &utils.JWTClaims{RegisteredClaims: jwt.RegisteredClaims{ID: local}}); err != nil || !revoked {
+		t.Errorf("local revocation after a sync = %v, %v, want revoked", revoked, err)
+	}
+	if _, ok := repo.cache.tokens["expired"]; ok {
+		t.Error("expired revocation was kept by the sync")
+	}
+}
+
+func TestRevocationRepositoryPurgeExpired(t *testing.T) {
+	repo := newTestRevocationRepository(t)
+	user := createTestAccount(t, repo.DB, "alice")
+	ctx := context.Background()
+
+	expired := fmt.Sprintf("expired-%d", time.Now().UnixNano())
+	live := fmt.Sprintf("live-%d", time.Now().UnixNano())
+	if err := repo.RevokeToken(ctx, expired, user.ID, time.Now().Add(-time.Minute)); err != nil {
+		t.Fatalf("RevokeToken failed: %v", err)
+	}
+	if err := repo.RevokeToken(ctx, live, user.ID, time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("RevokeToken failed: %v", err)
+	}
+
+	if err := repo.PurgeExpired(ctx); err != nil {
+		t.Fatalf("PurgeExpired failed: %v", err)
+	}
+
+	var jtis []string
+	if err := repo.DB.Model(&models.RevokedToken{}).Where("jti IN ?", []string{expired, live}).Pluck("jti", &jtis).Error; err != nil {
+		t.Fatalf("failed to load revoked tokens: %v", err)
+	}
+	if len(jtis) != 1 || jtis[0] != live {
+		t.Errorf("revoked tokens after the purge = %v, want only %s", jtis, live)
+	}
+}
@@ -0,0 +1,286 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"time"
+	"todo-api/config"
+	"todo-api/database"
+	"todo-api/internal/dtos"
+	"todo-api/internal/models"
+	"todo-api/internal/password"
+	"todo-api/internal/utils"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// errBootstrapAdminNotVerified is returned when BOOTSTRAP_ADMIN_EMAIL belongs to a user who never verified it
+var errBootstrapAdminNotVerified = errors.New("bootstrap admin email is not verified")
+
+// errBootstrapAdminPasswordMismatch is returned when BOOTSTRAP_ADMIN_PASSWORD is not the password of the existing user
+var errBootstrapAdminPasswordMismatch = errors.New("bootstrap admin password does not match")
+
+type RoleRepository struct {
+	DB          *gorm.DB
+	Logger      *zap.Logger
+	revocations *RevocationRepository
+	passwords   *password.Manager
+}
+
+func NewRoleRepository(logger *zap.Logger) *RoleRepository {
+	return &RoleRepository{
+		DB:          database.GetDB(),
+		Logger:      logger,
+		revocations: NewRevocationRepository(logger),
+		passwords:   password.New(config.GetConfig().PasswordHashing),
+	}
+}
+
+func (r *RoleRepository) GetRoles(ctx context.Context) (dtos.StructuredResponse, error) {
+	var roles []models.Role
+
+	if err := r.DB.Preload("Permissions").Order("name").Find(&roles).Error; err != nil {
+		r.Logger.Error("Failed to retrieve roles", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to retrieve roles",
+			Payload: nil,
+		}, err
+	}
+
+	return dtos.StructuredResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Roles retrieved successfully",
+		Payload: roles,
+	}, nil
+}
+
+// SetUserRoles replaces the roles of a user. Tokens already issued keep their old claims,
+// so when a role is taken away the user is logged out to make the change effective immediately.
+func (r *RoleRepository) SetUserRoles(ctx context.Context, setUserRolesDto dtos.SetUserRolesDto) (dtos.StructuredResponse, error) {
+	var user models.User
+
+	if err := r.DB.Preload("Roles").First(&user, setUserRolesDto.UserID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return dtos.StructuredResponse{
+				Success: false,
+				Status:  http.StatusNotFound,
+				Message: "User not found",
+				Payload: nil,
+			}, nil
+		}
+		r.Logger.Error("Failed to find user", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to update roles",
+			Payload: nil,
+		}, err
+	}
+
+	if len(setUserRolesDto.Roles) == 0 {
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusBadRequest,
+			Message: "At least one role is required",
+			Payload: nil,
+		}, nil
+	}
+
+	slices.Sort(setUserRolesDto.Roles)
+	setUserRolesDto.Roles = slices.Compact(setUserRolesDto.Roles)
+
+	var roles []models.Role
+
+	if err := r.DB.Where("name IN ?", setUserRolesDto.Roles).Find(&roles).Error; err != nil {
+		r.Logger.Error("Failed to find roles", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to update roles",
+			Payload: nil,
+		}, err
+	}
+
+	if len(roles) != len(setUserRolesDto.Roles) {
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusBadRequest,
+			Message: "Unknown role",
+			Payload: nil,
+		}, nil
+	}
+
+	// Never let the last administrator demote themselves out of existence
+	if user.HasRole(models.RoleAdmin) && !containsRole(roles, models.RoleAdmin) {
+		adminCount, err := countAdmins(r.DB)
+		if err != nil {
+			r.Logger.Error("Failed to count administrators", zap.Error(err))
+			return dtos.StructuredResponse{
+				Success: false,
+				Status:  http.StatusInternalServerError,
+				Message: "Failed to update roles",
+				Payload: nil,
+			}, err
+		}
+		if adminCount <= 1 {
+			return dtos.StructuredResponse{
+				Success: false,
+				Status:  http.StatusConflict,
+				Message: "Cannot remove the last administrator",
+				Payload: nil,
+			}, nil
+		}
+	}
+
+	removed := false
+	for _, role := range user.Roles {
+		if !containsRole(roles, role.Name) {
+			removed = true
+		}
+	}
+
+	if err := r.DB.Model(&user).Association("Roles").Replace(roles); err != nil {
+		r.Logger.Error("Failed to update roles", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to update roles",
+			Payload: nil,
+		}, err
+	}
+
+	if removed {
+		// Personal access tokens and OAuth tokens follow the roles on their own
+		if err := r.revocations.RevokeLoginTokensForUser(ctx, user.ID); err != nil {
+			r.Logger.Error("Failed to revoke tokens after role change", zap.Uint("userId", user.ID), zap.Error(err))
+		}
+	}
+
+	return dtos.StructuredResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Roles updated successfully",
+		Payload: dtos.UserRolesDto{
+			UserID: user.ID,
+			Roles:  setUserRolesDto.Roles,
+		},
+	}, nil
+}
+
+// BootstrapAdmin creates the first administrator from BOOTSTRAP_ADMIN_* when no user has the admin role yet
+func (r *RoleRepository) BootstrapAdmin(ctx context.Context) error {
+	bootstrapConfig := config.GetConfig().BootstrapAdmin
+	bootstrapConfig.Email = utils.NormalizeEmail(bootstrapConfig.Email)
+
+	var adminCount int64
+	if err := r.DB.Model(&models.User{}).
+		Joins(`JOIN "UserRoles" ON "UserRoles".user_id = "Users".id`).
+		Joins(`JOIN "Roles" ON "Roles".id = "UserRoles".role_id`).
+		Where(`"Roles".name = ?`, models.RoleAdmin).
+		Count(&adminCount).Error; err != nil {
+		return err
+	}
+
+	if adminCount > 0 {
+		return nil
+	}
+
+	if bootstrapConfig.Email == "" {
+		r.Logger.Warn("No administrator exists, set BOOTSTRAP_ADMIN_EMAIL to create one")
+		return nil
+	}
+
+	var adminRole, userRole models.Role
+	if err := r.DB.Where("name = ?", models.RoleAdmin).First(&adminRole).Error; err != nil {
+		return err
+	}
+	if err := r.DB.Where("name = ?", models.RoleUser).First(&userRole).Error; err != nil {
+		return err
+	}
+
+	var user models.User
+	err := r.DB.Where("email = ?", bootstrapConfig.Email).First(&user).Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		if bootstrapConfig.Password == "" {
+			r.Logger.Warn("Bootstrap admin does not exist, set BOOTSTRAP_ADMIN_PASSWORD to create it",
+				zap.String("email", bootstrapConfig.Email))
+			return nil
+		}
+
+		hashedPassword, err := r.passwords.Hash(bootstrapConfig.Password)
+		if err != nil {
+			return err
+		}
+
+		verifiedAt := time.Now()
+		user = models.User{
+			Email:           bootstrapConfig.Email,
+			Name:            bootstrapConfig.Name,
+			PasswordHash:    hashedPassword,
+			EmailVerifiedAt: &verifiedAt,
+			Roles:           []models.Role{userRole, adminRole},
+		}
+
+		if err := r.DB.Omit("Roles.*").Create(&user).Error; err != nil {
+			return err
+		}
+
+		r.Logger.Info("Bootstrap administrator created", zap.String("email", user.Email))
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	// Anyone can register with the configured email, so the account must prove it is the operator's
+	if user.EmailVerifiedAt == nil {
+		r.Logger.Error("Refusing to promote the bootstrap admin, the email of the existing user is not verified",
+			zap.String("email", user.Email))
+		return errBootstrapAdminNotVerified
+	}
+	if bootstrapConfig.Password == "" {
+		r.Logger.Error("Refusing to promote the bootstrap admin, set BOOTSTRAP_ADMIN_PASSWORD to the password of the existing user",
+			zap.String("email", user.Email))
+		return errBootstrapAdminPasswordMismatch
+	}
+	if matches, _, err := r.passwords.Verify(bootstrapConfig.Password, user.PasswordHash); err != nil || !matches {
+		r.Logger.Error("Refusing to promote the bootstrap admin, BOOTSTRAP_ADMIN_PASSWORD does not match the password of the existing user",
+			zap.String("email", user.Email), zap.Error(err))
+		return errBootstrapAdminPasswordMismatch
+	}
+
+	if err := r.DB.Model(&user).Association("Roles").Append(&adminRole); err != nil {
+		return err
+	}
+
+	r.Logger.Info("Existing user promoted to administrator", zap.String("email", user.Email))
+	return nil
+}
+
+// countAdmins returns the number of enabled users with the admin role
+func countAdmins(db *gorm.DB) (int64, error) {
+	var adminCount int64
+	err := db.Table(`"UserRoles"`).
+		Joins(`JOIN "Roles" ON "Roles".id = "UserRoles".role_id`).
+		Joins(`JOIN "Users" ON "Users".id = "UserRoles".user_id`).
+		Where(`"Roles".name = ? AND "Users"."disabledAt" IS NULL`, models.RoleAdmin).
+		Count(&adminCount).Error
+	return adminCount, err
+}
+
+func containsRole(roles []models.Role, name string) bool {
+	for _, role := range roles {
+		if role.Name == name {
+			return true
+		}
+	}
+	return false
+}
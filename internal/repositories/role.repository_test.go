@@ -0,0 +1,154 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"todo-api/config"
+	"todo-api/internal/dtos"
+	"todo-api/internal/models"
+	"todo-api/internal/password"
+
+	"go.uber.org/zap"
+)
+
+func TestRoleRepositorySetUserRoles(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewRoleRepository(zap.NewNop())
+	ctx := context.Background()
+	alice := createTestAccount(t, db, "alice")
+	bob := createTestAccount(t, db, "bob")
+	accessToken := createTestPersonalAccessToken(t, db, alice)
+
+	setRoles := func(t *testing.T, user models.User, roles ...string) int {
+		t.Helper()
+
+		response, err := repo.SetUserRoles(ctx, dtos.SetUserRolesDto{UserID: user.ID, Roles: roles})
+		if err != nil {
+			t.Fatalf("SetUserRoles failed: %v", err)
+		}
+		return response.Status
+	}
+
+	loadUser := func(t *testing.T, user models.User) models.User {
+		t.Helper()
+
+		var stored models.User
+		if err := db.Preload("Roles").First(&stored, user.ID).Error; err != nil {
+			t.Fatalf("failed to load user: %v", err)
+		}
+		return stored
+	}
+
+	t.Run("invalid roles", func(t *testing.T) {
+		if status := setRoles(t, alice); status != http.StatusBadRequest {
+			t.Errorf("no roles: status = %d, want 400", status)
+		}
+		if status := setRoles(t, alice, models.RoleUser, "superuser"); status != http.StatusBadRequest {
+			t.Errorf("unknown role: status = %d, want 400", status)
+		}
+		if status := setRoles(t, models.User{ID: 0}, models.RoleUser); status != http.StatusNotFound {
+			t.Errorf("unknown user: status = %d, want 404", status)
+		}
+	})
+
+	t.Run("grant", func(t *testing.T) {
+		for _, user := range []models.User{alice, bob} {
+			if status := setRoles(t, user, models.RoleAdmin, models.RoleUser, models.RoleAdmin); status != http.StatusOK {
+				t.Fatalf("status = %d, want 200", status)
+			}
+		}
+
+		stored := loadUser(t, alice)
+		if !stored.HasRole(models.RoleAdmin) || !stored.HasRole(models.RoleUser) || len(stored.Roles) != 2 {
+			t.Errorf("roles = %v, want admin and user", stored.RoleNames())
+		}
+		// Nothing was taken away, the tokens already issued stay valid
+		if stored.TokensRevokedAt != nil {
+			t.Error("tokens were revoked although no role was removed")
+		}
+	})
+
+	t.Run("revoke", func(t *testing.T) {
+		if status := setRoles(t, alice, models.RoleUser); status != http.StatusOK {
+			t.Fatalf("status = %d, want 200", status)
+		}
+
+		stored := loadUser(t, alice)
+		if stored.HasRole(models.RoleAdmin) {
+			t.Error("admin role was not removed")
+		}
+		// Tokens still claim the removed role, so they must no longer be accepted
+		if stored.TokensRevokedAt == nil {
+			t.Error("tokens were not revoked after a role was removed")
+		}
+		// Personal access tokens read the permissions on every request and are kept
+		if personalAccessTokenRevoked(t, db, accessToken) {
+			t.Error("personal access token was revoked after a role was removed")
+		}
+	})
+}
+
+func TestRoleRepositoryBootstrapAdminExistingUser(t *testing.T) {
+	db := openTestDB(t)
+	passwords := password.New(config.GetConfig().PasswordHashing)
+	hashedPassword, err := passwords.Hash("correct horse battery staple")
+	if err != nil {
+		t.Fatalf("failed to hash password: %v", err)
+	}
+
+	bootstrapConfig := config.GetConfig().BootstrapAdmin
+	t.Cleanup(func() {
+		config.GetConfig().BootstrapAdmin = bootstrapConfig
+	})
+
+	tests := []struct {
+		name     string
+		verified bool
+		password string
+		wantErr  error
+	}{
+		{"unverified email", false, "correct horse battery staple", errBootstrapAdminNotVerified},
+		{"no password configured", true, "", errBootstrapAdminPasswordMismatch},
+		{"wrong password", true, "guessed", errBootstrapAdminPasswordMismatch},
+		{"verified with the password", true, "correct horse battery staple", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Rolled back at the end, so the existing administrators of the database are only hidden
+			tx := db.Begin()
+			t.Cleanup(func() {
+				tx.Rollback()
+			})
+			if err := tx.Exec(`DELETE FROM "UserRoles" WHERE role_id IN (SELECT id FROM "Roles" WHERE name = ?)`, models.RoleAdmin).Error; err != nil {
+				t.Fatalf("failed to hide administrators: %v", err)
+			}
+
+			user := createTestAccount(t, tx, "alice")
+			if err := tx.Model(&user).Update("passwordHash", hashedPassword).Error; err != nil {
+				t.Fatalf("failed to set password: %v", err)
+			}
+			if !tt.verified {
+				if err := tx.Model(&user).Update("emailVerifiedAt", nil).Error; err != nil {
+					t.Fatalf("failed to unverify email: %v", err)
+				}
+			}
+			config.GetConfig().BootstrapAdmin = config.BootstrapAdminConfig{Email: user.Email, Password: tt.password}
+
+			repo := &RoleRepository{DB: tx, Logger: zap.NewNop(), passwords: passwords}
+			if err := repo.BootstrapAdmin(context.Background()); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("BootstrapAdmin = %v, want %v", err, tt.wantErr)
+			}
+
+			adminCount, err := countAdmins(tx)
+			if err != nil {
+				t.Fatalf("failed to count administrators: %v", err)
+			}
+			if promoted := adminCount > 0; promoted != (tt.wantErr == nil) {
+				t.Errorf("promoted = %v, want %v", promoted, tt.wantErr == nil)
+			}
+		})
+	}
+}
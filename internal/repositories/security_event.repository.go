@@ -0,0 +1,163 @@
+package repositories
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+	"todo-api/config"
+	"todo-api/database"
+	"todo-api/internal/dtos"
+	"todo-api/internal/models"
+	"todo-api/internal/utils"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// How often events past the retention period are deleted
+const securityEventPurgeInterval = time.Hour
+
+// securityEventPurge remembers when the process last deleted expired events, so the purge
+// runs at most once per interval however many events are recorded
+var securityEventPurge struct {
+	mu      sync.Mutex
+	lastRun time.Time
+}
+
+type SecurityEventRepository struct {
+	DB     *gorm.DB
+	Logger *zap.Logger
+}
+
+func NewSecurityEventRepository(logger *zap.Logger) *SecurityEventRepository {
+	return &SecurityEventRepository{
+		DB:     database.GetDB(),
+		Logger: logger,
+	}
+}
+
+// Record stores a security event. The client IP address and user agent are taken from the
+// request context when the event does not name them. Failures are logged and never fail
+// the action being recorded.
+func (r *SecurityEventRepository) Record(ctx context.Context, event models.SecurityEvent) {
+	client := utils.GetClientInfoFromContext(ctx)
+	if event.IPAddress == "" {
+		event.IPAddress = client.IPAddress
+	}
+	if event.UserAgent == "" {
+		event.UserAgent = client.UserAgent
+	}
+	event.UserAgent = truncate(event.UserAgent, 512)
+	event.Details = truncate(event.Details, 500)
+
+	// The request may be over by the time a slow insert runs, the event must still be kept
+	if err := r.DB.WithContext(context.WithoutCancel(ctx)).Create(&event).Error; err != nil {
+		r.Logger.Error("Failed to record security event",
+			zap.Uint("userId", event.UserID),
+			zap.String("type", event.Type),
+			zap.Error(err),
+		)
+		return
+	}
+
+	r.purgeExpired(ctx)
+}
+
+// ListEvents returns a page of a user's security events, newest first
+func (r *SecurityEventRepository) ListEvents(ctx context.Context, listSecurityEventsDto dtos.ListSecurityEventsDto) (dtos.StructuredResponse, error) {
+	page, pageSize := normalizePage(listSecurityEventsDto.Page, listSecurityEventsDto.PageSize)
+
+	query := r.DB.WithContext(ctx).Model(&models.SecurityEvent{}).Where(`"userId" = ?`, listSecurityEventsDto.UserID)
+	if listSecurityEventsDto.Type != "" {
+		query = query.Where("type = ?", listSecurityEventsDto.Type)
+	}
+	// Expired events may not have been purged yet
+	if retention := config.GetConfig().SecurityEvents.Retention; retention > 0 {
+		query = query.Where(`"createdAt" >= ?`, time.Now().Add(-retention))
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		r.Logger.Error("Failed to count security events", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to retrieve security events",
+			Payload: nil,
+		}, err
+	}
+
+	var events []models.SecurityEvent
+	if err := query.Order(`"createdAt" DESC, id DESC`).
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&events).Error; err != nil {
+		r.Logger.Error("Failed to list security events", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to retrieve security events",
+			Payload: nil,
+		}, err
+	}
+
+	eventDtos := make([]dtos.SecurityEventDto, 0, len(events))
+	for _, event := range events {
+		eventDtos = append(eventDtos, dtos.SecurityEventDto{
+			ID:        event.ID,
+			Type:      event.Type,
+			IPAddress: event.IPAddress,
+			UserAgent: event.UserAgent,
+			Details:   event.Details,
+			ActorID:   event.ActorID,
+			CreatedAt: event.CreatedAt,
+		})
+	}
+
+	return dtos.StructuredResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Security events retrieved successfully",
+		Payload: dtos.SecurityEventListDto{
+			Events:   eventDtos,
+			Page:     page,
+			PageSize: pageSize,
+			Total:    total,
+		},
+	}, nil
+}
+
+// purgeExpired deletes events older than the retention period, at most once per interval
+func (r *SecurityEventRepository) purgeExpired(ctx context.Context) {
+	retention := config.GetConfig().SecurityEvents.Retention
+	if retention <= 0 {
+		return
+	}
+
+	securityEventPurge.mu.Lock()
+	if time.Since(securityEventPurge.lastRun) < securityEventPurgeInterval {
+		securityEventPurge.mu.Unlock()
+		return
+	}
+	securityEventPurge.lastRun = time.Now()
+	securityEventPurge.mu.Unlock()
+
+	if err := r.DB.WithContext(context.WithoutCancel(ctx)).
+		Where(`"createdAt" < ?`, time.Now().Add(-retention)).
+		Delete(&models.SecurityEvent{}).Error; err != nil {
+		r.Logger.Warn("Failed to purge expired security events", zap.Error(err))
+	}
+}
+
+// truncate shortens a string to at most limit bytes without splitting a character
+func truncate(value string, limit int) string {
+	if len(value) <= limit {
+		return value
+	}
+	for limit > 0 && !utf8.RuneStart(value[limit]) {
+		limit--
+	}
+	return value[:limit]
+}
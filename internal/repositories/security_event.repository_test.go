@@ -0,0 +1,85 @@
+package repositories
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+	"todo-api/config"
+	"todo-api/internal/dtos"
+	"todo-api/internal/models"
+	"todo-api/internal/utils"
+
+	"go.uber.org/zap"
+)
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		value string
+		limit int
+		want  string
+	}{
+		{"short", 10, "short"},
+		{"exactly", 7, "exactly"},
+		{"too long", 3, "too"},
+		// "é" takes two bytes and is dropped rather than split
+		{"café", 4, "caf"},
+		{"café", 5, "café"},
+		{"", 0, ""},
+	}
+
+	for _, tt := range tests {
+		if got := truncate(tt.value, tt.limit); got != tt.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tt.value, tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestSecurityEventRepository(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewSecurityEventRepository(zap.NewNop())
+
+	retention := &config.GetConfig().SecurityEvents.Retention
+	previous := *retention
+	*retention = 24 * time.Hour
+	t.Cleanup(func() { *retention = previous })
+
+	user := createTestAccount(t, db, "alice")
+	ctx := utils.SetClientInfoInContext(context.Background(), utils.ClientInfo{IPAddress: "203.0.113.1", UserAgent: "test"})
+
+	repo.Record(ctx, models.SecurityEvent{UserID: user.ID, Type: models.SecurityEventLoginFailed, Details: "Invalid password"})
+	repo.Record(ctx, models.SecurityEvent{UserID: user.ID, Type: models.SecurityEventLoginSucceeded, IPAddress: "198.51.100.7"})
+
+	// Past the retention period, listed nowhere even before it is purged
+	expired := models.SecurityEvent{UserID: user.ID, Type: models.SecurityEventLoginFailed, CreatedAt: time.Now().Add(-48 * time.Hour)}
+	if err := db.Create(&expired).Error; err != nil {
+		t.Fatalf("failed to create expired event: %v", err)
+	}
+
+	list := func(t *testing.T, eventType string) dtos.SecurityEventListDto {
+		t.Helper()
+
+		response, err := repo.ListEvents(context.Background(), dtos.ListSecurityEventsDto{UserID: user.ID, Type: eventType})
+		if err != nil || response.Status != http.StatusOK {
+			t.Fatalf("ListEvents = %d, %v, want 200", response.Status, err)
+		}
+		return response.Payload.(dtos.SecurityEventListDto)
+	}
+
+	events := list(t, "")
+	if events.Total != 2 || len(events.Events) != 2 {
+		t.Fatalf("listed %d of %d events, want 2 of 2", len(events.Events), events.Total)
+	}
+
+	// Newest first, the client of the request fills in what the event does not name
+	if succeeded := events.Events[0]; succeeded.Type != models.SecurityEventLoginSucceeded || succeeded.IPAddress != "198.51.100.7" || succeeded.UserAgent != "test" {
+		t.Errorf("newest event = %+v, want the successful login from 198.51.100.7", succeeded)
+	}
+	if failed := events.Events[1]; failed.IPAddress != "203.0.113.1" || failed.Details != "Invalid password" {
+		t.Errorf("oldest event = %+v, want the failed login from 203.0.113.1", failed)
+	}
+
+	if failed := list(t, models.SecurityEventLoginFailed); failed.Total != 1 {
+		t.Errorf("listed %d failed logins, want 1", failed.Total)
+	}
+}
@@ -0,0 +1,210 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+	"todo-api/config"
+	"todo-api/database"
+	"todo-api/internal/dtos"
+	"todo-api/internal/models"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// maxUserAgentLength matches the column size of Session.UserAgent
+const maxUserAgentLength = 512
+
+// sessionTouches remembers when each session's last-seen time was last written by this
+// instance, so busy clients cost one UPDATE per SESSION_TOUCH_INTERVAL instead of one per request
+var sessionTouches = struct {
+	sync.Mutex
+	seen map[uint]time.Time
+}{seen: map[uint]time.Time{}}
+
+type SessionRepository struct {
+	DB             *gorm.DB
+	Logger         *zap.Logger
+	revocations    *RevocationRepository
+	securityEvents *SecurityEventRepository
+}
+
+func NewSessionRepository(logger *zap.Logger) *SessionRepository {
+	return &SessionRepository{
+		DB:             database.GetDB(),
+		Logger:         logger,
+		revocations:    NewRevocationRepository(logger),
+		securityEvents: NewSecurityEventRepository(logger),
+	}
+}
+
+// CreateSession records a new login of the user from a client
+func (r *SessionRepository) CreateSession(db *gorm.DB, userID uint, userAgent string, ipAddress string) (models.Session, error) {
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+
+	now := time.Now()
+	session := models.Session{
+		UserID:     userID,
+		UserAgent:  userAgent,
+		IPAddress:  ipAddress,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(config.GetConfig().Auth.RefreshTokenTTL),
+	}
+
+	err := db.Create(&session).Error
+	return session, err
+}
+
+// GetSessions lists the user's active sessions, most recently used first
+func (r *SessionRepository) GetSessions(ctx context.Context, userID uint, currentSessionID uint) (dtos.StructuredResponse, error) {
+	var sessions []models.Session
+
+	if err := r.DB.Where(`"userId" = ? AND "revokedAt" IS NULL AND "expiresAt" > ?`, userID, time.Now()).
+		Order(`"lastSeenAt" DESC`).
+		Find(&sessions).Error; err != nil {
+		r.Logger.Error("Failed to retrieve sessions", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to retrieve sessions",
+			Payload: nil,
+		}, err
+	}
+
+	sessionDtos := make([]dtos.SessionDto, 0, len(sessions))
+	for _, session := range sessions {
+		sessionDtos = append(sessionDtos, dtos.SessionDto{
+			ID:         session.ID,
+			UserAgent:  session.UserAgent,
+			IPAddress:  session.IPAddress,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+			Current:    session.ID == currentSessionID,
+		})
+	}
+
+	return dtos.StructuredResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Sessions retrieved successfully",
+		Payload: sessionDtos,
+	}, nil
+}
+
+// RevokeSession logs out one of the user's sessions
+func (r *SessionRepository) RevokeSession(ctx context.Context, revokeSessionDto dtos.RevokeSessionDto) (dtos.StructuredResponse, error) {
+	revoked, err := r.revocations.RevokeSessions(ctx, revokeSessionDto.UserID, []uint{revokeSessionDto.ID})
+	if err != nil {
+		r.Logger.Error("Failed to revoke session", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to revoke session",
+			Payload: nil,
+		}, err
+	}
+
+	if revoked == 0 {
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusNotFound,
+			Message: "Session not found",
+			Payload: nil,
+		}, nil
+	}
+
+	r.securityEvents.Record(ctx, models.SecurityEvent{
+		UserID:  revokeSessionDto.UserID,
+		Type:    models.SecurityEventTokenRevoked,
+		Details: fmt.Sprintf("Session %d revoked", revokeSessionDto.ID),
+	})
+
+	return dtos.StructuredResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Session revoked successfully",
+		Payload: nil,
+	}, nil
+}
+
+// RevokeOtherSessions logs out every session of the user except the one making the request
+func (r *SessionRepository) RevokeOtherSessions(ctx context.Context, revokeOtherSessionsDto dtos.RevokeOtherSessionsDto) (dtos.StructuredResponse, error) {
+	var sessionIDs []uint
+
+	if err := r.DB.Model(&models.Session{}).
+		Where(`"userId" = ? AND id <> ? AND "revokedAt" IS NULL`, revokeOtherSessionsDto.UserID, revokeOtherSessionsDto.CurrentSessionID).
+		Pluck("id", &sessionIDs).Error; err != nil {
+		r.Logger.Error("Failed to find sessions", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to revoke sessions",
+			Payload: nil,
+		}, err
+	}
+
+	revoked, err := r.revocations.RevokeSessions(ctx, revokeOtherSessionsDto.UserID, sessionIDs)
+	if err != nil {
+		r.Logger.Error("Failed to revoke sessions", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to revoke sessions",
+			Payload: nil,
+		}, err
+	}
+
+	if revoked > 0 {
+		r.securityEvents.Record(ctx, models.SecurityEvent{
+			UserID:  revokeOtherSessionsDto.UserID,
+			Type:    models.SecurityEventTokenRevoked,
+			Details: fmt.Sprintf("%d other sessions revoked", revoked),
+		})
+	}
+
+	return dtos.StructuredResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Other sessions revoked successfully",
+		Payload: map[string]interface{}{
+			"revoked": revoked,
+		},
+	}, nil
+}
+
+// TouchSession records activity on a session. Writes are throttled per session and
+// conditional, so concurrent instances rarely write the same row twice.
+func (r *SessionRepository) TouchSession(ctx context.Context, sessionID uint, ipAddress string) {
+	interval := config.GetConfig().Auth.SessionTouchInterval
+	now := time.Now()
+
+	sessionTouches.Lock()
+	if lastTouch, ok := sessionTouches.seen[sessionID]; ok && now.Sub(lastTouch) < interval {
+		sessionTouches.Unlock()
+		return
+	}
+	sessionTouches.seen[sessionID] = now
+	// Drop stale entries from time to time so the map does not grow forever
+	if len(sessionTouches.seen) > 10000 {
+		for id, lastTouch := range sessionTouches.seen {
+			if now.Sub(lastTouch) >= interval {
+				delete(sessionTouches.seen, id)
+			}
+		}
+	}
+	sessionTouches.Unlock()
+
+	if err := r.DB.WithContext(ctx).Model(&models.Session{}).
+		Where(`id = ? AND "lastSeenAt" < ?`, sessionID, now.Add(-interval)).
+		Updates(map[string]interface{}{
+			"lastSeenAt": now,
+			"ipAddress":  ipAddress,
+		}).Error; err != nil {
+		r.Logger.Warn("Failed to record session activity", zap.Uint("sessionId", sessionID), zap.Error(err))
+	}
+}
@@ -0,0 +1,97 @@
+package repositories
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"todo-api/internal/dtos"
+	"todo-api/internal/utils"
+
+	"go.uber.org/zap"
+)
+
+// sessionIDOf returns the session a login belongs to, as carried by its access token
+func sessionIDOf(t *testing.T, tokens dtos.AuthTokensDto) uint {
+	t.Helper()
+
+	claims, err := utils.ValidateToken(tokens.Token)
+	if err != nil {
+		t.Fatalf("ValidateToken failed: %v", err)
+	}
+	return claims.SessionID
+}
+
+func TestSessionRepository(t *testing.T) {
+	db := openTestDB(t)
+	auth := NewAuthRepository(zap.NewNop())
+	repo := NewSessionRepository(zap.NewNop())
+	ctx := context.Background()
+	user := createTestAccount(t, db, "alice")
+
+	current, err := auth.startSession(db, user, "laptop", "203.0.113.1")
+	if err != nil {
+		t.Fatalf("startSession failed: %v", err)
+	}
+	other, err := auth.startSession(db, user, "phone", "203.0.113.2")
+	if err != nil {
+		t.Fatalf("startSession failed: %v", err)
+	}
+	currentSessionID, otherSessionID := sessionIDOf(t, current), sessionIDOf(t, other)
+
+	sessionsOf := func(t *testing.T) []dtos.SessionDto {
+		t.Helper()
+
+		response, err := repo.GetSessions(ctx, user.ID, currentSessionID)
+		if err != nil || response.Status != http.StatusOK {
+			t.Fatalf("GetSessions = %d %s, %v", response.Status, response.Message, err)
+		}
+		return response.Payload.([]dtos.SessionDto)
+	}
+
+	sessions := sessionsOf(t)
+	if len(sessions) != 2 {
+		t.Fatalf("got %d sessions, want 2", len(sessions))
+	}
+	for _, session := range sessions {
+		if session.Current != (session.ID == currentSessionID) {
+			t.Errorf("session %d (%s) current = %v", session.ID, session.UserAgent, session.Current)
+		}
+	}
+
+	t.Run("sessions of other users cannot be revoked", func(t *testing.T) {
+		intruder := createTestAccount(t, db, "mallory")
+		response, err := repo.RevokeSession(ctx, dtos.RevokeSessionDto{ID: otherSessionID, UserID: intruder.ID})
+		if err != nil || response.Status != http.StatusNotFound {
+			t.Errorf("RevokeSession = %d %s, %v, want 404", response.Status, response.Message, err)
+		}
+	})
+
+	t.Run("revoke the other sessions", func(t *testing.T) {
+		response, err := repo.RevokeOtherSessions(ctx, dtos.RevokeOtherSessionsDto{UserID: user.ID, CurrentSessionID: currentSessionID})
+		if err != nil || response.Status != http.StatusOK {
+			t.Fatalf("RevokeOtherSessions = %d %s, %v", response.Status, response.Message, err)
+		}
+
+		if sessions := sessionsOf(t); len(sessions) != 1 || sessions[0].ID != currentSessionID {
+			t.Errorf("sessions = %+v, want only the current one", sessions)
+		}
+
+		// The refresh and access tokens of the revoked session stop working at once
+		response, err = auth.RefreshToken(ctx, dtos.RefreshTokenDto{RefreshToken: other.RefreshToken})
+		if err != nil || response.Status != http.StatusUnauthorized {
+			t.Errorf("refresh of the revoked session = %d %s, %v, want 401", response.Status, response.Message, err)
+		}
+		claims, err := utils.ValidateToken(other.Token)
+		if err != nil {
+			t.Fatalf("ValidateToken failed: %v", err)
+		}
+		if revoked, err := auth.revocations.IsRevoked(ctx, claims); err != nil || !revoked {
+			t.Errorf("access token of the revoked session revoked = %v, %v, want true", revoked, err)
+		}
+
+		response, err = auth.RefreshToken(ctx, dtos.RefreshTokenDto{RefreshToken: current.RefreshToken})
+		if err != nil || response.Status != http.StatusOK {
+			t.Errorf("refresh of the current session = %d %s, %v, want 200", response.Status, response.Message, err)
+		}
+	})
+}
@@ -0,0 +1,152 @@
+package repositories
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+	"todo-api/config"
+	"todo-api/database"
+	"todo-api/internal/models"
+	"todo-api/internal/password"
+	"todo-api/internal/utils"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// testPasswords hashes with the lowest costs so the tests stay fast
+var testPasswords = password.New(config.PasswordHashingConfig{
+	Algorithm:         "argon2id",
+	Argon2Memory:      1024,
+	Argon2Iterations:  1,
+	Argon2Parallelism: 1,
+	BcryptCost:        4,
+})
+
+// TestMain gives the tests secrets of their own unless the environment sets real ones
+func TestMain(m *testing.M) {
+	cfg := config.GetConfig()
+	if config.InsecureSecret(cfg.JWTSecret) {
+		cfg.JWTSecret = "repositories-test-jwt-secret"
+	}
+	if config.InsecureSecret(cfg.EncryptionKey) {
+		cfg.EncryptionKey = "repositories-test-encryption-key"
+	}
+
+	os.Exit(m.Run())
+}
+
+// openTestDB connects to the Postgres database named by TEST_DATABASE_URL and migrates it.
+// The test is skipped when the variable is not set.
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+		TranslateError: true,
+		Logger:         logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("failed to connect to the test database: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	// The real migration also adds the columns that are not part of the models
+	database.DB = db
+	if err := database.Migrate(); err != nil {
+		t.Fatalf("failed to migrate the test database: %v", err)
+	}
+
+	return db
+}
+
+// createTestAccount creates a verified user that is removed, with everything it owns, when the test ends
+func createTestAccount(t *testing.T, db *gorm.DB, name string) models.User {
+	t.Helper()
+
+	verifiedAt := time.Now()
+	user := models.User{
+		Email:           fmt.Sprintf("%s-%d@example.com", name, time.Now().UnixNano()),
+		Name:            name,
+		PasswordHash:    "unused",
+		EmailVerifiedAt: &verifiedAt,
+	}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("failed to create user %s: %v", name, err)
+	}
+	t.Cleanup(func() {
+		db.Delete(&models.User{}, user.ID)
+	})
+
+	return user
+}
+
+// createTestOAuthClient registers a public app that is removed, with its codes and tokens, when the test ends
+func createTestOAuthClient(t *testing.T, db *gorm.DB, redirectURI string) models.OAuthClient {
+	t.Helper()
+
+	client := models.OAuthClient{
+		ClientID:     fmt.Sprintf("test-%d", time.Now().UnixNano()),
+		Name:         "Test app",
+		RedirectURIs: redirectURI,
+		Scopes:       models.PermissionTodosRead + " " + models.PermissionTodosWrite,
+	}
+	if err := db.Create(&client).Error; err != nil {
+		t.Fatalf("failed to create OAuth client: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Delete(&models.OAuthClient{}, client.ID)
+	})
+
+	return client
+}
+
+// createTestPersonalAccessToken stores a personal access token of the user, it goes away with the user
+func createTestPersonalAccessToken(t *testing.T, db *gorm.DB, user models.User) models.PersonalAccessToken {
+	t.Helper()
+
+	accessToken := models.PersonalAccessToken{
+		UserID:    user.ID,
+		Name:      "CI",
+		Prefix:    "tdp_test",
+		TokenHash: utils.HashToken(fmt.Sprintf("pat-%d", time.Now().UnixNano())),
+	}
+	if err := db.Create(&accessToken).Error; err != nil {
+		t.Fatalf("failed to create personal access token: %v", err)
+	}
+
+	return accessToken
+}
+
+// personalAccessTokenRevoked reads back whether the token was revoked
+func personalAccessTokenRevoked(t *testing.T, db *gorm.DB, accessToken models.PersonalAccessToken) bool {
+	t.Helper()
+
+	if err := db.First(&accessToken, accessToken.ID).Error; err != nil {
+		t.Fatalf("failed to reload personal access token: %v", err)
+	}
+	return accessToken.RevokedAt != nil
+}
+
+// setTestPassword gives the user a password it can log in with
+func setTestPassword(t *testing.T, db *gorm.DB, user models.User, plainPassword string) {
+	t.Helper()
+
+	hashedPassword, err := testPasswords.Hash(plainPassword)
+	if err != nil {
+		t.Fatalf("failed to hash password: %v", err)
+	}
+	if err := db.Model(&user).Update("passwordHash", hashedPassword).Error; err != nil {
+		t.Fatalf("failed to set password: %v", err)
+	}
+}
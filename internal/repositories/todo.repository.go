@@ -2,15 +2,42 @@ package repositories
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"todo-api/config"
 	"todo-api/database"
 	"todo-api/internal/dtos"
 	"todo-api/internal/models"
+	"todo-api/internal/utils"
 
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
+// Fields GET /todos can be sorted on, with their column
+var todoSortColumns = map[string]string{
+	"id":        `"TodoItems".id`,
+	"title":     `"TodoItems".title`,
+	"createdAt": `"TodoItems"."createdAt"`,
+	"updatedAt": `"TodoItems"."updatedAt"`,
+	"dueAt":     `"TodoItems"."dueAt"`,
+}
+
+// todoCursor marks the last item of a page: the sort the page was made with, the value of the
+// sort field and the ID, which breaks ties between items with the same value. The value is
+// empty for an item without a due date.
+type todoCursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    uint   `json:"id"`
+}
+
 type TodoRepository struct {
 	DB     *gorm.DB
 	Logger *zap.Logger
@@ -22,13 +49,29 @@ func NewTodoRepository(logger *zap.Logger) *TodoRepository {
 		Logger: logger,
 	}
 }
+
+// ownedItems returns a query limited to the todo items of the authenticated user. Every query
+// of the repository starts from it, so items of other users look like they do not exist.
+func (r *TodoRepository) ownedItems(ctx context.Context) (*gorm.DB, uint, error) {
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	return r.DB.WithContext(ctx).Model(&models.TodoItem{}).Where(`"TodoItems".user_id = ?`, userID), userID, nil
+}
+
 func (r *TodoRepository) GetTodoItems(ctx context.Context) (dtos.StructuredResponse, error) {
 	var todoItems []models.TodoItem
 
 	r.Logger.Info("GetTodoItems request received")
 
+	query, _, err := r.ownedItems(ctx)
+	if err != nil {
+		return unauthenticatedResponse(), nil
+	}
+
 	// Use Preload to load the related Notes for each TodoItem
-	if err := r.DB.Preload("Notes").Preload("User").Find(&todoItems).Error; err != nil {
+	if err := query.Preload("Notes").Order("id").Find(&todoItems).Error; err != nil {
 		r.Logger.Error("Failed to retrieve todo items", zap.Error(err))
 		return dtos.StructuredResponse{
 			Success: false,
@@ -46,15 +89,236 @@ func (r *TodoRepository) GetTodoItems(ctx context.Context) (dtos.StructuredRespo
 	}, nil
 }
 
+// ListTodoItems returns one page of the user's todo items. Pages are found with the sort value
+// and ID of the last item of the previous page rather than an offset, so they stay fast and no
+// item is skipped or repeated when items are added or removed in between.
+func (r *TodoRepository) ListTodoItems(ctx context.Context, listTodoItemsDto dtos.ListTodoItemsDto) (dtos.StructuredResponse, error) {
+	_, userID, err := r.ownedItems(ctx)
+	if err != nil {
+		return unauthenticatedResponse(), nil
+	}
+
+	sort := strings.TrimPrefix(listTodoItemsDto.Sort, "-")
+	descending := strings.HasPrefix(listTodoItemsDto.Sort, "-")
+	if sort == "" {
+		sort = "id"
+	}
+	column, ok := todoSortColumns[sort]
+	if !ok {
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusBadRequest,
+			Message: "Invalid sort, use id, title, createdAt, updatedAt or dueAt, prefixed with - for descending order",
+			Payload: nil,
+		}, nil
+	}
+
+	// "Today" is the user's today, so the views need their time zone
+	var viewCondition string
+	var viewArgs []interface{}
+	if listTodoItemsDto.View != "" {
+		viewCondition, viewArgs, ok = todoViewCondition(listTodoItemsDto.View, time.Now(), r.userLocation(ctx, userID))
+		if !ok {
+			return dtos.StructuredResponse{
+				Success: false,
+				Status:  http.StatusBadRequest,
+				Message: "Invalid view, use overdue, today, next7days or nodate",
+				Payload: nil,
+			}, nil
+		}
+	}
+
+	sortKey := sort
+	if descending {
+		sortKey = "-" + sort
+	}
+
+	// The cursor only makes sense for the sort it was made with
+	var cursor *todoCursor
+	var cursorValue interface{}
+	if listTodoItemsDto.Cursor != "" {
+		decoded, err := decodeTodoCursor(listTodoItemsDto.Cursor)
+		if err == nil && decoded.Sort != sortKey {
+			err = errors.New("cursor was made for another sort")
+		}
+		if err == nil && !(sort == "dueAt" && decoded.Value == "") {
+			cursorValue, err = parseTodoSortValue(sort, decoded.Value)
+		}
+		if err != nil {
+			return dtos.StructuredResponse{
+				Success: false,
+				Status:  http.StatusBadRequest,
+				Message: "Invalid cursor",
+				Payload: nil,
+			}, nil
+		}
+		cursor = &decoded
+	}
+
+	listConfig := config.GetConfig().TodoList
+	limit := listTodoItemsDto.Limit
+	if limit < 1 {
+		limit = listConfig.DefaultPageSize
+	}
+	limit = min(limit, listConfig.MaxPageSize)
+
+	filtered := func() *gorm.DB {
+		query, _, _ := r.ownedItems(ctx)
+		if listTodoItemsDto.IsCompleted != nil {
+			query = query.Where(`"TodoItems"."isCompleted" = ?`, *listTodoItemsDto.IsCompleted)
+		}
+		if listTodoItemsDto.CreatedAfter != nil {
+			query = query.Where(`"TodoItems"."createdAt" > ?`, *listTodoItemsDto.CreatedAfter)
+		}
+		if listTodoItemsDto.CreatedBefore != nil {
+			query = query.Where(`"TodoItems"."createdAt" < ?`, *listTodoItemsDto.CreatedBefore)
+		}
+		if listTodoItemsDto.UpdatedAfter != nil {
+			query = query.Where(`"TodoItems"."updatedAt" > ?`, *listTodoItemsDto.UpdatedAfter)
+		}
+		if listTodoItemsDto.UpdatedBefore != nil {
+			query = query.Where(`"TodoItems"."updatedAt" < ?`, *listTodoItemsDto.UpdatedBefore)
+		}
+		if title := strings.TrimSpace(listTodoItemsDto.Title); title != "" {
+			query = query.Where(`LOWER("TodoItems".title) LIKE ?`, "%"+escapeLikePattern(strings.ToLower(title))+"%")
+		}
+		if viewCondition != "" {
+			query = query.Where(viewCondition, viewArgs...)
+		}
+		return query
+	}
+
+	var total *int64
+	if listTodoItemsDto.IncludeTotal {
+		var count int64
+		if err := filtered().Count(&count).Error; err != nil {
+			r.Logger.Error("Failed to count todo items", zap.Error(err))
+			return dtos.StructuredResponse{
+				Success: false,
+				Status:  http.StatusInternalServerError,
+				Message: "Failed to retrieve todo items",
+				Payload: nil,
+			}, err
+		}
+		total = &count
+	}
+
+	direction, comparison := "ASC", ">"
+	if descending {
+		direction, comparison = "DESC", "<"
+	}
+
+	query := filtered()
+	if cursor != nil {
+		switch {
+		case sort == "id":
+			query = query.Where(fmt.Sprintf(`"TodoItems".id %s ?`, comparison), cursor.ID)
+		case sort == "dueAt" && cursorValue == nil:
+			// Items without a due date come last in both directions and are ordered by ID
+			query = query.Where(fmt.Sprintf(`%s IS NULL AND "TodoItems".id %s ?`, column, comparison), cursor.ID)
+		case sort == "dueAt":
+			query = query.Where(fmt.Sprintf(`((%s, "TodoItems".id) %s (?, ?) OR %s IS NULL)`, column, comparison, column), cursorValue, cursor.ID)
+		default:
+			query = query.Where(fmt.Sprintf(`(%s, "TodoItems".id) %s (?, ?)`, column, comparison), cursorValue, cursor.ID)
+		}
+	}
+	if sort == "dueAt" {
+		query = query.Order(column + " " + direction + " NULLS LAST")
+	} else if sort != "id" {
+		query = query.Order(column + " " + direction)
+	}
+
+	// One item more than the page tells whether another page follows
+	var todoItems []models.TodoItem
+	if err := query.Order(`"TodoItems".id `+direction).
+		Limit(limit+1).
+		Preload("Notes", func(db *gorm.DB) *gorm.DB {
+			return db.Order("id")
+		}).
+		Find(&todoItems).Error; err != nil {
+		r.Logger.Error("Failed to retrieve todo items", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to retrieve todo items",
+			Payload: nil,
+		}, err
+	}
+
+	hasMore := len(todoItems) > limit
+	if hasMore {
+		todoItems = todoItems[:limit]
+	}
+
+	var nextCursor *string
+	if hasMore {
+		last := todoItems[len(todoItems)-1]
+		encoded := encodeTodoCursor(todoCursor{
+			Sort:  sortKey,
+			Value: todoSortValue(sort, last),
+			ID:    last.ID,
+		})
+		nextCursor = &encoded
+	}
+
+	itemDtos := make([]dtos.TodoItemDto, 0, len(todoItems))
+	for _, todoItem := range todoItems {
+		itemDtos = append(itemDtos, toTodoItemDto(todoItem))
+	}
+
+	return dtos.StructuredResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Todo items retrieved successfully",
+		Payload: dtos.TodoItemListDto{
+			Items:      itemDtos,
+			NextCursor: nextCursor,
+			HasMore:    hasMore,
+			Total:      total,
+		},
+	}, nil
+}
+
+// GetTodoItem returns one of the user's todo items with its notes
+func (r *TodoRepository) GetTodoItem(ctx context.Context, id uint) (dtos.StructuredResponse, error) {
+	query, _, err := r.ownedItems(ctx)
+	if err != nil {
+		return unauthenticatedResponse(), nil
+	}
+
+	var todoItem models.TodoItem
+	if err := query.Preload("Notes", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id")
+	}).First(&todoItem, id).Error; err != nil {
+		return r.todoLookupFailed(err)
+	}
+
+	return dtos.StructuredResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Todo item retrieved successfully",
+		Payload: todoItem,
+	}, nil
+}
+
 func (r *TodoRepository) CreateTodoItem(ctx context.Context, todoItemDto dtos.CreateTodoItemDto) (dtos.StructuredResponse, error) {
+	_, userID, err := r.ownedItems(ctx)
+	if err != nil {
+		return unauthenticatedResponse(), nil
+	}
+
 	// Convert DTO to model
 	todoItem := models.TodoItem{
 		Title:       todoItemDto.Title,
 		Description: todoItemDto.Description,
 		IsCompleted: false,
+		UserID:      userID,
+	}
+	if message := applyTodoSchedule(&todoItem, todoItemDto.TodoScheduleDto); message != "" {
+		return invalidScheduleResponse(message), nil
 	}
 
-	if err := r.DB.Create(&todoItem).Error; err != nil {
+	if err := r.DB.WithContext(ctx).Create(&todoItem).Error; err != nil {
 		return dtos.StructuredResponse{
 			Success: false,
 			Status:  http.StatusInternalServerError,
@@ -72,14 +336,24 @@ func (r *TodoRepository) CreateTodoItem(ctx context.Context, todoItemDto dtos.Cr
 }
 
 func (r *TodoRepository) CreateTodoNote(ctx context.Context, todoNoteDto dtos.CreateTodoNoteDto) (dtos.StructuredResponse, error) {
+	query, _, err := r.ownedItems(ctx)
+	if err != nil {
+		return unauthenticatedResponse(), nil
+	}
+
+	// Notes can only be added to the user's own items
+	var todoItem models.TodoItem
+	if err := query.First(&todoItem, todoNoteDto.TodoItemID).Error; err != nil {
+		return r.todoLookupFailed(err)
+	}
 
 	// Convert DTO to model
 	todoNote := models.TodoNote{
-		TodoItemID: todoNoteDto.TodoItemID,
+		TodoItemID: todoItem.ID,
 		Note:       todoNoteDto.Note,
 	}
 
-	if err := r.DB.Create(&todoNote).Error; err != nil {
+	if err := r.DB.WithContext(ctx).Create(&todoNote).Error; err != nil {
 		return dtos.StructuredResponse{
 			Success: false,
 			Status:  http.StatusInternalServerError,
@@ -96,24 +370,59 @@ func (r *TodoRepository) CreateTodoNote(ctx context.Context, todoNoteDto dtos.Cr
 	}, nil
 }
 
-func (r *TodoRepository) UpdateTodoItem(ctx context.Context, todoItemDto dtos.UpdateTodoItemDto) (dtos.StructuredResponse, error) {
+// GetTodoNotes lists the notes of one of the user's todo items, oldest first
+func (r *TodoRepository) GetTodoNotes(ctx context.Context, todoItemID uint) (dtos.StructuredResponse, error) {
+	query, _, err := r.ownedItems(ctx)
+	if err != nil {
+		return unauthenticatedResponse(), nil
+	}
 
 	var todoItem models.TodoItem
+	if err := query.Select("id").First(&todoItem, todoItemID).Error; err != nil {
+		return r.todoLookupFailed(err)
+	}
 
-	if err := r.DB.First(&todoItem, todoItemDto.ID).Error; err != nil {
+	todoNotes := []models.TodoNote{}
+	if err := r.DB.WithContext(ctx).Where(&models.TodoNote{TodoItemID: todoItem.ID}).Order("id").Find(&todoNotes).Error; err != nil {
+		r.Logger.Error("Failed to retrieve todo notes", zap.Error(err))
 		return dtos.StructuredResponse{
 			Success: false,
-			Status:  http.StatusNotFound,
-			Message: "Todo item not found",
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to retrieve todo notes",
 			Payload: nil,
-		}, nil
+		}, err
+	}
+
+	return dtos.StructuredResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Todo notes retrieved successfully",
+		Payload: todoNotes,
+	}, nil
+}
+
+func (r *TodoRepository) UpdateTodoItem(ctx context.Context, todoItemDto dtos.UpdateTodoItemDto) (dtos.StructuredResponse, error) {
+	query, _, err := r.ownedItems(ctx)
+	if err != nil {
+		return unauthenticatedResponse(), nil
+	}
+
+	var todoItem models.TodoItem
+
+	if err := query.First(&todoItem, todoItemDto.ID).Error; err != nil {
+		return r.todoLookupFailed(err)
 	}
 
 	todoItem.Title = todoItemDto.Title
 	todoItem.Description = todoItemDto.Description
 	todoItem.IsCompleted = todoItemDto.IsCompleted
+	if todoItemDto.Schedule != nil {
+		if message := applyTodoSchedule(&todoItem, *todoItemDto.Schedule); message != "" {
+			return invalidScheduleResponse(message), nil
+		}
+	}
 
-	if err := r.DB.Save(&todoItem).Error; err != nil {
+	if err := r.DB.WithContext(ctx).Save(&todoItem).Error; err != nil {
 		return dtos.StructuredResponse{
 			Success: false,
 			Status:  http.StatusInternalServerError,
@@ -130,25 +439,72 @@ func (r *TodoRepository) UpdateTodoItem(ctx context.Context, todoItemDto dtos.Up
 	}, nil
 }
 
-func (r *TodoRepository) DeleteTodoItem(ctx context.Context, todoItemDto dtos.DeleteTodoItemDto) (dtos.StructuredResponse, error) {
+// PatchTodoItem changes the fields of the DTO that are set and leaves the others alone
+func (r *TodoRepository) PatchTodoItem(ctx context.Context, todoItemDto dtos.PatchTodoItemDto) (dtos.StructuredResponse, error) {
+	query, userID, err := r.ownedItems(ctx)
+	if err != nil {
+		return unauthenticatedResponse(), nil
+	}
+
 	var todoItem models.TodoItem
 
-	if err := r.DB.First(&todoItem, todoItemDto.ID).Error; err != nil {
+	if err := query.First(&todoItem, todoItemDto.ID).Error; err != nil {
+		return r.todoLookupFailed(err)
+	}
+
+	if todoItemDto.Title != nil {
+		todoItem.Title = *todoItemDto.Title
+	}
+	if todoItemDto.Description != nil {
+		todoItem.Description = *todoItemDto.Description
+	}
+	if todoItemDto.IsCompleted != nil {
+		todoItem.IsCompleted = *todoItemDto.IsCompleted
+	}
+	if todoItemDto.DueAt.Set || todoItemDto.AllDay != nil || todoItemDto.ReminderOffsets != nil {
+		schedule := r.patchTodoSchedule(ctx, userID, todoItem, todoItemDto)
+		if message := applyTodoSchedule(&todoItem, schedule); message != "" {
+			return invalidScheduleResponse(message), nil
+		}
+	}
+
+	if err := r.DB.WithContext(ctx).Save(&todoItem).Error; err != nil {
 		return dtos.StructuredResponse{
 			Success: false,
-			Status:  http.StatusNotFound,
-			Message: "Todo item not found",
+			Status:  http.StatusInternalServerError,
+			Message: err.Error(),
 			Payload: nil,
-		}, nil
+		}, err
 	}
 
-	if err := r.DB.Delete(&todoItem).Error; err != nil {
+	return dtos.StructuredResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Todo item updated successfully",
+		Payload: todoItem,
+	}, nil
+}
+
+func (r *TodoRepository) DeleteTodoItem(ctx context.Context, todoItemDto dtos.DeleteTodoItemDto) (dtos.StructuredResponse, error) {
+	query, _, err := r.ownedItems(ctx)
+	if err != nil {
+		return unauthenticatedResponse(), nil
+	}
+
+	// The owner condition is part of the delete itself, another user's item is simply not matched
+	result := query.Delete(&models.TodoItem{}, todoItemDto.ID)
+
+	if result.Error != nil {
 		return dtos.StructuredResponse{
 			Success: false,
 			Status:  http.StatusInternalServerError,
-			Message: err.Error(),
+			Message: result.Error.Error(),
 			Payload: nil,
-		}, err
+		}, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return r.todoLookupFailed(gorm.ErrRecordNotFound)
 	}
 
 	return dtos.StructuredResponse{
@@ -158,3 +514,123 @@ func (r *TodoRepository) DeleteTodoItem(ctx context.Context, todoItemDto dtos.De
 		Payload: nil,
 	}, nil
 }
+
+// todoLookupFailed answers a failed lookup of one of the user's items. Items of other users
+// are reported as not found, so their IDs do not reveal anything.
+func (r *TodoRepository) todoLookupFailed(err error) (dtos.StructuredResponse, error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusNotFound,
+			Message: "Todo item not found",
+			Payload: nil,
+		}, nil
+	}
+
+	r.Logger.Error("Failed to find todo item", zap.Error(err))
+	return dtos.StructuredResponse{
+		Success: false,
+		Status:  http.StatusInternalServerError,
+		Message: "Failed to find todo item",
+		Payload: nil,
+	}, err
+}
+
+// invalidScheduleResponse rejects a due date or reminders that applyTodoSchedule refused
+func invalidScheduleResponse(message string) dtos.StructuredResponse {
+	return dtos.StructuredResponse{
+		Success: false,
+		Status:  http.StatusBadRequest,
+		Message: message,
+		Payload: nil,
+	}
+}
+
+// unauthenticatedResponse is returned when the context carries no user, which only happens
+// when a route is registered without the authentication middleware
+func unauthenticatedResponse() dtos.StructuredResponse {
+	return dtos.StructuredResponse{
+		Success: false,
+		Status:  http.StatusUnauthorized,
+		Message: "Unauthorized",
+		Payload: nil,
+	}
+}
+
+func encodeTodoCursor(cursor todoCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeTodoCursor(value string) (todoCursor, error) {
+	var cursor todoCursor
+
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return cursor, err
+	}
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return cursor, err
+	}
+	if cursor.ID == 0 {
+		return cursor, errors.New("cursor without ID")
+	}
+	return cursor, nil
+}
+
+// todoSortValue writes the value of the sort field of an item into a cursor
+func todoSortValue(sort string, todoItem models.TodoItem) string {
+	switch sort {
+	case "title":
+		return todoItem.Title
+	case "createdAt":
+		return todoItem.CreatedAt.UTC().Format(time.RFC3339Nano)
+	case "updatedAt":
+		return todoItem.UpdatedAt.UTC().Format(time.RFC3339Nano)
+	case "dueAt":
+		if todoItem.DueAt == nil {
+			return ""
+		}
+		return todoItem.DueAt.UTC().Format(time.RFC3339Nano)
+	default:
+		return strconv.FormatUint(uint64(todoItem.ID), 10)
+	}
+}
+
+// parseTodoSortValue reads the value of the sort field back from a cursor
+func parseTodoSortValue(sort string, value string) (interface{}, error) {
+	switch sort {
+	case "title":
+		return value, nil
+	case "createdAt", "updatedAt", "dueAt":
+		return time.Parse(time.RFC3339Nano, value)
+	default:
+		return strconv.ParseUint(value, 10, 64)
+	}
+}
+
+func toTodoItemDto(todoItem models.TodoItem) dtos.TodoItemDto {
+	noteDtos := make([]dtos.TodoNoteDto, 0, len(todoItem.Notes))
+	for _, todoNote := range todoItem.Notes {
+		noteDtos = append(noteDtos, dtos.TodoNoteDto{
+			ID:         todoNote.ID,
+			TodoItemID: todoNote.TodoItemID,
+			Note:       todoNote.Note,
+			CreatedAt:  todoNote.CreatedAt,
+			UpdatedAt:  todoNote.UpdatedAt,
+		})
+	}
+
+	return dtos.TodoItemDto{
+		ID:              todoItem.ID,
+		Title:           todoItem.Title,
+		Description:     todoItem.Description,
+		IsCompleted:     todoItem.IsCompleted,
+		CreatedAt:       todoItem.CreatedAt,
+		UpdatedAt:       todoItem.UpdatedAt,
+		DueAt:           todoItem.DueAt,
+		AllDay:          todoItem.AllDay,
+		ReminderOffsets: append([]int{}, todoItem.ReminderOffsets...),
+		Notes:           noteDtos,
+	}
+}
@@ -0,0 +1,317 @@
+package repositories
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+	"todo-api/internal/dtos"
+	"todo-api/internal/models"
+	"todo-api/internal/utils"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// newTestTodoRepository returns a repository on the test database, see openTestDB
+func newTestTodoRepository(t *testing.T) *TodoRepository {
+	t.Helper()
+
+	return &TodoRepository{DB: openTestDB(t), Logger: zap.NewNop()}
+}
+
+// createTestUser creates a user that is removed, along with its todo items, when the test ends
+func createTestUser(t *testing.T, db *gorm.DB, name string) context.Context {
+	t.Helper()
+
+	user := createTestAccount(t, db, name)
+	return utils.SetUserIDInContext(context.Background(), user.ID)
+}
+
+func createTestTodoItem(t *testing.T, repo *TodoRepository, ctx context.Context, title string) models.TodoItem {
+	t.Helper()
+
+	response, err := repo.CreateTodoItem(ctx, dtos.CreateTodoItemDto{Title: title})
+	if err != nil || response.Status != http.StatusOK {
+		t.Fatalf("failed to create todo item %q: %d %s %v", title, response.Status, response.Message, err)
+	}
+	return response.Payload.(models.TodoItem)
+}
+
+func TestTodoRepositoryRequiresAuthenticatedUser(t *testing.T) {
+	// The user is checked before the database is touched
+	repo := &TodoRepository{Logger: zap.NewNop()}
+	ctx := context.Background()
+
+	checks := map[string]func() (dtos.StructuredResponse, error){
+		"GetTodoItems": func() (dtos.StructuredResponse, error) {
+			return repo.GetTodoItems(ctx)
+		},
+		"ListTodoItems": func() (dtos.StructuredResponse, error) {
+			return repo.ListTodoItems(ctx, dtos.ListTodoItemsDto{})
+		},
+		"CreateTodoItem": func() (dtos.StructuredResponse, error) {
+			return repo.CreateTodoItem(ctx, dtos.CreateTodoItemDto{Title: "Buy groceries"})
+		},
+		"CreateTodoNote": func() (dtos.StructuredResponse, error) {
+			return repo.CreateTodoNote(ctx, dtos.CreateTodoNoteDto{TodoItemID: 1, Note: "Check dates"})
+		},
+		"UpdateTodoItem": func() (dtos.StructuredResponse, error) {
+			return repo.UpdateTodoItem(ctx, dtos.UpdateTodoItemDto{ID: 1, Title: "Buy groceries"})
+		},
+		"DeleteTodoItem": func() (dtos.StructuredResponse, error) {
+			return repo.DeleteTodoItem(ctx, dtos.DeleteTodoItemDto{ID: 1})
+		},
+	}
+
+	for name, check := range checks {
+		response, err := check()
+		if err != nil || response.Status != http.StatusUnauthorized {
+			t.Errorf("%s without a user: got %d %v, want %d", name, response.Status, err, http.StatusUnauthorized)
+		}
+	}
+}
+
+func TestTodoRepositoryCreateAssignsAuthenticatedUser(t *testing.T) {
+	repo := newTestTodoRepository(t)
+	alice := createTestUser(t, repo.DB, "alice")
+
+	item := createTestTodoItem(t, repo, alice, "Buy groceries")
+
+	aliceID, _ := utils.GetUserIDFromContext(alice)
+	if item.UserID != aliceID {
+		t.Errorf("created item belongs to user %d, want %d", item.UserID, aliceID)
+	}
+}
+
+func TestTodoRepositoryIsolatesUsers(t *testing.T) {
+	repo := newTestTodoRepository(t)
+	alice := createTestUser(t, repo.DB, "alice")
+	bob := createTestUser(t, repo.DB, "bob")
+
+	aliceItem := createTestTodoItem(t, repo, alice, "Alice's item")
+	bobItem := createTestTodoItem(t, repo, bob, "Bob's item")
+
+	t.Run("list only returns own items", func(t *testing.T) {
+		response, err := repo.GetTodoItems(bob)
+		if err != nil || response.Status != http.StatusOK {
+			t.Fatalf("GetTodoItems: %d %v", response.Status, err)
+		}
+
+		items := response.Payload.([]models.TodoItem)
+		if len(items) != 1 || items[0].ID != bobItem.ID {
+			t.Errorf("bob sees %d items, want only item %d", len(items), bobItem.ID)
+		}
+	})
+
+	t.Run("update of another user's item is not found", func(t *testing.T) {
+		response, err := repo.UpdateTodoItem(bob, dtos.UpdateTodoItemDto{ID: aliceItem.ID, Title: "Taken over", IsCompleted: true})
+		if err != nil || response.Status != http.StatusNotFound {
+			t.Errorf("UpdateTodoItem: got %d %v, want %d", response.Status, err, http.StatusNotFound)
+		}
+
+		var stored models.TodoItem
+		if err := repo.DB.First(&stored, aliceItem.ID).Error; err != nil {
+			t.Fatalf("failed to reload alice's item: %v", err)
+		}
+		if stored.Title != aliceItem.Title || stored.IsCompleted {
+			t.Errorf("alice's item was modified: %+v", stored)
+		}
+	})
+
+	t.Run("note on another user's item is not found", func(t *testing.T) {
+		response, err := repo.CreateTodoNote(bob, dtos.CreateTodoNoteDto{TodoItemID: aliceItem.ID, Note: "Hello"})
+		if err != nil || response.Status != http.StatusNotFound {
+			t.Errorf("CreateTodoNote: got %d %v, want %d", response.Status, err, http.StatusNotFound)
+		}
+
+		var notes int64
+		repo.DB.Model(&models.TodoNote{}).Where(&models.TodoNote{TodoItemID: aliceItem.ID}).Count(&notes)
+		if notes != 0 {
+			t.Errorf("alice's item has %d notes, want 0", notes)
+		}
+	})
+
+	t.Run("reads of another user's item are not found", func(t *testing.T) {
+		response, err := repo.GetTodoItem(bob, aliceItem.ID)
+		if err != nil || response.Status != http.StatusNotFound {
+			t.Errorf("GetTodoItem: got %d %v, want %d", response.Status, err, http.StatusNotFound)
+		}
+
+		response, err = repo.GetTodoNotes(bob, aliceItem.ID)
+		if err != nil || response.Status != http.StatusNotFound {
+			t.Errorf("GetTodoNotes: got %d %v, want %d", response.Status, err, http.StatusNotFound)
+		}
+	})
+
+	t.Run("patch of another user's item is not found", func(t *testing.T) {
+		completed := true
+		response, err := repo.PatchTodoItem(bob, dtos.PatchTodoItemDto{ID: aliceItem.ID, IsCompleted: &completed})
+		if err != nil || response.Status != http.StatusNotFound {
+			t.Errorf("PatchTodoItem: got %d %v, want %d", response.Status, err, http.StatusNotFound)
+		}
+	})
+
+	t.Run("delete of another user's item is not found", func(t *testing.T) {
+		response, err := repo.DeleteTodoItem(bob, dtos.DeleteTodoItemDto{ID: aliceItem.ID})
+		if err != nil || response.Status != http.StatusNotFound {
+			t.Errorf("DeleteTodoItem: got %d %v, want %d", response.Status, err, http.StatusNotFound)
+		}
+
+		var count int64
+		repo.DB.Model(&models.TodoItem{}).Where("id = ?", aliceItem.ID).Count(&count)
+		if count != 1 {
+			t.Errorf("alice's item was deleted")
+		}
+	})
+
+	t.Run("owner can still change their item", func(t *testing.T) {
+		response, err := repo.CreateTodoNote(alice, dtos.CreateTodoNoteDto{TodoItemID: aliceItem.ID, Note: "Milk"})
+		if err != nil || response.Status != http.StatusOK {
+			t.Errorf("CreateTodoNote: got %d %v, want %d", response.Status, err, http.StatusOK)
+		}
+
+		response, err = repo.UpdateTodoItem(alice, dtos.UpdateTodoItemDto{ID: aliceItem.ID, Title: "Alice's item", IsCompleted: true})
+		if err != nil || response.Status != http.StatusOK {
+			t.Errorf("UpdateTodoItem: got %d %v, want %d", response.Status, err, http.StatusOK)
+		}
+
+		response, err = repo.DeleteTodoItem(alice, dtos.DeleteTodoItemDto{ID: aliceItem.ID})
+		if err != nil || response.Status != http.StatusOK {
+			t.Errorf("DeleteTodoItem: got %d %v, want %d", response.Status, err, http.StatusOK)
+		}
+	})
+}
+
+func TestTodoCursorRoundTrip(t *testing.T) {
+	createdAt := time.Date(2026, time.October, 17, 8, 30, 0, 123456000, time.UTC)
+	item := models.TodoItem{ID: 42, Title: "Buy groceries", CreatedAt: createdAt}
+
+	for _, sort := range []string{"id", "title", "createdAt"} {
+		encoded := encodeTodoCursor(todoCursor{Sort: "-" + sort, Value: todoSortValue(sort, item), ID: item.ID})
+
+		cursor, err := decodeTodoCursor(encoded)
+		if err != nil {
+			t.Fatalf("%s: failed to decode cursor: %v", sort, err)
+		}
+		if cursor.Sort != "-"+sort || cursor.ID != item.ID {
+			t.Errorf("%s: decoded %+v", sort, cursor)
+		}
+
+		value, err := parseTodoSortValue(sort, cursor.Value)
+		if err != nil {
+			t.Fatalf("%s: failed to parse sort value: %v", sort, err)
+		}
+		if sort == "createdAt" && !value.(time.Time).Equal(createdAt) {
+			t.Errorf("createdAt: got %v, want %v", value, createdAt)
+		}
+	}
+
+	for _, invalid := range []string{"not base64!", "bm90IGpzb24", encodeTodoCursor(todoCursor{Sort: "id"})} {
+		if _, err := decodeTodoCursor(invalid); err == nil {
+			t.Errorf("cursor %q was accepted", invalid)
+		}
+	}
+}
+
+func TestTodoRepositoryListTodoItemsPages(t *testing.T) {
+	repo := newTestTodoRepository(t)
+	alice := createTestUser(t, repo.DB, "alice")
+	bob := createTestUser(t, repo.DB, "bob")
+
+	titles := []string{"Buy milk", "Call mom", "Buy bread", "Pay rent", "Book flights"}
+	for _, title := range titles {
+		createTestTodoItem(t, repo, alice, title)
+	}
+	createTestTodoItem(t, repo, bob, "Buy coffee")
+
+	list := func(ctx context.Context, listTodoItemsDto dtos.ListTodoItemsDto) dtos.TodoItemListDto {
+		t.Helper()
+		response, err := repo.ListTodoItems(ctx, listTodoItemsDto)
+		if err != nil || response.Status != http.StatusOK {
+			t.Fatalf("ListTodoItems(%+v): %d %s %v", listTodoItemsDto, response.Status, response.Message, err)
+		}
+		return response.Payload.(dtos.TodoItemListDto)
+	}
+
+	for _, sort := range []string{"id", "-id", "title", "-createdAt", "updatedAt"} {
+		t.Run("pages through every item sorted by "+sort, func(t *testing.T) {
+			seen := map[uint]bool{}
+			var previous *dtos.TodoItemDto
+			listTodoItemsDto := dtos.ListTodoItemsDto{Limit: 2, Sort: sort, IncludeTotal: true}
+
+			for pages := 0; ; pages++ {
+				if pages > len(titles) {
+					t.Fatal("pagination does not end")
+				}
+
+				page := list(alice, listTodoItemsDto)
+				if page.Total == nil || *page.Total != int64(len(titles)) {
+					t.Errorf("total: got %v, want %d", page.Total, len(titles))
+				}
+				for _, item := range page.Items {
+					if seen[item.ID] {
+						t.Errorf("item %d returned twice", item.ID)
+					}
+					seen[item.ID] = true
+					if sort == "title" && previous != nil && previous.Title > item.Title {
+						t.Errorf("%q listed before %q", previous.Title, item.Title)
+					}
+					previous = &item
+				}
+
+				if !page.HasMore {
+					if page.NextCursor != nil {
+						t.Error("last page has a next cursor")
+					}
+					break
+				}
+				listTodoItemsDto.Cursor = *page.NextCursor
+			}
+
+			if len(seen) != len(titles) {
+				t.Errorf("saw %d items, want %d", len(seen), len(titles))
+			}
+		})
+	}
+
+	t.Run("filters", func(t *testing.T) {
+		page := list(alice, dtos.ListTodoItemsDto{Title: "BUY", IncludeTotal: true})
+		if len(page.Items) != 2 || *page.Total != 2 {
+			t.Errorf("title filter matched %d items, want 2", len(page.Items))
+		}
+
+		completed := true
+		if page := list(alice, dtos.ListTodoItemsDto{IsCompleted: &completed}); len(page.Items) != 0 {
+			t.Errorf("completed filter matched %d items, want 0", len(page.Items))
+		}
+
+		future := time.Now().Add(time.Hour)
+		if page := list(alice, dtos.ListTodoItemsDto{CreatedBefore: &future}); len(page.Items) != len(titles) {
+			t.Errorf("createdBefore matched %d items, want %d", len(page.Items), len(titles))
+		}
+		if page := list(alice, dtos.ListTodoItemsDto{UpdatedAfter: &future}); len(page.Items) != 0 {
+			t.Errorf("updatedAfter matched %d items, want 0", len(page.Items))
+		}
+	})
+
+	t.Run("other users' items are not listed", func(t *testing.T) {
+		page := list(bob, dtos.ListTodoItemsDto{Title: "Buy"})
+		if len(page.Items) != 1 || page.Items[0].Title != "Buy coffee" {
+			t.Errorf("bob sees %+v", page.Items)
+		}
+	})
+
+	t.Run("invalid sort and cursor", func(t *testing.T) {
+		response, _ := repo.ListTodoItems(alice, dtos.ListTodoItemsDto{Sort: "description"})
+		if response.Status != http.StatusBadRequest {
+			t.Errorf("unknown sort: got %d, want %d", response.Status, http.StatusBadRequest)
+		}
+
+		page := list(alice, dtos.ListTodoItemsDto{Limit: 1, Sort: "title"})
+		response, _ = repo.ListTodoItems(alice, dtos.ListTodoItemsDto{Limit: 1, Sort: "-createdAt", Cursor: *page.NextCursor})
+		if response.Status != http.StatusBadRequest {
+			t.Errorf("cursor of another sort: got %d, want %d", response.Status, http.StatusBadRequest)
+		}
+	})
+}
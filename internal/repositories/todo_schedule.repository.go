@@ -0,0 +1,166 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+	"todo-api/internal/dtos"
+	"todo-api/internal/models"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// Most reminders a todo item can have
+	maxTodoReminders = 5
+	// Longest time a reminder can come before the due time, four weeks in minutes
+	maxTodoReminderOffset = 4 * 7 * 24 * 60
+)
+
+// Smart views of GET /todos
+const (
+	TodoViewOverdue   = "overdue"
+	TodoViewToday     = "today"
+	TodoViewNext7Days = "next7days"
+	TodoViewNoDate    = "nodate"
+)
+
+// todoDay is a calendar day of a user: the instant it starts in their time zone, which bounds
+// items due at a time, and its date as midnight UTC, which is how all-day items are stored
+type todoDay struct {
+	start time.Time
+	date  time.Time
+}
+
+// todayIn returns the day that is current at now in the time zone
+func todayIn(now time.Time, location *time.Location) todoDay {
+	year, month, day := now.In(location).Date()
+	return todoDay{
+		start: time.Date(year, month, day, 0, 0, 0, 0, location),
+		date:  time.Date(year, month, day, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+// addDays moves by calendar days. Days are not always 24 hours long when daylight saving time
+// starts or ends, so the start is computed from the date again.
+func (d todoDay) addDays(days int) todoDay {
+	year, month, day := d.start.Date()
+	return todoDay{
+		start: time.Date(year, month, day+days, 0, 0, 0, 0, d.start.Location()),
+		date:  d.date.AddDate(0, 0, days),
+	}
+}
+
+// todoViewCondition returns the condition selecting the items of a view, for a user whose
+// clock shows now in the time zone. Overdue items are open items whose due time has passed,
+// or whose date is before today when they are due all day. Today and the next 7 days (today
+// included) contain completed items too. The last result is false for an unknown view.
+func todoViewCondition(view string, now time.Time, location *time.Location) (string, []interface{}, bool) {
+	today := todayIn(now, location)
+
+	// Items due at a time and all-day items in [from, to)
+	dueBetween := func(from todoDay, to todoDay) (string, []interface{}, bool) {
+		return `(("TodoItems"."allDay" = false AND "TodoItems"."dueAt" >= ? AND "TodoItems"."dueAt" < ?) OR ("TodoItems"."allDay" = true AND "TodoItems"."dueAt" >= ? AND "TodoItems"."dueAt" < ?))`,
+			[]interface{}{from.start, to.start, from.date, to.date}, true
+	}
+
+	switch view {
+	case TodoViewOverdue:
+		return `("TodoItems"."isCompleted" = false AND (("TodoItems"."allDay" = false AND "TodoItems"."dueAt" < ?) OR ("TodoItems"."allDay" = true AND "TodoItems"."dueAt" < ?)))`,
+			[]interface{}{now, today.date}, true
+	case TodoViewToday:
+		return dueBetween(today, today.addDays(1))
+	case TodoViewNext7Days:
+		return dueBetween(today, today.addDays(7))
+	case TodoViewNoDate:
+		return `"TodoItems"."dueAt" IS NULL`, nil, true
+	default:
+		return "", nil, false
+	}
+}
+
+// applyTodoSchedule checks the schedule and stores it on the item. All-day items keep the date
+// the due time has in its own UTC offset. A message is returned when the schedule is invalid.
+func applyTodoSchedule(todoItem *models.TodoItem, schedule dtos.TodoScheduleDto) string {
+	if schedule.DueAt == nil && (schedule.AllDay || len(schedule.ReminderOffsets) > 0) {
+		return "allDay and reminderOffsets need a dueAt"
+	}
+
+	offsets := slices.Clone(schedule.ReminderOffsets)
+	slices.Sort(offsets)
+	offsets = slices.Compact(offsets)
+	if len(offsets) != len(schedule.ReminderOffsets) || len(offsets) > maxTodoReminders ||
+		(len(offsets) > 0 && (offsets[0] < 0 || offsets[len(offsets)-1] > maxTodoReminderOffset)) {
+		return fmt.Sprintf("reminderOffsets must be at most %d different numbers of minutes between 0 and %d", maxTodoReminders, maxTodoReminderOffset)
+	}
+
+	var dueAt *time.Time
+	if schedule.DueAt != nil {
+		due := schedule.DueAt.UTC()
+		if schedule.AllDay {
+			year, month, day := schedule.DueAt.Date()
+			due = time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+		}
+		dueAt = &due
+	}
+
+	todoItem.DueAt = dueAt
+	todoItem.AllDay = schedule.AllDay
+	todoItem.ReminderOffsets = offsets
+	return ""
+}
+
+// patchTodoSchedule merges the schedule fields of a PATCH into the schedule of the item. When
+// only allDay changes, the stored due time is read in the user's time zone, so the item stays
+// on the same day for them.
+func (r *TodoRepository) patchTodoSchedule(ctx context.Context, userID uint, todoItem models.TodoItem, todoItemDto dtos.PatchTodoItemDto) dtos.TodoScheduleDto {
+	schedule := dtos.TodoScheduleDto{
+		DueAt:           todoItem.DueAt,
+		AllDay:          todoItem.AllDay,
+		ReminderOffsets: todoItem.ReminderOffsets,
+	}
+
+	switch {
+	case todoItemDto.DueAt.Set:
+		schedule.DueAt = todoItemDto.DueAt.Time
+		if schedule.DueAt == nil {
+			schedule.AllDay = false
+			schedule.ReminderOffsets = nil
+		}
+	case todoItem.DueAt != nil && todoItemDto.AllDay != nil && *todoItemDto.AllDay != todoItem.AllDay:
+		location := r.userLocation(ctx, userID)
+		dueAt := todoItem.DueAt.In(location)
+		if todoItem.AllDay {
+			year, month, day := todoItem.DueAt.UTC().Date()
+			dueAt = time.Date(year, month, day, 0, 0, 0, 0, location)
+		}
+		schedule.DueAt = &dueAt
+	}
+
+	if todoItemDto.AllDay != nil {
+		schedule.AllDay = *todoItemDto.AllDay
+	}
+	if todoItemDto.ReminderOffsets != nil {
+		schedule.ReminderOffsets = todoItemDto.ReminderOffsets
+	}
+
+	return schedule
+}
+
+// userLocation returns the time zone of the user. UTC is used when the setting cannot be read,
+// which only shifts the day boundaries rather than failing the request.
+func (r *TodoRepository) userLocation(ctx context.Context, userID uint) *time.Location {
+	var user models.User
+	if err := r.DB.WithContext(ctx).Select("timezone").First(&user, userID).Error; err != nil {
+		r.Logger.Error("Failed to read the time zone of the user", zap.Error(err))
+		return time.UTC
+	}
+
+	location, err := time.LoadLocation(user.Timezone)
+	if err != nil {
+		r.Logger.Error("Failed to load the time zone of the user", zap.String("timezone", user.Timezone), zap.Error(err))
+		return time.UTC
+	}
+	return location
+}
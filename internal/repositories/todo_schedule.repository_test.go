@@ -0,0 +1,193 @@
+package repositories
+
+import (
+	"net/http"
+	"slices"
+	"testing"
+	"time"
+	"todo-api/internal/dtos"
+	"todo-api/internal/models"
+	"todo-api/internal/utils"
+)
+
+func TestTodayInFollowsDaylightSavingTime(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("failed to load time zone: %v", err)
+	}
+
+	// Already 30 March in Berlin, the day the clocks go forward
+	today := todayIn(time.Date(2025, 3, 29, 23, 30, 0, 0, time.UTC), berlin)
+
+	if want := time.Date(2025, 3, 29, 23, 0, 0, 0, time.UTC); !today.start.Equal(want) {
+		t.Errorf("start = %s, want %s", today.start.UTC(), want)
+	}
+	if want := time.Date(2025, 3, 30, 0, 0, 0, 0, time.UTC); !today.date.Equal(want) {
+		t.Errorf("date = %s, want %s", today.date, want)
+	}
+
+	// The day only has 23 hours
+	tomorrow := today.addDays(1)
+	if want := time.Date(2025, 3, 30, 22, 0, 0, 0, time.UTC); !tomorrow.start.Equal(want) {
+		t.Errorf("start of tomorrow = %s, want %s", tomorrow.start.UTC(), want)
+	}
+	if want := time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC); !tomorrow.date.Equal(want) {
+		t.Errorf("date of tomorrow = %s, want %s", tomorrow.date, want)
+	}
+}
+
+func TestTodoViewCondition(t *testing.T) {
+	now := time.Date(2025, 6, 12, 15, 0, 0, 0, time.UTC)
+
+	for _, view := range []string{TodoViewOverdue, TodoViewToday, TodoViewNext7Days, TodoViewNoDate} {
+		if _, _, ok := todoViewCondition(view, now, time.UTC); !ok {
+			t.Errorf("view %q was rejected", view)
+		}
+	}
+	if _, _, ok := todoViewCondition("someday", now, time.UTC); ok {
+		t.Error("unknown view was accepted")
+	}
+
+	_, args, _ := todoViewCondition(TodoViewNext7Days, now, time.UTC)
+	want := []interface{}{
+		time.Date(2025, 6, 12, 0, 0, 0, 0, time.UTC),
+		time.Date(2025, 6, 19, 0, 0, 0, 0, time.UTC),
+		time.Date(2025, 6, 12, 0, 0, 0, 0, time.UTC),
+		time.Date(2025, 6, 19, 0, 0, 0, 0, time.UTC),
+	}
+	if !slices.EqualFunc(args, want, func(a interface{}, b interface{}) bool {
+		return a.(time.Time).Equal(b.(time.Time))
+	}) {
+		t.Errorf("next7days bounds = %v, want %v", args, want)
+	}
+}
+
+func TestApplyTodoSchedule(t *testing.T) {
+	eveningInBerlin := time.Date(2025, 6, 12, 23, 30, 0, 0, time.FixedZone("CEST", 2*60*60))
+
+	tests := []struct {
+		name      string
+		schedule  dtos.TodoScheduleDto
+		wantDueAt *time.Time
+		wantValid bool
+	}{
+		{"no due date", dtos.TodoScheduleDto{}, nil, true},
+		{"due at a time is stored in UTC", dtos.TodoScheduleDto{DueAt: &eveningInBerlin}, ptr(eveningInBerlin.UTC()), true},
+		{"all day keeps the date of its offset", dtos.TodoScheduleDto{DueAt: &eveningInBerlin, AllDay: true}, ptr(time.Date(2025, 6, 12, 0, 0, 0, 0, time.UTC)), true},
+		{"reminders", dtos.TodoScheduleDto{DueAt: &eveningInBerlin, ReminderOffsets: []int{60, 0, 15}}, ptr(eveningInBerlin.UTC()), true},
+		{"all day without due date", dtos.TodoScheduleDto{AllDay: true}, nil, false},
+		{"reminders without due date", dtos.TodoScheduleDto{ReminderOffsets: []int{15}}, nil, false},
+		{"repeated reminder", dtos.TodoScheduleDto{DueAt: &eveningInBerlin, ReminderOffsets: []int{15, 15}}, nil, false},
+		{"negative reminder", dtos.TodoScheduleDto{DueAt: &eveningInBerlin, ReminderOffsets: []int{-5}}, nil, false},
+		{"reminder too early", dtos.TodoScheduleDto{DueAt: &eveningInBerlin, ReminderOffsets: []int{maxTodoReminderOffset + 1}}, nil, false},
+		{"too many reminders", dtos.TodoScheduleDto{DueAt: &eveningInBerlin, ReminderOffsets: []int{1, 2, 3, 4, 5, 6}}, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var todoItem models.TodoItem
+			message := applyTodoSchedule(&todoItem, tt.schedule)
+
+			if (message == "") != tt.wantValid {
+				t.Fatalf("message = %q, want valid %v", message, tt.wantValid)
+			}
+			if !tt.wantValid {
+				return
+			}
+			if (todoItem.DueAt == nil) != (tt.wantDueAt == nil) || (todoItem.DueAt != nil && !todoItem.DueAt.Equal(*tt.wantDueAt)) {
+				t.Errorf("dueAt = %v, want %v", todoItem.DueAt, tt.wantDueAt)
+			}
+			if !slices.IsSorted(todoItem.ReminderOffsets) || len(todoItem.ReminderOffsets) != len(tt.schedule.ReminderOffsets) {
+				t.Errorf("reminderOffsets = %v", todoItem.ReminderOffsets)
+			}
+		})
+	}
+}
+
+func TestTodoRepositoryViews(t *testing.T) {
+	repo := newTestTodoRepository(t)
+	ctx := createTestUser(t, repo.DB, "alice")
+	userID, _ := utils.GetUserIDFromContext(ctx)
+
+	// Far from UTC, so a day computed on the server's clock would be wrong most of the time
+	auckland, err := time.LoadLocation("Pacific/Auckland")
+	if err != nil {
+		t.Fatalf("failed to load time zone: %v", err)
+	}
+	if err := repo.DB.Model(&models.User{}).Where("id = ?", userID).Update("timezone", auckland.String()).Error; err != nil {
+		t.Fatalf("failed to set time zone: %v", err)
+	}
+
+	today := todayIn(time.Now(), auckland)
+	create := func(title string, schedule dtos.TodoScheduleDto) models.TodoItem {
+		response, err := repo.CreateTodoItem(ctx, dtos.CreateTodoItemDto{Title: title, TodoScheduleDto: schedule})
+		if err != nil || response.Status != http.StatusOK {
+			t.Fatalf("failed to create todo item %q: %d %s %v", title, response.Status, response.Message, err)
+		}
+		return response.Payload.(models.TodoItem)
+	}
+
+	create("Late yesterday", dtos.TodoScheduleDto{DueAt: ptr(today.start.Add(-time.Minute))})
+	create("All day yesterday", dtos.TodoScheduleDto{DueAt: ptr(today.date.AddDate(0, 0, -1)), AllDay: true})
+	create("All day today", dtos.TodoScheduleDto{DueAt: ptr(today.date), AllDay: true, ReminderOffsets: []int{60}})
+	create("Late today", dtos.TodoScheduleDto{DueAt: ptr(today.addDays(1).start.Add(-time.Second))})
+	create("In three days", dtos.TodoScheduleDto{DueAt: ptr(today.addDays(3).start.Add(12 * time.Hour))})
+	create("In eight days", dtos.TodoScheduleDto{DueAt: ptr(today.addDays(8).start)})
+	create("Someday", dtos.TodoScheduleDto{})
+	done := create("Done yesterday", dtos.TodoScheduleDto{DueAt: ptr(today.start.Add(-time.Hour))})
+	if response, err := repo.PatchTodoItem(ctx, dtos.PatchTodoItemDto{ID: done.ID, IsCompleted: ptr(true)}); err != nil || response.Status != http.StatusOK {
+		t.Fatalf("failed to complete todo item: %d %s %v", response.Status, response.Message, err)
+	}
+
+	views := map[string][]string{
+		TodoViewOverdue:   {"All day yesterday", "Late yesterday"},
+		TodoViewToday:     {"All day today", "Late today"},
+		TodoViewNext7Days: {"All day today", "Late today", "In three days"},
+		TodoViewNoDate:    {"Someday"},
+	}
+	for view, want := range views {
+		t.Run(view, func(t *testing.T) {
+			response, err := repo.ListTodoItems(ctx, dtos.ListTodoItemsDto{View: view, Sort: "dueAt"})
+			if err != nil || response.Status != http.StatusOK {
+				t.Fatalf("ListTodoItems = %d %s %v", response.Status, response.Message, err)
+			}
+
+			var titles []string
+			for _, item := range response.Payload.(dtos.TodoItemListDto).Items {
+				titles = append(titles, item.Title)
+			}
+			if !slices.Equal(titles, want) {
+				t.Errorf("titles = %v, want %v", titles, want)
+			}
+		})
+	}
+
+	t.Run("unknown view", func(t *testing.T) {
+		response, _ := repo.ListTodoItems(ctx, dtos.ListTodoItemsDto{View: "someday"})
+		if response.Status != http.StatusBadRequest {
+			t.Errorf("status = %d, want 400", response.Status)
+		}
+	})
+
+	t.Run("patch keeps the local day when switching to all day", func(t *testing.T) {
+		item := create("Lunch", dtos.TodoScheduleDto{DueAt: ptr(today.start.Add(12 * time.Hour)), ReminderOffsets: []int{30}})
+
+		response, err := repo.PatchTodoItem(ctx, dtos.PatchTodoItemDto{ID: item.ID, AllDay: ptr(true)})
+		if err != nil || response.Status != http.StatusOK {
+			t.Fatalf("PatchTodoItem = %d %s %v", response.Status, response.Message, err)
+		}
+		patched := response.Payload.(models.TodoItem)
+		if patched.DueAt == nil || !patched.DueAt.Equal(today.date) || !slices.Equal(patched.ReminderOffsets, []int{30}) {
+			t.Errorf("dueAt = %v, reminderOffsets = %v, want %s and [30]", patched.DueAt, patched.ReminderOffsets, today.date)
+		}
+
+		response, err = repo.PatchTodoItem(ctx, dtos.PatchTodoItemDto{ID: item.ID, DueAt: dtos.NullableTime{Set: true}})
+		if err != nil || response.Status != http.StatusOK {
+			t.Fatalf("PatchTodoItem = %d %s %v", response.Status, response.Message, err)
+		}
+		cleared := response.Payload.(models.TodoItem)
+		if cleared.DueAt != nil || cleared.AllDay || len(cleared.ReminderOffsets) != 0 {
+			t.Errorf("schedule was not removed: %v %v %v", cleared.DueAt, cleared.AllDay, cleared.ReminderOffsets)
+		}
+	})
+}
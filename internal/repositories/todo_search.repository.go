@@ -0,0 +1,230 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"todo-api/internal/dtos"
+	"todo-api/internal/models"
+	"unicode"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// Longest search text accepted
+	maxTodoSearchLength = 200
+	// Most words and phrases in one search
+	maxTodoSearchTerms = 20
+)
+
+// Options of ts_headline: the whole title is returned, descriptions and notes are cut to the
+// fragments around the matching words
+var (
+	todoTitleHeadlineOptions   = "HighlightAll=true, StartSel=<mark>, StopSel=</mark>"
+	todoSnippetHeadlineOptions = "StartSel=<mark>, StopSel=</mark>, MaxWords=25, MinWords=10, MaxFragments=2, FragmentDelimiter=\" … \""
+)
+
+// todoSearchRow is a row of the search query
+type todoSearchRow struct {
+	ID                 uint
+	Title              string
+	Description        string
+	IsCompleted        bool      `gorm:"column:isCompleted"`
+	CreatedAt          time.Time `gorm:"column:createdAt"`
+	UpdatedAt          time.Time `gorm:"column:updatedAt"`
+	Rank               float64
+	TitleHighlight     string `gorm:"column:titleHighlight"`
+	DescriptionSnippet string `gorm:"column:descriptionSnippet"`
+	NoteID             *uint  `gorm:"column:noteId"`
+	NoteSnippet        string `gorm:"column:noteSnippet"`
+}
+
+// SearchTodoItems finds the user's todo items whose title, description or notes match the search,
+// most relevant first. The ranks of an item and of its best matching note are added up.
+func (r *TodoRepository) SearchTodoItems(ctx context.Context, searchTodoItemsDto dtos.SearchTodoItemsDto) (dtos.StructuredResponse, error) {
+	_, userID, err := r.ownedItems(ctx)
+	if err != nil {
+		return unauthenticatedResponse(), nil
+	}
+
+	search := strings.TrimSpace(searchTodoItemsDto.Query)
+	if search == "" || utf8.RuneCountInString(search) > maxTodoSearchLength {
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusBadRequest,
+			Message: fmt.Sprintf("q is required and must be at most %d characters", maxTodoSearchLength),
+			Payload: nil,
+		}, nil
+	}
+
+	tsquery, args, terms := todoSearchQuery(search)
+	if terms == 0 || terms > maxTodoSearchTerms {
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusBadRequest,
+			Message: fmt.Sprintf("q must contain between 1 and %d words or phrases", maxTodoSearchTerms),
+			Payload: nil,
+		}, nil
+	}
+
+	page, pageSize := normalizePage(searchTodoItemsDto.Page, searchTodoItemsDto.PageSize)
+
+	// Items matching themselves or through one of their notes, with the best matching note
+	matches := fmt.Sprintf(`WITH search_query AS (SELECT %s AS query),
+		note_matches AS (
+			SELECT DISTINCT ON (n."todoItemId") n."todoItemId", n.id AS "noteId", ts_rank(n."searchVector", search_query.query) AS rank
+			FROM "TodoNotes" n
+			JOIN "TodoItems" i ON i.id = n."todoItemId"
+			CROSS JOIN search_query
+			WHERE i.user_id = ? AND n."searchVector" @@ search_query.query
+			ORDER BY n."todoItemId", rank DESC, n.id
+		),
+		matches AS (
+			SELECT i.id, ts_rank(i."searchVector", search_query.query) + COALESCE(note_matches.rank, 0) AS rank, note_matches."noteId"
+			FROM "TodoItems" i
+			CROSS JOIN search_query
+			LEFT JOIN note_matches ON note_matches."todoItemId" = i.id
+			WHERE i.user_id = ? AND (i."searchVector" @@ search_query.query OR note_matches."noteId" IS NOT NULL)
+		)`, tsquery)
+	args = append(args, userID, userID)
+
+	var total int64
+	if err := r.DB.WithContext(ctx).Raw(matches+` SELECT COUNT(*) FROM matches`, args...).Scan(&total).Error; err != nil {
+		r.Logger.Error("Failed to count todo search results", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to search todo items",
+			Payload: nil,
+		}, err
+	}
+
+	// Highlights are only computed for the page, ts_headline is slow
+	var rows []todoSearchRow
+	if err := r.DB.WithContext(ctx).Raw(matches+`,
+		page AS (
+			SELECT * FROM matches ORDER BY rank DESC, id DESC LIMIT ? OFFSET ?
+		)
+		SELECT i.id, i.title, COALESCE(i.description, '') AS description, i."isCompleted", i."createdAt", i."updatedAt", page.rank,
+			ts_headline(?::regconfig, i.title, search_query.query, ?) AS "titleHighlight",
+			CASE WHEN to_tsvector(?::regconfig, COALESCE(i.description, '')) @@ search_query.query
+				THEN ts_headline(?::regconfig, i.description, search_query.query, ?) ELSE '' END AS "descriptionSnippet",
+			page."noteId",
+			COALESCE(ts_headline(?::regconfig, n.note, search_query.query, ?), '') AS "noteSnippet"
+		FROM page
+		JOIN "TodoItems" i ON i.id = page.id
+		CROSS JOIN search_query
+		LEFT JOIN "TodoNotes" n ON n.id = page."noteId"
+		ORDER BY page.rank DESC, page.id DESC`,
+		append(args,
+			pageSize, (page-1)*pageSize,
+			models.TodoSearchConfig, todoTitleHeadlineOptions,
+			models.TodoSearchConfig,
+			models.TodoSearchConfig, todoSnippetHeadlineOptions,
+			models.TodoSearchConfig, todoSnippetHeadlineOptions,
+		)...).Scan(&rows).Error; err != nil {
+		r.Logger.Error("Failed to search todo items", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to search todo items",
+			Payload: nil,
+		}, err
+	}
+
+	results := make([]dtos.TodoSearchResultDto, 0, len(rows))
+	for _, row := range rows {
+		results = append(results, dtos.TodoSearchResultDto{
+			ID:                 row.ID,
+			Title:              row.Title,
+			Description:        row.Description,
+			IsCompleted:        row.IsCompleted,
+			CreatedAt:          row.CreatedAt,
+			UpdatedAt:          row.UpdatedAt,
+			Rank:               row.Rank,
+			TitleHighlight:     row.TitleHighlight,
+			DescriptionSnippet: row.DescriptionSnippet,
+			NoteID:             row.NoteID,
+			NoteSnippet:        row.NoteSnippet,
+		})
+	}
+
+	return dtos.StructuredResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Todo items found successfully",
+		Payload: dtos.TodoSearchResultsDto{
+			Results:  results,
+			Page:     page,
+			PageSize: pageSize,
+			Total:    total,
+		},
+	}, nil
+}
+
+// todoSearchQuery turns search text into a tsquery expression and its arguments. Every word
+// must match, a "quoted phrase" must match as consecutive words, and a word ending in * matches
+// every word starting with it. The text only ever reaches Postgres as query arguments.
+// The number of terms is returned with the expression.
+func todoSearchQuery(search string) (string, []interface{}, int) {
+	var parts []string
+	var args []interface{}
+
+	isWordChar := func(char rune) bool {
+		return unicode.IsLetter(char) || unicode.IsDigit(char)
+	}
+
+	addTerm := func(term string, phrase bool) {
+		// Punctuation alone would only add an empty query
+		if !strings.ContainsFunc(term, isWordChar) {
+			return
+		}
+
+		switch {
+		case phrase:
+			parts = append(parts, "phraseto_tsquery(?::regconfig, ?)")
+			args = append(args, models.TodoSearchConfig, term)
+		case strings.HasSuffix(term, "*"):
+			// to_tsquery parses its own syntax, so the prefix is reduced to letters and digits
+			prefix := strings.Map(func(char rune) rune {
+				if isWordChar(char) {
+					return unicode.ToLower(char)
+				}
+				return -1
+			}, term)
+			parts = append(parts, "to_tsquery(?::regconfig, ?)")
+			args = append(args, models.TodoSearchConfig, prefix+":*")
+		default:
+			parts = append(parts, "plainto_tsquery(?::regconfig, ?)")
+			args = append(args, models.TodoSearchConfig, term)
+		}
+	}
+
+	for rest := search; rest != ""; {
+		rest = strings.TrimLeftFunc(rest, unicode.IsSpace)
+		if rest == "" {
+			break
+		}
+
+		if strings.HasPrefix(rest, `"`) {
+			// An unclosed quote runs to the end of the text
+			phrase, after, _ := strings.Cut(rest[1:], `"`)
+			addTerm(phrase, true)
+			rest = after
+			continue
+		}
+
+		end := strings.IndexFunc(rest, unicode.IsSpace)
+		if end < 0 {
+			end = len(rest)
+		}
+		addTerm(rest[:end], false)
+		rest = rest[end:]
+	}
+
+	return "(" + strings.Join(parts, " && ") + ")", args, len(parts)
+}
@@ -0,0 +1,118 @@
+package repositories
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"todo-api/internal/dtos"
+	"todo-api/internal/models"
+)
+
+func TestTodoSearchQuery(t *testing.T) {
+	tests := []struct {
+		search string
+		parts  []string
+		values []string
+	}{
+		{"milk", []string{"plainto_tsquery"}, []string{"milk"}},
+		{`buy "fresh milk"`, []string{"plainto_tsquery", "phraseto_tsquery"}, []string{"buy", "fresh milk"}},
+		{"gro* Caf'é*", []string{"to_tsquery", "to_tsquery"}, []string{"gro:*", "café:*"}},
+		{`"unclosed phrase`, []string{"phraseto_tsquery"}, []string{"unclosed phrase"}},
+		{`& ! :* "" milk`, []string{"plainto_tsquery"}, []string{"milk"}},
+		{"* !", nil, nil},
+	}
+
+	for _, test := range tests {
+		expression, args, terms := todoSearchQuery(test.search)
+
+		if terms != len(test.parts) {
+			t.Errorf("%q: got %d terms, want %d", test.search, terms, len(test.parts))
+			continue
+		}
+		for i, part := range test.parts {
+			if strings.Count(expression, part+"(") < 1 {
+				t.Errorf("%q: expression %s does not use %s", test.search, expression, part)
+			}
+			if args[2*i] != models.TodoSearchConfig || args[2*i+1] != test.values[i] {
+				t.Errorf("%q: term %d has arguments %v %v, want %q", test.search, i, args[2*i], args[2*i+1], test.values[i])
+			}
+		}
+	}
+}
+
+func TestTodoRepositorySearch(t *testing.T) {
+	repo := newTestTodoRepository(t)
+	alice := createTestUser(t, repo.DB, "alice")
+	bob := createTestUser(t, repo.DB, "bob")
+
+	groceries := createTestTodoItem(t, repo, alice, "Buy groceries")
+	repo.PatchTodoItem(alice, dtos.PatchTodoItemDto{ID: groceries.ID, Description: ptr("Fresh milk, eggs and bread")})
+	party := createTestTodoItem(t, repo, alice, "Plan the party")
+	repo.CreateTodoNote(alice, dtos.CreateTodoNoteDto{TodoItemID: party.ID, Note: "Ask Sam to bring milk for the cake"})
+	createTestTodoItem(t, repo, alice, "Renew passport")
+	createTestTodoItem(t, repo, bob, "Walk the dog")
+
+	search := func(q string) dtos.TodoSearchResultsDto {
+		t.Helper()
+		response, err := repo.SearchTodoItems(alice, dtos.SearchTodoItemsDto{Query: q})
+		if err != nil || response.Status != http.StatusOK {
+			t.Fatalf("SearchTodoItems(%q): %d %s %v", q, response.Status, response.Message, err)
+		}
+		return response.Payload.(dtos.TodoSearchResultsDto)
+	}
+
+	t.Run("matches descriptions and notes, best match first", func(t *testing.T) {
+		results := search("milk")
+		if results.Total != 2 || len(results.Results) != 2 {
+			t.Fatalf("got %d results, want 2: %+v", results.Total, results.Results)
+		}
+		if results.Results[0].ID != groceries.ID {
+			t.Errorf("the description match should rank above the note match: %+v", results.Results)
+		}
+		if !strings.Contains(results.Results[0].DescriptionSnippet, "<mark>milk</mark>") {
+			t.Errorf("description snippet %q is not highlighted", results.Results[0].DescriptionSnippet)
+		}
+		if results.Results[1].NoteID == nil || !strings.Contains(results.Results[1].NoteSnippet, "<mark>milk</mark>") {
+			t.Errorf("note snippet %q is not highlighted", results.Results[1].NoteSnippet)
+		}
+	})
+
+	t.Run("stems words", func(t *testing.T) {
+		results := search("buying")
+		if len(results.Results) != 1 || results.Results[0].TitleHighlight != "<mark>Buy</mark> groceries" {
+			t.Errorf("got %+v", results.Results)
+		}
+	})
+
+	t.Run("prefix", func(t *testing.T) {
+		if results := search("pass*"); len(results.Results) != 1 || results.Results[0].Title != "Renew passport" {
+			t.Errorf("got %+v", results.Results)
+		}
+	})
+
+	t.Run("phrase", func(t *testing.T) {
+		if results := search(`"fresh milk"`); len(results.Results) != 1 || results.Results[0].ID != groceries.ID {
+			t.Errorf("got %+v", results.Results)
+		}
+		if results := search(`"milk fresh"`); len(results.Results) != 0 {
+			t.Errorf("words in the wrong order matched: %+v", results.Results)
+		}
+	})
+
+	t.Run("other users' items are not found", func(t *testing.T) {
+		for _, result := range search("dog").Results {
+			t.Errorf("found %+v", result)
+		}
+	})
+
+	t.Run("empty search", func(t *testing.T) {
+		response, _ := repo.SearchTodoItems(alice, dtos.SearchTodoItemsDto{Query: "  "})
+		if response.Status != http.StatusBadRequest {
+			t.Errorf("got %d, want %d", response.Status, http.StatusBadRequest)
+		}
+	})
+}
+
+func ptr[T any](value T) *T {
+	return &value
+}
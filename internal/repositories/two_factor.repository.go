@@ -0,0 +1,480 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+	"todo-api/database"
+	"todo-api/internal/dtos"
+	"todo-api/internal/models"
+	"todo-api/internal/utils"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	totpIssuer        = "todo-api"
+	recoveryCodeCount = 10
+)
+
+type TwoFactorRepository struct {
+	DB     *gorm.DB
+	Logger *zap.Logger
+	auth   *AuthRepository
+}
+
+func NewTwoFactorRepository(logger *zap.Logger) *TwoFactorRepository {
+	return &TwoFactorRepository{
+		DB:     database.GetDB(),
+		Logger: logger,
+		auth:   NewAuthRepository(logger),
+	}
+}
+
+// Enroll generates a new TOTP secret for the user. Two-factor authentication only becomes
+// active once a code generated from it is confirmed.
+func (r *TwoFactorRepository) Enroll(ctx context.Context, userID uint) (dtos.StructuredResponse, error) {
+	var user models.User
+
+	if err := r.DB.First(&user, userID).Error; err != nil {
+		r.Logger.Error("Failed to find user", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to start two-factor enrollment",
+			Payload: nil,
+		}, err
+	}
+
+	if user.TwoFactorEnabledAt != nil {
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusConflict,
+			Message: "Two-factor authentication is already enabled",
+			Payload: nil,
+		}, nil
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		r.Logger.Error("Failed to generate TOTP secret", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to start two-factor enrollment",
+			Payload: nil,
+		}, err
+	}
+
+	encryptedSecret, err := utils.EncryptSecret(secret)
+	if err != nil {
+		r.Logger.Error("Failed to encrypt TOTP secret", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to start two-factor enrollment",
+			Payload: nil,
+		}, err
+	}
+
+	if err := r.DB.Model(&user).Updates(map[string]interface{}{
+		"totpSecret":       encryptedSecret,
+		"totpLastUsedStep": 0,
+	}).Error; err != nil {
+		r.Logger.Error("Failed to store TOTP secret", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to start two-factor enrollment",
+			Payload: nil,
+		}, err
+	}
+
+	return dtos.StructuredResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Scan the code with your authenticator app and confirm with a generated code",
+		Payload: dtos.TwoFactorEnrollmentDto{
+			Secret:     secret,
+			OTPAuthURI: utils.TOTPAuthURI(totpIssuer, user.Email, secret),
+		},
+	}, nil
+}
+
+// ConfirmEnrollment enables two-factor authentication once the user proves their app
+// produces valid codes, and returns the recovery codes. Wrong codes count as failed logins,
+// otherwise a stolen session could guess codes for a secret it never saw.
+func (r *TwoFactorRepository) ConfirmEnrollment(ctx context.Context, codeDto dtos.TwoFactorCodeDto) (dtos.StructuredResponse, error) {
+	var user models.User
+
+	if err := r.DB.First(&user, codeDto.UserID).Error; err != nil {
+		r.Logger.Error("Failed to find user", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to confirm two-factor enrollment",
+			Payload: nil,
+		}, err
+	}
+
+	if user.TwoFactorEnabledAt != nil {
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusConflict,
+			Message: "Two-factor authentication is already enabled",
+			Payload: nil,
+		}, nil
+	}
+
+	if user.TOTPSecret == "" {
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusBadRequest,
+			Message: "Start the enrollment first",
+			Payload: nil,
+		}, nil
+	}
+
+	retryAfter, err := r.auth.loginAttempts.RetryAfter(ctx, user.Email, codeDto.IPAddress)
+	if err != nil {
+		r.Logger.Error("Failed to check login attempts", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to confirm two-factor enrollment",
+			Payload: nil,
+		}, err
+	}
+	if retryAfter > 0 {
+		return tooManyAttemptsResponse(retryAfter), nil
+	}
+
+	valid, err := r.verifyTOTP(r.DB, user, codeDto.Code)
+	if err != nil {
+		r.Logger.Error("Failed to verify TOTP code", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to confirm two-factor enrollment",
+			Payload: nil,
+		}, err
+	}
+
+	if !valid {
+		if _, err := r.auth.loginAttempts.RecordFailure(ctx, user.Email, codeDto.IPAddress); err != nil {
+			r.Logger.Error("Failed to record login attempt", zap.Error(err))
+		}
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusBadRequest,
+			Message: "Invalid code",
+			Payload: nil,
+		}, nil
+	}
+
+	if err := r.auth.loginAttempts.RecordSuccess(ctx, user.Email); err != nil {
+		r.Logger.Warn("Failed to reset login attempts", zap.Error(err))
+	}
+
+	var recoveryCodes []string
+
+	err = r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Update("twoFactorEnabledAt", time.Now()).Error; err != nil {
+			return err
+		}
+
+		var err error
+		recoveryCodes, err = r.replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		r.Logger.Error("Failed to enable two-factor authentication", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to confirm two-factor enrollment",
+			Payload: nil,
+		}, err
+	}
+
+	r.auth.securityEvents.Record(ctx, models.SecurityEvent{
+		UserID: user.ID,
+		Type:   models.SecurityEventTwoFactorEnabled,
+	})
+
+	return dtos.StructuredResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Two-factor authentication enabled, store the recovery codes somewhere safe",
+		Payload: dtos.RecoveryCodesDto{RecoveryCodes: recoveryCodes},
+	}, nil
+}
+
+// Disable turns two-factor authentication off. Both the password and a second factor are
+// required so a stolen session alone cannot remove the protection.
+func (r *TwoFactorRepository) Disable(ctx context.Context, disableDto dtos.DisableTwoFactorDto) (dtos.StructuredResponse, error) {
+	var user models.User
+
+	if err := r.DB.First(&user, disableDto.UserID).Error; err != nil {
+		r.Logger.Error("Failed to find user", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to disable two-factor authentication",
+			Payload: nil,
+		}, err
+	}
+
+	if user.TwoFactorEnabledAt == nil {
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusBadRequest,
+			Message: "Two-factor authentication is not enabled",
+			Payload: nil,
+		}, nil
+	}
+
+	if matches, _, err := r.auth.passwords.Verify(disableDto.Password, user.PasswordHash); err != nil || !matches {
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusUnauthorized,
+			Message: "Invalid password or code",
+			Payload: nil,
+		}, nil
+	}
+
+	valid, err := r.verifySecondFactor(r.DB, user, disableDto.Code)
+	if err != nil {
+		r.Logger.Error("Failed to verify second factor", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to disable two-factor authentication",
+			Payload: nil,
+		}, err
+	}
+
+	if !valid {
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusUnauthorized,
+			Message: "Invalid password or code",
+			Payload: nil,
+		}, nil
+	}
+
+	err = r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Updates(map[string]interface{}{
+			"totpSecret":         "",
+			"twoFactorEnabledAt": nil,
+			"totpLastUsedStep":   0,
+		}).Error; err != nil {
+			return err
+		}
+
+		return tx.Where(`"userId" = ?`, user.ID).Delete(&models.RecoveryCode{}).Error
+	})
+	if err != nil {
+		r.Logger.Error("Failed to disable two-factor authentication", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to disable two-factor authentication",
+			Payload: nil,
+		}, err
+	}
+
+	r.auth.securityEvents.Record(ctx, models.SecurityEvent{
+		UserID: user.ID,
+		Type:   models.SecurityEventTwoFactorDisabled,
+	})
+
+	return dtos.StructuredResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Two-factor authentication disabled",
+		Payload: nil,
+	}, nil
+}
+
+// CompleteLogin exchanges the challenge from the first login step plus a TOTP or recovery
+// code for a token pair. Wrong codes count as failed logins, so guessing is throttled.
+func (r *TwoFactorRepository) CompleteLogin(ctx context.Context, loginDto dtos.TwoFactorLoginDto) (dtos.StructuredResponse, error) {
+	invalidResponse := dtos.StructuredResponse{
+		Success: false,
+		Status:  http.StatusUnauthorized,
+		Message: "Invalid or expired challenge or code",
+		Payload: nil,
+	}
+
+	claims, err := utils.ValidateActionToken(loginDto.ChallengeToken, utils.PurposeTwoFactorChallenge)
+	if err != nil {
+		return invalidResponse, nil
+	}
+
+	retryAfter, err := r.auth.loginAttempts.RetryAfter(ctx, claims.Email, loginDto.IPAddress)
+	if err != nil {
+		r.Logger.Error("Failed to check login attempts", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to login",
+			Payload: nil,
+		}, err
+	}
+	if retryAfter > 0 {
+		return tooManyAttemptsResponse(retryAfter), nil
+	}
+
+	var user models.User
+
+	if err := r.DB.First(&user, claims.UserID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return invalidResponse, nil
+		}
+		r.Logger.Error("Failed to find user", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to login",
+			Payload: nil,
+		}, err
+	}
+
+	if user.TwoFactorEnabledAt == nil || user.Email != claims.Email {
+		return invalidResponse, nil
+	}
+
+	valid, err := r.verifySecondFactor(r.DB, user, loginDto.Code)
+	if err != nil {
+		r.Logger.Error("Failed to verify second factor", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to login",
+			Payload: nil,
+		}, err
+	}
+
+	if !valid {
+		if _, err := r.auth.loginAttempts.RecordFailure(ctx, user.Email, loginDto.IPAddress); err != nil {
+			r.Logger.Error("Failed to record login attempt", zap.Error(err))
+		}
+		r.auth.recordLogin(ctx, user, models.SecurityEventLoginFailed, "Wrong two-factor code", loginDto.UserAgent, loginDto.IPAddress)
+		return invalidResponse, nil
+	}
+
+	if err := r.auth.loginAttempts.RecordSuccess(ctx, user.Email); err != nil {
+		r.Logger.Warn("Failed to reset login attempts", zap.Error(err))
+	}
+
+	// The account may have been disabled while the challenge was pending
+	if user.DisabledAt != nil {
+		r.auth.recordLogin(ctx, user, models.SecurityEventLoginFailed, "Account disabled", loginDto.UserAgent, loginDto.IPAddress)
+		return accountDisabledResponse(), nil
+	}
+
+	tokens, err := r.auth.startSession(r.DB, user, loginDto.UserAgent, loginDto.IPAddress)
+	if err != nil {
+		r.Logger.Error("Failed to issue tokens", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to login",
+			Payload: nil,
+		}, err
+	}
+
+	r.auth.recordLogin(ctx, user, models.SecurityEventLoginSucceeded, "Two-factor authentication", loginDto.UserAgent, loginDto.IPAddress)
+
+	return dtos.StructuredResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Login successful",
+		Payload: tokens,
+	}, nil
+}
+
+// verifySecondFactor accepts either a TOTP code or an unused recovery code
+func (r *TwoFactorRepository) verifySecondFactor(db *gorm.DB, user models.User, code string) (bool, error) {
+	code = strings.TrimSpace(code)
+
+	if len(code) == 6 && strings.Trim(code, "0123456789") == "" {
+		return r.verifyTOTP(db, user, code)
+	}
+
+	return r.useRecoveryCode(db, user.ID, code)
+}
+
+// verifyTOTP checks a TOTP code and records its time step so the same code cannot be replayed
+func (r *TwoFactorRepository) verifyTOTP(db *gorm.DB, user models.User, code string) (bool, error) {
+	secret, err := utils.DecryptSecret(user.TOTPSecret)
+	if err != nil {
+		return false, err
+	}
+
+	step, valid := utils.ValidateTOTP(secret, code, time.Now())
+	if !valid || step <= user.TOTPLastUsedStep {
+		return false, nil
+	}
+
+	// The condition makes concurrent attempts with the same code race for a single success
+	result := db.Model(&models.User{}).
+		Where(`id = ? AND "totpLastUsedStep" < ?`, user.ID, step).
+		Update("totpLastUsedStep", step)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+func (r *TwoFactorRepository) useRecoveryCode(db *gorm.DB, userID uint, code string) (bool, error) {
+	result := db.Model(&models.RecoveryCode{}).
+		Where(`"userId" = ? AND "codeHash" = ? AND "usedAt" IS NULL`, userID, utils.HashToken(normalizeRecoveryCode(code))).
+		Update("usedAt", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// replaceRecoveryCodes deletes the user's recovery codes and stores a new set
+func (r *TwoFactorRepository) replaceRecoveryCodes(db *gorm.DB, userID uint) ([]string, error) {
+	if err := db.Where(`"userId" = ?`, userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	records := make([]models.RecoveryCode, 0, recoveryCodeCount)
+
+	for i := 0; i < recoveryCodeCount; i++ {
+		secret, err := utils.GenerateTOTPSecret()
+		if err != nil {
+			return nil, err
+		}
+
+		// Ten base32 characters split in two groups, e.g. k7m2p-x9q4r
+		code := strings.ToLower(secret[:5] + "-" + secret[5:10])
+		codes = append(codes, code)
+		records = append(records, models.RecoveryCode{
+			UserID:   userID,
+			CodeHash: utils.HashToken(normalizeRecoveryCode(code)),
+		})
+	}
+
+	if err := db.Create(&records).Error; err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// normalizeRecoveryCode makes recovery codes case and dash insensitive
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+}
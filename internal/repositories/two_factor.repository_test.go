@@ -0,0 +1,152 @@
+package repositories
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+	"todo-api/internal/dtos"
+	"todo-api/internal/models"
+	"todo-api/internal/utils"
+
+	"go.uber.org/zap"
+)
+
+// testTOTPCode computes the code an authenticator app shows for the secret right now (RFC 6238)
+func testTOTPCode(t *testing.T, secret string) string {
+	t.Helper()
+
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil {
+		t.Fatalf("invalid TOTP secret: %v", err)
+	}
+
+	counter := make([]byte, 8)
+	binary.BigEndian.PutUint64(counter, uint64(time.Now().Unix()/30))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter)
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+
+	return fmt.Sprintf("%06d", (binary.BigEndian.Uint32(sum[offset:offset+4])&0x7fffffff)%1000000)
+}
+
+func TestNormalizeRecoveryCode(t *testing.T) {
+	for _, code := range []string{"k7m2p-x9q4r", "K7M2P-X9Q4R", " k7m2px9q4r "} {
+		if got := normalizeRecoveryCode(code); got != "k7m2px9q4r" {
+			t.Errorf("normalizeRecoveryCode(%q) = %q", code, got)
+		}
+	}
+}
+
+func TestTwoFactorRepositoryTOTPReplay(t *testing.T) {
+	repo := &TwoFactorRepository{DB: openTestDB(t), Logger: zap.NewNop()}
+	user := createTestAccount(t, repo.DB, "alice")
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("GenerateTOTPSecret failed: %v", err)
+	}
+	encryptedSecret, err := utils.EncryptSecret(secret)
+	if err != nil {
+		t.Fatalf("EncryptSecret failed: %v", err)
+	}
+	if err := repo.DB.Model(&user).Updates(map[string]interface{}{"totpSecret": encryptedSecret, "totpLastUsedStep": 0}).Error; err != nil {
+		t.Fatalf("failed to store TOTP secret: %v", err)
+	}
+
+	reload := func() models.User {
+		var stored models.User
+		if err := repo.DB.First(&stored, user.ID).Error; err != nil {
+			t.Fatalf("failed to reload user: %v", err)
+		}
+		return stored
+	}
+	stale := reload()
+	code := testTOTPCode(t, secret)
+
+	if valid, err := repo.verifySecondFactor(repo.DB, reload(), code); err != nil || !valid {
+		t.Fatalf("first use of the code = %v, %v, want valid", valid, err)
+	}
+	if valid, err := repo.verifySecondFactor(repo.DB, reload(), code); err != nil || valid {
+		t.Errorf("replayed code = %v, %v, want invalid", valid, err)
+	}
+
+	// A concurrent request that loaded the user before the first use loses the race
+	if valid, err := repo.verifySecondFactor(repo.DB, stale, code); err != nil || valid {
+		t.Errorf("replayed code with a stale user = %v, %v, want invalid", valid, err)
+	}
+}
+
+func TestTwoFactorRepositoryRecoveryCodes(t *testing.T) {
+	repo := &TwoFactorRepository{DB: openTestDB(t), Logger: zap.NewNop()}
+	user := createTestAccount(t, repo.DB, "alice")
+
+	codes, err := repo.replaceRecoveryCodes(repo.DB, user.ID)
+	if err != nil {
+		t.Fatalf("replaceRecoveryCodes failed: %v", err)
+	}
+	if len(codes) != recoveryCodeCount {
+		t.Fatalf("got %d recovery codes, want %d", len(codes), recoveryCodeCount)
+	}
+
+	// Codes are typed back without the dash and in any case
+	typed := strings.ToUpper(strings.ReplaceAll(codes[0], "-", ""))
+	if valid, err := repo.verifySecondFactor(repo.DB, user, typed); err != nil || !valid {
+		t.Fatalf("first use of a recovery code = %v, %v, want valid", valid, err)
+	}
+	if valid, err := repo.verifySecondFactor(repo.DB, user, codes[0]); err != nil || valid {
+		t.Errorf("second use of a recovery code = %v, %v, want invalid", valid, err)
+	}
+
+	other := createTestAccount(t, repo.DB, "bob")
+	if valid, err := repo.verifySecondFactor(repo.DB, other, codes[1]); err != nil || valid {
+		t.Errorf("recovery code of another user = %v, %v, want invalid", valid, err)
+	}
+
+	// A new set replaces the old one
+	if _, err := repo.replaceRecoveryCodes(repo.DB, user.ID); err != nil {
+		t.Fatalf("replaceRecoveryCodes failed: %v", err)
+	}
+	if valid, err := repo.verifySecondFactor(repo.DB, user, codes[1]); err != nil || valid {
+		t.Errorf("recovery code of the old set = %v, %v, want invalid", valid, err)
+	}
+}
+
+func TestTwoFactorRepositoryConfirmEnrollmentThrottled(t *testing.T) {
+	db := openTestDB(t)
+	repo := &TwoFactorRepository{
+		DB:     db,
+		Logger: zap.NewNop(),
+		auth: &AuthRepository{
+			DB:             db,
+			Logger:         zap.NewNop(),
+			loginAttempts:  newTestLoginAttemptRepository(&memoryLoginAttemptStore{attempts: map[string]*memoryLoginAttempt{}}),
+			securityEvents: &SecurityEventRepository{DB: db, Logger: zap.NewNop()},
+		},
+	}
+	user := createTestAccount(t, db, "alice")
+	ctx := context.Background()
+
+	response, err := repo.Enroll(ctx, user.ID)
+	if err != nil || response.Status != http.StatusOK {
+		t.Fatalf("Enroll = %d %s %v", response.Status, response.Message, err)
+	}
+	secret := response.Payload.(dtos.TwoFactorEnrollmentDto).Secret
+
+	// Every wrong code is a failed login, the next attempt has to wait for the progressive delay
+	response, err = repo.ConfirmEnrollment(ctx, dtos.TwoFactorCodeDto{Code: "000000", UserID: user.ID, IPAddress: "192.0.2.1"})
+	if err != nil || response.Status != http.StatusBadRequest {
+		t.Fatalf("ConfirmEnrollment with a wrong code = %d %s %v, want 400", response.Status, response.Message, err)
+	}
+	response, err = repo.ConfirmEnrollment(ctx, dtos.TwoFactorCodeDto{Code: testTOTPCode(t, secret), UserID: user.ID, IPAddress: "192.0.2.1"})
+	if err != nil || response.Status != http.StatusTooManyRequests {
+		t.Errorf("ConfirmEnrollment right after a wrong code = %d %s %v, want 429", response.Status, response.Message, err)
+	}
+}
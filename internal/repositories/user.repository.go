@@ -0,0 +1,488 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+	"todo-api/config"
+	"todo-api/database"
+	"todo-api/internal/dtos"
+	"todo-api/internal/models"
+	"todo-api/internal/password"
+	"todo-api/internal/utils"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// Limits of the profile fields, they match the column sizes of models.User
+const (
+	maxNameLength      = 100
+	maxBioLength       = 500
+	maxAvatarURLLength = 500
+	maxTimezoneLength  = 64
+)
+
+// errLastAdministrator is returned inside the deletion transaction when the account is the only administrator
+var errLastAdministrator = errors.New("cannot delete the last administrator")
+
+type UserRepository struct {
+	DB                *gorm.DB
+	Logger            *zap.Logger
+	revocations       *RevocationRepository
+	emailVerification *EmailVerificationRepository
+	securityEvents    *SecurityEventRepository
+	passwords         *password.Manager
+}
+
+func NewUserRepository(logger *zap.Logger) *UserRepository {
+	return &UserRepository{
+		DB:                database.GetDB(),
+		Logger:            logger,
+		revocations:       NewRevocationRepository(logger),
+		emailVerification: NewEmailVerificationRepository(logger),
+		securityEvents:    NewSecurityEventRepository(logger),
+		passwords:         password.New(config.GetConfig().PasswordHashing),
+	}
+}
+
+// GetProfile returns the profile of the user
+func (r *UserRepository) GetProfile(ctx context.Context, userID uint) (dtos.StructuredResponse, error) {
+	var user models.User
+
+	if err := r.DB.Preload("Roles").First(&user, userID).Error; err != nil {
+		return r.userLookupFailed(err, "Failed to retrieve profile")
+	}
+
+	return dtos.StructuredResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Profile retrieved successfully",
+		Payload: toUserDto(user),
+	}, nil
+}
+
+// UpdateProfile changes the name, bio, avatar and time zone of the user, fields left out of the request are kept
+func (r *UserRepository) UpdateProfile(ctx context.Context, updateProfileDto dtos.UpdateProfileDto) (dtos.StructuredResponse, error) {
+	updates := map[string]interface{}{}
+
+	if updateProfileDto.Name != nil {
+		name := strings.TrimSpace(*updateProfileDto.Name)
+		if name == "" || utf8.RuneCountInString(name) > maxNameLength {
+			return dtos.StructuredResponse{
+				Success: false,
+				Status:  http.StatusBadRequest,
+				Message: "Name must be between 1 and 100 characters",
+				Payload: nil,
+			}, nil
+		}
+		updates["name"] = name
+	}
+
+	if updateProfileDto.Bio != nil {
+		bio := strings.TrimSpace(*updateProfileDto.Bio)
+		if utf8.RuneCountInString(bio) > maxBioLength {
+			return dtos.StructuredResponse{
+				Success: false,
+				Status:  http.StatusBadRequest,
+				Message: "Bio must be at most 500 characters",
+				Payload: nil,
+			}, nil
+		}
+		updates["bio"] = bio
+	}
+
+	if updateProfileDto.AvatarURL != nil {
+		avatarURL := strings.TrimSpace(*updateProfileDto.AvatarURL)
+		if avatarURL != "" && !isWebURL(avatarURL) {
+			return dtos.StructuredResponse{
+				Success: false,
+				Status:  http.StatusBadRequest,
+				Message: "Avatar URL must be an http or https URL",
+				Payload: nil,
+			}, nil
+		}
+		if len(avatarURL) > maxAvatarURLLength {
+			return dtos.StructuredResponse{
+				Success: false,
+				Status:  http.StatusBadRequest,
+				Message: "Avatar URL must be at most 500 characters",
+				Payload: nil,
+			}, nil
+		}
+		updates["avatarUrl"] = avatarURL
+	}
+
+	if updateProfileDto.Timezone != nil {
+		timezone := strings.TrimSpace(*updateProfileDto.Timezone)
+		// LoadLocation also accepts "" and "Local", which would mean the server's zone
+		if _, err := time.LoadLocation(timezone); err != nil || timezone == "" || timezone == "Local" || len(timezone) > maxTimezoneLength {
+			return dtos.StructuredResponse{
+				Success: false,
+				Status:  http.StatusBadRequest,
+				Message: "Timezone must be an IANA time zone name such as Europe/Berlin",
+				Payload: nil,
+			}, nil
+		}
+		updates["timezone"] = timezone
+	}
+
+	if len(updates) > 0 {
+		result := r.DB.Model(&models.User{}).Where("id = ?", updateProfileDto.UserID).Updates(updates)
+		if result.Error != nil {
+			r.Logger.Error("Failed to update profile", zap.Error(result.Error))
+			return dtos.StructuredResponse{
+				Success: false,
+				Status:  http.StatusInternalServerError,
+				Message: "Failed to update profile",
+				Payload: nil,
+			}, result.Error
+		}
+	}
+
+	response, err := r.GetProfile(ctx, updateProfileDto.UserID)
+	if err != nil || !response.Success {
+		return response, err
+	}
+
+	response.Message = "Profile updated successfully"
+	return response, nil
+}
+
+// ChangePassword sets a new password after checking the current one. Every other session of
+// the user is logged out, so a stolen session does not survive the change.
+func (r *UserRepository) ChangePassword(ctx context.Context, changePasswordDto dtos.ChangePasswordDto) (dtos.StructuredResponse, error) {
+	var user models.User
+
+	if err := r.DB.First(&user, changePasswordDto.UserID).Error; err != nil {
+		return r.userLookupFailed(err, "Failed to change password")
+	}
+
+	// Accounts created through single sign-on have no password, they set one with the reset flow
+	if matches, _, err := r.passwords.Verify(changePasswordDto.CurrentPassword, user.PasswordHash); err != nil || !matches {
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusUnauthorized,
+			Message: "Current password is incorrect",
+			Payload: nil,
+		}, nil
+	}
+
+	policy, err := password.GetPolicy()
+	if err != nil {
+		r.Logger.Error("Failed to load password policy", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to change password",
+			Payload: nil,
+		}, err
+	}
+
+	if problems := policy.Check(changePasswordDto.NewPassword, user.Email, user.Name); len(problems) > 0 {
+		return passwordPolicyResponse("newPassword", problems), nil
+	}
+
+	hashedPassword, err := r.passwords.Hash(changePasswordDto.NewPassword)
+	if err != nil {
+		r.Logger.Error("Failed to hash password", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to change password",
+			Payload: nil,
+		}, err
+	}
+
+	// Only replace the hash we checked, a concurrent change must not be silently overwritten
+	result := r.DB.Model(&user).Where(`"passwordHash" = ?`, user.PasswordHash).Update("passwordHash", hashedPassword)
+	if result.Error != nil {
+		r.Logger.Error("Failed to change password", zap.Error(result.Error))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to change password",
+			Payload: nil,
+		}, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusConflict,
+			Message: "Password was changed by another request, please try again",
+			Payload: nil,
+		}, nil
+	}
+
+	r.securityEvents.Record(ctx, models.SecurityEvent{
+		UserID: user.ID,
+		Type:   models.SecurityEventPasswordChanged,
+	})
+
+	var sessionIDs []uint
+	if err := r.DB.Model(&models.Session{}).
+		Where(`"userId" = ? AND id <> ? AND "revokedAt" IS NULL`, user.ID, changePasswordDto.SessionID).
+		Pluck("id", &sessionIDs).Error; err != nil {
+		r.Logger.Error("Failed to find sessions", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Password changed but other sessions could not be logged out",
+			Payload: nil,
+		}, err
+	}
+
+	revoked, err := r.revocations.RevokeSessions(ctx, user.ID, sessionIDs)
+	if err != nil {
+		r.Logger.Error("Failed to revoke sessions after password change", zap.Uint("userId", user.ID), zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Password changed but other sessions could not be logged out",
+			Payload: nil,
+		}, err
+	}
+
+	return dtos.StructuredResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Password changed successfully",
+		Payload: map[string]interface{}{
+			"revokedSessions": revoked,
+		},
+	}, nil
+}
+
+// ChangeEmail starts a change of email address. The current address stays in use until the
+// link sent to the new one is opened, see EmailVerificationRepository.ConfirmEmailChange.
+func (r *UserRepository) ChangeEmail(ctx context.Context, changeEmailDto dtos.ChangeEmailDto) (dtos.StructuredResponse, error) {
+	newEmail := utils.NormalizeEmail(changeEmailDto.Email)
+
+	if address, err := mail.ParseAddress(newEmail); err != nil || address.Address != newEmail {
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusBadRequest,
+			Message: "Invalid email address",
+			Payload: nil,
+		}, nil
+	}
+
+	var user models.User
+
+	if err := r.DB.First(&user, changeEmailDto.UserID).Error; err != nil {
+		return r.userLookupFailed(err, "Failed to change email")
+	}
+
+	if matches, _, err := r.passwords.Verify(changeEmailDto.Password, user.PasswordHash); err != nil || !matches {
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusUnauthorized,
+			Message: "Password is incorrect",
+			Payload: nil,
+		}, nil
+	}
+
+	if strings.EqualFold(newEmail, user.Email) {
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusBadRequest,
+			Message: "New email address is the same as the current one",
+			Payload: nil,
+		}, nil
+	}
+
+	var existingCount int64
+	if err := r.DB.Model(&models.User{}).Where("LOWER(email) = LOWER(?)", newEmail).Count(&existingCount).Error; err != nil {
+		r.Logger.Error("Failed to check email availability", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to change email",
+			Payload: nil,
+		}, err
+	}
+
+	if existingCount > 0 {
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusConflict,
+			Message: "Email address is already in use",
+			Payload: nil,
+		}, nil
+	}
+
+	// Links sent for an earlier pending address stop working once it is replaced
+	if err := r.DB.Model(&user).Update("pendingEmail", newEmail).Error; err != nil {
+		r.Logger.Error("Failed to store pending email", zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to change email",
+			Payload: nil,
+		}, err
+	}
+
+	if err := r.emailVerification.SendEmailChangeVerification(user, newEmail); err != nil {
+		r.Logger.Error("Failed to send email change confirmation", zap.Uint("userId", user.ID), zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to change email",
+			Payload: nil,
+		}, err
+	}
+
+	r.securityEvents.Record(ctx, models.SecurityEvent{
+		UserID:  user.ID,
+		Type:    models.SecurityEventEmailChangeRequested,
+		Details: "New address " + newEmail,
+	})
+
+	return dtos.StructuredResponse{
+		Success: true,
+		Status:  http.StatusAccepted,
+		Message: "A confirmation link has been sent to the new email address",
+		Payload: nil,
+	}, nil
+}
+
+// DeleteAccount removes the user together with their todo items and notes. Sessions, tokens and
+// other per-user rows go with the user through their ON DELETE CASCADE foreign keys.
+func (r *UserRepository) DeleteAccount(ctx context.Context, deleteAccountDto dtos.DeleteAccountDto) (dtos.StructuredResponse, error) {
+	var user models.User
+
+	if err := r.DB.Preload("Roles").First(&user, deleteAccountDto.UserID).Error; err != nil {
+		return r.userLookupFailed(err, "Failed to delete account")
+	}
+
+	// Accounts created through single sign-on have no password to confirm with
+	if user.PasswordHash != "" {
+		if matches, _, err := r.passwords.Verify(deleteAccountDto.Password, user.PasswordHash); err != nil || !matches {
+			return dtos.StructuredResponse{
+				Success: false,
+				Status:  http.StatusUnauthorized,
+				Message: "Password is incorrect",
+				Payload: nil,
+			}, nil
+		}
+	}
+
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		if user.HasRole(models.RoleAdmin) {
+			adminCount, err := countAdmins(tx)
+			if err != nil {
+				return err
+			}
+			if adminCount <= 1 {
+				return errLastAdministrator
+			}
+		}
+
+		// Delete explicitly instead of relying on foreign keys, tables created before the
+		// constraints existed do not cascade
+		if err := tx.Where(`"todoItemId" IN (?)`, tx.Model(&models.TodoItem{}).Select("id").Where("user_id = ?", user.ID)).
+			Delete(&models.TodoNote{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.TodoItem{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&user).Association("Roles").Clear(); err != nil {
+			return err
+		}
+
+		return tx.Delete(&user).Error
+	})
+
+	if errors.Is(err, errLastAdministrator) {
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusConflict,
+			Message: "Cannot delete the last administrator",
+			Payload: nil,
+		}, nil
+	}
+
+	if err != nil {
+		r.Logger.Error("Failed to delete account", zap.Uint("userId", user.ID), zap.Error(err))
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to delete account",
+			Payload: nil,
+		}, err
+	}
+
+	// The refresh tokens are gone with the account, the access token of this request is revoked
+	// too. Access tokens of other devices can no longer be refreshed and expire on their own.
+	if claims, err := utils.GetClaimsFromContext(ctx); err == nil && claims.ID != "" {
+		if err := r.revocations.RevokeToken(ctx, claims.ID, claims.UserID, claims.ExpiresAt.Time); err != nil {
+			r.Logger.Warn("Failed to revoke access token of deleted account", zap.Error(err))
+		}
+	}
+
+	// Kept for the retention period like the other events of the account
+	r.securityEvents.Record(ctx, models.SecurityEvent{
+		UserID: user.ID,
+		Type:   models.SecurityEventAccountDeleted,
+	})
+
+	r.Logger.Info("Account deleted", zap.Uint("userId", user.ID))
+
+	return dtos.StructuredResponse{
+		Success: true,
+		Status:  http.StatusOK,
+		Message: "Account deleted successfully",
+		Payload: nil,
+	}, nil
+}
+
+// userLookupFailed turns an error loading the current user into a response, a missing user
+// means the account was deleted while its token was still valid
+func (r *UserRepository) userLookupFailed(err error, message string) (dtos.StructuredResponse, error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return dtos.StructuredResponse{
+			Success: false,
+			Status:  http.StatusNotFound,
+			Message: "User not found",
+			Payload: nil,
+		}, nil
+	}
+
+	r.Logger.Error("Failed to find user", zap.Error(err))
+	return dtos.StructuredResponse{
+		Success: false,
+		Status:  http.StatusInternalServerError,
+		Message: message,
+		Payload: nil,
+	}, err
+}
+
+func toUserDto(user models.User) dtos.UserDto {
+	return dtos.UserDto{
+		ID:               user.ID,
+		Email:            user.Email,
+		Name:             user.Name,
+		Bio:              user.Bio,
+		AvatarURL:        user.AvatarURL,
+		Timezone:         user.Timezone,
+		EmailVerifiedAt:  user.EmailVerifiedAt,
+		PendingEmail:     user.PendingEmail,
+		TwoFactorEnabled: user.TwoFactorEnabledAt != nil,
+		Roles:            user.RoleNames(),
+	}
+}
+
+// isWebURL reports whether the value is an absolute http or https URL
+func isWebURL(value string) bool {
+	parsed, err := url.Parse(value)
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
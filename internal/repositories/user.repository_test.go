@@ -0,0 +1,196 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"todo-api/internal/dtos"
+	"todo-api/internal/models"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func TestIsWebURL(t *testing.T) {
+	tests := map[string]bool{
+		"https://example.com/avatar.png": true,
+		"http://example.com":             true,
+		"javascript:alert(1)":            false,
+		"ftp://example.com/avatar.png":   false,
+		"//example.com/avatar.png":       false,
+		"https://":                       false,
+		"avatar.png":                     false,
+	}
+
+	for value, want := range tests {
+		if got := isWebURL(value); got != want {
+			t.Errorf("isWebURL(%q) = %v, want %v", value, got, want)
+		}
+	}
+}
+
+func TestUserRepositoryUpdateProfileRejectsInput(t *testing.T) {
+	// Invalid fields are refused before anything is written, so no database is needed
+	repo := &UserRepository{Logger: zap.NewNop()}
+	text := func(value string) *string { return &value }
+
+	tests := []struct {
+		name  string
+		input dtos.UpdateProfileDto
+	}{
+		{"blank name", dtos.UpdateProfileDto{Name: text("   ")}},
+		{"long name", dtos.UpdateProfileDto{Name: text(strings.Repeat("n", maxNameLength+1))}},
+		{"long bio", dtos.UpdateProfileDto{Bio: text(strings.Repeat("b", maxBioLength+1))}},
+		{"avatar is not a web URL", dtos.UpdateProfileDto{AvatarURL: text("javascript:alert(1)")}},
+		{"long avatar URL", dtos.UpdateProfileDto{AvatarURL: text("https://example.com/" + strings.Repeat("a", maxAvatarURLLength))}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := repo.UpdateProfile(context.Background(), tt.input)
+			if err != nil {
+				t.Fatalf("UpdateProfile failed: %v", err)
+			}
+			if response.Status != http.StatusBadRequest {
+				t.Errorf("status = %d, want 400", response.Status)
+			}
+		})
+	}
+}
+
+func TestUserRepositoryChangePassword(t *testing.T) {
+	db := openTestDB(t)
+	auth := NewAuthRepository(zap.NewNop())
+	repo := NewUserRepository(zap.NewNop())
+	repo.passwords = testPasswords
+	ctx := context.Background()
+
+	user := createTestAccount(t, db, "alice")
+	setTestPassword(t, db, user, "correct horse battery staple")
+
+	current, err := auth.startSession(db, user, "laptop", "127.0.0.1")
+	if err != nil {
+		t.Fatalf("startSession failed: %v", err)
+	}
+	other, err := auth.startSession(db, user, "phone", "127.0.0.1")
+	if err != nil {
+		t.Fatalf("startSession failed: %v", err)
+	}
+
+	changePassword := func(t *testing.T, currentPassword string) dtos.StructuredResponse {
+		t.Helper()
+
+		response, err := repo.ChangePassword(ctx, dtos.ChangePasswordDto{
+			CurrentPassword: currentPassword,
+			NewPassword:     "a brand new passphrase",
+			UserID:          user.ID,
+			SessionID:       sessionIDOf(t, current),
+		})
+		if err != nil {
+			t.Fatalf("ChangePassword failed: %v", err)
+		}
+		return response
+	}
+
+	if response := changePassword(t, "guessed"); response.Status != http.StatusUnauthorized {
+		t.Fatalf("wrong current password: status = %d, want 401", response.Status)
+	}
+
+	if response := changePassword(t, "correct horse battery staple"); response.Status != http.StatusOK {
+		t.Fatalf("status = %d %s, want 200", response.Status, response.Message)
+	}
+
+	// Only the other session is logged out
+	for _, session := range []struct {
+		tokens  dtos.AuthTokensDto
+		revoked bool
+	}{{current, false}, {other, true}} {
+		var stored models.Session
+		if err := db.First(&stored, sessionIDOf(t, session.tokens)).Error; err != nil {
+			t.Fatalf("failed to load session: %v", err)
+		}
+		if revoked := stored.RevokedAt != nil; revoked != session.revoked {
+			t.Errorf("session %d revoked = %v, want %v", stored.ID, revoked, session.revoked)
+		}
+	}
+
+	var stored models.User
+	if err := db.First(&stored, user.ID).Error; err != nil {
+		t.Fatalf("failed to load user: %v", err)
+	}
+	if matches, _, err := testPasswords.Verify("a brand new passphrase", stored.PasswordHash); err != nil || !matches {
+		t.Errorf("new password does not match: %v, %v", matches, err)
+	}
+}
+
+func TestUserRepositoryDeleteAccount(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewUserRepository(zap.NewNop())
+	repo.passwords = testPasswords
+	ctx := context.Background()
+
+	user := createTestAccount(t, db, "alice")
+	setTestPassword(t, db, user, "correct horse battery staple")
+
+	todoItem := models.TodoItem{Title: "Buy milk", UserID: user.ID}
+	if err := db.Create(&todoItem).Error; err != nil {
+		t.Fatalf("failed to create todo item: %v", err)
+	}
+
+	deleteAccount := func(t *testing.T, plainPassword string) int {
+		t.Helper()
+
+		response, err := repo.DeleteAccount(ctx, dtos.DeleteAccountDto{Password: plainPassword, UserID: user.ID})
+		if err != nil {
+			t.Fatalf("DeleteAccount failed: %v", err)
+		}
+		return response.Status
+	}
+
+	if status := deleteAccount(t, "guessed"); status != http.StatusUnauthorized {
+		t.Fatalf("wrong password: status = %d, want 401", status)
+	}
+
+	if status := deleteAccount(t, "correct horse battery staple"); status != http.StatusOK {
+		t.Fatalf("status = %d, want 200", status)
+	}
+
+	if err := db.First(&models.User{}, user.ID).Error; !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("user still exists: %v", err)
+	}
+	if err := db.First(&models.TodoItem{}, todoItem.ID).Error; !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("todo item still exists: %v", err)
+	}
+}
+
+func TestUserRepositoryDeleteAccountKeepsSecurityEvents(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewUserRepository(zap.NewNop())
+	repo.passwords = testPasswords
+	ctx := context.Background()
+
+	user := createTestAccount(t, db, "erin")
+	setTestPassword(t, db, user, "correct horse battery staple")
+	t.Cleanup(func() {
+		db.Where(`"userId" = ?`, user.ID).Delete(&models.SecurityEvent{})
+	})
+
+	repo.securityEvents.Record(ctx, models.SecurityEvent{UserID: user.ID, Type: models.SecurityEventLoginSucceeded})
+
+	response, err := repo.DeleteAccount(ctx, dtos.DeleteAccountDto{UserID: user.ID, Password: "correct horse battery staple"})
+	if err != nil || response.Status != http.StatusOK {
+		t.Fatalf("DeleteAccount = %d %s, %v", response.Status, response.Message, err)
+	}
+
+	var types []string
+	if err := db.Model(&models.SecurityEvent{}).Where(`"userId" = ?`, user.ID).Order("id").Pluck("type", &types).Error; err != nil {
+		t.Fatalf("failed to load security events: %v", err)
+	}
+	want := []string{models.SecurityEventLoginSucceeded, models.SecurityEventAccountDeleted}
+	if !slices.Equal(types, want) {
+		t.Errorf("security events after deletion = %q, want %q", types, want)
+	}
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"todo-api/internal/dtos"
+	"todo-api/internal/repositories"
+
+	"go.uber.org/zap"
+)
+
+type AdminService struct {
+	logger            *zap.Logger
+	roleRepo          *repositories.RoleRepository
+	userRepo          *repositories.AdminUserRepository
+	securityEventRepo *repositories.SecurityEventRepository
+	inviteCodeRepo    *repositories.InviteCodeRepository
+	oauthClientRepo   *repositories.OAuthClientRepository
+}
+
+func NewAdminService(logger *zap.Logger) *AdminService {
+	return &AdminService{
+		logger:            logger,
+		roleRepo:          repositories.NewRoleRepository(logger),
+		userRepo:          repositories.NewAdminUserRepository(logger),
+		securityEventRepo: repositories.NewSecurityEventRepository(logger),
+		inviteCodeRepo:    repositories.NewInviteCodeRepository(logger),
+		oauthClientRepo:   repositories.NewOAuthClientRepository(logger),
+	}
+}
+
+func (s *AdminService) GetRoles(ctx context.Context) (dtos.StructuredResponse, error) {
+	return s.roleRepo.GetRoles(ctx)
+}
+
+func (s *AdminService) SetUserRoles(ctx context.Context, setUserRolesDto dtos.SetUserRolesDto) (dtos.StructuredResponse, error) {
+	return s.roleRepo.SetUserRoles(ctx, setUserRolesDto)
+}
+
+func (s *AdminService) ListUsers(ctx context.Context, listUsersDto dtos.ListUsersDto) (dtos.StructuredResponse, error) {
+	return s.userRepo.ListUsers(ctx, listUsersDto)
+}
+
+func (s *AdminService) CreateUser(ctx context.Context, createUserDto dtos.CreateUserDto) (dtos.StructuredResponse, error) {
+	return s.userRepo.CreateUser(ctx, createUserDto)
+}
+
+func (s *AdminService) GetUser(ctx context.Context, userID uint) (dtos.StructuredResponse, error) {
+	return s.userRepo.GetUser(ctx, userID)
+}
+
+func (s *AdminService) GetUserSecurityEvents(ctx context.Context, listSecurityEventsDto dtos.ListSecurityEventsDto) (dtos.StructuredResponse, error) {
+	return s.securityEventRepo.ListEvents(ctx, listSecurityEventsDto)
+}
+
+func (s *AdminService) DisableUser(ctx context.Context, actionDto dtos.AdminUserActionDto) (dtos.StructuredResponse, error) {
+	return s.userRepo.DisableUser(ctx, actionDto)
+}
+
+func (s *AdminService) EnableUser(ctx context.Context, actionDto dtos.AdminUserActionDto) (dtos.StructuredResponse, error) {
+	return s.userRepo.EnableUser(ctx, actionDto)
+}
+
+func (s *AdminService) ResetUserPassword(ctx context.Context, actionDto dtos.AdminUserActionDto) (dtos.StructuredResponse, error) {
+	return s.userRepo.ResetPassword(ctx, actionDto)
+}
+
+func (s *AdminService) ForceLogout(ctx context.Context, actionDto dtos.AdminUserActionDto) (dtos.StructuredResponse, error) {
+	return s.userRepo.ForceLogout(ctx, actionDto)
+}
+
+func (s *AdminService) ImpersonateUser(ctx context.Context, actionDto dtos.AdminUserActionDto) (dtos.StructuredResponse, error) {
+	return s.userRepo.Impersonate(ctx, actionDto)
+}
+
+func (s *AdminService) CreateInviteCode(ctx context.Context, createInviteCodeDto dtos.CreateInviteCodeDto) (dtos.StructuredResponse, error) {
+	return s.inviteCodeRepo.CreateInviteCode(ctx, createInviteCodeDto)
+}
+
+func (s *AdminService) GetInviteCodes(ctx context.Context) (dtos.StructuredResponse, error) {
+	return s.inviteCodeRepo.GetInviteCodes(ctx)
+}
+
+func (s *AdminService) RevokeInviteCode(ctx context.Context, inviteCodeID uint) (dtos.StructuredResponse, error) {
+	return s.inviteCodeRepo.RevokeInviteCode(ctx, inviteCodeID)
+}
+
+func (s *AdminService) CreateOAuthClient(ctx context.Context, createClientDto dtos.CreateOAuthClientDto) (dtos.StructuredResponse, error) {
+	return s.oauthClientRepo.CreateClient(ctx, createClientDto)
+}
+
+func (s *AdminService) GetOAuthClients(ctx context.Context) (dtos.StructuredResponse, error) {
+	return s.oauthClientRepo.GetClients(ctx)
+}
+
+func (s *AdminService) DeleteOAuthClient(ctx context.Context, clientID uint) (dtos.StructuredResponse, error) {
+	return s.oauthClientRepo.DeleteClient(ctx, clientID)
+}
@@ -9,14 +9,28 @@ import (
 )
 
 type AuthService struct {
-	logger *zap.Logger
-	repo   *repositories.AuthRepository
+	logger                *zap.Logger
+	repo                  *repositories.AuthRepository
+	passwordResetRepo     *repositories.PasswordResetRepository
+	emailVerificationRepo *repositories.EmailVerificationRepository
+	accessTokenRepo       *repositories.PersonalAccessTokenRepository
+	twoFactorRepo         *repositories.TwoFactorRepository
+	oidcRepo              *repositories.OIDCRepository
+	magicLinkRepo         *repositories.MagicLinkRepository
+	sessionRepo           *repositories.SessionRepository
 }
 
 func NewAuthService(logger *zap.Logger) *AuthService {
 	return &AuthService{
-		logger: logger,
-		repo:   repositories.NewAuthRepository(logger),
+		logger:                logger,
+		repo:                  repositories.NewAuthRepository(logger),
+		passwordResetRepo:     repositories.NewPasswordResetRepository(logger),
+		emailVerificationRepo: repositories.NewEmailVerificationRepository(logger),
+		accessTokenRepo:       repositories.NewPersonalAccessTokenRepository(logger),
+		twoFactorRepo:         repositories.NewTwoFactorRepository(logger),
+		oidcRepo:              repositories.NewOIDCRepository(logger),
+		magicLinkRepo:         repositories.NewMagicLinkRepository(logger),
+		sessionRepo:           repositories.NewSessionRepository(logger),
 	}
 }
 
@@ -27,3 +41,91 @@ func (s *AuthService) RegisterUser(ctx context.Context, registerUserDto dtos.Reg
 func (s *AuthService) LoginUser(ctx context.Context, loginUserDto dtos.LoginUserDto) (dtos.StructuredResponse, error) {
 	return s.repo.LoginUser(ctx, loginUserDto)
 }
+
+func (s *AuthService) RefreshToken(ctx context.Context, refreshTokenDto dtos.RefreshTokenDto) (dtos.StructuredResponse, error) {
+	return s.repo.RefreshToken(ctx, refreshTokenDto)
+}
+
+func (s *AuthService) Logout(ctx context.Context, logoutDto dtos.LogoutDto) (dtos.StructuredResponse, error) {
+	return s.repo.Logout(ctx, logoutDto)
+}
+
+func (s *AuthService) LogoutAll(ctx context.Context) (dtos.StructuredResponse, error) {
+	return s.repo.LogoutAll(ctx)
+}
+
+func (s *AuthService) ForgotPassword(ctx context.Context, forgotPasswordDto dtos.ForgotPasswordDto) (dtos.StructuredResponse, error) {
+	return s.passwordResetRepo.ForgotPassword(ctx, forgotPasswordDto)
+}
+
+func (s *AuthService) ResetPassword(ctx context.Context, resetPasswordDto dtos.ResetPasswordDto) (dtos.StructuredResponse, error) {
+	return s.passwordResetRepo.ResetPassword(ctx, resetPasswordDto)
+}
+
+func (s *AuthService) VerifyEmail(ctx context.Context, verifyEmailDto dtos.VerifyEmailDto) (dtos.StructuredResponse, error) {
+	return s.emailVerificationRepo.VerifyEmail(ctx, verifyEmailDto)
+}
+
+func (s *AuthService) ConfirmEmailChange(ctx context.Context, confirmEmailChangeDto dtos.ConfirmEmailChangeDto) (dtos.StructuredResponse, error) {
+	return s.emailVerificationRepo.ConfirmEmailChange(ctx, confirmEmailChangeDto)
+}
+
+func (s *AuthService) ResendVerification(ctx context.Context, resendVerificationDto dtos.ResendVerificationDto) (dtos.StructuredResponse, error) {
+	return s.emailVerificationRepo.ResendVerification(ctx, resendVerificationDto)
+}
+
+func (s *AuthService) CreatePersonalAccessToken(ctx context.Context, createTokenDto dtos.CreatePersonalAccessTokenDto) (dtos.StructuredResponse, error) {
+	return s.accessTokenRepo.CreateToken(ctx, createTokenDto)
+}
+
+func (s *AuthService) GetPersonalAccessTokens(ctx context.Context, userID uint) (dtos.StructuredResponse, error) {
+	return s.accessTokenRepo.GetTokens(ctx, userID)
+}
+
+func (s *AuthService) RevokePersonalAccessToken(ctx context.Context, revokeTokenDto dtos.RevokePersonalAccessTokenDto) (dtos.StructuredResponse, error) {
+	return s.accessTokenRepo.RevokeToken(ctx, revokeTokenDto)
+}
+
+func (s *AuthService) LoginWithTwoFactor(ctx context.Context, loginDto dtos.TwoFactorLoginDto) (dtos.StructuredResponse, error) {
+	return s.twoFactorRepo.CompleteLogin(ctx, loginDto)
+}
+
+func (s *AuthService) EnrollTwoFactor(ctx context.Context, userID uint) (dtos.StructuredResponse, error) {
+	return s.twoFactorRepo.Enroll(ctx, userID)
+}
+
+func (s *AuthService) ConfirmTwoFactor(ctx context.Context, codeDto dtos.TwoFactorCodeDto) (dtos.StructuredResponse, error) {
+	return s.twoFactorRepo.ConfirmEnrollment(ctx, codeDto)
+}
+
+func (s *AuthService) DisableTwoFactor(ctx context.Context, disableDto dtos.DisableTwoFactorDto) (dtos.StructuredResponse, error) {
+	return s.twoFactorRepo.Disable(ctx, disableDto)
+}
+
+func (s *AuthService) StartOIDCLogin(ctx context.Context) (dtos.StructuredResponse, error) {
+	return s.oidcRepo.StartLogin(ctx)
+}
+
+func (s *AuthService) CompleteOIDCLogin(ctx context.Context, callbackDto dtos.OIDCCallbackDto) (dtos.StructuredResponse, error) {
+	return s.oidcRepo.CompleteLogin(ctx, callbackDto)
+}
+
+func (s *AuthService) RequestMagicLink(ctx context.Context, requestMagicLinkDto dtos.RequestMagicLinkDto) (dtos.StructuredResponse, error) {
+	return s.magicLinkRepo.RequestLink(ctx, requestMagicLinkDto)
+}
+
+func (s *AuthService) LoginWithMagicLink(ctx context.Context, magicLinkLoginDto dtos.MagicLinkLoginDto) (dtos.StructuredResponse, error) {
+	return s.magicLinkRepo.Login(ctx, magicLinkLoginDto)
+}
+
+func (s *AuthService) GetSessions(ctx context.Context, userID uint, currentSessionID uint) (dtos.StructuredResponse, error) {
+	return s.sessionRepo.GetSessions(ctx, userID, currentSessionID)
+}
+
+func (s *AuthService) RevokeSession(ctx context.Context, revokeSessionDto dtos.RevokeSessionDto) (dtos.StructuredResponse, error) {
+	return s.sessionRepo.RevokeSession(ctx, revokeSessionDto)
+}
+
+func (s *AuthService) RevokeOtherSessions(ctx context.Context, revokeOtherSessionsDto dtos.RevokeOtherSessionsDto) (dtos.StructuredResponse, error) {
+	return s.sessionRepo.RevokeOtherSessions(ctx, revokeOtherSessionsDto)
+}
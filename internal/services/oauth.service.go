@@ -0,0 +1,41 @@
+package services
+
+import (
+	"context"
+	"todo-api/internal/dtos"
+	"todo-api/internal/repositories"
+
+	"go.uber.org/zap"
+)
+
+type OAuthService struct {
+	logger    *zap.Logger
+	oauthRepo *repositories.OAuthRepository
+}
+
+func NewOAuthService(logger *zap.Logger) *OAuthService {
+	return &OAuthService{
+		logger:    logger,
+		oauthRepo: repositories.NewOAuthRepository(logger),
+	}
+}
+
+func (s *OAuthService) GetAuthorization(ctx context.Context, authorizationDto dtos.OAuthAuthorizationRequestDto) (dtos.StructuredResponse, error) {
+	return s.oauthRepo.GetAuthorization(ctx, authorizationDto)
+}
+
+func (s *OAuthService) Authorize(ctx context.Context, authorizationDto dtos.OAuthAuthorizationRequestDto) (dtos.StructuredResponse, error) {
+	return s.oauthRepo.Authorize(ctx, authorizationDto)
+}
+
+func (s *OAuthService) Token(ctx context.Context, tokenDto dtos.OAuthTokenRequestDto) (dtos.StructuredResponse, error) {
+	return s.oauthRepo.Token(ctx, tokenDto)
+}
+
+func (s *OAuthService) Introspect(ctx context.Context, referenceDto dtos.OAuthTokenReferenceDto) (dtos.StructuredResponse, error) {
+	return s.oauthRepo.Introspect(ctx, referenceDto)
+}
+
+func (s *OAuthService) Revoke(ctx context.Context, referenceDto dtos.OAuthTokenReferenceDto) (dtos.StructuredResponse, error) {
+	return s.oauthRepo.Revoke(ctx, referenceDto)
+}
@@ -31,6 +31,18 @@ func (s *TodoService) GetTodoItems(ctx context.Context) (dtos.StructuredResponse
 	return response, nil
 }
 
+func (s *TodoService) ListTodoItems(ctx context.Context, listTodoItemsDto dtos.ListTodoItemsDto) (dtos.StructuredResponse, error) {
+	return s.todoRepository.ListTodoItems(ctx, listTodoItemsDto)
+}
+
+func (s *TodoService) SearchTodoItems(ctx context.Context, searchTodoItemsDto dtos.SearchTodoItemsDto) (dtos.StructuredResponse, error) {
+	return s.todoRepository.SearchTodoItems(ctx, searchTodoItemsDto)
+}
+
+func (s *TodoService) GetTodoItem(ctx context.Context, id uint) (dtos.StructuredResponse, error) {
+	return s.todoRepository.GetTodoItem(ctx, id)
+}
+
 func (s *TodoService) CreateTodoItem(ctx context.Context, todoItem dtos.CreateTodoItemDto) (dtos.StructuredResponse, error) {
 
 	return s.todoRepository.CreateTodoItem(ctx, todoItem)
@@ -40,10 +52,18 @@ func (s *TodoService) CreateTodoNote(ctx context.Context, todoNoteDto dtos.Creat
 	return s.todoRepository.CreateTodoNote(ctx, todoNoteDto)
 }
 
+func (s *TodoService) GetTodoNotes(ctx context.Context, todoItemID uint) (dtos.StructuredResponse, error) {
+	return s.todoRepository.GetTodoNotes(ctx, todoItemID)
+}
+
 func (s *TodoService) UpdateTodoItem(ctx context.Context, todoItemDto dtos.UpdateTodoItemDto) (dtos.StructuredResponse, error) {
 	return s.todoRepository.UpdateTodoItem(ctx, todoItemDto)
 }
 
+func (s *TodoService) PatchTodoItem(ctx context.Context, todoItemDto dtos.PatchTodoItemDto) (dtos.StructuredResponse, error) {
+	return s.todoRepository.PatchTodoItem(ctx, todoItemDto)
+}
+
 func (s *TodoService) DeleteTodoItem(ctx context.Context, todoItemDto dtos.DeleteTodoItemDto) (dtos.StructuredResponse, error) {
 	return s.todoRepository.DeleteTodoItem(ctx, todoItemDto)
 }
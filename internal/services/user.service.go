@@ -0,0 +1,47 @@
+package services
+
+import (
+	"context"
+	"todo-api/internal/dtos"
+	"todo-api/internal/repositories"
+
+	"go.uber.org/zap"
+)
+
+type UserService struct {
+	logger            *zap.Logger
+	userRepo          *repositories.UserRepository
+	securityEventRepo *repositories.SecurityEventRepository
+}
+
+func NewUserService(logger *zap.Logger) *UserService {
+	return &UserService{
+		logger:            logger,
+		userRepo:          repositories.NewUserRepository(logger),
+		securityEventRepo: repositories.NewSecurityEventRepository(logger),
+	}
+}
+
+func (s *UserService) GetProfile(ctx context.Context, userID uint) (dtos.StructuredResponse, error) {
+	return s.userRepo.GetProfile(ctx, userID)
+}
+
+func (s *UserService) UpdateProfile(ctx context.Context, updateProfileDto dtos.UpdateProfileDto) (dtos.StructuredResponse, error) {
+	return s.userRepo.UpdateProfile(ctx, updateProfileDto)
+}
+
+func (s *UserService) ChangePassword(ctx context.Context, changePasswordDto dtos.ChangePasswordDto) (dtos.StructuredResponse, error) {
+	return s.userRepo.ChangePassword(ctx, changePasswordDto)
+}
+
+func (s *UserService) ChangeEmail(ctx context.Context, changeEmailDto dtos.ChangeEmailDto) (dtos.StructuredResponse, error) {
+	return s.userRepo.ChangeEmail(ctx, changeEmailDto)
+}
+
+func (s *UserService) DeleteAccount(ctx context.Context, deleteAccountDto dtos.DeleteAccountDto) (dtos.StructuredResponse, error) {
+	return s.userRepo.DeleteAccount(ctx, deleteAccountDto)
+}
+
+func (s *UserService) GetSecurityEvents(ctx context.Context, listSecurityEventsDto dtos.ListSecurityEventsDto) (dtos.StructuredResponse, error) {
+	return s.securityEventRepo.ListEvents(ctx, listSecurityEventsDto)
+}
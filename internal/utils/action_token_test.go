@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"testing"
+	"time"
+	"todo-api/config"
+	"todo-api/internal/models"
+)
+
+// useJWTSecret sets JWT_SECRET for the test and restores it afterwards
+func useJWTSecret(t *testing.T, secret string) {
+	t.Helper()
+
+	previous := config.GetConfig().JWTSecret
+	config.GetConfig().JWTSecret = secret
+	t.Cleanup(func() {
+		config.GetConfig().JWTSecret = previous
+	})
+}
+
+func TestActionToken(t *testing.T) {
+	useJWTSecret(t, "action-token-test-secret")
+	user := models.User{ID: 7, Email: "alice@example.com"}
+
+	token, err := GenerateActionToken(PurposeEmailVerification, user, time.Hour)
+	if err != nil {
+		t.Fatalf("GenerateActionToken failed: %v", err)
+	}
+
+	claims, err := ValidateActionToken(token, PurposeEmailVerification)
+	if err != nil {
+		t.Fatalf("ValidateActionToken failed: %v", err)
+	}
+	if claims.UserID != user.ID || claims.Email != user.Email {
+		t.Errorf("claims = %d %s, want %d %s", claims.UserID, claims.Email, user.ID, user.Email)
+	}
+
+	t.Run("other purpose", func(t *testing.T) {
+		if _, err := ValidateActionToken(token, "other-purpose"); err == nil {
+			t.Error("token was accepted for another purpose")
+		}
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		expired, err := GenerateActionToken(PurposeEmailVerification, user, -time.Minute)
+		if err != nil {
+			t.Fatalf("GenerateActionToken failed: %v", err)
+		}
+		if _, err := ValidateActionToken(expired, PurposeEmailVerification); err == nil {
+			t.Error("expired token was accepted")
+		}
+	})
+
+	t.Run("other secret", func(t *testing.T) {
+		useJWTSecret(t, "another-test-secret")
+		if _, err := ValidateActionToken(token, PurposeEmailVerification); err == nil {
+			t.Error("token signed with another secret was accepted")
+		}
+	})
+
+	t.Run("tampered", func(t *testing.T) {
+		if _, err := ValidateActionToken(token+"x", PurposeEmailVerification); err == nil {
+			t.Error("tampered token was accepted")
+		}
+	})
+}
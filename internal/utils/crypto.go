@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"todo-api/config"
+)
+
+// EncryptSecret encrypts a value that has to be readable again later, such as a TOTP secret,
+// with AES-256-GCM under a key derived from ENCRYPTION_KEY
+func EncryptSecret(plaintext string) (string, error) {
+	gcm, err := newSecretCipher()
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	ciphertext := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+// DecryptSecret reverses EncryptSecret
+func DecryptSecret(encoded string) (string, error) {
+	gcm, err := newSecretCipher()
+	if err != nil {
+		return "", err
+	}
+
+	ciphertext, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", err
+	}
+
+	if len(ciphertext) < gcm.NonceSize() {
+		return "", errors.New("ciphertext too short")
+	}
+
+	nonce, sealed := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return string(plaintext), nil
+}
+
+func newSecretCipher() (cipher.AEAD, error) {
+	encryptionKey := config.GetConfig().EncryptionKey
+	if config.InsecureSecret(encryptionKey) {
+		return nil, errors.New("encryption key is not configured")
+	}
+
+	key := sha256.Sum256([]byte(encryptionKey))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"testing"
+	"todo-api/config"
+)
+
+// useEncryptionKey sets ENCRYPTION_KEY for the test and restores it afterwards
+func useEncryptionKey(t *testing.T, key string) {
+	t.Helper()
+
+	previous := config.GetConfig().EncryptionKey
+	config.GetConfig().EncryptionKey = key
+	t.Cleanup(func() {
+		config.GetConfig().EncryptionKey = previous
+	})
+}
+
+func TestEncryptSecret(t *testing.T) {
+	useEncryptionKey(t, "crypto-test-key")
+
+	encrypted, err := EncryptSecret("JBSWY3DPEHPK3PXP")
+	if err != nil {
+		t.Fatalf("EncryptSecret failed: %v", err)
+	}
+	if decrypted, err := DecryptSecret(encrypted); err != nil || decrypted != "JBSWY3DPEHPK3PXP" {
+		t.Fatalf("DecryptSecret = %q, %v, want the plaintext", decrypted, err)
+	}
+
+	// Every encryption uses a fresh nonce
+	if again, _ := EncryptSecret("JBSWY3DPEHPK3PXP"); again == encrypted {
+		t.Error("encrypting twice gave the same ciphertext")
+	}
+
+	t.Run("other key", func(t *testing.T) {
+		useEncryptionKey(t, "another-test-key")
+		if _, err := DecryptSecret(encrypted); err == nil {
+			t.Error("secret was decrypted with another key")
+		}
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		if _, err := DecryptSecret(encrypted[:8]); err == nil {
+			t.Error("truncated ciphertext was decrypted")
+		}
+	})
+}
@@ -0,0 +1,9 @@
+package utils
+
+import "strings"
+
+// NormalizeEmail returns the form emails are stored and looked up in. Addresses are
+// compared case-insensitively, so two accounts can never differ only in case.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"time"
 	"todo-api/config"
 	"todo-api/internal/models"
@@ -11,31 +12,98 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
-// JWTClaims represents the claims in the JWT
+// JWTClaims represents the claims in the JWT.
+// RegisteredClaims.ID holds the jti, which is what logout revokes.
 type JWTClaims struct {
 	UserID uint   `json:"userId"`
 	Email  string `json:"email"`
 	Name   string `json:"name"`
+	// ReadOnly tokens are issued to users who have not verified their email yet
+	ReadOnly bool `json:"readOnly,omitempty"`
+	// Roles and the permissions they grant at the time the token was issued
+	Roles       []string `json:"roles"`
+	Permissions []string `json:"permissions"`
+	// TokenType is empty for regular access tokens, TokenTypePersonalAccessToken when the
+	// request was authenticated with an API key, TokenTypeOAuth for tokens issued to
+	// third-party apps and TokenTypeImpersonation for impersonation tokens
+	TokenType string `json:"tokenType,omitempty"`
+	// ClientID is the OAuth2 app the token was issued to, only set for TokenTypeOAuth
+	ClientID string `json:"clientId,omitempty"`
+	// SessionID is the login session the token belongs to, zero for personal access tokens
+	SessionID uint `json:"sid,omitempty"`
+	// Actor is the administrator acting as the user, only set on impersonation tokens
+	Actor *ActorClaim `json:"act,omitempty"`
 	jwt.RegisteredClaims
 }
 
-// GenerateToken creates a new JWT token for a user
-func GenerateToken(user models.User) (string, error) {
-	// Get JWT secret from config
-	jwtSecret := config.GetConfig().JWTSecret
-	if jwtSecret == "" {
-		return "", errors.New("JWT secret is not configured")
+// ActorClaim identifies who is really making the requests of an impersonation token,
+// like the "act" claim of RFC 8693
+type ActorClaim struct {
+	UserID uint   `json:"userId"`
+	Email  string `json:"email"`
+}
+
+const (
+	// TokenTypePersonalAccessToken marks claims built from a personal access token
+	TokenTypePersonalAccessToken = "pat"
+	// TokenTypeImpersonation marks tokens an administrator got to act as the user
+	TokenTypeImpersonation = "impersonation"
+	// TokenTypeOAuth marks claims built from an access token of the OAuth2 authorization server
+	TokenTypeOAuth = "oauth"
+)
+
+// HasRole reports whether the token carries the role
+func (c *JWTClaims) HasRole(role string) bool {
+	return slices.Contains(c.Roles, role)
+}
+
+// HasPermission reports whether the token carries the permission
+func (c *JWTClaims) HasPermission(permission string) bool {
+	return slices.Contains(c.Permissions, permission)
+}
+
+// GenerateToken creates a new JWT token for a user within a login session.
+// The user's Roles and their Permissions must be preloaded.
+func GenerateToken(user models.User, sessionID uint) (string, error) {
+	// Access tokens are short lived, clients renew them with a refresh token
+	return signUserToken(user, sessionID, config.GetConfig().Auth.AccessTokenTTL, nil)
+}
+
+// GenerateImpersonationToken creates a token that lets an administrator act as the user.
+// It carries the user's roles and permissions, names the administrator in its act claim
+// and belongs to no session, so it cannot be refreshed.
+func GenerateImpersonationToken(user models.User, actor ActorClaim) (string, error) {
+	return signUserToken(user, 0, config.GetConfig().Auth.ImpersonationTTL, &actor)
+}
+
+func signUserToken(user models.User, sessionID uint, ttl time.Duration, actor *ActorClaim) (string, error) {
+	// The keyring decides which key and algorithm sign the token
+	keys, err := GetKeyring()
+	if err != nil {
+		return "", err
 	}
 
-	// Set token expiration time (e.g., 24 hours)
-	expirationTime := time.Now().Add(24 * time.Hour)
+	expirationTime := time.Now().Add(ttl)
+
+	// Every token gets a unique ID so it can be revoked individually
+	tokenID, err := GenerateRandomToken(16)
+	if err != nil {
+		return "", err
+	}
 
 	// Create claims with user information
 	claims := &JWTClaims{
 		UserID: user.ID,
 		Email:  user.Email,
 		Name:   user.Name,
+		// Blocked logins never reach this point, so an unverified user here is in restricted mode
+		ReadOnly:    user.EmailVerifiedAt == nil,
+		Roles:       user.RoleNames(),
+		Permissions: user.PermissionNames(),
+		SessionID:   sessionID,
+		Actor:       actor,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
@@ -44,38 +112,24 @@ func GenerateToken(user models.User) (string, error) {
 		},
 	}
 
-	// Create token with claims
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	// Sign the token with the secret key
-	tokenString, err := token.SignedString([]byte(jwtSecret))
-	if err != nil {
-		return "", err
+	if actor != nil {
+		claims.TokenType = TokenTypeImpersonation
 	}
 
-	return tokenString, nil
+	// Sign the token with the active key
+	return keys.Sign(claims)
 }
 
-// ValidateToken validates a JWT token and returns the claims
+// ValidateToken validates a JWT token and returns the claims.
+// The kid header selects the key, so tokens signed by retired keys stay valid until they expire.
 func ValidateToken(tokenString string) (*JWTClaims, error) {
-	// Get JWT secret from config
-	jwtSecret := config.GetConfig().JWTSecret
-	if jwtSecret == "" {
-		return nil, errors.New("JWT secret is not configured")
+	keys, err := GetKeyring()
+	if err != nil {
+		return nil, err
 	}
 
 	// Parse the token
-	token, err := jwt.ParseWithClaims(
-		tokenString,
-		&JWTClaims{},
-		func(token *jwt.Token) (interface{}, error) {
-			// Validate the signing method
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			return []byte(jwtSecret), nil
-		},
-	)
+	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, keys.Keyfunc)
 
 	if err != nil {
 		return nil, err
@@ -92,7 +146,11 @@ func ValidateToken(tokenString string) (*JWTClaims, error) {
 // Context key for user ID
 type contextKey string
 
-const userIDKey contextKey = "userID"
+const (
+	userIDKey     contextKey = "userID"
+	claimsKey     contextKey = "claims"
+	clientInfoKey contextKey = "clientInfo"
+)
 
 // SetUserIDInContext adds the user ID to the context
 func SetUserIDInContext(ctx context.Context, userID uint) context.Context {
@@ -107,3 +165,17 @@ func GetUserIDFromContext(ctx context.Context) (uint, error) {
 	}
 	return userID, nil
 }
+
+// SetClaimsInContext adds the validated token claims to the context
+func SetClaimsInContext(ctx context.Context, claims *JWTClaims) context.Context {
+	return context.WithValue(ctx, claimsKey, claims)
+}
+
+// GetClaimsFromContext retrieves the validated token claims from the context
+func GetClaimsFromContext(ctx context.Context) (*JWTClaims, error) {
+	claims, ok := ctx.Value(claimsKey).(*JWTClaims)
+	if !ok {
+		return nil, errors.New("token claims not found in context")
+	}
+	return claims, nil
+}
@@ -0,0 +1,272 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"todo-api/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// signingKey is one entry of the keyring. Retired keys and public-only keys have no signer.
+type signingKey struct {
+	id     string
+	method jwt.SigningMethod
+	// verify is the HMAC secret or the public key
+	verify interface{}
+	// sign is nil for keys that may only verify
+	sign interface{}
+}
+
+// Keyring holds the key that signs access tokens and every key that may still verify them
+type Keyring struct {
+	active *signingKey
+	keys   map[string]*signingKey
+}
+
+// JWK is a public key in JSON Web Key format
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+	// RSA modulus and exponent
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Ed25519 curve and public key
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+}
+
+// JWKSet is the document served at /.well-known/jwks.json
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+var (
+	keyring     *Keyring
+	keyringErr  error
+	keyringOnce sync.Once
+)
+
+// GetKeyring returns the keyring built from the configuration, loading it on first use
+func GetKeyring() (*Keyring, error) {
+	keyringOnce.Do(func() {
+		keyring, keyringErr = LoadKeyring(config.GetConfig())
+	})
+	return keyring, keyringErr
+}
+
+// LoadKeyring builds a keyring from JWT_SECRET, JWT_PREVIOUS_SECRETS and the keys in JWT_KEYS_DIR.
+// JWT_SECRET must be set and not a sample value, whichever key signs.
+func LoadKeyring(cfg *config.Config) (*Keyring, error) {
+	ring := &Keyring{keys: make(map[string]*signingKey)}
+
+	// Emailed links and 2FA challenges are keyed from the secret even when a PEM key signs access tokens
+	if config.InsecureSecret(cfg.JWTSecret) {
+		return nil, errors.New("JWT_SECRET must be set to a secret of your own")
+	}
+	for _, secret := range cfg.JWT.PreviousSecrets {
+		if config.InsecureSecret(secret) {
+			return nil, errors.New("JWT_PREVIOUS_SECRETS cannot contain a sample secret")
+		}
+		ring.add(newHMACKey(secret, false))
+	}
+
+	if cfg.JWT.KeysDir != "" {
+		paths, err := filepath.Glob(filepath.Join(cfg.JWT.KeysDir, "*.pem"))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, path := range paths {
+			key, err := loadPEMKey(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load JWT key %s: %w", path, err)
+			}
+			if _, exists := ring.keys[key.id]; exists {
+				return nil, fmt.Errorf("duplicate JWT key ID %q", key.id)
+			}
+			ring.add(key)
+		}
+	}
+
+	if activeID := cfg.JWT.ActiveKeyID; activeID != "" {
+		key, ok := ring.keys[activeID]
+		if !ok {
+			return nil, fmt.Errorf("JWT_ACTIVE_KID %q not found in JWT_KEYS_DIR", activeID)
+		}
+		if key.sign == nil {
+			return nil, fmt.Errorf("JWT key %q has no private key and cannot sign", activeID)
+		}
+		ring.active = key
+	} else {
+		// The secret is only an access token key while it signs, once a PEM key took over
+		// its tokens verify only when it is listed in JWT_PREVIOUS_SECRETS
+		ring.active = newHMACKey(cfg.JWTSecret, true)
+		ring.add(ring.active)
+	}
+
+	return ring, nil
+}
+
+func (k *Keyring) add(key *signingKey) {
+	k.keys[key.id] = key
+}
+
+// Sign signs the claims with the active key and sets the kid header
+func (k *Keyring) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.active.method, claims)
+	token.Header["kid"] = k.active.id
+	return token.SignedString(k.active.sign)
+}
+
+// Keyfunc resolves the verification key of a token from its kid header.
+// Tokens issued before key IDs existed are tried against every HMAC secret.
+func (k *Keyring) Keyfunc(token *jwt.Token) (interface{}, error) {
+	keyID, _ := token.Header["kid"].(string)
+
+	if keyID == "" {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("token has no key ID")
+		}
+
+		var secrets jwt.VerificationKeySet
+		for _, key := range k.keys {
+			if _, ok := key.method.(*jwt.SigningMethodHMAC); ok {
+				secrets.Keys = append(secrets.Keys, key.verify)
+			}
+		}
+		return secrets, nil
+	}
+
+	key, ok := k.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("unknown key ID %q", keyID)
+	}
+
+	// Never let the token pick the algorithm, or a public key could be used as an HMAC secret
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+
+	return key.verify, nil
+}
+
+// JWKS lists the public keys, HMAC secrets are never published
+func (k *Keyring) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+
+	for _, key := range k.keys {
+		switch publicKey := key.verify.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				KeyType:   "RSA",
+				KeyID:     key.id,
+				Algorithm: key.method.Alg(),
+				Use:       "sig",
+				N:         base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()),
+				E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				KeyType:   "OKP",
+				KeyID:     key.id,
+				Algorithm: key.method.Alg(),
+				Use:       "sig",
+				Curve:     "Ed25519",
+				X:         base64.RawURLEncoding.EncodeToString(publicKey),
+			})
+		}
+	}
+
+	sort.Slice(set.Keys, func(i, j int) bool {
+		return set.Keys[i].KeyID < set.Keys[j].KeyID
+	})
+
+	return set
+}
+
+func newHMACKey(secret string, canSign bool) *signingKey {
+	key := &signingKey{
+		id:     hmacKeyID(secret),
+		method: jwt.SigningMethodHS256,
+		verify: []byte(secret),
+	}
+	if canSign {
+		key.sign = []byte(secret)
+	}
+	return key
+}
+
+// hmacKeyID derives a stable key ID that does not reveal anything about the secret
+func hmacKeyID(secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("jwt-key-id"))
+	return "hs-" + hex.EncodeToString(mac.Sum(nil)[:8])
+}
+
+// loadPEMKey reads a PKCS#8, PKCS#1 or PKIX encoded RSA or Ed25519 key
+func loadPEMKey(path string) (*signingKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+
+	key := &signingKey{id: strings.TrimSuffix(filepath.Base(path), ".pem")}
+
+	var parsed interface{}
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if signer, ok := parsed.(crypto.Signer); ok {
+		key.sign = signer
+		parsed = signer.Public()
+	}
+
+	switch publicKey := parsed.(type) {
+	case *rsa.PublicKey:
+		if publicKey.N.BitLen() < 2048 {
+			return nil, errors.New("RSA keys must be at least 2048 bits")
+		}
+		key.method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		key.method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", parsed)
+	}
+	key.verify = parsed
+
+	return key, nil
+}
@@ -0,0 +1,186 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"todo-api/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// writePEM stores a key in dir under the key ID, private keys as PKCS#8 and public keys as PKIX
+func writePEM(t *testing.T, dir string, keyID string, key interface{}) {
+	t.Helper()
+
+	var block *pem.Block
+	switch key.(type) {
+	case *rsa.PublicKey, ed25519.PublicKey:
+		der, err := x509.MarshalPKIXPublicKey(key)
+		if err != nil {
+			t.Fatalf("failed to encode public key: %v", err)
+		}
+		block = &pem.Block{Type: "PUBLIC KEY", Bytes: der}
+	default:
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			t.Fatalf("failed to encode private key: %v", err)
+		}
+		block = &pem.Block{Type: "PRIVATE KEY", Bytes: der}
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, keyID+".pem"), pem.EncodeToMemory(block), 0o600); err != nil {
+		t.Fatalf("failed to write key: %v", err)
+	}
+}
+
+func TestKeyring(t *testing.T) {
+	dir := t.TempDir()
+
+	_, activeKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate Ed25519 key: %v", err)
+	}
+	writePEM(t, dir, "ed-2025", activeKey)
+
+	// Only the public half of the retired key is kept, its tokens verify until they expire
+	retiredKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+	writePEM(t, dir, "rsa-2024", &retiredKey.PublicKey)
+
+	cfg := &config.Config{
+		JWTSecret: "current-secret",
+		JWT: config.JWTConfig{
+			KeysDir:         dir,
+			ActiveKeyID:     "ed-2025",
+			PreviousSecrets: []string{"previous-secret"},
+		},
+	}
+	ring, err := LoadKeyring(cfg)
+	if err != nil {
+		t.Fatalf("LoadKeyring failed: %v", err)
+	}
+
+	claims := jwt.RegisteredClaims{Subject: "1", ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute))}
+	parse := func(signed string) error {
+		_, err := jwt.ParseWithClaims(signed, &jwt.RegisteredClaims{}, ring.Keyfunc)
+		return err
+	}
+	sign := func(method jwt.SigningMethod, keyID string, key interface{}) string {
+		token := jwt.NewWithClaims(method, claims)
+		if keyID != "" {
+			token.Header["kid"] = keyID
+		}
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Fatalf("failed to sign token: %v", err)
+		}
+		return signed
+	}
+
+	t.Run("signs with the active key", func(t *testing.T) {
+		signed, err := ring.Sign(claims)
+		if err != nil {
+			t.Fatalf("Sign failed: %v", err)
+		}
+		token, _, err := jwt.NewParser().ParseUnverified(signed, &jwt.RegisteredClaims{})
+		if err != nil {
+			t.Fatalf("failed to read token: %v", err)
+		}
+		if token.Header["kid"] != "ed-2025" || token.Method.Alg() != "EdDSA" {
+			t.Errorf("token signed with %v %v, want ed-2025 EdDSA", token.Header["kid"], token.Method.Alg())
+		}
+		if err := parse(signed); err != nil {
+			t.Errorf("token of the active key was rejected: %v", err)
+		}
+	})
+
+	publicKeyDER, _ := x509.MarshalPKIXPublicKey(&retiredKey.PublicKey)
+	publicKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKeyDER})
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{"retired RSA key", sign(jwt.SigningMethodRS256, "rsa-2024", retiredKey), false},
+		// The secret still keys action tokens but is no access token key once a PEM key signs
+		{"JWT secret while a PEM key signs", sign(jwt.SigningMethodHS256, hmacKeyID("current-secret"), []byte("current-secret")), true},
+		{"JWT secret without key ID while a PEM key signs", sign(jwt.SigningMethodHS256, "", []byte("current-secret")), true},
+		{"previous secret", sign(jwt.SigningMethodHS256, hmacKeyID("previous-secret"), []byte("previous-secret")), false},
+		{"previous secret without key ID", sign(jwt.SigningMethodHS256, "", []byte("previous-secret")), false},
+		{"unknown secret without key ID", sign(jwt.SigningMethodHS256, "", []byte("guessed-secret")), true},
+		{"unknown key ID", sign(jwt.SigningMethodHS256, "hs-0000000000000000", []byte("previous-secret")), true},
+		{"secret under the key ID of another secret", sign(jwt.SigningMethodHS256, hmacKeyID("current-secret"), []byte("previous-secret")), true},
+		{"public key used as HMAC secret", sign(jwt.SigningMethodHS256, "rsa-2024", publicKeyPEM), true},
+		{"asymmetric token without key ID", sign(jwt.SigningMethodEdDSA, "", activeKey), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := parse(tt.token); (err != nil) != tt.wantErr {
+				t.Errorf("parse error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("publishes only public keys", func(t *testing.T) {
+		keys := ring.JWKS().Keys
+		if len(keys) != 2 || keys[0].KeyID != "ed-2025" || keys[1].KeyID != "rsa-2024" {
+			t.Errorf("JWKS = %+v, want ed-2025 and rsa-2024", keys)
+		}
+	})
+}
+
+func TestLoadKeyringRejectsActiveKey(t *testing.T) {
+	dir := t.TempDir()
+
+	publicKey, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate Ed25519 key: %v", err)
+	}
+	writePEM(t, dir, "public-only", publicKey)
+
+	for _, activeKeyID := range []string{"public-only", "missing"} {
+		cfg := &config.Config{JWTSecret: "current-secret", JWT: config.JWTConfig{KeysDir: dir, ActiveKeyID: activeKeyID}}
+		if _, err := LoadKeyring(cfg); err == nil {
+			t.Errorf("active key %q was accepted", activeKeyID)
+		}
+	}
+
+	// Without JWT_ACTIVE_KID the secret signs
+	ring, err := LoadKeyring(&config.Config{JWTSecret: "current-secret", JWT: config.JWTConfig{KeysDir: dir}})
+	if err != nil {
+		t.Fatalf("LoadKeyring failed: %v", err)
+	}
+	if ring.active.id != hmacKeyID("current-secret") {
+		t.Errorf("active key = %s, want the JWT secret", ring.active.id)
+	}
+}
+
+func TestLoadKeyringRejectsSampleSecrets(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  *config.Config
+	}{
+		{"no secret", &config.Config{}},
+		{"sample secret", &config.Config{JWTSecret: "your-256-bit-secret"}},
+		{"sample previous secret", &config.Config{JWTSecret: "current-secret", JWT: config.JWTConfig{PreviousSecrets: []string{"your-256-bit-secret"}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := LoadKeyring(tt.cfg); err == nil {
+				t.Error("LoadKeyring accepted the configuration")
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package utils
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"todo-api/config"
+)
+
+// ClientInfo describes the client that sent a request
+type ClientInfo struct {
+	IPAddress string
+	UserAgent string
+}
+
+// NewClientInfo reads the client IP address and user agent of a request
+func NewClientInfo(r *http.Request) ClientInfo {
+	return ClientInfo{
+		IPAddress: GetClientIP(r),
+		UserAgent: r.UserAgent(),
+	}
+}
+
+// SetClientInfoInContext adds the client of the request to the context
+func SetClientInfoInContext(ctx context.Context, client ClientInfo) context.Context {
+	return context.WithValue(ctx, clientInfoKey, client)
+}
+
+// GetClientInfoFromContext retrieves the client of the request, empty when it was not set
+func GetClientInfoFromContext(ctx context.Context) ClientInfo {
+	client, _ := ctx.Value(clientInfoKey).(ClientInfo)
+	return client
+}
+
+// GetClientIP returns the IP address of the client that sent the request.
+// Proxy headers are only honoured when TRUST_PROXY_HEADERS is enabled, otherwise
+// any client could spoof them to dodge per-IP limits.
+func GetClientIP(r *http.Request) string {
+	serverConfig := config.GetConfig().Server
+	if serverConfig.TrustProxyHeaders {
+		if forwardedFor := r.Header.Values("X-Forwarded-For"); len(forwardedFor) > 0 {
+			return forwardedClientIP(strings.Split(strings.Join(forwardedFor, ","), ","), serverConfig.TrustedProxyHops)
+		}
+		if realIP := r.Header.Get("X-Real-IP"); realIP != "" {
+			return strings.TrimSpace(realIP)
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// forwardedClientIP picks the client from the X-Forwarded-For entries. Each trusted proxy appends
+// the address it received the request from, so the client is the hops-th entry from the right.
+// Entries further left were sent by the client itself and could be anything.
+func forwardedClientIP(entries []string, hops int) string {
+	index := len(entries) - max(hops, 1)
+	// Fewer entries than proxies, the left-most was still added by one of them
+	if index < 0 {
+		index = 0
+	}
+	return strings.TrimSpace(entries[index])
+}
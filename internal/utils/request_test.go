@@ -0,0 +1,25 @@
+package utils
+
+import "testing"
+
+func TestForwardedClientIP(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []string
+		hops    int
+		want    string
+	}{
+		{"one proxy", []string{"203.0.113.7"}, 1, "203.0.113.7"},
+		{"spoofed entry is ignored", []string{"10.0.0.1", " 203.0.113.7"}, 1, "203.0.113.7"},
+		{"two proxies", []string{"10.0.0.1", "203.0.113.7", "198.51.100.2"}, 2, "203.0.113.7"},
+		{"fewer entries than proxies", []string{"203.0.113.7"}, 2, "203.0.113.7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := forwardedClientIP(tt.entries, tt.hops); got != tt.want {
+				t.Errorf("forwardedClientIP = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"todo-api/config"
+)
+
+// Cookies and headers of the cookie session mode
+const (
+	// HttpOnly cookie with the access token, sent to every API route
+	AccessTokenCookie = "access_token"
+	// HttpOnly cookie with the refresh token, only sent to the auth routes
+	RefreshTokenCookie = "refresh_token"
+	// Readable cookie with the CSRF token, so the web app can copy it into CSRFHeader after a reload
+	CSRFTokenCookie = "csrf_token"
+	CSRFHeader      = "X-CSRF-Token"
+	// Clients opt in to the cookie session mode by sending "cookie" in this header when logging in
+	SessionModeHeader = "X-Session-Mode"
+
+	accessTokenCookiePath  = "/api"
+	refreshTokenCookiePath = "/api/v1/auth"
+	csrfTokenCookiePath    = "/"
+)
+
+// UsesSessionCookies reports whether the client asked for its tokens as cookies and the mode is enabled
+func UsesSessionCookies(r *http.Request) bool {
+	return config.GetConfig().SessionCookie.Enabled && strings.EqualFold(r.Header.Get(SessionModeHeader), "cookie")
+}
+
+// SetSessionCookies stores a token pair and its CSRF token in the browser
+func SetSessionCookies(w http.ResponseWriter, accessToken string, refreshToken string, csrfToken string) {
+	authConfig := config.GetConfig().Auth
+
+	http.SetCookie(w, newSessionCookie(AccessTokenCookie, accessToken, accessTokenCookiePath, authConfig.AccessTokenTTL, true))
+	http.SetCookie(w, newSessionCookie(RefreshTokenCookie, refreshToken, refreshTokenCookiePath, authConfig.RefreshTokenTTL, true))
+	http.SetCookie(w, newSessionCookie(CSRFTokenCookie, csrfToken, csrfTokenCookiePath, authConfig.RefreshTokenTTL, false))
+}
+
+// ClearSessionCookies removes the cookies set by SetSessionCookies
+func ClearSessionCookies(w http.ResponseWriter) {
+	http.SetCookie(w, newSessionCookie(AccessTokenCookie, "", accessTokenCookiePath, -1, true))
+	http.SetCookie(w, newSessionCookie(RefreshTokenCookie, "", refreshTokenCookiePath, -1, true))
+	http.SetCookie(w, newSessionCookie(CSRFTokenCookie, "", csrfTokenCookiePath, -1, false))
+}
+
+// newSessionCookie applies the configured attributes, a negative lifetime deletes the cookie
+func newSessionCookie(name string, value string, path string, lifetime time.Duration, httpOnly bool) *http.Cookie {
+	cookieConfig := config.GetConfig().SessionCookie
+
+	cookie := &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		Domain:   cookieConfig.Domain,
+		MaxAge:   int(lifetime.Seconds()),
+		HttpOnly: httpOnly,
+		Secure:   cookieConfig.Secure,
+		SameSite: http.SameSiteLaxMode,
+	}
+	if lifetime < 0 {
+		cookie.MaxAge = -1
+	}
+
+	switch strings.ToLower(cookieConfig.SameSite) {
+	case "strict":
+		cookie.SameSite = http.SameSiteStrictMode
+	case "none":
+		// Browsers drop SameSite=None cookies that are not Secure
+		cookie.SameSite = http.SameSiteNoneMode
+		cookie.Secure = true
+	}
+
+	return cookie
+}
+
+// CSRFToken returns the CSRF token of a login session. It is derived from the session ID with
+// a key only the server knows, so it stays the same across refreshes and needs no storage, and
+// a token planted in the browser by another site does not match the session. Tokens without a
+// session, such as impersonation tokens, and servers without a key of their own get no CSRF
+// token, so writes with them are always refused.
+func CSRFToken(sessionID uint) string {
+	encryptionKey := config.GetConfig().EncryptionKey
+	if sessionID == 0 || config.InsecureSecret(encryptionKey) {
+		return ""
+	}
+
+	mac := hmac.New(sha256.New, []byte(encryptionKey))
+	mac.Write([]byte("csrf:" + strconv.FormatUint(uint64(sessionID), 10)))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// ValidCSRFToken compares a CSRF token sent by the client with the expected one in constant time
+func ValidCSRFToken(sent string, expected string) bool {
+	return sent != "" && expected != "" && subtle.ConstantTimeCompare([]byte(sent), []byte(expected)) == 1
+}
@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"net/http"
+	"testing"
+	"time"
+	"todo-api/config"
+)
+
+func TestCSRFToken(t *testing.T) {
+	useEncryptionKey(t, "csrf-test-key")
+
+	token := CSRFToken(7)
+	if token == "" || token != CSRFToken(7) {
+		t.Fatalf("CSRFToken(7) = %q, want the same non-empty token every time", token)
+	}
+	if CSRFToken(8) == token {
+		t.Error("two sessions share a CSRF token")
+	}
+	if CSRFToken(0) != "" {
+		t.Error("a token without a session got a CSRF token")
+	}
+
+	useEncryptionKey(t, "another-key")
+	if CSRFToken(7) == token {
+		t.Error("CSRF token does not depend on ENCRYPTION_KEY")
+	}
+
+	for _, key := range []string{"", "your-encryption-key"} {
+		useEncryptionKey(t, key)
+		if got := CSRFToken(7); got != "" {
+			t.Errorf("CSRFToken with ENCRYPTION_KEY %q = %q, want none", key, got)
+		}
+	}
+}
+
+func TestValidCSRFToken(t *testing.T) {
+	tests := []struct {
+		name     string
+		sent     string
+		expected string
+		want     bool
+	}{
+		{"matching", "abc", "abc", true},
+		{"different", "abd", "abc", false},
+		{"nothing sent", "", "abc", false},
+		{"nothing expected", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ValidCSRFToken(tt.sent, tt.expected); got != tt.want {
+				t.Errorf("ValidCSRFToken(%q, %q) = %v, want %v", tt.sent, tt.expected, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewSessionCookie(t *testing.T) {
+	cookieConfig := &config.GetConfig().SessionCookie
+	previous := *cookieConfig
+	t.Cleanup(func() { *cookieConfig = previous })
+
+	*cookieConfig = config.SessionCookieConfig{Secure: false, SameSite: "none", Domain: "example.com"}
+	cookie := newSessionCookie(AccessTokenCookie, "token", accessTokenCookiePath, time.Minute, true)
+	if cookie.SameSite != http.SameSiteNoneMode || !cookie.Secure {
+		t.Errorf("SameSite=none cookie: SameSite = %v, Secure = %v, want None and Secure", cookie.SameSite, cookie.Secure)
+	}
+	if cookie.MaxAge != 60 || cookie.Domain != "example.com" || !cookie.HttpOnly {
+		t.Errorf("cookie = %+v, want a 60 second HttpOnly cookie for example.com", cookie)
+	}
+
+	*cookieConfig = config.SessionCookieConfig{Secure: true, SameSite: "unknown"}
+	cookie = newSessionCookie(CSRFTokenCookie, "", csrfTokenCookiePath, -1, false)
+	if cookie.SameSite != http.SameSiteLaxMode {
+		t.Errorf("unknown SameSite = %v, want Lax", cookie.SameSite)
+	}
+	if cookie.MaxAge != -1 {
+		t.Errorf("deleted cookie MaxAge = %d, want -1", cookie.MaxAge)
+	}
+}
@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// PersonalAccessTokenPrefix marks personal access tokens so they can be told apart from JWTs
+// and picked up by secret scanners
+const PersonalAccessTokenPrefix = "tdp_"
+
+// GenerateRandomToken returns a URL-safe random string built from n random bytes
+func GenerateRandomToken(n int) (string, error) {
+	bytes := make([]byte, n)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(bytes), nil
+}
+
+// HashToken returns the hex encoded SHA-256 digest of an opaque token.
+// Only the digest is persisted so a database leak does not expose usable tokens.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// GeneratePersonalAccessToken returns a new personal access token in the form
+// tdp_<id>_<secret> together with its visible "tdp_<id>" prefix
+func GeneratePersonalAccessToken() (string, string, error) {
+	idBytes := make([]byte, 4)
+	if _, err := rand.Read(idBytes); err != nil {
+		return "", "", err
+	}
+	id := hex.EncodeToString(idBytes)
+
+	secret, err := GenerateRandomToken(32)
+	if err != nil {
+		return "", "", err
+	}
+
+	prefix := PersonalAccessTokenPrefix + id
+	return prefix + "_" + secret, prefix, nil
+}
+
+// InviteCodePrefix marks invite codes, like PersonalAccessTokenPrefix does for tokens
+const InviteCodePrefix = "inv_"
+
+// GenerateInviteCode returns a new invite code in the form inv_<id>_<secret> together with
+// its visible "inv_<id>" prefix. Codes are shorter than tokens since people type them in.
+func GenerateInviteCode() (string, string, error) {
+	idBytes := make([]byte, 4)
+	if _, err := rand.Read(idBytes); err != nil {
+		return "", "", err
+	}
+	id := hex.EncodeToString(idBytes)
+
+	secret, err := GenerateRandomToken(16)
+	if err != nil {
+		return "", "", err
+	}
+
+	prefix := InviteCodePrefix + id
+	return prefix + "_" + secret, prefix, nil
+}
+
+// Prefixes of the credentials of the OAuth2 authorization server. Access tokens are told apart
+// from JWTs and personal access tokens by theirs.
+const (
+	OAuthClientIDPrefix     = "tdc_"
+	OAuthClientSecretPrefix = "tds_"
+	OAuthAccessTokenPrefix  = "tdo_"
+	OAuthRefreshTokenPrefix = "tdr_"
+)
+
+// GeneratePrefixedToken returns a random token built from n random bytes behind the prefix
+func GeneratePrefixedToken(prefix string, n int) (string, error) {
+	token, err := GenerateRandomToken(n)
+	if err != nil {
+		return "", err
+	}
+	return prefix + token, nil
+}
+
+// VerifyPKCE reports whether the code verifier matches an S256 code challenge (RFC 7636)
+func VerifyPKCE(verifier string, challenge string) bool {
+	sum := sha256.Sum256([]byte(verifier))
+	return subtle.ConstantTimeCompare([]byte(base64.RawURLEncoding.EncodeToString(sum[:])), []byte(challenge)) == 1
+}
@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGeneratePersonalAccessToken(t *testing.T) {
+	token, prefix, err := GeneratePersonalAccessToken()
+	if err != nil {
+		t.Fatalf("GeneratePersonalAccessToken failed: %v", err)
+	}
+
+	if !strings.HasPrefix(prefix, PersonalAccessTokenPrefix) || len(prefix) != len(PersonalAccessTokenPrefix)+8 {
+		t.Errorf("prefix = %q, want %s followed by 8 hex digits", prefix, PersonalAccessTokenPrefix)
+	}
+	if !strings.HasPrefix(token, prefix+"_") {
+		t.Errorf("token %q does not start with its prefix %q", token, prefix)
+	}
+
+	other, _, err := GeneratePersonalAccessToken()
+	if err != nil {
+		t.Fatalf("GeneratePersonalAccessToken failed: %v", err)
+	}
+	if other == token {
+		t.Error("two tokens are equal")
+	}
+}
+
+func TestGenerateInviteCode(t *testing.T) {
+	code, prefix, err := GenerateInviteCode()
+	if err != nil {
+		t.Fatalf("GenerateInviteCode failed: %v", err)
+	}
+
+	// inv_ and 8 hex characters, followed by an underscore and the secret
+	if len(prefix) != len(InviteCodePrefix)+8 || !strings.HasPrefix(prefix, InviteCodePrefix) {
+		t.Errorf("prefix %q, want %s and 8 characters", prefix, InviteCodePrefix)
+	}
+	if !strings.HasPrefix(code, prefix+"_") || len(code) <= len(prefix)+1 {
+		t.Errorf("code %q does not continue its prefix %q with a secret", code, prefix)
+	}
+
+	if other, _, _ := GenerateInviteCode(); other == code {
+		t.Error("two invite codes are the same")
+	}
+}
+
+func TestVerifyPKCE(t *testing.T) {
+	// Example of RFC 7636 appendix B
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	challenge := "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+
+	if !VerifyPKCE(verifier, challenge) {
+		t.Error("verifier of the RFC example was rejected")
+	}
+	if VerifyPKCE(challenge, challenge) {
+		t.Error("the challenge itself was accepted as verifier, as with the plain method")
+	}
+	if VerifyPKCE("", challenge) {
+		t.Error("empty verifier was accepted")
+	}
+}
@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidateTOTP(t *testing.T) {
+	// Secret and SHA1 values of RFC 6238 appendix B, cut to six digits
+	secret := totpEncoding.EncodeToString([]byte("12345678901234567890"))
+
+	tests := []struct {
+		name     string
+		at       time.Time
+		code     string
+		wantStep int64
+		wantOK   bool
+	}{
+		{"current step", time.Unix(59, 0), "287082", 1, true},
+		{"current step", time.Unix(1111111109, 0), "081804", 37037036, true},
+		{"previous step within the skew", time.Unix(1111111109+totpPeriod, 0), "081804", 37037036, true},
+		{"two steps old", time.Unix(1111111109+2*totpPeriod, 0), "081804", 0, false},
+		{"wrong code", time.Unix(59, 0), "287083", 0, false},
+		{"too short", time.Unix(59, 0), "28708", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := ValidateTOTP(secret, tt.code, tt.at)
+			if ok != tt.wantOK || step != tt.wantStep {
+				t.Errorf("ValidateTOTP = %d, %v, want %d, %v", step, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
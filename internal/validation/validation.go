@@ -0,0 +1,126 @@
+// Package validation evaluates the binding tags of request DTOs.
+//
+// Supported rules, separated by commas:
+//
+//	required       the value is not empty (blank strings and empty slices are empty)
+//	eqfield=Other  the value equals the struct field Other
+//	email          the value is a plain email address
+//	min=N, max=N   the string has at least / at most N characters
+package validation
+
+import (
+	"fmt"
+	"net/mail"
+	"reflect"
+	"strconv"
+	"strings"
+	"todo-api/internal/dtos"
+	"unicode/utf8"
+)
+
+// Validate checks the binding tags of the fields of a struct or pointer to a struct and
+// returns one error per rejected field, named after its JSON key
+func Validate(dst interface{}) []dtos.FieldError {
+	value := reflect.ValueOf(dst)
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var fieldErrors []dtos.FieldError
+	structType := value.Type()
+
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		tag := field.Tag.Get("binding")
+		if tag == "" || !field.IsExported() {
+			continue
+		}
+
+		if message := checkField(value, value.Field(i), tag); message != "" {
+			fieldErrors = append(fieldErrors, dtos.FieldError{
+				Field:   jsonName(field),
+				Message: message,
+			})
+		}
+	}
+
+	return fieldErrors
+}
+
+// checkField applies the rules of a tag in order and returns the first failure
+func checkField(parent reflect.Value, fieldValue reflect.Value, tag string) string {
+	for fieldValue.Kind() == reflect.Pointer {
+		if fieldValue.IsNil() {
+			if strings.Contains(tag, "required") {
+				return "Is required"
+			}
+			return ""
+		}
+		fieldValue = fieldValue.Elem()
+	}
+
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+
+		switch name {
+		case "required":
+			if isEmpty(fieldValue) {
+				return "Is required"
+			}
+
+		case "eqfield":
+			other := parent.FieldByName(param)
+			if !other.IsValid() || !reflect.DeepEqual(fieldValue.Interface(), other.Interface()) {
+				otherField, _ := parent.Type().FieldByName(param)
+				return fmt.Sprintf("Must match %s", jsonName(otherField))
+			}
+
+		case "email":
+			text := strings.TrimSpace(fieldValue.String())
+			if address, err := mail.ParseAddress(text); text != "" && (err != nil || address.Address != text) {
+				return "Must be a valid email address"
+			}
+
+		case "min", "max":
+			limit, err := strconv.Atoi(param)
+			if err != nil || fieldValue.Kind() != reflect.String {
+				continue
+			}
+			length := utf8.RuneCountInString(fieldValue.String())
+			if name == "min" && length < limit {
+				return fmt.Sprintf("Must be at least %d characters", limit)
+			}
+			if name == "max" && length > limit {
+				return fmt.Sprintf("Must be at most %d characters", limit)
+			}
+		}
+	}
+
+	return ""
+}
+
+func isEmpty(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.String:
+		return strings.TrimSpace(value.String()) == ""
+	case reflect.Slice, reflect.Map:
+		return value.Len() == 0
+	default:
+		return value.IsZero()
+	}
+}
+
+// jsonName returns the key a field has in the request body
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}
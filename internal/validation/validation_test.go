@@ -0,0 +1,99 @@
+package validation
+
+import (
+	"reflect"
+	"testing"
+	"todo-api/internal/dtos"
+)
+
+type testDto struct {
+	Email           string   `json:"email" binding:"required,email"`
+	Password        string   `json:"password" binding:"required,min=8,max=12"`
+	ConfirmPassword string   `json:"confirmPassword" binding:"required,eqfield=Password"`
+	Title           *string  `json:"title,omitempty" binding:"max=5"`
+	Note            *string  `json:"note" binding:"required"`
+	Tags            []string `json:"tags" binding:"required"`
+	NoJSONName      string   `binding:"required"`
+	unexported      string   `binding:"required"`
+	Unchecked       string   `json:"unchecked"`
+}
+
+// validTestDto returns a DTO that passes every rule, each test case breaks one of them
+func validTestDto() testDto {
+	title := "short"
+	note := "note"
+	return testDto{
+		Email:           "alice@example.com",
+		Password:        "password",
+		ConfirmPassword: "password",
+		Title:           &title,
+		Note:            &note,
+		Tags:            []string{"work"},
+		NoJSONName:      "set",
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(dto *testDto)
+		want   []dtos.FieldError
+	}{
+		{"valid", func(dto *testDto) {}, nil},
+
+		{"required empty string", func(dto *testDto) { dto.Email = "" }, []dtos.FieldError{{Field: "email", Message: "Is required"}}},
+		{"required blank string", func(dto *testDto) { dto.Email = "   " }, []dtos.FieldError{{Field: "email", Message: "Is required"}}},
+		{"required nil pointer", func(dto *testDto) { dto.Note = nil }, []dtos.FieldError{{Field: "note", Message: "Is required"}}},
+		{"required empty slice", func(dto *testDto) { dto.Tags = []string{} }, []dtos.FieldError{{Field: "tags", Message: "Is required"}}},
+		{"required without json name", func(dto *testDto) { dto.NoJSONName = "" }, []dtos.FieldError{{Field: "NoJSONName", Message: "Is required"}}},
+
+		{"eqfield mismatch", func(dto *testDto) { dto.ConfirmPassword = "passwort" }, []dtos.FieldError{{Field: "confirmPassword", Message: "Must match password"}}},
+		{"eqfield is case sensitive", func(dto *testDto) { dto.ConfirmPassword = "PASSWORD" }, []dtos.FieldError{{Field: "confirmPassword", Message: "Must match password"}}},
+
+		{"email without at", func(dto *testDto) { dto.Email = "alice.example.com" }, []dtos.FieldError{{Field: "email", Message: "Must be a valid email address"}}},
+		{"email with display name", func(dto *testDto) { dto.Email = "Alice <alice@example.com>" }, []dtos.FieldError{{Field: "email", Message: "Must be a valid email address"}}},
+		{"email with surrounding spaces", func(dto *testDto) { dto.Email = " alice@example.com " }, nil},
+
+		{"min", func(dto *testDto) { dto.Password, dto.ConfirmPassword = "1234567", "1234567" }, []dtos.FieldError{{Field: "password", Message: "Must be at least 8 characters"}}},
+		{"min counts characters, not bytes", func(dto *testDto) { dto.Password, dto.ConfirmPassword = "ääääääää", "ääääääää" }, nil},
+		{"max", func(dto *testDto) { dto.Password, dto.ConfirmPassword = "1234567890123", "1234567890123" }, []dtos.FieldError{{Field: "password", Message: "Must be at most 12 characters"}}},
+		{"max on a pointer", func(dto *testDto) { title := "too long"; dto.Title = &title }, []dtos.FieldError{{Field: "title", Message: "Must be at most 5 characters"}}},
+		{"optional nil pointer", func(dto *testDto) { dto.Title = nil }, nil},
+
+		{"unexported and untagged fields are skipped", func(dto *testDto) { dto.unexported, dto.Unchecked = "", "" }, nil},
+		{"first failing rule of a field only", func(dto *testDto) { dto.Password = "" }, []dtos.FieldError{
+			{Field: "password", Message: "Is required"},
+			{Field: "confirmPassword", Message: "Must match password"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dto := validTestDto()
+			tt.modify(&dto)
+
+			if got := Validate(&dto); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateNonStructs(t *testing.T) {
+	var nilDto *testDto
+
+	for name, dst := range map[string]interface{}{
+		"nil pointer": nilDto,
+		"string":      "not a struct",
+		"map":         map[string]string{"email": ""},
+	} {
+		if got := Validate(dst); got != nil {
+			t.Errorf("%s: Validate() = %+v, want nothing", name, got)
+		}
+	}
+
+	// A struct passed by value is checked like a pointer to it
+	if got := Validate(testDto{}); len(got) == 0 {
+		t.Error("Validate(struct value) found no errors in an empty DTO")
+	}
+}
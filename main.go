@@ -5,13 +5,20 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"slices"
 	"syscall"
 	"time"
+	// Time zones of users must load even where the host has no zoneinfo files
+	_ "time/tzdata"
 	"todo-api/api/routes"
 	"todo-api/config"
 	"todo-api/database"
 	_ "todo-api/docs"
+	"todo-api/internal/jobs"
 	"todo-api/internal/logger"
+	"todo-api/internal/password"
+	"todo-api/internal/repositories"
+	"todo-api/internal/utils"
 
 	"github.com/gorilla/mux"
 	"github.com/rs/cors"
@@ -52,6 +59,73 @@ func main() {
 	// Ensure logger syncs before program exits
 	defer zap.L().Sync()
 
+	// Fail fast on a broken signing key configuration instead of on the first login
+	if _, err := utils.GetKeyring(); err != nil {
+		fmt.Printf("JWT keyring error: %v\n", err)
+		panic("failed to load JWT keys")
+	}
+
+	// TOTP secrets encrypted under a published key would be readable by anyone with a database dump,
+	// and anyone could compute the CSRF tokens of the cookie session mode
+	if config.InsecureSecret(cfg.EncryptionKey) {
+		fmt.Println("ENCRYPTION_KEY must be set to a secret of your own")
+		panic("invalid encryption key")
+	}
+
+	// argon2 would panic on the first login with zero threads or passes
+	if err := password.ValidateConfig(cfg.PasswordHashing); err != nil {
+		fmt.Printf("Password hashing error: %v\n", err)
+		panic("invalid password hashing configuration")
+	}
+
+	if _, err := password.GetPolicy(); err != nil {
+		fmt.Printf("Password policy error: %v\n", err)
+		panic("failed to load password policy")
+	}
+
+	// With credentials allowed a wildcard origin would let any site act with the session cookies
+	if cfg.SessionCookie.Enabled && slices.Contains(cfg.CORS.AllowedOrigins, "*") {
+		fmt.Println("CORS_ALLOWED_ORIGINS cannot contain * when SESSION_COOKIES_ENABLED is set")
+		panic("invalid CORS configuration")
+	}
+
+	// Without a proxy counted the client IP would be the address of the last proxy
+	if cfg.Server.TrustedProxyHops < 1 {
+		fmt.Println("TRUSTED_PROXY_HOPS must be at least 1")
+		panic("invalid trusted proxy hops")
+	}
+
+	// An unknown mode would otherwise leave registration in an unintended state
+	if !slices.Contains([]string{config.RegistrationOpen, config.RegistrationInvite, config.RegistrationClosed}, cfg.Registration.Mode) {
+		fmt.Printf("REGISTRATION_MODE must be open, invite or closed, got %q\n", cfg.Registration.Mode)
+		panic("invalid registration mode")
+	}
+
+	// A typo would otherwise silently let unverified users log in with restricted access
+	if !slices.Contains([]string{config.EmailVerificationBlock, config.EmailVerificationRestricted}, cfg.Auth.EmailVerificationMode) {
+		fmt.Printf("EMAIL_VERIFICATION_MODE must be block or restricted, got %q\n", cfg.Auth.EmailVerificationMode)
+		panic("invalid email verification mode")
+	}
+
+	// The routes cannot be removed before they were deprecated
+	if legacyRoutes := cfg.LegacyRoutes; !legacyRoutes.DeprecatedAt.IsZero() && !legacyRoutes.SunsetAt.IsZero() &&
+		!legacyRoutes.SunsetAt.After(legacyRoutes.DeprecatedAt) {
+		fmt.Println("LEGACY_TODO_ROUTES_SUNSET_AT must be after LEGACY_TODO_ROUTES_DEPRECATED_AT")
+		panic("invalid legacy route dates")
+	}
+
+	// Without a worker queued emails would never be sent
+	if jobsConfig := cfg.BackgroundJobs; jobsConfig.Workers < 1 || jobsConfig.QueueSize < 0 || jobsConfig.Timeout <= 0 {
+		fmt.Println("BACKGROUND_WORKERS and BACKGROUND_JOB_TIMEOUT must be positive and BACKGROUND_QUEUE_SIZE not negative")
+		panic("invalid background job configuration")
+	}
+
+	// A zero limit would make every page of GET /todos empty
+	if cfg.TodoList.DefaultPageSize < 1 || cfg.TodoList.MaxPageSize < cfg.TodoList.DefaultPageSize {
+		fmt.Println("TODO_DEFAULT_PAGE_SIZE must be at least 1 and at most TODO_MAX_PAGE_SIZE")
+		panic("invalid todo page size")
+	}
+
 	err := database.InitDatabase(&cfg.Database)
 
 	if err != nil {
@@ -65,6 +139,17 @@ func main() {
 		panic("failed to migrate database")
 	}
 
+	if err := repositories.NewRoleRepository(zap.L()).BootstrapAdmin(context.Background()); err != nil {
+		fmt.Printf("Bootstrap admin error: %v\n", err)
+		panic("failed to bootstrap admin")
+	}
+
+	// Background work stops with the server
+	background, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+
+	go repositories.NewRevocationRepository(zap.L()).RunPurge(background)
+
 	router := mux.NewRouter()
 
 	routes.SetupRoutes(router, zap.L())
@@ -75,12 +160,14 @@ func main() {
 		w.Write([]byte("Welcome to Go Boilerplate Beginner Project"))
 	}).Methods(http.MethodGet)
 
+	// Credentialed requests carry the session cookies, so only the configured origins may make them
 	corsMiddleware := cors.New(cors.Options{
-		AllowedOrigins:   []string{"*"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"*"},
+		AllowedOrigins:   cfg.CORS.AllowedOrigins,
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Authorization", "Content-Type", utils.CSRFHeader, utils.SessionModeHeader},
+		ExposedHeaders:   []string{"Retry-After"},
 		AllowCredentials: true,
-		MaxAge:           300,
+		MaxAge:           int(cfg.CORS.MaxAge.Seconds()),
 	})
 
 	server := &http.Server{
@@ -114,5 +201,10 @@ func main() {
 		fmt.Printf("Server forced to shutdown: %v\n", err)
 	}
 
+	// Emails queued by the last requests are still sent, as far as the deadline allows
+	if err := jobs.Default().Shutdown(ctx); err != nil {
+		fmt.Printf("Background jobs cancelled: %v\n", err)
+	}
+
 	fmt.Println("Server exited properly")
 }